@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Manage a long-running agent pod that tests can reuse",
+	Long: `Every test normally pays for scheduling a pod and pulling its image. An agent
+pod keeps the clients of all built-in testers running in the namespace, one
+container each; tests with --reuse run in it through exec and start at once.
+
+The agent stops after a day like every pocket pod; "agent start" replaces a
+stopped agent. Tests fall back to a temporary pod when there is no agent, when --image asks
+for a client the agent does not have, or when their pod needs more than the
+agent has, such as --password-from-secret or --ssh.
+
+Examples:
+  kubectl pocket agent start -n payments
+  kubectl pocket test postgres postgres://pg-svc:5432/mydb --reuse -n payments
+  kubectl pocket agent stop -n payments`,
+}
+
+var agentStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start the agent pod in the namespace",
+	Args:  cobra.NoArgs,
+	RunE:  runAgentStart,
+}
+
+var agentStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Delete the agent pod from the namespace",
+	Args:  cobra.NoArgs,
+	RunE:  runAgentStop,
+}
+
+func init() {
+	addPodFlags(agentStartCmd)
+	agentCmd.AddCommand(agentStartCmd, agentStopCmd)
+}
+
+// addReuseFlag registers --reuse on cmd
+func (o *testOptions) addReuseFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.reuse, "reuse", false,
+		"run the test in the namespace's agent pod (see 'kubectl pocket agent') instead of a new pod, if there is one")
+}
+
+// findAgent returns the namespace's running agent pod, or nil when there is
+// none and tests use temporary pods
+func findAgent(client *k8s.Client) (*corev1.Pod, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ns := client.Namespace
+	agent, err := client.Clientset.CoreV1().Pods(ns).Get(ctx, tester.AgentPodName, metav1.GetOptions{})
+	switch {
+	case err == nil && agent.Status.Phase == corev1.PodRunning && agent.DeletionTimestamp == nil:
+		return agent, nil
+	case err == nil || apierrors.IsNotFound(err):
+		i18n.Printf("💡 No running agent pod in %s, using a temporary pod (start one with 'kubectl pocket agent start')\n", ns)
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("failed to get agent pod: %w", err)
+	}
+}
+
+// printUsingAgent tells that a test runs in the agent pod
+func printUsingAgent(ns, podName string) {
+	i18n.Printf("♻️  Using agent pod: %s/%s\n", ns, podName)
+}
+
+func runAgentStart(cmd *cobra.Command, args []string) error {
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+	cfg, err := GetConfig()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	ns := client.Namespace
+	existing, err := client.Clientset.CoreV1().Pods(ns).Get(ctx, tester.AgentPodName, metav1.GetOptions{})
+	switch {
+	case err == nil && existing.Status.Phase == corev1.PodRunning:
+		i18n.Printf("✅ Agent pod %s/%s is already running\n", ns, tester.AgentPodName)
+		return nil
+	case err == nil && existing.Status.Phase == corev1.PodPending:
+		return fmt.Errorf("agent pod %s is still starting", tester.AgentPodName)
+	case err == nil:
+		// A finished agent, e.g. one past its deadline, is replaced
+		if err := client.DeletePod(ctx, ns, tester.AgentPodName); err != nil {
+			return fmt.Errorf("failed to delete finished agent pod: %w", err)
+		}
+		if err := client.WaitForPodDeleted(ctx, ns, tester.AgentPodName, time.Minute); err != nil {
+			return fmt.Errorf("failed to delete finished agent pod: %w", err)
+		}
+	case !apierrors.IsNotFound(err):
+		return fmt.Errorf("failed to get agent pod: %w", err)
+	}
+
+	var testers []tester.Tester
+	var names []string
+	for _, name := range tester.Names() {
+		t, _ := tester.Get(name)
+		if t.Image() == "" {
+			continue
+		}
+		testers = append(testers, t)
+		names = append(names, name)
+	}
+	config := tester.AgentPodConfig(ns, testers, func(t tester.Tester) string {
+		return resolveImage(cfg, t.Name(), t.Image())
+	})
+	config.Image = resolveImage(cfg, "agent", tester.AgentImage)
+	podOpts.applyConfig(cfg)
+	if err := podOpts.apply(&config); err != nil {
+		return err
+	}
+	for i := range config.Containers {
+		config.Containers[i].Resources = config.Resources
+	}
+
+	i18n.Printf("📦 Creating agent pod: %s/%s\n", ns, config.Name)
+	if _, err := client.CreatePod(ctx, config); err != nil {
+		return fmt.Errorf("failed to create agent pod: %w", err)
+	}
+
+	i18n.Printf("⏳ Waiting for pod to be ready...\n")
+	if err := client.WaitForPodRunning(ctx, ns, config.Name, 5*time.Minute); err != nil {
+		cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cleanupCancel()
+		_ = client.DeletePod(cleanupCtx, ns, config.Name)
+		return fmt.Errorf("agent pod failed to start: %w", err)
+	}
+
+	i18n.Printf("✅ Agent pod %s/%s is running with %s; pass --reuse to tests to use it\n", ns, config.Name, strings.Join(names, ", "))
+	return nil
+}
+
+func runAgentStop(cmd *cobra.Command, args []string) error {
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ns := client.Namespace
+	err = client.DeletePod(ctx, ns, tester.AgentPodName)
+	if apierrors.IsNotFound(err) {
+		i18n.Printf("💡 No agent pod in %s\n", ns)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete agent pod: %w", err)
+	}
+	i18n.Printf("🧹 Deleted agent pod %s/%s\n", ns, tester.AgentPodName)
+	return nil
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+)
+
+// showAPIUsage is --api-usage
+var showAPIUsage bool
+
+// reportAPIUsage prints the API requests the command made with
+// --api-usage, and warns when they exceed the configured budget
+func reportAPIUsage(w io.Writer) {
+	if k8sClient == nil || k8sClient.Usage == nil {
+		return
+	}
+	usage := k8sClient.Usage
+	total := usage.Total()
+
+	if showAPIUsage {
+		_, _ = fmt.Fprint(w, i18n.T("\n📊 API requests: %d (%d throttled)\n", total, usage.Throttled()))
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "VERB\tRESOURCE\tCOUNT")
+		for _, c := range usage.Counts() {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\n", c.Verb, c.Resource, c.Count)
+		}
+		_ = tw.Flush()
+	}
+
+	budget := 0
+	if cfg, err := GetConfig(); err == nil {
+		budget = cfg.RequestBudget()
+	}
+	if budget > 0 && total > budget {
+		_, _ = fmt.Fprint(w, i18n.T("⚠️  This command made %d API requests, over the budget of %d (apiBudget in the config file)\n", total, budget))
+	}
+	if throttled := usage.Throttled(); throttled > 0 && !showAPIUsage {
+		_, _ = fmt.Fprint(w, i18n.T("⚠️  The API server throttled %d request(s); run with --api-usage for details\n", throttled))
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// attachShell starts bash where the image has it
+const attachShell = `if command -v bash >/dev/null; then exec bash; fi; exec sh`
+
+var attachCmd = &cobra.Command{
+	Use:   "attach <pod> [-- <command>...]",
+	Short: "Open a shell in a test pod kept with --keep",
+	Long: `Open an interactive shell in a pod that a test left running with --keep, to
+poke around with the database client after the test. The shell can be
+reopened as often as needed; the pod keeps running when it exits.
+
+Kept pods carry the kubectl-pocket/kept=true label. Shell pods stop after
+their --keepalive and test pods after a day; remove them earlier with
+"kubectl pocket clean".
+
+Arguments after -- run as a command instead of a shell.
+
+Examples:
+  kubectl pocket test postgres postgres://pg-svc:5432/mydb --keep
+  kubectl pocket attach pocket-postgres-1717171717-x7k2p
+  kubectl pocket attach pocket-redis-1717171717-q4m8z -- redis-cli -h redis-svc ping`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runAttach,
+}
+
+// attachRecord records the attach session
+var attachRecord recordOptions
+
+func init() {
+	attachRecord.addFlags(attachCmd)
+}
+
+// addKeepFlag registers --keep on cmd
+func (o *testOptions) addKeepFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.keep, "keep", false, "leave the test pod running for 'kubectl pocket attach' instead of deleting it")
+}
+
+func runAttach(cmd *cobra.Command, args []string) error {
+	if err := attachRecord.validate(); err != nil {
+		return err
+	}
+	podName := args[0]
+	command := []string{"sh", "-c", attachShell}
+	if len(args) > 1 {
+		if cmd.ArgsLenAtDash() != 1 {
+			return fmt.Errorf("pass the command after --, e.g. attach %s -- %s", podName, args[1])
+		}
+		command = args[1:]
+	}
+
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+
+	ns := client.Namespace
+	pod, err := client.Clientset.CoreV1().Pods(ns).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get pod %s: %w", podName, err)
+	}
+	if pod.Labels[k8s.LabelManagedBy] != "kubectl-pocket" {
+		return fmt.Errorf("pod %s was not created by pocket; use 'kubectl pocket debug attach' for application pods", podName)
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return fmt.Errorf("pod %s is %s, not running", podName, pod.Status.Phase)
+	}
+
+	title := sessionTitle(client, ns, fmt.Sprintf("shell in pod %s", podName))
+	return recordSession(attachRecord, title, func(stdin io.Reader, stdout, stderr io.Writer) error {
+		tty := term.IsTerminal(int(os.Stdin.Fd()))
+		var resize k8s.TerminalSizeQueue
+		if tty {
+			i18n.Printf("✅ Attached to pod %s/%s. Exit the shell to detach; the pod keeps running.\n\n", ns, podName)
+			restore, err := makeRawTerminal()
+			if err != nil {
+				return fmt.Errorf("failed to set raw terminal: %w", err)
+			}
+			defer restore()
+			resize = newTerminalSizeQueue(ctx)
+		}
+
+		return client.Exec(ctx, k8s.ExecOptions{
+			Namespace: ns,
+			PodName:   podName,
+			Container: "main",
+			Command:   command,
+			Stdin:     stdin,
+			Stdout:    stdout,
+			Stderr:    stderr,
+			TTY:       tty,
+			Resize:    resize,
+		})
+	})
+}
+
+// printKept tells how to reattach to a pod kept with --keep
+func printKept(ns, podName string) {
+	i18n.Printf("📌 Kept pod %s/%s; reattach with: kubectl pocket attach %s\n", ns, podName, podName)
+}
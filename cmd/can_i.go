@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/spf13/cobra"
+)
+
+// Accesses pocket's commands need in the target namespace
+var (
+	accessCreatePods   = k8s.ResourceAccess{Verb: "create", Resource: "pods"}
+	accessWatchPods    = k8s.ResourceAccess{Verb: "watch", Resource: "pods"}
+	accessDeletePods   = k8s.ResourceAccess{Verb: "delete", Resource: "pods"}
+	accessPodLogs      = k8s.ResourceAccess{Verb: "get", Resource: "pods", Subresource: "log"}
+	accessExec         = k8s.ResourceAccess{Verb: "create", Resource: "pods", Subresource: "exec"}
+	accessAttach       = k8s.ResourceAccess{Verb: "create", Resource: "pods", Subresource: "attach"}
+	accessPortForward  = k8s.ResourceAccess{Verb: "create", Resource: "pods", Subresource: "portforward"}
+	accessEphemeral    = k8s.ResourceAccess{Verb: "update", Resource: "pods", Subresource: "ephemeralcontainers"}
+	accessListServices = k8s.ResourceAccess{Verb: "list", Resource: "services"}
+	accessNodeProxy    = k8s.ResourceAccess{Verb: "get", Resource: "nodes", Subresource: "proxy"}
+)
+
+// accessesRunPods are needed by every command that runs its own pod
+var accessesRunPods = []k8s.ResourceAccess{accessCreatePods, accessWatchPods, accessDeletePods}
+
+// permission is an access and the features that need it
+type permission struct {
+	access k8s.ResourceAccess
+	usedBy string
+}
+
+// permissions are the accesses can-i checks
+var permissions = []permission{
+	{accessCreatePods, "tests, shells, debug node, proxy"},
+	{accessWatchPods, "waiting for pods to start"},
+	{accessDeletePods, "cleaning up pods"},
+	{accessPodLogs, "test results, logs"},
+	{accessExec, "shells, queries, --watch, --latency, --from-pod, attach"},
+	{accessAttach, "debug attach"},
+	{accessPortForward, "port-forward, proxy"},
+	{accessEphemeral, "--from-pod, debug attach"},
+	{accessListServices, "port-forward, discover"},
+	{accessNodeProxy, "top db volume usage"},
+}
+
+var canICmd = &cobra.Command{
+	Use:   "can-i",
+	Short: "Check whether you may do what pocket needs in the namespace",
+	Long: `Check with access reviews whether you are allowed the RBAC permissions pocket
+needs in the namespace, and list the features each missing one breaks.
+
+Commands that create pods check the permissions they need before starting,
+so a missing one is reported up front instead of as a Forbidden error half
+way through.
+
+Examples:
+  kubectl pocket can-i
+  kubectl pocket can-i -n payments --as system:serviceaccount:ci:deployer`,
+	Args: cobra.NoArgs,
+	RunE: runCanI,
+}
+
+func init() {
+	markStructuredOutput(canICmd)
+}
+
+// permissionReport is the structured result of one access review
+type permissionReport struct {
+	Verb     string `json:"verb"`
+	Resource string `json:"resource"`
+	Allowed  bool   `json:"allowed"`
+	Reason   string `json:"reason,omitempty"`
+	UsedBy   string `json:"usedBy"`
+}
+
+func runCanI(cmd *cobra.Command, args []string) error {
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	i18n.Printf("🔐 Checking permissions in %s\n", i18n.T("namespace %s", client.Namespace))
+	accesses := make([]k8s.ResourceAccess, len(permissions))
+	for i, p := range permissions {
+		accesses[i] = p.access
+		accesses[i].Namespace = client.Namespace
+	}
+	denied, err := client.MissingAccess(ctx, accesses)
+	if err != nil {
+		return err
+	}
+	reasons := map[string]string{}
+	for _, d := range denied {
+		reasons[d.String()] = d.Reason
+	}
+
+	reports := make([]permissionReport, len(permissions))
+	for i, p := range permissions {
+		reason, missing := reasons[p.access.String()]
+		reports[i] = permissionReport{
+			Verb:     p.access.Verb,
+			Resource: p.access.FullResource(),
+			Allowed:  !missing,
+			Reason:   reason,
+			UsedBy:   p.usedBy,
+		}
+	}
+
+	if structured() {
+		if err := printStructured(reports); err != nil {
+			return err
+		}
+	} else {
+		w := newTable()
+		_, _ = fmt.Fprintln(w, "\nSTATUS\tVERB\tRESOURCE\tNEEDED FOR")
+		for _, r := range reports {
+			status := checkHealthy
+			if !r.Allowed {
+				status = checkUnhealthy
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", status.icon(), r.Verb, r.Resource, r.UsedBy)
+		}
+		_ = w.Flush()
+		printf("\n")
+	}
+
+	if len(denied) > 0 {
+		i18n.Printf("❌ %d of %d permission(s) missing\n", len(denied), len(permissions))
+		return checkFailed(&k8s.AccessError{Namespace: client.Namespace, Denied: denied})
+	}
+	i18n.Printf("✅ All %d permission(s) granted\n", len(permissions))
+	return nil
+}
+
+// preflight checks that the current user has accesses in the client's
+// namespace, so a missing permission is reported before any pod is created.
+// A review that cannot be made is not an error; the command then runs and
+// fails where it would have.
+func preflight(client *k8s.Client, accesses ...k8s.ResourceAccess) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	reviews := make([]k8s.ResourceAccess, len(accesses))
+	for i, access := range accesses {
+		reviews[i] = access
+		reviews[i].Namespace = client.Namespace
+	}
+	denied, err := client.MissingAccess(ctx, reviews)
+	if err != nil || len(denied) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w (run 'kubectl pocket can-i' for details)", &k8s.AccessError{Namespace: client.Namespace, Denied: denied})
+}
@@ -0,0 +1,38 @@
+package cmd
+
+// checkStatus is the verdict for one item of a multi-target check
+type checkStatus int
+
+const (
+	checkHealthy checkStatus = iota
+	checkWarning
+	checkUnhealthy
+)
+
+// icon returns the status column marker for result tables
+func (s checkStatus) icon() string {
+	switch {
+	case plainOutput && s == checkUnhealthy:
+		return "[FAIL]"
+	case plainOutput && s == checkWarning:
+		return "[WARN]"
+	case plainOutput:
+		return "[OK]"
+	case s == checkUnhealthy:
+		return "❌"
+	case s == checkWarning:
+		return "⚠️"
+	}
+	return "✅"
+}
+
+// String returns the status as written in structured output
+func (s checkStatus) String() string {
+	switch s {
+	case checkUnhealthy:
+		return statusFailed
+	case checkWarning:
+		return statusWarning
+	}
+	return statusPassed
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Delete pods pocket left behind",
+	Long: `Delete the pods pocket created that are still around, e.g. because the laptop
+went to sleep or the process was killed mid-test, and pods kept with --keep.
+Pods are found by the app.kubernetes.io/managed-by=kubectl-pocket label.
+
+The agent pod is left alone; stop it with "kubectl pocket agent stop".
+
+Examples:
+  kubectl pocket clean --dry-run
+  kubectl pocket clean --older-than 1h
+  kubectl pocket clean --all-namespaces`,
+	Args: cobra.NoArgs,
+	RunE: runClean,
+}
+
+var (
+	cleanAllNamespaces bool
+	cleanOlderThan     time.Duration
+	cleanDryRun        bool
+)
+
+func init() {
+	cleanCmd.Flags().BoolVarP(&cleanAllNamespaces, "all-namespaces", "A", false, "clean pods in all namespaces")
+	cleanCmd.Flags().DurationVar(&cleanOlderThan, "older-than", 0, "only delete pods older than this, e.g. 1h")
+	cleanCmd.Flags().BoolVar(&cleanDryRun, "dry-run", false, "list the pods that would be deleted without deleting them")
+	markStructuredOutput(cleanCmd)
+}
+
+// cleanedPod is a pod listed by clean
+type cleanedPod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Purpose   string `json:"purpose,omitempty"`
+	Phase     string `json:"phase"`
+	Age       string `json:"age"`
+	Deleted   bool   `json:"deleted"`
+	Error     string `json:"error,omitempty"`
+}
+
+func runClean(cmd *cobra.Command, args []string) error {
+	if cleanOlderThan < 0 {
+		return fmt.Errorf("--older-than must not be negative")
+	}
+
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	ns := client.Namespace
+	if cleanAllNamespaces {
+		ns = metav1.NamespaceAll
+	}
+	list, err := client.Clientset.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{
+		LabelSelector: k8s.LabelManagedBy + "=kubectl-pocket",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	var stale []corev1.Pod
+	for _, pod := range list.Items {
+		if pod.Name == tester.AgentPodName && pod.Labels[k8s.LabelPurpose] == "agent" {
+			continue
+		}
+		if time.Since(pod.CreationTimestamp.Time) < cleanOlderThan {
+			continue
+		}
+		stale = append(stale, pod)
+	}
+	sort.Slice(stale, func(i, j int) bool {
+		if stale[i].Namespace != stale[j].Namespace {
+			return stale[i].Namespace < stale[j].Namespace
+		}
+		return stale[i].Name < stale[j].Name
+	})
+
+	report := []cleanedPod{}
+	deleted, failed := 0, 0
+	for _, pod := range stale {
+		entry := cleanedPod{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			Purpose:   pod.Labels[k8s.LabelPurpose],
+			Phase:     string(pod.Status.Phase),
+			Age:       duration.HumanDuration(time.Since(pod.CreationTimestamp.Time)),
+		}
+		if !cleanDryRun {
+			err := client.DeletePod(ctx, pod.Namespace, pod.Name)
+			switch {
+			case err == nil || apierrors.IsNotFound(err):
+				entry.Deleted = true
+				deleted++
+			default:
+				entry.Error = err.Error()
+				failed++
+			}
+		}
+		report = append(report, entry)
+	}
+
+	var failErr error
+	if failed > 0 {
+		failErr = fmt.Errorf("failed to delete %d pod(s)", failed)
+	}
+	if structured() {
+		if err := printStructured(report); err != nil {
+			return err
+		}
+		return failErr
+	}
+	if len(report) == 0 {
+		i18n.Printf("✨ No pocket pods to clean up\n")
+		return nil
+	}
+
+	w := newTable()
+	_, _ = fmt.Fprintln(w, "NAMESPACE\tNAME\tPURPOSE\tSTATUS\tAGE\tRESULT")
+	for _, entry := range report {
+		result := i18n.T("would delete")
+		switch {
+		case entry.Deleted:
+			result = i18n.T("deleted")
+		case entry.Error != "":
+			result = entry.Error
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.Namespace, entry.Name, valueOr(entry.Purpose, "-"), entry.Phase, entry.Age, result)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if cleanDryRun {
+		i18n.Printf("💡 Dry run: %d pod(s) would be deleted\n", len(report))
+		return nil
+	}
+	i18n.Printf("🧹 Deleted %d pod(s)\n", deleted)
+	return failErr
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// completionTimeout bounds the API requests of a shell completion, so a
+// slow or unreachable cluster does not hang the shell
+const completionTimeout = 5 * time.Second
+
+// listFunc lists resource names in the client's namespace, or in the
+// cluster for cluster-scoped resources
+type listFunc func(ctx context.Context, client *k8s.Client) ([]string, error)
+
+// completeResources returns the names listed by list that start with
+// toComplete, each with prefix prepended. Errors yield no completions.
+func completeResources(list listFunc, prefix, toComplete string) ([]string, cobra.ShellCompDirective) {
+	client, err := GetK8sClient()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	names, err := list(ctx, client)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, name := range names {
+		if strings.HasPrefix(prefix+name, toComplete) {
+			completions = append(completions, prefix+name)
+		}
+	}
+	sort.Strings(completions)
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// listNamespaces lists the namespaces of the cluster
+func listNamespaces(ctx context.Context, client *k8s.Client) ([]string, error) {
+	list, err := client.Clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(list.Items))
+	for i := range list.Items {
+		names[i] = list.Items[i].Name
+	}
+	return names, nil
+}
+
+// listServices lists the Services in the namespace
+func listServices(ctx context.Context, client *k8s.Client) ([]string, error) {
+	list, err := client.Clientset.CoreV1().Services(client.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(list.Items))
+	for i := range list.Items {
+		names[i] = list.Items[i].Name
+	}
+	return names, nil
+}
+
+// listSecrets lists the Secrets in the namespace
+func listSecrets(ctx context.Context, client *k8s.Client) ([]string, error) {
+	list, err := client.Clientset.CoreV1().Secrets(client.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(list.Items))
+	for i := range list.Items {
+		names[i] = list.Items[i].Name
+	}
+	return names, nil
+}
+
+// completeNamespaces completes --namespace
+func completeNamespaces(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeResources(listNamespaces, "", toComplete)
+}
+
+// completeSecrets completes a Secret name in the namespace
+func completeSecrets(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeResources(listSecrets, "", toComplete)
+}
+
+// completePortForward completes port-forward targets: database aliases and
+// the namespace's Services as svc/<name>
+func completePortForward(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if strings.Contains(toComplete, "/") {
+		kind, _, _ := strings.Cut(toComplete, "/")
+		if kind != "svc" && kind != "service" {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeResources(listServices, kind+"/", toComplete)
+	}
+
+	var completions []string
+	seen := map[string]bool{}
+	addAlias := func(name string) {
+		if !seen[name] && strings.HasPrefix(name, toComplete) {
+			seen[name] = true
+			completions = append(completions, name)
+		}
+	}
+	for name := range dbAliases {
+		addAlias(name)
+	}
+	if cfg, err := GetConfig(); err == nil {
+		for name := range cfg.Aliases {
+			addAlias(name)
+		}
+	}
+	sort.Strings(completions)
+
+	services, directive := completeResources(listServices, "svc/", toComplete)
+	return append(completions, services...), directive
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/config"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "View and change kubectl-pocket defaults",
+	Long: `View and change kubectl-pocket defaults.
+
+Settings are stored in ~/.config/kubectl-pocket/config.yaml (override the
+location with $KUBECTL_POCKET_CONFIG). Command-line flags always win over
+the config file.
+
+Example config.yaml:
+  namespace: databases
+  timeout: 45s
+  keepalive: 2h
+  imageRegistry: mirror.example.com
+  testers:
+    postgres:
+      image: postgres:16-alpine
+      timeout: 1m
+  aliases:
+    kafka:
+      services: [kafka, kafka-broker]
+      port: 9092
+  podLabels:
+    cost-center: platform
+  podAnnotations:
+    sidecar.istio.io/inject: "false"
+
+Examples:
+  kubectl pocket config view
+  kubectl pocket config set namespace databases
+  kubectl pocket config set testers.redis.image redis:7.2-alpine
+  kubectl pocket config set aliases.kafka.services kafka,kafka-broker
+  kubectl pocket config set aliases.kafka.port 9092
+  kubectl pocket config set podAnnotations.sidecar.istio.io/inject false
+  kubectl pocket config unset timeout`,
+}
+
+var configViewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Print the current configuration",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigView,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a configuration value",
+	Long: fmt.Sprintf(`Set a configuration value.
+
+Valid keys:
+  %s`, strings.Join(config.Keys, "\n  ")),
+	Args: cobra.ExactArgs(2),
+	RunE: runConfigSet,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a configuration value",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigUnset,
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the configuration file path",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		printf("%s\n", config.DefaultPath())
+	},
+}
+
+func init() {
+	// rootCmd.AddCommand is handled in root.go addSubcommands()
+	configCmd.AddCommand(configViewCmd, configSetCmd, configUnsetCmd, configPathCmd)
+}
+
+func runConfigView(cmd *cobra.Command, args []string) error {
+	cfg, err := GetConfig()
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	printf("# %s\n%s", config.DefaultPath(), data)
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	return updateConfig(args[0], args[1])
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string) error {
+	return updateConfig(args[0], "")
+}
+
+// updateConfig sets key to value and saves the config file
+func updateConfig(key, value string) error {
+	cfg, err := GetConfig()
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.Set(key, value); err != nil {
+		return err
+	}
+
+	path := config.DefaultPath()
+	if err := cfg.Save(path); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	if value == "" {
+		printf("✅ Unset %s in %s\n", key, path)
+	} else {
+		printf("✅ Set %s = %s in %s\n", key, value, path)
+	}
+	return nil
+}
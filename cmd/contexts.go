@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/parallel"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// contextResult is the outcome of a test in one kubeconfig context
+type contextResult struct {
+	context   string
+	namespace string
+	display   string
+	result    *tester.Result
+	latency   time.Duration
+	// err reports a test that could not run in the context
+	err error
+}
+
+// addContextsFlags registers --contexts, --all-contexts and --parallel on
+// cmd
+func (o *testOptions) addContextsFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&o.contexts, "contexts", nil, "run the test in each of these kubeconfig contexts at once")
+	cmd.Flags().BoolVar(&o.allContexts, "all-contexts", false, "run the test in every kubeconfig context at once")
+	cmd.Flags().IntVar(&o.parallel, "parallel", 5, "maximum number of contexts tested at once")
+}
+
+// multiContext reports whether the test runs in several contexts
+func (o *testOptions) multiContext() bool {
+	return len(o.contexts) > 0 || o.allContexts
+}
+
+// selectedContexts returns the contexts named by --contexts, or all of the
+// kubeconfig's contexts with --all-contexts
+func (o *testOptions) selectedContexts() ([]string, error) {
+	raw, err := configFlags.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	if o.allContexts {
+		names := make([]string, 0, len(raw.Contexts))
+		for name := range raw.Contexts {
+			names = append(names, name)
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("kubeconfig has no contexts")
+		}
+		sort.Strings(names)
+		return names, nil
+	}
+
+	var names []string
+	seen := map[string]bool{}
+	for _, name := range o.contexts {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		if _, ok := raw.Contexts[name]; !ok {
+			return nil, fmt.Errorf("context %q not found in kubeconfig", name)
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// clientForContext creates a client for a kubeconfig context. The kubeconfig,
+// impersonation and request timeout flags apply to every context; --cluster
+// and --user do not, as each context names its own.
+func clientForContext(name string) (*k8s.Client, error) {
+	flags := genericclioptions.NewConfigFlags(false)
+	flags.KubeConfig = configFlags.KubeConfig
+	flags.Context = &name
+	flags.Impersonate = configFlags.Impersonate
+	flags.ImpersonateUID = configFlags.ImpersonateUID
+	flags.ImpersonateGroup = configFlags.ImpersonateGroup
+	flags.Timeout = configFlags.Timeout
+
+	namespace, err := namespaceOverride()
+	if err != nil {
+		return nil, err
+	}
+	client, err := k8s.NewClient(flags, namespace)
+	if err != nil {
+		return nil, err
+	}
+	setVerbosity(client)
+	setAudit(client)
+	setImagePolicy(client)
+	return client, nil
+}
+
+// runTesterContexts runs t against target in several kubeconfig contexts
+// concurrently and prints the results as a table, one row per context
+func runTesterContexts(t tester.Tester, target string, opts *testOptions) error {
+	if len(opts.contexts) > 0 && opts.allContexts {
+		return fmt.Errorf("--contexts cannot be combined with --all-contexts")
+	}
+	if opts.shell || opts.watch || opts.cached || opts.latencyRuns() > 0 || opts.file != "" {
+		return fmt.Errorf("--contexts cannot be combined with --shell, --watch, --cached, --latency or --file")
+	}
+	if opts.fromPod != "" || opts.like != "" || opts.reuse || opts.keep || opts.ssh.enabled() {
+		return fmt.Errorf("--contexts cannot be combined with --from-pod, --like, --reuse, --keep or --ssh")
+	}
+
+	if opts.parallel < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+
+	names, err := opts.selectedContexts()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("--contexts names no contexts")
+	}
+
+	// Runners are created up front, as applying the config file updates opts
+	results := make([]*contextResult, len(names))
+	runners := make([]*tester.Runner, len(names))
+	for i, name := range names {
+		results[i] = &contextResult{context: name}
+		client, err := clientForContext(name)
+		if err != nil {
+			results[i].err = fmt.Errorf("failed to create k8s client: %w", err)
+			continue
+		}
+		results[i].namespace = client.Namespace
+		if runners[i], err = newRunnerFor(client, t.Name(), opts); err != nil {
+			var accessErr *k8s.AccessError
+			if !errors.As(err, &accessErr) {
+				return err
+			}
+			results[i].err = err
+		}
+	}
+
+	i18n.Printf("🔍 Testing %s connection in %d contexts: %s\n", t.DisplayName(), len(names), strings.Join(names, ", "))
+
+	// Ctrl+C stops the run; the test pods are deleted before it returns
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var tasks []parallel.Task
+	for i, runner := range runners {
+		if runner == nil {
+			continue
+		}
+		res := results[i]
+		tasks = append(tasks, parallel.Task{Name: res.context, Run: func(ctx context.Context) error {
+			testInContext(ctx, runner, t, target, opts, res)
+			if status, _, detail := res.row(); status == checkUnhealthy {
+				return errors.New(detail)
+			}
+			return nil
+		}})
+	}
+	progress := startProgress(len(tasks))
+	parallel.Run(ctx, opts.parallel, progress, tasks)
+	progress.Stop()
+	if ctx.Err() != nil {
+		return errInterrupted()
+	}
+
+	failed := 0
+	for _, res := range results {
+		if res.err != nil || !res.result.Success {
+			failed++
+		}
+		recordTestResult(metricLabels{tester: t.Name(), target: valueOr(res.display, target), namespace: res.namespace, context: res.context},
+			res.result, res.err, res.latency)
+	}
+
+	if structured() {
+		if err := printContextsReport(t, results); err != nil {
+			return err
+		}
+	} else {
+		w := newTable()
+		_, _ = fmt.Fprintln(w, "\nSTATUS\tCONTEXT\tNAMESPACE\tLATENCY\tDETAIL")
+		for _, res := range results {
+			status, latency, detail := res.row()
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				status.icon(), res.context, valueOr(res.namespace, "-"), latency, valueOr(detail, "-"))
+		}
+		_ = w.Flush()
+		printf("\n")
+	}
+
+	if failed > 0 {
+		i18n.Printf("❌ %s connection failed in %d of %d contexts\n", t.DisplayName(), failed, len(results))
+		return checkFailed(fmt.Errorf("connection test failed in %d of %d contexts", failed, len(results)))
+	}
+	i18n.Printf("✅ %s connection successful in all %d contexts\n", t.DisplayName(), len(results))
+	return nil
+}
+
+// testInContext resolves target in the runner's cluster, where Secrets and
+// workloads may differ from the other contexts, and runs the test quietly
+func testInContext(ctx context.Context, runner *tester.Runner, t tester.Tester, target string, opts *testOptions, res *contextResult) {
+	runner.OnAttempt = nil
+
+	var err error
+	if opts.from != "" {
+		target, res.display, err = opts.targetFromWorkload(runnerClient(runner), t)
+	} else {
+		target, res.display, err = resolveTarget(runnerClient(runner), target)
+	}
+	if err == nil && opts.ipFamily != "" {
+		target, err = opts.pinIPFamily(runnerClient(runner), t, target)
+	}
+	if err == nil {
+		t, err = opts.configureTester(runner, t)
+	}
+	if err != nil {
+		res.err = err
+		return
+	}
+
+	start := time.Now()
+	res.result, res.err = runner.Run(ctx, t, target)
+	res.latency = time.Since(start)
+}
+
+// row returns the status, latency and detail columns of the result
+func (res *contextResult) row() (status checkStatus, latency, detail string) {
+	switch {
+	case res.err != nil:
+		return checkUnhealthy, "-", res.err.Error()
+	case !res.result.Success:
+		return checkUnhealthy, "-", firstLine(res.result.Err.Error())
+	}
+	return checkHealthy, res.latency.Round(time.Millisecond).String(), ""
+}
+
+// printContextsReport prints the results of a multi-context test as
+// structured output, one test report per context
+func printContextsReport(t tester.Tester, results []*contextResult) error {
+	reports := make([]testReport, 0, len(results))
+	for _, res := range results {
+		report := testReport{
+			Context:   res.context,
+			Tester:    t.Name(),
+			Target:    res.display,
+			Namespace: res.namespace,
+			Status:    statusPassed,
+		}
+		switch {
+		case res.err != nil:
+			report.Status, report.Error = statusError, res.err.Error()
+		default:
+			report.Pod = res.result.PodName
+			report.Output = strings.TrimSpace(res.result.Output)
+			report.LatencyMs = res.latency.Milliseconds()
+			report.Status = string(res.result.Status)
+			report.Timings = newTimingsReport(res.result.Timings)
+			if !res.result.Success {
+				report.Error = res.result.Err.Error()
+			}
+			for _, attempt := range res.result.Attempts {
+				report.Attempts = append(report.Attempts, newAttemptReport(attempt))
+			}
+		}
+		reports = append(reports, report)
+	}
+	return printStructured(reports)
+}
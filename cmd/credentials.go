@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultPasswordKey is the secret key used when --password-from-secret
+// names none
+const defaultPasswordKey = "password"
+
+// addPasswordFlag registers --password-from-secret on cmd
+func (o *testOptions) addPasswordFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.passwordSecret, "password-from-secret", "",
+		"read the password from a Secret as <name>[:<key>] (default key \""+defaultPasswordKey+"\") instead of the target")
+	_ = cmd.RegisterFlagCompletionFunc("password-from-secret", completeSecrets)
+}
+
+// injectPassword makes the runner's pods read the password from the
+// --password-from-secret Secret and returns the tester to run. The value is
+// only referenced by the pod spec, never copied into it.
+func (o *testOptions) injectPassword(runner *tester.Runner, t tester.Tester) (tester.Tester, error) {
+	injector, ok := t.(tester.PasswordInjector)
+	if !ok {
+		return nil, fmt.Errorf("--password-from-secret is not supported by %s", t.Name())
+	}
+
+	name, key, _ := strings.Cut(o.passwordSecret, ":")
+	if key == "" {
+		key = defaultPasswordKey
+	}
+	if name == "" {
+		return nil, fmt.Errorf("invalid --password-from-secret %q (use <name>[:<key>])", o.passwordSecret)
+	}
+
+	if err := checkSecretKey(runnerClient(runner), runner.Namespace, name, key); err != nil {
+		return nil, err
+	}
+
+	injected, env := injector.InjectPassword()
+	configure := runner.ConfigurePod
+	runner.ConfigurePod = func(podConfig *k8s.PodConfig) error {
+		if configure != nil {
+			if err := configure(podConfig); err != nil {
+				return err
+			}
+		}
+		podConfig.Env = append(podConfig.Env, secretEnv(env, name, key, false))
+		return nil
+	}
+	return injected, nil
+}
+
+// checkSecretKey fails early when the Secret or key is missing in the
+// namespace the pods run in. Users who may create pods but not read Secrets
+// skip the check; the kubelet still resolves the reference.
+func checkSecretKey(client *k8s.Client, namespace, name, key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	secret, err := client.Clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsForbidden(err):
+		return nil
+	case apierrors.IsNotFound(err):
+		return fmt.Errorf("secret %s not found in namespace %s", name, namespace)
+	case err != nil:
+		return fmt.Errorf("failed to read secret %s: %w", name, err)
+	}
+
+	if _, ok := secret.Data[key]; !ok {
+		return fmt.Errorf("secret %s has no key %q (keys: %s)", name, key, strings.Join(secretKeys(secret), ", "))
+	}
+	return nil
+}
+
+func secretKeys(secret *corev1.Secret) []string {
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Target reference schemes resolved by resolveTarget
+const (
+	secretTargetScheme    = "secret://"
+	configMapTargetScheme = "configmap://"
+)
+
+// resolveTarget resolves a secret://[<namespace>/]<name>/<key> or
+// configmap://[<namespace>/]<name>/<key> target to the connection string
+// stored there. Other targets are returned as is. display is what to print:
+// the reference rather than a connection string that may hold a password.
+func resolveTarget(client *k8s.Client, target string) (resolved, display string, err error) {
+	var scheme, ref string
+	switch {
+	case strings.HasPrefix(target, secretTargetScheme):
+		scheme, ref = secretTargetScheme, strings.TrimPrefix(target, secretTargetScheme)
+	case strings.HasPrefix(target, configMapTargetScheme):
+		scheme, ref = configMapTargetScheme, strings.TrimPrefix(target, configMapTargetScheme)
+	default:
+		return target, target, nil
+	}
+
+	namespace := client.Namespace
+	parts := strings.Split(ref, "/")
+	if len(parts) == 3 {
+		namespace, parts = parts[0], parts[1:]
+	}
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid target %q (use %s[<namespace>/]<name>/<key>)", target, scheme)
+	}
+	name, key := parts[0], parts[1]
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var value string
+	if scheme == secretTargetScheme {
+		data, err := client.GetSecretValue(ctx, namespace, name, key)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read connection string from secret %s/%s: %w", namespace, name, err)
+		}
+		value = string(data)
+	} else {
+		value, err = client.GetConfigMapValue(ctx, namespace, name, key)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read connection string from config map %s/%s: %w", namespace, name, err)
+		}
+	}
+
+	// Values written with echo or a heredoc often end in a newline
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", "", fmt.Errorf("%s is empty", target)
+	}
+	return value, target, nil
+}
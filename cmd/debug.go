@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/images"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// debugNodeImage provides nsenter and a shell
+const debugNodeImage = "busybox:1.36"
+
+// debugAttachImage provides network troubleshooting tools
+const debugAttachImage = "nicolaka/netshoot:v0.13"
+
+// debugImageAliases are short names accepted by --image
+var debugImageAliases = map[string]string{
+	"netshoot": "nicolaka/netshoot:v0.13",
+	"busybox":  "busybox:1.36",
+}
+
+// nodeShell enters every namespace of the node's init process and starts
+// a login shell, bash where the node has it
+const nodeShell = `exec nsenter --target 1 --mount --uts --ipc --net --pid -- /bin/sh -c 'if command -v bash >/dev/null; then exec bash -l; fi; exec sh -l'`
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Debug nodes and running pods",
+}
+
+var debugNodeCmd = &cobra.Command{
+	Use:   "node <node-name> [-- <command>...]",
+	Short: "Open a root shell on a node",
+	Long: `Create a privileged pod pinned to a node, sharing its process and network
+namespaces, and open a root shell in the node's own namespaces with nsenter.
+The node's filesystem is also mounted at /host in the pod. The pod is deleted
+when the session ends, including on Ctrl+C and errors.
+
+Arguments after -- run as a command on the node instead of a shell.
+
+Examples:
+  kubectl pocket debug node worker-1
+  kubectl pocket debug node worker-1 -- journalctl -u kubelet -n 100
+  kubectl pocket debug node worker-1 -- crictl ps
+  kubectl pocket debug node worker-1 --record node-session.cast`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runDebugNode,
+}
+
+var debugAttachCmd = &cobra.Command{
+	Use:   "attach <pod> [-- <command>...]",
+	Short: "Open a shell in an ephemeral container added to a running pod",
+	Long: `Add an ephemeral debug container to a running pod and attach to it, to
+troubleshoot the pod's connectivity without restarting it or creating a
+separate pod. The container shares the pod's network namespace and, where
+the runtime supports it, the process namespace of its target container.
+
+It runs as a non-root user without capabilities, so it is allowed under the
+restricted Pod Security Standard; --root runs it as root with NET_RAW and
+NET_ADMIN for tools such as tcpdump and ping. Ephemeral containers cannot be
+removed; the container stops when the shell exits.
+
+Examples:
+  kubectl pocket debug attach api-7d9f8
+  kubectl pocket debug attach deploy/api -c app --image busybox
+  kubectl pocket debug attach api-7d9f8 --root -- tcpdump -i any port 5432
+  kubectl pocket debug attach api-7d9f8 --record api-debug.cast --record-input`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runDebugAttach,
+}
+
+var (
+	debugImage     string
+	debugContainer string
+	debugRoot      bool
+	debugRecord    recordOptions
+)
+
+func init() {
+	debugNodeCmd.Flags().StringVar(&debugImage, "image", "", "override the debug image; it needs nsenter (default "+debugNodeImage+")")
+	addPodMetadataFlags(debugNodeCmd)
+	debugRecord.addFlags(debugNodeCmd)
+
+	debugAttachCmd.Flags().StringVar(&debugImage, "image", "", "debug image, or netshoot or busybox (default "+debugAttachImage+")")
+	debugAttachCmd.Flags().StringVarP(&debugContainer, "container", "c", "", "container whose process namespace to share (default the pod's first)")
+	debugAttachCmd.Flags().BoolVar(&debugRoot, "root", false,
+		"run as root with NET_RAW and NET_ADMIN (not allowed by the restricted Pod Security Standard)")
+	debugRecord.addFlags(debugAttachCmd)
+
+	debugCmd.AddCommand(debugNodeCmd, debugAttachCmd)
+}
+
+func runDebugNode(cmd *cobra.Command, args []string) error {
+	if err := debugRecord.validate(); err != nil {
+		return err
+	}
+	nodeName := args[0]
+	command := []string{"sh", "-c", nodeShell}
+	if len(args) > 1 {
+		if cmd.ArgsLenAtDash() != 1 {
+			return fmt.Errorf("pass the command after --, e.g. debug node %s -- %s", nodeName, args[1])
+		}
+		command = append([]string{"nsenter", "--target", "1", "--mount", "--uts", "--ipc", "--net", "--pid", "--"}, args[1:]...)
+	}
+
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+	cfg, err := GetConfig()
+	if err != nil {
+		return err
+	}
+	if err := preflight(client, append(accessesRunPods, accessExec)...); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+
+	if _, err := client.Clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{}); err != nil {
+		return fmt.Errorf("failed to get node %s: %w", nodeName, err)
+	}
+
+	image := debugImage
+	if alias, ok := debugImageAliases[image]; ok {
+		image = images.Pin(alias)
+	}
+	if image == "" {
+		image = resolveImage(cfg, "debug", debugNodeImage)
+	}
+
+	ns := client.Namespace
+	podName := fmt.Sprintf("pocket-debug-node-%d", time.Now().Unix())
+	podConfig := k8s.PodConfig{
+		Name:        podName,
+		Namespace:   ns,
+		Image:       image,
+		Command:     []string{"sleep", "86400"},
+		Purpose:     "debug",
+		TTY:         true,
+		Stdin:       true,
+		NodeName:    nodeName,
+		HostNetwork: true,
+		HostPID:     true,
+		Tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+		SecurityContext: &corev1.SecurityContext{
+			Privileged: ptr.To(true),
+			RunAsUser:  ptr.To(int64(0)),
+		},
+		Volumes: []corev1.Volume{{
+			Name:         "host",
+			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/"}},
+		}},
+		VolumeMounts: []corev1.VolumeMount{{Name: "host", MountPath: "/host"}},
+	}
+	podOpts.applyConfig(cfg)
+	if err := podOpts.applyMetadata(&podConfig); err != nil {
+		return err
+	}
+
+	i18n.Printf("📦 Creating debug pod on node %s: %s/%s\n", nodeName, ns, podName)
+	if _, err := client.CreatePod(ctx, podConfig); err != nil {
+		return fmt.Errorf("failed to create debug pod: %w", err)
+	}
+	defer func() {
+		i18n.Printf("\n🧹 Cleaning up pod: %s\n", podName)
+		cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cleanupCancel()
+		_ = client.DeletePod(cleanupCtx, ns, podName)
+	}()
+
+	i18n.Printf("⏳ Waiting for pod to be ready...\n")
+	if err := client.WaitForPodRunning(ctx, ns, podName, 2*time.Minute); err != nil {
+		return fmt.Errorf("debug pod failed to start: %w", err)
+	}
+
+	title := sessionTitle(client, ns, fmt.Sprintf("root shell on node %s", nodeName))
+	return recordSession(debugRecord, title, func(stdin io.Reader, stdout, stderr io.Writer) error {
+		tty := term.IsTerminal(int(os.Stdin.Fd()))
+		var resize k8s.TerminalSizeQueue
+		if tty {
+			i18n.Printf("✅ Connected to node %s as root. Exit the shell to clean up.\n\n", nodeName)
+			restore, err := makeRawTerminal()
+			if err != nil {
+				return fmt.Errorf("failed to set raw terminal: %w", err)
+			}
+			defer restore()
+			resize = newTerminalSizeQueue(ctx)
+		}
+
+		return client.Exec(ctx, k8s.ExecOptions{
+			Namespace: ns,
+			PodName:   podName,
+			Container: "main",
+			Command:   command,
+			Stdin:     stdin,
+			Stdout:    stdout,
+			Stderr:    stderr,
+			TTY:       tty,
+			Resize:    resize,
+		})
+	})
+}
+
+func runDebugAttach(cmd *cobra.Command, args []string) error {
+	if err := debugRecord.validate(); err != nil {
+		return err
+	}
+	var command []string
+	if len(args) > 1 {
+		if cmd.ArgsLenAtDash() != 1 {
+			return fmt.Errorf("pass the command after --, e.g. debug attach %s -- %s", args[0], args[1])
+		}
+		command = args[1:]
+	}
+
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+	cfg, err := GetConfig()
+	if err != nil {
+		return err
+	}
+	if err := preflight(client, accessEphemeral, accessAttach); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pods, err := resolvePods(ctx, client, args[0])
+	if err != nil {
+		return err
+	}
+	var pod *corev1.Pod
+	for i := range pods {
+		if pods[i].Status.Phase == corev1.PodRunning {
+			pod = &pods[i]
+			break
+		}
+	}
+	if pod == nil {
+		return fmt.Errorf("no running pod found for %s", args[0])
+	}
+
+	image := debugImage
+	if alias, ok := debugImageAliases[image]; ok {
+		image = images.Pin(alias)
+	}
+	if image == "" {
+		image = resolveImage(cfg, "debug-attach", debugAttachImage)
+	}
+	target := debugContainer
+	if target == "" {
+		target = pod.Spec.Containers[0].Name
+	}
+
+	securityContext := k8s.RestrictedSecurityContext(k8s.DefaultRunAsUser)
+	if debugRoot {
+		securityContext = &corev1.SecurityContext{
+			RunAsUser: ptr.To(int64(0)),
+			Capabilities: &corev1.Capabilities{
+				Add: []corev1.Capability{"NET_RAW", "NET_ADMIN"},
+			},
+		}
+	}
+
+	tty := term.IsTerminal(int(os.Stdin.Fd()))
+	name := fmt.Sprintf("pocket-debug-%d", time.Now().Unix())
+	container := corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:            name,
+			Image:           image,
+			Command:         command,
+			Stdin:           true,
+			StdinOnce:       true,
+			TTY:             tty,
+			SecurityContext: securityContext,
+		},
+		TargetContainerName: target,
+	}
+
+	i18n.Printf("📦 Adding debug container %s to pod %s\n", name, pod.Name)
+	if err := client.AddEphemeralContainer(ctx, pod.Namespace, pod.Name, container, 2*time.Minute); err != nil {
+		return err
+	}
+
+	title := sessionTitle(client, pod.Namespace, fmt.Sprintf("debug container %s in pod %s", name, pod.Name))
+	return recordSession(debugRecord, title, func(stdin io.Reader, stdout, stderr io.Writer) error {
+		var resize k8s.TerminalSizeQueue
+		if tty {
+			i18n.Printf("✅ Attached to %s in pod %s. If you don't see a prompt, press Enter.\n\n", name, pod.Name)
+			restore, err := makeRawTerminal()
+			if err != nil {
+				return fmt.Errorf("failed to set raw terminal: %w", err)
+			}
+			defer restore()
+			resize = newTerminalSizeQueue(ctx)
+		}
+
+		return client.Attach(ctx, k8s.ExecOptions{
+			Namespace: pod.Namespace,
+			PodName:   pod.Name,
+			Container: name,
+			Stdin:     stdin,
+			Stdout:    stdout,
+			Stderr:    stderr,
+			TTY:       tty,
+			Resize:    resize,
+		})
+	})
+}
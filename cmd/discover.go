@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/spf13/cobra"
+)
+
+var discoverCmd = &cobra.Command{
+	Use:   "discover [type]",
+	Short: "Find databases in the namespace",
+	Long: `Scan Services and StatefulSets for databases, recognised by their port,
+well-known labels (app.kubernetes.io/name, app) or image, and print their type,
+service, port and ready replicas with a test command to copy.
+
+Examples:
+  kubectl pocket discover
+  kubectl pocket discover postgres -n payments
+  kubectl pocket discover -A`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDiscover,
+}
+
+var discoverAllNamespaces bool
+
+func init() {
+	discoverCmd.Flags().BoolVarP(&discoverAllNamespaces, "all-namespaces", "A", false, "search all namespaces")
+	markStructuredOutput(discoverCmd)
+}
+
+// discoverURLSchemes are the connection string schemes for discovered
+// types; types not listed take a plain host:port
+var discoverURLSchemes = map[string]string{
+	"postgres": "postgres",
+	"mongo":    "mongodb",
+}
+
+// discoveredDatabase is the structured output of a discovered database
+type discoveredDatabase struct {
+	k8s.Database
+	// Test is the command that tests it
+	Test string `json:"test,omitempty"`
+}
+
+func runDiscover(cmd *cobra.Command, args []string) error {
+	var filter string
+	if len(args) == 1 {
+		filter = args[0]
+		if !knownDatabaseType(filter) {
+			return fmt.Errorf("unknown database type %q (supported: %s)", filter, strings.Join(databaseTypes(), ", "))
+		}
+	}
+
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+
+	ns := client.Namespace
+	scope := i18n.T("namespace %s", ns)
+	if discoverAllNamespaces {
+		ns, scope = "", i18n.T("all namespaces")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	i18n.Printf("🔍 Discovering databases in %s\n", scope)
+	found, err := client.DiscoverDatabases(ctx, ns)
+	if err != nil {
+		return err
+	}
+
+	var databases []k8s.Database
+	for _, db := range found {
+		if filter == "" || db.Type == filter {
+			databases = append(databases, db)
+		}
+	}
+	if structured() {
+		report := []discoveredDatabase{}
+		for _, db := range databases {
+			report = append(report, discoveredDatabase{Database: db, Test: discoveredTestCommand(db, client.Namespace)})
+		}
+		return printStructured(report)
+	}
+	if len(databases) == 0 {
+		i18n.Printf("💡 No databases found\n")
+		return nil
+	}
+
+	w := newTable()
+	header := "TYPE\tSERVICE\tPORT\tREADY\tWORKLOAD\tMATCHED BY"
+	if discoverAllNamespaces {
+		header = "NAMESPACE\t" + header
+	}
+	_, _ = fmt.Fprintln(w, "\n"+header)
+	for _, db := range databases {
+		row := fmt.Sprintf("%s\t%s\t%d\t%d/%d\t%s\t%s",
+			db.Type, valueOr(db.Service, "-"), db.Port, db.Ready, db.Replicas, valueOr(db.Workload, "-"), db.MatchedBy)
+		if discoverAllNamespaces {
+			row = db.Namespace + "\t" + row
+		}
+		_, _ = fmt.Fprintln(w, row)
+	}
+	_ = w.Flush()
+
+	i18n.Printf("\n💡 Test with:\n")
+	for _, db := range databases {
+		if command := discoveredTestCommand(db, client.Namespace); command != "" {
+			printf("   %s\n", command)
+		}
+	}
+	return nil
+}
+
+// discoveredTarget returns the test target for a discovered database in
+// its own namespace, or "" when it has no service to connect to
+func discoveredTarget(db k8s.Database) string {
+	if db.Service == "" {
+		return ""
+	}
+	target := net.JoinHostPort(db.Service, strconv.Itoa(int(db.Port)))
+	if scheme, ok := discoverURLSchemes[db.Type]; ok {
+		target = scheme + "://" + target
+	}
+	return target
+}
+
+// discoveredTestCommand renders a test command for a discovered database,
+// run in the database's namespace
+func discoveredTestCommand(db k8s.Database, currentNamespace string) string {
+	target := discoveredTarget(db)
+	if target == "" {
+		return ""
+	}
+	command := fmt.Sprintf("kubectl pocket test %s %s", db.Type, target)
+	if db.Namespace != currentNamespace {
+		command += " -n " + db.Namespace
+	}
+	return command
+}
+
+func knownDatabaseType(name string) bool {
+	for _, kind := range k8s.DatabaseKinds {
+		if kind.Type == name {
+			return true
+		}
+	}
+	return false
+}
+
+func databaseTypes() []string {
+	var names []string
+	for _, kind := range k8s.DatabaseKinds {
+		names = append(names, kind.Type)
+	}
+	return names
+}
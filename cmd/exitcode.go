@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Exit codes, documented in the README for CI jobs and runbooks
+const (
+	// ExitOK means every check passed
+	ExitOK = 0
+	// ExitCheckFailed means a check ran and failed, e.g. a connection was
+	// refused or a pod would be denied
+	ExitCheckFailed = 1
+	// ExitError means the check could not run, e.g. the test pod could not
+	// be scheduled, the API server was unreachable or a flag was invalid
+	ExitError = 2
+	// ExitTimeout means the check did not finish in time
+	ExitTimeout = 3
+)
+
+// checkFailedError marks an error as a failed check rather than a failure
+// to run it
+type checkFailedError struct {
+	err error
+}
+
+func (e *checkFailedError) Error() string { return e.err.Error() }
+
+func (e *checkFailedError) Unwrap() error { return e.err }
+
+// checkFailed marks err, a check's negative verdict, for ExitCheckFailed
+func checkFailed(err error) error {
+	return &checkFailedError{err: err}
+}
+
+// ExitCode returns the process exit code for an error returned by Execute
+func ExitCode(err error) int {
+	var failed *checkFailedError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &failed):
+		return ExitCheckFailed
+	case errors.Is(err, tester.ErrPodNotStarted):
+		return ExitError
+	case wait.Interrupted(err), errors.Is(err, context.DeadlineExceeded):
+		return ExitTimeout
+	default:
+		return ExitError
+	}
+}
+
+// reportStartFailure prints the events of a test pod that did not start,
+// after cobra printed the error itself
+func reportStartFailure(w io.Writer, err error) {
+	var startErr *tester.PodStartError
+	if !errors.As(err, &startErr) {
+		return
+	}
+	if len(startErr.Events) > 0 {
+		_, _ = fmt.Fprint(w, i18n.T("\n📋 Events of pod %s/%s:\n", startErr.Namespace, startErr.Pod))
+		for _, event := range startErr.Events {
+			_, _ = fmt.Fprintf(w, "  %s\n", event)
+		}
+	}
+	if quiet {
+		return
+	}
+	_, _ = fmt.Fprint(w, i18n.T("💡 The test pod could not start, so the connection was not tested; this is a cluster problem, not a failed connection\n"))
+}
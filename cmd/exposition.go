@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxExpositionErrors caps how many format errors are reported
+const maxExpositionErrors = 5
+
+// expositionStats summarizes a Prometheus text exposition payload
+type expositionStats struct {
+	samples  int
+	families map[string]bool
+	// invalid counts malformed lines; errors holds the first few
+	invalid int
+	errors  []string
+}
+
+// validMetricTypes lists the TYPE values accepted by Prometheus and OpenMetrics
+var validMetricTypes = map[string]bool{
+	"counter": true, "gauge": true, "histogram": true, "summary": true, "untyped": true,
+	"unknown": true, "info": true, "stateset": true, "gaugehistogram": true,
+}
+
+// familySuffixes are appended to a family name by histogram, summary and
+// counter samples
+var familySuffixes = []string{"_bucket", "_sum", "_count", "_total", "_created", "_info"}
+
+// validateExposition checks text against the Prometheus text exposition
+// format and counts its samples and metric families
+func validateExposition(text string) expositionStats {
+	stats := expositionStats{families: map[string]bool{}}
+	declared := map[string]bool{}
+
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		var err error
+		if strings.HasPrefix(line, "#") {
+			err = checkExpositionComment(line, declared)
+		} else {
+			var name string
+			name, err = checkExpositionSample(line)
+			if err == nil {
+				stats.samples++
+				stats.families[metricFamily(name, declared)] = true
+			}
+		}
+
+		if err != nil {
+			stats.invalid++
+			if len(stats.errors) < maxExpositionErrors {
+				stats.errors = append(stats.errors, fmt.Sprintf("line %d: %v", i+1, err))
+			}
+		}
+	}
+
+	return stats
+}
+
+// checkExpositionComment validates HELP and TYPE lines, recording declared
+// families. Other comments are ignored.
+func checkExpositionComment(line string, declared map[string]bool) error {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || (fields[1] != "TYPE" && fields[1] != "HELP") {
+		return nil
+	}
+	if len(fields) < 3 || !isMetricName(fields[2]) {
+		return fmt.Errorf("invalid metric name in %s line", fields[1])
+	}
+	if fields[1] == "TYPE" {
+		if len(fields) != 4 || !validMetricTypes[fields[3]] {
+			return fmt.Errorf("invalid TYPE line for %s", fields[2])
+		}
+		declared[fields[2]] = true
+	}
+	return nil
+}
+
+// checkExpositionSample validates a sample line and returns its metric name
+func checkExpositionSample(line string) (string, error) {
+	end := 0
+	for end < len(line) && isMetricNameChar(line[end], end == 0) {
+		end++
+	}
+	name := line[:end]
+	if name == "" {
+		return "", fmt.Errorf("sample does not start with a metric name")
+	}
+
+	rest := line[end:]
+	if strings.HasPrefix(rest, "{") {
+		var err error
+		rest, err = skipLabels(rest)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) < 1 || len(fields) > 2 {
+		return "", fmt.Errorf("%s: expected a value and optional timestamp", name)
+	}
+	if _, err := strconv.ParseFloat(fields[0], 64); err != nil {
+		return "", fmt.Errorf("%s: invalid value %q", name, fields[0])
+	}
+	if len(fields) == 2 {
+		if _, err := strconv.ParseFloat(fields[1], 64); err != nil {
+			return "", fmt.Errorf("%s: invalid timestamp %q", name, fields[1])
+		}
+	}
+	return name, nil
+}
+
+// skipLabels consumes a {label="value",...} block and returns the remainder
+func skipLabels(s string) (string, error) {
+	i := 1
+	for {
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		if i < len(s) && s[i] == '}' {
+			return s[i+1:], nil
+		}
+
+		start := i
+		for i < len(s) && isMetricNameChar(s[i], i == start) && s[i] != ':' {
+			i++
+		}
+		if i == start {
+			return "", fmt.Errorf("invalid label name")
+		}
+		if i+1 >= len(s) || s[i] != '=' || s[i+1] != '"' {
+			return "", fmt.Errorf("expected =\" after label %s", s[start:i])
+		}
+		i += 2
+
+		for i < len(s) && s[i] != '"' {
+			if s[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(s) {
+			return "", fmt.Errorf("unterminated label value")
+		}
+		i++
+
+		if i < len(s) && s[i] == ',' {
+			i++
+		}
+	}
+}
+
+// metricFamily maps a sample name to its declared family, if any
+func metricFamily(name string, declared map[string]bool) string {
+	for _, suffix := range familySuffixes {
+		if base, ok := strings.CutSuffix(name, suffix); ok && declared[base] {
+			return base
+		}
+	}
+	return name
+}
+
+func isMetricName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isMetricNameChar(s[i], i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func isMetricNameChar(c byte, first bool) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c == ':':
+		return true
+	case c >= '0' && c <= '9':
+		return !first
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+)
+
+// printFootprint reports what a test pod cost. It does nothing for nil.
+func printFootprint(fp *k8s.Footprint) {
+	if fp == nil {
+		return
+	}
+
+	parts := []string{fmt.Sprintf("scheduled in %s", fp.SchedulingLatency.Round(time.Millisecond))}
+
+	switch {
+	case fp.ImageCached:
+		parts = append(parts, "image cached")
+	case fp.ImagePullTime > 0 && fp.ImageSize > 0:
+		parts = append(parts, fmt.Sprintf("image pulled in %s (%s)",
+			fp.ImagePullTime.Round(time.Millisecond), formatBytes(fp.ImageSize)))
+	case fp.ImagePullTime > 0:
+		parts = append(parts, fmt.Sprintf("image pulled in %s", fp.ImagePullTime.Round(time.Millisecond)))
+	}
+
+	if fp.UsageSampled {
+		parts = append(parts, fmt.Sprintf("peak CPU %dm, peak memory %s",
+			fp.PeakCPUMillis, formatBytes(fp.PeakMemoryBytes)))
+	} else {
+		parts = append(parts, "usage n/a (metrics-server unavailable or pod too short-lived)")
+	}
+
+	printf("📊 Footprint: %s\n", strings.Join(parts, ", "))
+}
+
+// printTimings reports where the time of a test run went, leaving out the
+// steps the run skipped
+func printTimings(t tester.Timings) {
+	var parts []string
+	for _, step := range []struct {
+		name string
+		took time.Duration
+	}{
+		{"scheduling", t.Scheduling},
+		{"image pull", t.ImagePull},
+		{"probe", t.Probe},
+	} {
+		if step.took > 0 {
+			parts = append(parts, fmt.Sprintf("%s %s", step.name, step.took.Round(time.Millisecond)))
+		}
+	}
+	if len(parts) == 0 {
+		return
+	}
+	printf("⏱️  Took %s: %s\n", t.Total.Round(time.Millisecond), strings.Join(parts, ", "))
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. "45.2 MiB"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
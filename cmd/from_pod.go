@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/spf13/cobra"
+)
+
+// addFromPodFlag registers --from-pod on cmd
+func (o *testOptions) addFromPodFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.fromPod, "from-pod", "",
+		"run the test in an ephemeral container of this pod, or of a ready pod of svc/<name> or deploy/<name>, so it uses the application's network identity")
+}
+
+// resolveFromPod returns the name of a ready pod behind the --from-pod
+// reference
+func (o *testOptions) resolveFromPod(client *k8s.Client) (string, error) {
+	if o.footprint {
+		return "", fmt.Errorf("--footprint cannot be combined with --from-pod")
+	}
+	if o.ssh.enabled() {
+		return "", fmt.Errorf("--ssh cannot be combined with --from-pod")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pods, err := resolvePods(ctx, client, o.fromPod)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", o.fromPod, err)
+	}
+	pod := readyPod(ctx, client, pods)
+	if pod == nil {
+		return "", fmt.Errorf("no ready pod found for %s", o.fromPod)
+	}
+	return pod.Name, nil
+}
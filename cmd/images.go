@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/config"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/images"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// imageRegistry is the --image-registry flag; it overrides the config file
+	imageRegistry string
+	// verifyImages is --verify-images; the config file can also turn it on
+	verifyImages bool
+)
+
+// resolveImage returns the image to run for a built-in tool: a per-tester
+// override from the config file, or the built-in image, pinned to its digest
+// in the image manifest and moved to the configured registry mirror.
+// User-supplied custom images are used as given.
+func resolveImage(cfg *config.Config, name, builtin string) string {
+	if name == "custom" {
+		return builtin
+	}
+
+	if imageRegistry != "" {
+		override := *cfg
+		override.ImageRegistry = imageRegistry
+		cfg = &override
+	}
+	return cfg.ImageFor(name, images.Pin(builtin))
+}
+
+// setImagePolicy makes client refuse images not pinned by digest when
+// --verify-images or the config file asks for it
+func setImagePolicy(client *k8s.Client) {
+	client.VerifyImages = verifyImages
+	if cfg, err := GetConfig(); err == nil && cfg.VerifyImages {
+		client.VerifyImages = true
+	}
+}
+
+// builtinImage is a tool image whose override is configured under name
+type builtinImage struct {
+	name string
+	ref  string
+}
+
+// builtinImages lists the images pocket runs by default, sorted by name
+func builtinImages() []builtinImage {
+	list := []builtinImage{
+		{"agent", tester.AgentImage},
+		{"check", quotaCheckImage},
+		{"debug", debugNodeImage},
+		{"debug-attach", debugAttachImage},
+		{"external-service", externalServiceProbeImage},
+		{"gateway", externalServiceProbeImage},
+		{"iperf", iperfImage},
+		{"metrics", metricsProbeImage},
+		{"proxy", socksProxyImage},
+		{"sniff", sniffImage},
+		{"ssh", sshTunnelImage},
+		{"tunnel", socatImage},
+		{"webhook", webhookProbeImage},
+	}
+	for _, name := range tester.Names() {
+		if t, ok := tester.Get(name); ok {
+			list = append(list, builtinImage{name, t.Image()})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].name < list[j].name })
+	return list
+}
+
+var imagesCmd = &cobra.Command{
+	Use:   "images",
+	Short: "View and override the tool images pocket runs",
+	Long: `View and override the tool images pocket runs.
+
+Built-in images run by the digest recorded for their tag in the image
+manifest this build of pocket ships, so a tag moved upstream does not change
+what runs in the cluster. An image whose tag has no digest in the manifest
+runs by tag and is listed as not pinned. An override replaces a tool's image;
+pin it too, as name:tag@sha256:<digest>. Overrides are stored in the config
+file as testers.<name>.image.
+
+With --verify-images, or verifyImages: true in the config file, pocket
+refuses to start pods whose images are not pinned by digest, including
+images given with --image.
+
+Examples:
+  kubectl pocket images list
+  kubectl pocket images set redis redis:7.2-alpine@sha256:<digest>
+  kubectl pocket images unset redis`,
+}
+
+var imagesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the tool images and whether they are pinned",
+	Args:  cobra.NoArgs,
+	RunE:  runImagesList,
+}
+
+var imagesSetCmd = &cobra.Command{
+	Use:               "set <name> <image>",
+	Short:             "Override a tool's image",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeImageNames,
+	RunE:              runImagesSet,
+}
+
+var imagesUnsetCmd = &cobra.Command{
+	Use:               "unset <name>",
+	Short:             "Go back to a tool's built-in image",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeImageNames,
+	RunE:              runImagesUnset,
+}
+
+func init() {
+	imagesCmd.AddCommand(imagesListCmd, imagesSetCmd, imagesUnsetCmd)
+	markStructuredOutput(imagesListCmd)
+}
+
+// toolImage is an image listed by images list
+type toolImage struct {
+	Name       string `json:"name"`
+	Image      string `json:"image"`
+	Builtin    string `json:"builtin"`
+	Overridden bool   `json:"overridden"`
+	Pinned     bool   `json:"pinned"`
+}
+
+func runImagesList(cmd *cobra.Command, args []string) error {
+	cfg, err := GetConfig()
+	if err != nil {
+		return err
+	}
+
+	list := []toolImage{}
+	unpinned := 0
+	for _, image := range builtinImages() {
+		entry := toolImage{
+			Name:       image.name,
+			Image:      resolveImage(cfg, image.name, image.ref),
+			Builtin:    images.Pin(image.ref),
+			Overridden: cfg.Testers[image.name].Image != "",
+		}
+		entry.Pinned = images.Pinned(entry.Image)
+		if !entry.Pinned {
+			unpinned++
+		}
+		list = append(list, entry)
+	}
+
+	if structured() {
+		return printStructured(list)
+	}
+	w := newTable()
+	_, _ = fmt.Fprintln(w, "NAME\tIMAGE\tSOURCE\tPINNED")
+	for _, entry := range list {
+		source, pinned := i18n.T("built-in"), i18n.T("yes")
+		if entry.Overridden {
+			source = i18n.T("config")
+		}
+		if !entry.Pinned {
+			pinned = i18n.T("no")
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.Name, entry.Image, source, pinned)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if unpinned > 0 {
+		i18n.Printf("\n⚠️  %d image(s) are not pinned by digest; --verify-images refuses to run them\n", unpinned)
+	}
+	return nil
+}
+
+func runImagesSet(cmd *cobra.Command, args []string) error {
+	name, image := args[0], args[1]
+	if err := checkImageName(name); err != nil {
+		return err
+	}
+	if strings.TrimSpace(image) == "" {
+		return fmt.Errorf("image must not be empty; use images unset to go back to the built-in image")
+	}
+	if err := updateConfig("testers."+name+".image", image); err != nil {
+		return err
+	}
+	if !images.Pinned(image) {
+		i18n.Printf("⚠️  %s is not pinned by digest; --verify-images refuses to run it\n", image)
+	}
+	return nil
+}
+
+func runImagesUnset(cmd *cobra.Command, args []string) error {
+	if err := checkImageName(args[0]); err != nil {
+		return err
+	}
+	return updateConfig("testers."+args[0]+".image", "")
+}
+
+// checkImageName rejects names that are not a built-in tool
+func checkImageName(name string) error {
+	var names []string
+	for _, image := range builtinImages() {
+		if image.name == name {
+			return nil
+		}
+		names = append(names, image.name)
+	}
+	return fmt.Errorf("unknown image %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// completeImageNames completes the first argument with the tool names
+func completeImageNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, image := range builtinImages() {
+		names = append(names, image.name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
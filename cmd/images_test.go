@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/images"
+)
+
+func TestBuiltinImagesInManifest(t *testing.T) {
+	for _, image := range builtinImages() {
+		if !strings.Contains(image.ref, ":") {
+			t.Errorf("%s image %q has no tag", image.name, image.ref)
+		}
+		if !images.Listed(image.ref) {
+			t.Errorf("%s image %q is missing from pkg/images/manifest.json", image.name, image.ref)
+		}
+	}
+}
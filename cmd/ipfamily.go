@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// addIPFamilyFlag registers --ip-family on a test command
+func (o *testOptions) addIPFamilyFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.ipFamily, "ip-family", "",
+		"connect to a dual-stack Service over this family (ipv4 or ipv6) by using its ClusterIP of that family")
+}
+
+// parseIPFamily accepts ipv4/ipv6 in any case, or 4/6
+func parseIPFamily(s string) (corev1.IPFamily, error) {
+	switch strings.ToLower(s) {
+	case "ipv4", "4":
+		return corev1.IPv4Protocol, nil
+	case "ipv6", "6":
+		return corev1.IPv6Protocol, nil
+	}
+	return "", fmt.Errorf("invalid --ip-family %q (use ipv4 or ipv6)", s)
+}
+
+// ipFamilyOf returns the family of an IP literal
+func ipFamilyOf(ip net.IP) corev1.IPFamily {
+	if ip.To4() != nil {
+		return corev1.IPv4Protocol
+	}
+	return corev1.IPv6Protocol
+}
+
+// pinIPFamily rewrites target to connect to its Service's ClusterIP of the
+// --ip-family family, so the test does not depend on which address the
+// client's resolver prefers. IP literals are only checked.
+func (o *testOptions) pinIPFamily(client *k8s.Client, t tester.Tester, target string) (string, error) {
+	family, err := parseIPFamily(o.ipFamily)
+	if err != nil {
+		return "", err
+	}
+	addresser, ok := t.(tester.Addresser)
+	if !ok {
+		return "", fmt.Errorf("--ip-family is not supported by %s", t.Name())
+	}
+
+	host, port, err := addresser.TargetAddress(target)
+	if err != nil {
+		return "", err
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if ipFamilyOf(ip) != family {
+			return "", fmt.Errorf("%s is not an %s address", host, family)
+		}
+		return target, nil
+	}
+
+	namespace, name, ok := serviceHost(host, client.Namespace)
+	if !ok {
+		return "", fmt.Errorf("--ip-family needs a Service name or IP literal; %s is not a cluster Service", host)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	svc, err := client.Clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get service %s/%s: %w", namespace, name, err)
+	}
+	if svc.Spec.ClusterIP == corev1.ClusterIPNone || svc.Spec.Type == corev1.ServiceTypeExternalName {
+		return "", fmt.Errorf("service %s has no ClusterIP; use a pod or endpoint IP with --ip-family", name)
+	}
+
+	for _, clusterIP := range svc.Spec.ClusterIPs {
+		if ip := net.ParseIP(clusterIP); ip != nil && ipFamilyOf(ip) == family {
+			i18n.Printf("💡 Using %s ClusterIP %s of service %s\n", family, clusterIP, name)
+			return addresser.WithAddress(target, clusterIP, port)
+		}
+	}
+	return "", fmt.Errorf("service %s has no %s ClusterIP (families: %v)", name, family, svc.Spec.IPFamilies)
+}
+
+// serviceHost splits a cluster DNS name (svc, svc.ns, svc.ns.svc or
+// svc.ns.svc.<cluster-domain>) into namespace and service name
+func serviceHost(host, defaultNamespace string) (namespace, name string, ok bool) {
+	parts := strings.Split(strings.TrimSuffix(host, "."), ".")
+	switch {
+	case len(parts) == 1:
+		return defaultNamespace, parts[0], true
+	case len(parts) == 2:
+		return parts[1], parts[0], true
+	case parts[2] == "svc":
+		return parts[1], parts[0], true
+	}
+	return "", "", false
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// controllerLabels are set by workload controllers on the pods they own.
+// They are not copied, so that no controller adopts the test pod.
+var controllerLabels = []string{
+	"pod-template-hash",
+	"controller-revision-hash",
+	"pod-template-generation",
+	"statefulset.kubernetes.io/pod-name",
+	"apps.kubernetes.io/pod-index",
+}
+
+// addLikeFlag registers --like on cmd
+func (o *testOptions) addLikeFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.like, "like", "",
+		"run the test pod with the labels, namespace and service account of [<namespace>/]deploy/<name> or pod/<name>, so NetworkPolicies and mesh policies treat it as that workload")
+}
+
+// workloadIdentity is what --like copies from a workload
+type workloadIdentity struct {
+	ref            string
+	namespace      string
+	serviceAccount string
+	labels         map[string]string
+}
+
+// resolveLike reads the identity of the --like workload. The reference may
+// start with the workload's namespace; it defaults to the client's.
+func (o *testOptions) resolveLike(client *k8s.Client) (*workloadIdentity, error) {
+	switch {
+	case o.fromPod != "":
+		return nil, fmt.Errorf("--like cannot be combined with --from-pod, which already runs in the application's pod")
+	case o.reuse:
+		return nil, fmt.Errorf("--like cannot be combined with --reuse")
+	case podOpts.serviceAccount != "":
+		return nil, fmt.Errorf("--like cannot be combined with --service-account")
+	}
+
+	ref, namespace := o.like, client.Namespace
+	if strings.Count(ref, "/") == 2 {
+		namespace, ref, _ = strings.Cut(ref, "/")
+	}
+	kind, name, err := parseResourceRef(ref, kindDeployment)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	identity := &workloadIdentity{ref: ref, namespace: namespace}
+	switch kind {
+	case kindDeployment:
+		deploy, err := client.Clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", o.like, err)
+		}
+		identity.serviceAccount = deploy.Spec.Template.Spec.ServiceAccountName
+		identity.labels = deploy.Spec.Template.Labels
+	case kindPod:
+		pod, err := client.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", o.like, err)
+		}
+		identity.serviceAccount = pod.Spec.ServiceAccountName
+		identity.labels = pod.Labels
+	default:
+		return nil, fmt.Errorf("--like takes deploy/<name> or pod/<name>, not %s", o.like)
+	}
+
+	identity.labels = mergeMaps(nil, identity.labels)
+	for _, key := range controllerLabels {
+		delete(identity.labels, key)
+	}
+	return identity, nil
+}
+
+// apply gives a pod configuration the workload's identity. The pod never
+// becomes ready, so Services selecting the workload's labels do not send it
+// traffic.
+func (w *workloadIdentity) apply(config *k8s.PodConfig) {
+	config.Labels = mergeMaps(mergeMaps(nil, w.labels), config.Labels)
+	if w.serviceAccount != "" {
+		config.ServiceAccountName = w.serviceAccount
+	}
+	config.NeverReady = true
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// defaultContainerAnnotation names the container kubectl reads logs from
+const defaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+
+var logsCmd = &cobra.Command{
+	Use:   "logs (<database> | <kind>/<name>)",
+	Short: "Print the logs of a database's pods",
+	Long: `Print the logs of the pods behind a database without looking up their
+names first. With several pods, lines are interleaved as they arrive and
+prefixed with the pod's name.
+
+<database> is a port-forward alias (redis, mongo, postgres or one from the
+config file), or the service or type of a database "kubectl pocket discover"
+finds; svc/, deploy/ and pod/ references name the pods directly.
+
+The container is the one named by the pod's
+kubectl.kubernetes.io/default-container annotation, or its first one, unless
+--container is given.
+
+With -o the lines are collected per pod into one document, so -o cannot be
+combined with --follow.
+
+Examples:
+  kubectl pocket logs postgres
+  kubectl pocket logs redis -f --since 10m
+  kubectl pocket logs orders-db --tail 500 -n payments
+  kubectl pocket logs svc/mongo -c mongod
+  kubectl pocket logs postgres --since 1h -o json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePortForward,
+	RunE:              runLogs,
+}
+
+var (
+	logsFollow    bool
+	logsSince     time.Duration
+	logsTail      int64
+	logsContainer string
+	logsPrefix    bool
+)
+
+func init() {
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "keep printing new lines until interrupted")
+	logsCmd.Flags().DurationVar(&logsSince, "since", 0, "only print lines newer than this, e.g. 10m")
+	logsCmd.Flags().Int64Var(&logsTail, "tail", 100, "lines to print from the end of each pod's logs; -1 prints all")
+	logsCmd.Flags().StringVarP(&logsContainer, "container", "c", "", "container to print the logs of")
+	logsCmd.Flags().BoolVar(&logsPrefix, "prefix", true, "prefix lines with the pod's name when there are several pods")
+	markStructuredOutput(logsCmd)
+}
+
+// logsReport is the structured result of logs
+type logsReport struct {
+	Ref       string          `json:"ref"`
+	Namespace string          `json:"namespace"`
+	Pods      []podLogsReport `json:"pods"`
+}
+
+// podLogsReport holds the lines of one pod's logs
+type podLogsReport struct {
+	Pod       string   `json:"pod"`
+	Container string   `json:"container"`
+	Lines     []string `json:"lines"`
+}
+
+func runLogs(cmd *cobra.Command, args []string) error {
+	if logsSince < 0 {
+		return fmt.Errorf("--since must be positive")
+	}
+	if structured() && logsFollow {
+		return fmt.Errorf("-o cannot be combined with --follow")
+	}
+
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	resolveCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	ref, err := resolveDatabaseRef(resolveCtx, client, args[0])
+	if err != nil {
+		return err
+	}
+	pods, err := resolvePods(resolveCtx, client, ref)
+	if err != nil {
+		return fmt.Errorf("failed to resolve pods: %w", err)
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+
+	names := make([]string, len(pods))
+	for i, pod := range pods {
+		names[i] = pod.Name
+	}
+	i18n.Printf("📜 Logs of %s: %v\n", ref, names)
+
+	prefix := logsPrefix && len(pods) > 1
+	report := logsReport{Ref: ref, Namespace: client.Namespace, Pods: make([]podLogsReport, len(pods))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errs := make([]error, len(pods))
+	for i, pod := range pods {
+		report.Pods[i] = podLogsReport{Pod: pod.Name, Container: logContainer(pod), Lines: []string{}}
+		// Each pod's lines are printed whole under mu so those of several
+		// pods do not mix, or collected for -o
+		emit := func(line string) {
+			mu.Lock()
+			defer mu.Unlock()
+			if prefix {
+				_, _ = fmt.Fprintf(ioStreams.Out, "[%s] ", pod.Name)
+			}
+			_, _ = io.WriteString(ioStreams.Out, line)
+		}
+		if structured() {
+			emit = func(line string) {
+				report.Pods[i].Lines = append(report.Pods[i].Lines, strings.TrimSuffix(line, "\n"))
+			}
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = streamLogs(ctx, client, pod, emit)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil && ctx.Err() == nil {
+			return fmt.Errorf("failed to get logs of %s: %w", pods[i].Name, err)
+		}
+	}
+	if structured() {
+		return printStructured(report)
+	}
+	return nil
+}
+
+// streamLogs passes the logs of pod to emit line by line, each ending in a
+// newline
+func streamLogs(ctx context.Context, client *k8s.Client, pod corev1.Pod, emit func(line string)) error {
+	opts := &corev1.PodLogOptions{Container: logContainer(pod), Follow: logsFollow}
+	if logsTail >= 0 {
+		opts.TailLines = &logsTail
+	}
+	if logsSince > 0 {
+		seconds := int64(logsSince.Seconds())
+		opts.SinceSeconds = &seconds
+	}
+
+	stream, err := client.StreamPodLogs(ctx, pod.Namespace, pod.Name, opts)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stream.Close() }()
+
+	reader := bufio.NewReader(stream)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			if line[len(line)-1] != '\n' {
+				line += "\n"
+			}
+			emit(line)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// logContainer returns the container to read the logs of in pod
+func logContainer(pod corev1.Pod) string {
+	if logsContainer != "" {
+		return logsContainer
+	}
+	if name := pod.Annotations[defaultContainerAnnotation]; name != "" {
+		return name
+	}
+	if len(pod.Spec.Containers) > 0 {
+		return pod.Spec.Containers[0].Name
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLogsStructuredOutput(t *testing.T) {
+	root := newTestRoot(t)
+	clientset := fake.NewClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "payments"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "postgres"}}},
+	})
+	var out bytes.Buffer
+	k8sClient, resultOut = k8s.NewClientForClientset(clientset, nil, nil, "payments"), &out
+	t.Cleanup(func() { k8sClient, resultOut, outputFormat, logsFollow = nil, &testRootOut, "", false })
+
+	root.SetArgs([]string{"logs", "pod/db-0", "-o", "json"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("logs -o json error = %v", err)
+	}
+	var report logsReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("logs -o json wrote %q: %v", out.String(), err)
+	}
+	// The fake clientset returns these logs for every pod
+	want := logsReport{Ref: "pod/db-0", Namespace: "payments", Pods: []podLogsReport{
+		{Pod: "db-0", Container: "postgres", Lines: []string{"fake logs"}},
+	}}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("logs -o json = %+v, want %+v", report, want)
+	}
+
+	root.SetArgs([]string{"logs", "pod/db-0", "-o", "json", "--follow"})
+	if err := root.Execute(); err == nil || !strings.Contains(err.Error(), "--follow") {
+		t.Errorf("logs -o json --follow error = %v, want a --follow error", err)
+	}
+}
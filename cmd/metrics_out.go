@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/bundle"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+)
+
+// defaultPushgatewayJob is the job label of pushed metrics
+const defaultPushgatewayJob = "kubectl_pocket"
+
+// latencyBuckets are the upper bounds, in seconds, of the latency histogram.
+// They span a probe in a running pod as well as a test that starts its own.
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// metricsOut holds --metrics-out, --pushgateway and --pushgateway-job. Only
+// one command runs per process, so the test commands share them.
+var metricsOut struct {
+	file        string
+	pushgateway string
+	job         string
+}
+
+// addMetricsOutFlags registers the Prometheus output flags on cmd
+func addMetricsOutFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&metricsOut.file, "metrics-out", "",
+		"write the results in Prometheus text format to this file, e.g. for the node_exporter textfile collector")
+	cmd.Flags().StringVar(&metricsOut.pushgateway, "pushgateway", "", "push the results to this Prometheus Pushgateway URL")
+	cmd.Flags().StringVar(&metricsOut.job, "pushgateway-job", defaultPushgatewayJob, "job label of the metrics pushed with --pushgateway")
+}
+
+// metricsOutEnabled reports whether the results are exported
+func metricsOutEnabled() bool {
+	return metricsOut.file != "" || metricsOut.pushgateway != ""
+}
+
+// metricLabels identify the series of a test
+type metricLabels struct {
+	tester    string
+	target    string
+	namespace string
+	context   string
+}
+
+// metricSeries is what was recorded for a test
+type metricSeries struct {
+	success   bool
+	latencies []time.Duration
+	checkedAt time.Time
+}
+
+// testMetrics are the results recorded by the command
+var testMetrics = struct {
+	sync.Mutex
+	series map[metricLabels]*metricSeries
+	// order keeps the series in the order they were first recorded
+	order []metricLabels
+}{series: map[metricLabels]*metricSeries{}}
+
+// recordTestMetric records a test's outcome and the latencies it observed.
+// Passwords in the target are redacted, as it becomes a label.
+func recordTestMetric(labels metricLabels, success bool, latencies ...time.Duration) {
+	if !metricsOutEnabled() {
+		return
+	}
+	labels.target = bundle.NewRedactor(targetSecrets([]string{labels.target})...).String(labels.target)
+
+	testMetrics.Lock()
+	defer testMetrics.Unlock()
+	series, ok := testMetrics.series[labels]
+	if !ok {
+		series = &metricSeries{}
+		testMetrics.series[labels] = series
+		testMetrics.order = append(testMetrics.order, labels)
+	}
+	series.success = success
+	series.latencies = append(series.latencies, latencies...)
+	series.checkedAt = time.Now()
+}
+
+// recordTestResult records the outcome of a single test run, which failed
+// to run when err is set
+func recordTestResult(labels metricLabels, result *tester.Result, err error, latency time.Duration) {
+	if err != nil || result == nil {
+		recordTestMetric(labels, false)
+		return
+	}
+	recordTestMetric(labels, result.Success, latency)
+}
+
+// exportTestMetrics writes and pushes the recorded results
+func exportTestMetrics() error {
+	if !metricsOutEnabled() {
+		return nil
+	}
+	testMetrics.Lock()
+	text := formatTestMetrics()
+	testMetrics.Unlock()
+	if text == "" {
+		return nil
+	}
+
+	if metricsOut.file != "" {
+		if err := writeFileAtomic(metricsOut.file, []byte(text)); err != nil {
+			return fmt.Errorf("failed to write metrics: %w", err)
+		}
+	}
+	if metricsOut.pushgateway != "" {
+		if err := pushMetrics(metricsOut.pushgateway, metricsOut.job, text); err != nil {
+			return fmt.Errorf("failed to push metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+// formatTestMetrics renders the recorded results in the Prometheus text
+// exposition format, or returns "" if nothing was recorded
+func formatTestMetrics() string {
+	if len(testMetrics.order) == 0 {
+		return ""
+	}
+	var b strings.Builder
+
+	b.WriteString("# HELP pocket_test_success Whether the last test of the target passed (1) or failed (0).\n")
+	b.WriteString("# TYPE pocket_test_success gauge\n")
+	for _, labels := range testMetrics.order {
+		value := 0
+		if testMetrics.series[labels].success {
+			value = 1
+		}
+		fmt.Fprintf(&b, "pocket_test_success%s %d\n", labels.format(), value)
+	}
+
+	b.WriteString("# HELP pocket_test_last_run_timestamp_seconds When the target was last tested.\n")
+	b.WriteString("# TYPE pocket_test_last_run_timestamp_seconds gauge\n")
+	for _, labels := range testMetrics.order {
+		fmt.Fprintf(&b, "pocket_test_last_run_timestamp_seconds%s %d\n", labels.format(), testMetrics.series[labels].checkedAt.Unix())
+	}
+
+	b.WriteString("# HELP pocket_test_latency_seconds Latency of the target's tests that completed.\n")
+	b.WriteString("# TYPE pocket_test_latency_seconds histogram\n")
+	for _, labels := range testMetrics.order {
+		latencies := testMetrics.series[labels].latencies
+		var sum float64
+		for _, bound := range latencyBuckets {
+			count := 0
+			for _, latency := range latencies {
+				if latency.Seconds() <= bound {
+					count++
+				}
+			}
+			fmt.Fprintf(&b, "pocket_test_latency_seconds_bucket%s %d\n",
+				labels.format("le", strconv.FormatFloat(bound, 'g', -1, 64)), count)
+		}
+		for _, latency := range latencies {
+			sum += latency.Seconds()
+		}
+		fmt.Fprintf(&b, "pocket_test_latency_seconds_bucket%s %d\n", labels.format("le", "+Inf"), len(latencies))
+		fmt.Fprintf(&b, "pocket_test_latency_seconds_sum%s %s\n", labels.format(), strconv.FormatFloat(sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "pocket_test_latency_seconds_count%s %d\n", labels.format(), len(latencies))
+	}
+	return b.String()
+}
+
+// format renders the labels, followed by extra name and value pairs, as a
+// {name="value",...} block. The context is left out when empty.
+func (l metricLabels) format(extra ...string) string {
+	pairs := []string{"tester", l.tester, "target", l.target, "namespace", l.namespace}
+	if l.context != "" {
+		pairs = append(pairs, "context", l.context)
+	}
+	pairs = append(pairs, extra...)
+
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		parts = append(parts, pairs[i]+`="`+escapeLabelValue(pairs[i+1])+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// labelValueEscaper escapes label values as the exposition format requires
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+// writeFileAtomic replaces path with data through a temporary file in the
+// same directory, so a collector never reads a partial file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// pushMetrics replaces the metrics of job on a Pushgateway with text
+func pushMetrics(gateway, job, text string) error {
+	if job == "" {
+		return fmt.Errorf("--pushgateway-job must not be empty")
+	}
+	u, err := url.Parse(gateway)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid Pushgateway URL %q", gateway)
+	}
+	u = u.JoinPath("metrics", "job", job)
+
+	req, err := http.NewRequest(http.MethodPut, u.String(), bytes.NewBufferString(text))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s returned %s", u.Redacted(), resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/bench"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// iperfImage provides iperf3
+const iperfImage = "nicolaka/netshoot:v0.13"
+
+// iperfPort is the port the iperf3 server listens on
+const iperfPort = 5201
+
+var netCmd = &cobra.Command{
+	Use:   "net",
+	Short: "Measure the network between pods, nodes and namespaces",
+}
+
+var netBenchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure network throughput between two nodes or namespaces with iperf3",
+	Long: `Start an iperf3 server pod and a client pod, run a throughput test between
+them and report the bandwidth and TCP retransmits, e.g. when cross-node
+database traffic is slow.
+
+The pods can be pinned to nodes with --from-node and --to-node, and placed in
+different namespaces with --from-namespace and --to-namespace; otherwise the
+scheduler places them in the current namespace. The client connects to the
+server pod's IP, bypassing services.
+
+Examples:
+  kubectl pocket net bench --from-node worker-1 --to-node worker-2
+  kubectl pocket net bench --from-namespace app --to-namespace databases --duration 30s
+  kubectl pocket net bench --from-node worker-1 --to-node worker-3 --parallel 4 -o json`,
+	Args: cobra.NoArgs,
+	RunE: runNetBench,
+}
+
+var (
+	netFromNode      string
+	netToNode        string
+	netFromNamespace string
+	netToNamespace   string
+	netDuration      time.Duration
+	netParallel      int
+	netImage         string
+)
+
+func init() {
+	netBenchCmd.Flags().StringVar(&netFromNode, "from-node", "", "node the client pod runs on")
+	netBenchCmd.Flags().StringVar(&netToNode, "to-node", "", "node the server pod runs on")
+	netBenchCmd.Flags().StringVar(&netFromNamespace, "from-namespace", "", "namespace of the client pod (default the current namespace)")
+	netBenchCmd.Flags().StringVar(&netToNamespace, "to-namespace", "", "namespace of the server pod (default the current namespace)")
+	netBenchCmd.Flags().DurationVar(&netDuration, "duration", 10*time.Second, "how long the test runs")
+	netBenchCmd.Flags().IntVar(&netParallel, "parallel", 1, "parallel client streams")
+	netBenchCmd.Flags().StringVar(&netImage, "image", "", "override the iperf3 image (default "+iperfImage+")")
+	addPodMetadataFlags(netBenchCmd)
+	markStructuredOutput(netBenchCmd)
+
+	netCmd.AddCommand(netBenchCmd)
+}
+
+// netBenchReport is the structured result of a network benchmark
+type netBenchReport struct {
+	FromNode              string  `json:"fromNode"`
+	FromNamespace         string  `json:"fromNamespace"`
+	ToNode                string  `json:"toNode"`
+	ToNamespace           string  `json:"toNamespace"`
+	Seconds               float64 `json:"seconds"`
+	Parallel              int     `json:"parallel"`
+	SentBitsPerSecond     float64 `json:"sentBitsPerSecond"`
+	ReceivedBitsPerSecond float64 `json:"receivedBitsPerSecond"`
+	Retransmits           int64   `json:"retransmits"`
+	Bytes                 int64   `json:"bytes"`
+}
+
+func runNetBench(cmd *cobra.Command, args []string) error {
+	seconds := int(netDuration.Round(time.Second) / time.Second)
+	if seconds < 1 {
+		return fmt.Errorf("--duration must be at least 1s")
+	}
+	if netParallel < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+	cfg, err := GetConfig()
+	if err != nil {
+		return err
+	}
+	podOpts.applyConfig(cfg)
+
+	image := netImage
+	if image == "" {
+		image = resolveImage(cfg, "iperf", iperfImage)
+	}
+	fromNS, toNS := valueOr(netFromNamespace, client.Namespace), valueOr(netToNamespace, client.Namespace)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute+netDuration)
+	defer cancel()
+
+	suffix := time.Now().Unix()
+	server := k8s.PodConfig{
+		Name:      fmt.Sprintf("pocket-iperf-server-%d", suffix),
+		Namespace: toNS,
+		Image:     image,
+		Command:   []string{"iperf3", "--server", "--port", fmt.Sprint(iperfPort)},
+		Purpose:   "bench",
+		NodeName:  netToNode,
+	}
+	clientPod := k8s.PodConfig{
+		Name:      fmt.Sprintf("pocket-iperf-client-%d", suffix),
+		Namespace: fromNS,
+		Image:     image,
+		Command:   []string{"sleep", "86400"},
+		Purpose:   "bench",
+		NodeName:  netFromNode,
+	}
+	for _, config := range []*k8s.PodConfig{&server, &clientPod} {
+		if err := podOpts.applyMetadata(config); err != nil {
+			return err
+		}
+		i18n.Printf("📦 Creating pod: %s/%s\n", config.Namespace, config.Name)
+		if _, err := client.CreatePod(ctx, *config); err != nil {
+			return fmt.Errorf("failed to create pod %s: %w", config.Name, err)
+		}
+		defer func(ns, name string) {
+			i18n.Printf("🧹 Cleaning up pod: %s\n", name)
+			cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cleanupCancel()
+			_ = client.DeletePod(cleanupCtx, ns, name)
+		}(config.Namespace, config.Name)
+	}
+
+	i18n.Printf("⏳ Waiting for pods to be ready...\n")
+	for _, config := range []k8s.PodConfig{server, clientPod} {
+		if err := client.WaitForPodRunning(ctx, config.Namespace, config.Name, 2*time.Minute); err != nil {
+			return fmt.Errorf("pod %s failed to start: %w", config.Name, err)
+		}
+	}
+	serverPod, err := client.Clientset.CoreV1().Pods(toNS).Get(ctx, server.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	runningClient, err := client.Clientset.CoreV1().Pods(fromNS).Get(ctx, clientPod.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	report := netBenchReport{
+		FromNode:      runningClient.Spec.NodeName,
+		FromNamespace: fromNS,
+		ToNode:        serverPod.Spec.NodeName,
+		ToNamespace:   toNS,
+		Parallel:      netParallel,
+	}
+	if report.FromNode == report.ToNode {
+		i18n.Printf("⚠️  Both pods run on node %s; pass --from-node and --to-node to measure between nodes\n", report.FromNode)
+	}
+
+	i18n.Printf("🏁 Measuring throughput from %s to %s for %s...\n", report.FromNode, report.ToNode, netDuration)
+	// The server may not listen yet just after its pod started
+	var output string
+	var exitOK bool
+	for attempt := 0; attempt < 5; attempt++ {
+		output, exitOK, err = client.ExecOutput(ctx, fromNS, clientPod.Name, "main",
+			bench.IperfCommand(serverPod.Status.PodIP, seconds, netParallel))
+		if err != nil || exitOK {
+			break
+		}
+		time.Sleep(time.Second)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run iperf3: %w", err)
+	}
+	result, err := bench.ParseIperf(output)
+	if err != nil {
+		return err
+	}
+	report.Seconds = result.Seconds
+	report.SentBitsPerSecond = result.SentBitsPerSecond
+	report.ReceivedBitsPerSecond = result.ReceivedBitsPerSecond
+	report.Retransmits = result.Retransmits
+	report.Bytes = result.Bytes
+
+	if structured() {
+		return printStructured(report)
+	}
+
+	i18n.Printf("\n📊 Throughput from %s/%s to %s/%s:\n", report.FromNamespace, report.FromNode, report.ToNamespace, report.ToNode)
+	i18n.Printf("   Sent:        %s\n", formatBits(report.SentBitsPerSecond))
+	i18n.Printf("   Received:    %s\n", formatBits(report.ReceivedBitsPerSecond))
+	i18n.Printf("   Retransmits: %d\n", report.Retransmits)
+	i18n.Printf("   Transferred: %s in %.1fs\n", formatBytes(report.Bytes), report.Seconds)
+	return nil
+}
+
+// formatBits renders a rate in bits per second with a decimal unit
+func formatBits(bps float64) string {
+	units := []string{"bit/s", "Kbit/s", "Mbit/s", "Gbit/s"}
+	i := 0
+	for bps >= 1000 && i < len(units)-1 {
+		bps /= 1000
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", bps, units[i])
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"sigs.k8s.io/yaml"
+)
+
+// Structured output formats for -o
+const (
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+// annotationStructuredOutput marks the commands that support -o
+const annotationStructuredOutput = "pocket/structured-output"
+
+// Statuses in structured output
+const (
+	statusPassed  = string(tester.StatusPassed)
+	statusWarning = "warning"
+	statusFailed  = string(tester.StatusFailed)
+	statusSkipped = "skipped"
+	statusError   = "error"
+)
+
+var (
+	// outputFormat is -o
+	outputFormat string
+	// quiet is --quiet
+	quiet bool
+	// ioStreams are the streams NewRootCmd was given
+	ioStreams = genericiooptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
+	// resultOut receives structured output and the data commands write to
+	// stdout, such as dumps
+	resultOut io.Writer = os.Stdout
+	// humanOut receives human-readable output. It is ioStreams.Out, or
+	// ErrOut while stdout carries a document, or nothing with --quiet.
+	humanOut io.Writer = os.Stdout
+)
+
+// setStreams makes commands read and write streams
+func setStreams(streams genericiooptions.IOStreams) {
+	ioStreams = streams
+	resultOut = streams.Out
+	setHumanOutput(streams.Out)
+}
+
+// setHumanOutput directs human-readable output, including i18n.Printf, to w
+func setHumanOutput(w io.Writer) {
+	w = humanWriter(w)
+	humanOut = w
+	i18n.SetOutput(w)
+}
+
+// moveHumanOutputToStderr keeps stdout for a document, such as -o output or
+// a dump written to -
+func moveHumanOutputToStderr() {
+	if !quiet {
+		setHumanOutput(ioStreams.ErrOut)
+	}
+}
+
+// printf writes untranslated human-readable output
+func printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(humanOut, format, args...)
+}
+
+// newTable returns a writer that aligns tab-separated human-readable
+// output in columns; Flush writes it
+func newTable() *tabwriter.Writer {
+	return tabwriter.NewWriter(humanOut, 0, 0, 2, ' ', 0)
+}
+
+// markStructuredOutput declares that cmds support -o
+func markStructuredOutput(cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		if cmd.Annotations == nil {
+			cmd.Annotations = map[string]string{}
+		}
+		cmd.Annotations[annotationStructuredOutput] = "true"
+	}
+}
+
+// setupOutput validates -o for cmd, moves human-readable output to stderr
+// for structured output and discards it for --quiet
+func setupOutput(cmd *cobra.Command) error {
+	human := ioStreams.Out
+	if structured() {
+		human = ioStreams.ErrOut
+	}
+	plainOutput = usePlainOutput(cmd, human)
+	setHumanOutput(ioStreams.Out)
+
+	if quiet {
+		cmd.Root().SilenceUsage = true
+		setHumanOutput(io.Discard)
+	}
+
+	switch outputFormat {
+	case "":
+		return nil
+	case outputJSON, outputYAML:
+	default:
+		return fmt.Errorf("unsupported output format %q (use json or yaml)", outputFormat)
+	}
+	if cmd.Annotations[annotationStructuredOutput] == "" {
+		return fmt.Errorf("%s does not support -o", cmd.CommandPath())
+	}
+	moveHumanOutputToStderr()
+	return nil
+}
+
+// structured reports whether -o asks for structured output
+func structured() bool {
+	return outputFormat != ""
+}
+
+// printStructured writes v to resultOut in the -o format
+func printStructured(v any) error {
+	var data []byte
+	var err error
+	if outputFormat == outputYAML {
+		data, err = yaml.Marshal(v)
+	} else {
+		data, err = json.MarshalIndent(v, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	_, err = resultOut.Write(data)
+	return err
+}
+
+// testReport is the structured result of a single test
+type testReport struct {
+	// Context is the kubeconfig context of a test run with --contexts
+	Context   string `json:"context,omitempty"`
+	Tester    string `json:"tester"`
+	Target    string `json:"target"`
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod,omitempty"`
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latencyMs"`
+	// Cached is set when --cached answered without a test pod
+	Cached bool   `json:"cached,omitempty"`
+	Output string `json:"output,omitempty"`
+	// Summary describes the output in a line, such as a cluster's health
+	Summary string `json:"summary,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// Events are the warning events of a test pod that did not start
+	Events []string `json:"events,omitempty"`
+	// Timings break down where the time of the run went
+	Timings *timingsReport `json:"timings,omitempty"`
+	// Attempts are the runs of a test retried with --retries
+	Attempts []attemptReport `json:"attempts,omitempty"`
+}
+
+// timingsReport is the structured timing breakdown of a test run
+type timingsReport struct {
+	SchedulingMs int64 `json:"schedulingMs"`
+	ImagePullMs  int64 `json:"imagePullMs"`
+	ProbeMs      int64 `json:"probeMs"`
+	TotalMs      int64 `json:"totalMs"`
+}
+
+// newTimingsReport returns the structured timings of a run
+func newTimingsReport(t tester.Timings) *timingsReport {
+	return &timingsReport{
+		SchedulingMs: t.Scheduling.Milliseconds(),
+		ImagePullMs:  t.ImagePull.Milliseconds(),
+		ProbeMs:      t.Probe.Milliseconds(),
+		TotalMs:      t.Total.Milliseconds(),
+	}
+}
+
+// attemptReport is the structured result of one attempt of a test
+type attemptReport struct {
+	Attempt    int    `json:"attempt"`
+	DurationMs int64  `json:"durationMs"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
+
+// checkReport is the structured result of one check of a multi-target test
+type checkReport struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Namespace string `json:"namespace"`
+	Target    string `json:"target,omitempty"`
+	Expect    string `json:"expect,omitempty"`
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latencyMs,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// checksReport is the structured result of a multi-target test
+type checksReport struct {
+	Checks  []checkReport `json:"checks"`
+	Passed  int           `json:"passed"`
+	Failed  int           `json:"failed"`
+	Skipped int           `json:"skipped"`
+}
+
+// verbosity is -v, repeated for more detail
+var verbosity int
+
+// setVerbosity makes client log to stderr at the -v level
+func setVerbosity(client *k8s.Client) {
+	if verbosity == 0 {
+		return
+	}
+	client.Verbosity = verbosity
+	var mu sync.Mutex
+	client.Logf = func(format string, args ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+		_, _ = fmt.Fprintf(ioStreams.ErrOut, "%s %s\n", time.Now().Format("15:04:05.000"), fmt.Sprintf(format, args...))
+	}
+}
@@ -0,0 +1,11 @@
+package cmd
+
+import "testing"
+
+func TestTestCommandsSupportStructuredOutput(t *testing.T) {
+	for _, cmd := range testCmd.Commands() {
+		if cmd.Annotations[annotationStructuredOutput] == "" {
+			t.Errorf("%s does not support -o", cmd.CommandPath())
+		}
+	}
+}
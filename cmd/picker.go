@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"golang.org/x/term"
+)
+
+// nonInteractive is --non-interactive
+var nonInteractive bool
+
+// pickerRows is how many options the picker shows at once
+const pickerRows = 10
+
+// errPickCanceled is returned when the picker is left without a choice
+var errPickCanceled = errors.New("selection canceled")
+
+// interactive reports whether pocket may prompt on the terminal. The picker
+// draws on stderr, so stdout may be redirected, e.g. for -o.
+func interactive() bool {
+	return !nonInteractive && term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// choose returns the index of the option to use among several candidates:
+// the user's pick on a terminal, or the first one with a note on how to
+// choose another otherwise
+func choose(what string, options []string, hint string) (int, error) {
+	if len(options) < 2 {
+		return 0, nil
+	}
+	if !interactive() {
+		i18n.Printf("💡 Several %s match: %s; using %s (%s)\n", what, strings.Join(options, ", "), options[0], hint)
+		return 0, nil
+	}
+	return pick(i18n.T("Several %s match; choose one", what), options)
+}
+
+// pick lets the user choose one of options on the terminal: the arrow keys
+// move, typing filters the options by a fuzzy match and Enter chooses
+func pick(title string, options []string) (int, error) {
+	restore, err := makeRawTerminal()
+	if err != nil {
+		return 0, fmt.Errorf("failed to set raw terminal: %w", err)
+	}
+	defer restore()
+
+	p := &picker{title: title, options: options}
+	p.filter()
+	drawn := 0
+	buf := make([]byte, 16)
+	for {
+		drawn = p.draw(drawn)
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return 0, err
+		}
+		switch key := string(buf[:n]); key {
+		case "\r", "\n":
+			if len(p.matches) == 0 {
+				continue
+			}
+			p.clear(drawn)
+			return p.matches[p.cursor], nil
+		case "\x03", "\x1b":
+			p.clear(drawn)
+			return 0, errPickCanceled
+		case "\x1b[A", "\x1bOA", "\x10":
+			p.move(-1)
+		case "\x1b[B", "\x1bOB", "\x0e":
+			p.move(1)
+		case "\x7f", "\x08":
+			if p.query != "" {
+				runes := []rune(p.query)
+				p.query = string(runes[:len(runes)-1])
+				p.filter()
+			}
+		default:
+			if !strings.HasPrefix(key, "\x1b") && key >= " " {
+				p.query += key
+				p.filter()
+			}
+		}
+	}
+}
+
+// picker is the state of pick
+type picker struct {
+	title   string
+	options []string
+	query   string
+	// matches are the indexes of the options matching query
+	matches []int
+	cursor  int
+}
+
+// filter keeps the options that contain the query's characters in order
+func (p *picker) filter() {
+	p.matches = p.matches[:0]
+	for i, option := range p.options {
+		if fuzzyMatch(option, p.query) {
+			p.matches = append(p.matches, i)
+		}
+	}
+	p.cursor = 0
+}
+
+// move moves the cursor by delta, wrapping around
+func (p *picker) move(delta int) {
+	if len(p.matches) == 0 {
+		return
+	}
+	p.cursor = (p.cursor + delta + len(p.matches)) % len(p.matches)
+}
+
+// draw replaces the previous drawing of drawn lines and returns how many
+// lines it drew. Lines end in \r\n, as the terminal is raw.
+func (p *picker) draw(drawn int) int {
+	p.clear(drawn)
+	var b strings.Builder
+	fmt.Fprintf(&b, "❓ %s %s\r\n", p.title, i18n.T("(↑/↓ to move, type to filter, Enter to choose, Esc to cancel)"))
+	fmt.Fprintf(&b, "> %s\r\n", p.query)
+	lines := 2
+
+	first := max(0, p.cursor-pickerRows+1)
+	for i := first; i < len(p.matches) && i < first+pickerRows; i++ {
+		marker := "  "
+		if i == p.cursor {
+			marker = "❯ "
+		}
+		fmt.Fprintf(&b, "%s%s\r\n", marker, p.options[p.matches[i]])
+		lines++
+	}
+	if len(p.matches) == 0 {
+		b.WriteString(i18n.T("  no match") + "\r\n")
+		lines++
+	}
+	_, _ = io.WriteString(ioStreams.ErrOut, b.String())
+	return lines
+}
+
+// clear erases the drawn lines above the cursor
+func (p *picker) clear(drawn int) {
+	if drawn > 0 {
+		_, _ = fmt.Fprintf(ioStreams.ErrOut, "\x1b[%dA\r\x1b[J", drawn)
+	}
+}
+
+// fuzzyMatch reports whether s contains the characters of query in order,
+// ignoring case
+func fuzzyMatch(s, query string) bool {
+	s, query = strings.ToLower(s), strings.ToLower(query)
+	for _, r := range query {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(string(r)):]
+	}
+	return true
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+func TestPickerDrawsOnStderr(t *testing.T) {
+	saved := ioStreams
+	defer func() { ioStreams = saved }()
+	var out, errOut bytes.Buffer
+	ioStreams = genericiooptions.IOStreams{Out: &out, ErrOut: &errOut}
+
+	p := &picker{title: "Choose", options: []string{"postgres", "redis"}}
+	p.filter()
+	p.clear(p.draw(0))
+
+	if out.Len() != 0 {
+		t.Errorf("picker wrote %q to stdout, want nothing", out.String())
+	}
+	if !strings.Contains(errOut.String(), "postgres") {
+		t.Errorf("picker wrote %q to stderr, want the options", errOut.String())
+	}
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// plainTags replace the emoji that start status lines in plain output.
+// Other emoji become plainInfoTag.
+var plainTags = map[rune]string{
+	'✅': "[OK]",
+	'✨': "[OK]",
+	'❌': "[FAIL]",
+	'💥': "[FAIL]",
+	'⚠': "[WARN]",
+	'💡': "[HINT]",
+}
+
+// plainInfoTag replaces emoji without a tag of their own
+const plainInfoTag = "[INFO]"
+
+// variationSelector follows emoji such as ⚠️ to request emoji presentation
+const variationSelector = '\uFE0F'
+
+var (
+	// noEmoji is --no-emoji, also set by --no-color
+	noEmoji bool
+	// plainOutput is set when status lines are written with tags instead of
+	// emoji
+	plainOutput bool
+)
+
+// addPlainFlags registers --no-emoji and --no-color on cmd
+func addPlainFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVar(&noEmoji, "no-emoji", false,
+		"write [OK], [FAIL] and [INFO] tags instead of emoji (default when the output is not a terminal or NO_COLOR is set)")
+	cmd.PersistentFlags().BoolVar(&noEmoji, "no-color", false, "same as --no-emoji")
+}
+
+// usePlainOutput decides whether human-readable output written to w is
+// plain: as --no-emoji or --no-color say, and otherwise when NO_COLOR is
+// set or w is not a terminal
+func usePlainOutput(cmd *cobra.Command, w io.Writer) bool {
+	flags := cmd.Flags()
+	if flags.Changed("no-emoji") || flags.Changed("no-color") {
+		return noEmoji
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return true
+	}
+	f, ok := w.(*os.File)
+	return !ok || !term.IsTerminal(int(f.Fd()))
+}
+
+// humanWriter returns w, or w rewriting emoji to tags in plain output
+func humanWriter(w io.Writer) io.Writer {
+	if !plainOutput || w == io.Discard {
+		return w
+	}
+	if _, ok := w.(*plainWriter); ok {
+		return w
+	}
+	return &plainWriter{w: w, lineStart: true}
+}
+
+// plainWriter replaces the emoji at the start of each line with a tag such
+// as [OK]. Writes are assumed not to split a character.
+type plainWriter struct {
+	mu        sync.Mutex
+	w         io.Writer
+	lineStart bool
+}
+
+// Write implements io.Writer
+func (p *plainWriter) Write(data []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var out bytes.Buffer
+	rest := data
+	for len(rest) > 0 {
+		if p.lineStart {
+			rest = plainLineStart(&out, rest)
+		}
+		line, after, found := bytes.Cut(rest, []byte("\n"))
+		out.Write(line)
+		if found {
+			out.WriteByte('\n')
+		}
+		p.lineStart = found
+		rest = after
+	}
+
+	if _, err := p.w.Write(out.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// plainLineStart writes the start of a line to out with a leading emoji
+// replaced by its tag, and returns the remainder of data
+func plainLineStart(out *bytes.Buffer, data []byte) []byte {
+	indent := len(data) - len(bytes.TrimLeft(data, " "))
+	out.Write(data[:indent])
+	data = data[indent:]
+
+	r, size := utf8.DecodeRune(data)
+	if r == utf8.RuneError || !isEmoji(r) {
+		return data
+	}
+	data = data[size:]
+	if r, size := utf8.DecodeRune(data); r == variationSelector {
+		data = data[size:]
+	}
+
+	tag, ok := plainTags[r]
+	if !ok {
+		tag = plainInfoTag
+	}
+	out.WriteString(tag)
+	out.WriteByte(' ')
+	return bytes.TrimLeft(data, " ")
+}
+
+// isEmoji reports whether r is a pictographic symbol rather than text
+func isEmoji(r rune) bool {
+	return r > unicode.MaxLatin1 && unicode.Is(unicode.So, r)
+}
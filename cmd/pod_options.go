@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/config"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// podOptions holds the flags that shape every pod pocket creates
+type podOptions struct {
+	imagePullSecrets []string
+	serviceAccount   string
+	nodeName         string
+	nodeSelector     map[string]string
+	tolerations      []string
+	cpu              string
+	memory           string
+	runAsUser        int64
+	allowRoot        bool
+	addCapabilities  []string
+	labels           []string
+	annotations      []string
+
+	// configLabels and configAnnotations are config file defaults; flags
+	// win for the same key
+	configLabels      map[string]string
+	configAnnotations map[string]string
+}
+
+// podOpts is shared by all pod-creating commands; only one command runs
+// per invocation
+var podOpts podOptions
+
+// addPodFlags registers the pod-shaping flags on a pod-creating command
+func addPodFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&podOpts.imagePullSecrets, "image-pull-secret", nil,
+		"secret used to pull the pod's image (repeatable)")
+	cmd.Flags().StringVar(&podOpts.serviceAccount, "service-account", "",
+		"service account the pod runs as, for NetworkPolicies, mesh policies and cloud IAM (default: namespace default)")
+	addPodMetadataFlags(cmd)
+	cmd.Flags().StringVar(&podOpts.nodeName, "node-name", "", "run the pod on this node, bypassing the scheduler")
+	cmd.Flags().StringToStringVar(&podOpts.nodeSelector, "node-selector", nil,
+		"node labels the pod must be scheduled on (e.g. pool=db,zone=eu-west-1a)")
+	cmd.Flags().StringArrayVar(&podOpts.tolerations, "toleration", nil,
+		"taint to tolerate as key[=value][:effect], or '*' for all taints (repeatable)")
+	cmd.Flags().StringVar(&podOpts.cpu, "cpu", "100m", "CPU request and limit for the pod (empty to omit)")
+	cmd.Flags().StringVar(&podOpts.memory, "memory", "256Mi", "memory request and limit for the pod (empty to omit)")
+	cmd.Flags().Int64Var(&podOpts.runAsUser, "run-as-user", k8s.DefaultRunAsUser, "UID the pod runs as")
+	cmd.Flags().BoolVar(&podOpts.allowRoot, "allow-root", false,
+		"run as the image's own user, even root (not allowed by the restricted Pod Security Standard)")
+	cmd.Flags().StringArrayVar(&podOpts.addCapabilities, "add-capability", nil,
+		"Linux capability to add, e.g. NET_RAW (repeatable; most are not allowed by the restricted Pod Security Standard)")
+}
+
+// applyConfig records the config file's pod label and annotation defaults
+func (o *podOptions) applyConfig(cfg *config.Config) {
+	o.configLabels = cfg.PodLabels
+	o.configAnnotations = cfg.PodAnnotations
+}
+
+// addPodMetadataFlags registers --pod-label and --pod-annotation, for
+// commands whose pods take no other pod flags
+func addPodMetadataFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&podOpts.labels, "pod-label", nil, "label to add to the pod as key=value (repeatable)")
+	cmd.Flags().StringArrayVar(&podOpts.annotations, "pod-annotation", nil,
+		"annotation to add to the pod as key=value, e.g. sidecar.istio.io/inject=false (repeatable)")
+}
+
+// apply sets the options on a pod configuration
+func (o *podOptions) apply(config *k8s.PodConfig) error {
+	if err := o.applyMetadata(config); err != nil {
+		return err
+	}
+
+	config.ImagePullSecrets = append(config.ImagePullSecrets, o.imagePullSecrets...)
+
+	if o.serviceAccount != "" {
+		config.ServiceAccountName = o.serviceAccount
+	}
+
+	if o.nodeName != "" {
+		config.NodeName = o.nodeName
+	}
+
+	if len(o.nodeSelector) > 0 {
+		if config.NodeSelector == nil {
+			config.NodeSelector = map[string]string{}
+		}
+		for k, v := range o.nodeSelector {
+			config.NodeSelector[k] = v
+		}
+	}
+
+	if err := o.applyResources(config); err != nil {
+		return err
+	}
+
+	if err := o.applySecurityContext(config); err != nil {
+		return err
+	}
+
+	for _, spec := range o.tolerations {
+		toleration, err := parseToleration(spec)
+		if err != nil {
+			return err
+		}
+		config.Tolerations = append(config.Tolerations, toleration)
+	}
+	return nil
+}
+
+// applyResources sets equal requests and limits, which satisfies both
+// LimitRanges and ResourceQuotas that require them
+func (o *podOptions) applyResources(config *k8s.PodConfig) error {
+	for name, value := range map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    o.cpu,
+		corev1.ResourceMemory: o.memory,
+	} {
+		if value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return fmt.Errorf("invalid --%s %q: %w", name, value, err)
+		}
+		if config.Resources.Requests == nil {
+			config.Resources.Requests = corev1.ResourceList{}
+			config.Resources.Limits = corev1.ResourceList{}
+		}
+		config.Resources.Requests[name] = quantity
+		config.Resources.Limits[name] = quantity
+	}
+	return nil
+}
+
+// applySecurityContext sets the restricted Pod Security Standard context,
+// relaxed by --allow-root and --add-capability
+func (o *podOptions) applySecurityContext(config *k8s.PodConfig) error {
+	if o.runAsUser == 0 && !o.allowRoot {
+		return fmt.Errorf("--run-as-user 0 requires --allow-root")
+	}
+
+	sc := k8s.RestrictedSecurityContext(o.runAsUser)
+	if o.allowRoot {
+		sc.RunAsNonRoot = nil
+		sc.RunAsUser = nil
+	} else if !hasEnv(config.Env, "HOME") {
+		// Client tools write history and settings to $HOME, which is
+		// usually not writable by an arbitrary UID
+		config.Env = append(config.Env, corev1.EnvVar{Name: "HOME", Value: "/tmp"})
+	}
+
+	for _, capability := range o.addCapabilities {
+		name := strings.TrimPrefix(strings.ToUpper(capability), "CAP_")
+		if name == "" {
+			return fmt.Errorf("invalid --add-capability %q", capability)
+		}
+		sc.Capabilities.Add = append(sc.Capabilities.Add, corev1.Capability(name))
+	}
+
+	config.SecurityContext = sc
+	return nil
+}
+
+// applyMetadata adds the config file and --pod-label/--pod-annotation
+// labels and annotations. It is also used for pods that take no other pod
+// flags.
+func (o *podOptions) applyMetadata(config *k8s.PodConfig) error {
+	labels, err := mergeKeyValues(o.configLabels, o.labels, "--pod-label")
+	if err != nil {
+		return err
+	}
+	for key, value := range labels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid pod label key %q: %s", key, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("invalid pod label value %q: %s", value, strings.Join(errs, "; "))
+		}
+	}
+
+	annotations, err := mergeKeyValues(o.configAnnotations, o.annotations, "--pod-annotation")
+	if err != nil {
+		return err
+	}
+	for key := range annotations {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid pod annotation key %q: %s", key, strings.Join(errs, "; "))
+		}
+	}
+
+	config.Labels = mergeMaps(config.Labels, labels)
+	config.Annotations = mergeMaps(config.Annotations, annotations)
+	return nil
+}
+
+// mergeKeyValues overlays key=value flag values on defaults
+func mergeKeyValues(defaults map[string]string, values []string, flag string) (map[string]string, error) {
+	merged := mergeMaps(nil, defaults)
+	for _, kv := range values {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid %s %q (use key=value)", flag, kv)
+		}
+		merged = mergeMaps(merged, map[string]string{key: value})
+	}
+	return merged, nil
+}
+
+// mergeMaps copies src into dst, allocating dst when needed
+func mergeMaps(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = map[string]string{}
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+func hasEnv(env []corev1.EnvVar, name string) bool {
+	for _, e := range env {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseToleration parses key[=value][:effect]. Without a value the taint
+// key is tolerated with any value; without an effect all effects are
+// tolerated. "*" tolerates every taint.
+func parseToleration(spec string) (corev1.Toleration, error) {
+	if spec == "*" {
+		return corev1.Toleration{Operator: corev1.TolerationOpExists}, nil
+	}
+
+	keyValue, effect, _ := strings.Cut(spec, ":")
+	key, value, hasValue := strings.Cut(keyValue, "=")
+	if key == "" {
+		return corev1.Toleration{}, fmt.Errorf("invalid toleration %q: missing key", spec)
+	}
+
+	toleration := corev1.Toleration{Key: key, Operator: corev1.TolerationOpExists}
+	if hasValue {
+		toleration.Operator = corev1.TolerationOpEqual
+		toleration.Value = value
+	}
+
+	switch corev1.TaintEffect(effect) {
+	case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		toleration.Effect = corev1.TaintEffect(effect)
+	default:
+		return corev1.Toleration{}, fmt.Errorf("invalid toleration %q: unknown effect %s", spec, effect)
+	}
+	return toleration, nil
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/spf13/cobra"
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Check admission policies against pocket's pods",
+}
+
+var policySimulateCmd = &cobra.Command{
+	Use:   "simulate [tester]",
+	Short: "Dry-run pocket's pod through admission and report policy decisions",
+	Long: `Send the pod pocket would create through a server-side dry run and report
+what admission does with it: which webhook or policy denies it (Kyverno
+policies and Gatekeeper constraints are listed one by one), the warnings it
+returns, and the fields mutating webhooks change.
+
+The pod is built from the same flags as a test (--pod-label, --run-as-user,
+--cpu, ...), so a passing simulation tells you which flags a real run needs.
+
+Examples:
+  kubectl pocket policy simulate
+  kubectl pocket policy simulate postgres -n payments --pod-label team=platform
+  kubectl pocket policy simulate redis --run-as-user 999`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPolicySimulate,
+}
+
+var policyImage string
+
+func init() {
+	policyCmd.AddCommand(policySimulateCmd)
+
+	policySimulateCmd.Flags().StringVar(&policyImage, "image", "", "override the client image")
+	addPodFlags(policySimulateCmd)
+	markStructuredOutput(policySimulateCmd)
+}
+
+// policyReport is the structured result of an admission simulation
+type policyReport struct {
+	Namespace string               `json:"namespace"`
+	Pod       string               `json:"pod"`
+	Allowed   bool                 `json:"allowed"`
+	Warnings  []string             `json:"warnings,omitempty"`
+	Denial    *k8s.AdmissionDenial `json:"denial,omitempty"`
+	Mutations []k8s.PodMutation    `json:"mutations,omitempty"`
+}
+
+func runPolicySimulate(cmd *cobra.Command, args []string) error {
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+	podConfig, err := plannedPod(client, args, policyImage)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	i18n.Printf("🔍 Simulating admission of %s in %s\n", podConfig.Name, client.Namespace)
+	sim, err := client.SimulatePodAdmission(ctx, podConfig)
+	if err != nil {
+		return err
+	}
+
+	if structured() {
+		report := policyReport{
+			Namespace: client.Namespace,
+			Pod:       podConfig.Name,
+			Allowed:   sim.Denial == nil,
+			Warnings:  sim.Warnings,
+			Denial:    sim.Denial,
+			Mutations: sim.Mutations,
+		}
+		if err := printStructured(report); err != nil {
+			return err
+		}
+		if sim.Denial != nil {
+			return checkFailed(fmt.Errorf("pod would be denied by %s", sim.Denial.Source))
+		}
+		return nil
+	}
+
+	for _, warning := range sim.Warnings {
+		printf("⚠️  %s\n", warning)
+	}
+
+	if sim.Denial != nil {
+		printDenial(sim.Denial)
+		return checkFailed(fmt.Errorf("pod would be denied by %s", sim.Denial.Source))
+	}
+
+	if len(sim.Mutations) > 0 {
+		i18n.Printf("\n📝 Admission would change the pod:\n")
+		w := newTable()
+		_, _ = fmt.Fprintln(w, "FIELD\tFROM\tTO")
+		for _, m := range sim.Mutations {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", m.Field, valueOr(m.From, "-"), valueOr(m.To, "-"))
+		}
+		_ = w.Flush()
+		printf("\n")
+	}
+
+	i18n.Printf("✅ Admission allows the pod in %s\n", client.Namespace)
+	return nil
+}
+
+// printDenial prints what denied the pod and flags that commonly fix it
+func printDenial(denial *k8s.AdmissionDenial) {
+	i18n.Printf("❌ Denied by %s\n", denial.Source)
+
+	w := newTable()
+	_, _ = fmt.Fprintln(w, "\nPOLICY\tRULE\tMESSAGE")
+	for _, v := range denial.Violations {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", valueOr(v.Policy, "-"), valueOr(v.Rule, "-"), strings.Join(strings.Fields(v.Message), " "))
+	}
+	_ = w.Flush()
+	printf("\n")
+
+	message := strings.ToLower(denial.Message)
+	switch {
+	case strings.Contains(message, "label"):
+		i18n.Printf("💡 Add the required labels with --pod-label key=value (or podLabels in the config file)\n")
+	case strings.Contains(message, "annotation"):
+		i18n.Printf("💡 Add the required annotations with --pod-annotation key=value (or podAnnotations in the config file)\n")
+	case strings.Contains(message, "runasnonroot") || strings.Contains(message, "runasuser"):
+		i18n.Printf("💡 Choose the pod's user with --run-as-user, or --allow-root where root is allowed\n")
+	case strings.Contains(message, "image") || strings.Contains(message, "registry"):
+		i18n.Printf("💡 Use an allowed image with --image, or set imageRegistry in the config file\n")
+	case strings.Contains(message, "cpu") || strings.Contains(message, "memory") || strings.Contains(message, "resource"):
+		i18n.Printf("💡 Adjust resources with --cpu and --memory, or run 'kubectl pocket quota check --adjust'\n")
+	}
+}
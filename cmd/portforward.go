@@ -2,64 +2,263 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net/http"
+	"io"
+	"net"
 	"os"
 	"os/signal"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
 	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/tools/portforward"
-	"k8s.io/client-go/transport/spdy"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
-// Supported databases only
-var dbAliases = map[string]struct {
+// pfAlias maps a short name to candidate services and their port
+type pfAlias struct {
 	serviceNames []string
 	defaultPort  int
-}{
+}
+
+// Built-in database aliases; more can be defined in the config file
+var dbAliases = map[string]pfAlias{
 	"redis":    {serviceNames: []string{"redis", "redis-master", "redis-svc"}, defaultPort: 6379},
 	"mongo":    {serviceNames: []string{"mongo", "mongodb", "mongo-svc"}, defaultPort: 27017},
 	"postgres": {serviceNames: []string{"postgres", "postgresql", "pg", "pg-svc"}, defaultPort: 5432},
 }
 
+// resolvePFAlias looks up an alias in the config file, then the built-ins
+func resolvePFAlias(name string) (pfAlias, error) {
+	cfg, err := GetConfig()
+	if err != nil {
+		return pfAlias{}, err
+	}
+
+	if alias, ok := cfg.Aliases[name]; ok {
+		if len(alias.Services) == 0 || alias.Port == 0 {
+			return pfAlias{}, fmt.Errorf("alias %s in config file needs services and port", name)
+		}
+		return pfAlias{serviceNames: alias.Services, defaultPort: alias.Port}, nil
+	}
+
+	if alias, ok := dbAliases[name]; ok {
+		return alias, nil
+	}
+
+	supported := []string{"redis", "mongo", "postgres"}
+	supported = append(supported, cfg.AliasNames()...)
+	return pfAlias{}, fmt.Errorf("unsupported database: %s (supported: %s; see 'kubectl pocket pf list-aliases')", name, strings.Join(supported, ", "))
+}
+
 var pfCmd = &cobra.Command{
-	Use:     "port-forward <database> [local-port]",
-	Aliases: []string{"pf", "portforward"},
-	Short:   "Quick port-forward to database services",
-	Long: `Quickly set up port-forwarding to supported database services.
+	Use:               "port-forward (<database> [local-port] | <kind>/<name> [[local:]remote])... | --all",
+	Aliases:           []string{"pf", "portforward"},
+	ValidArgsFunction: completePortForward,
+	Short:             "Quick port-forward to database services, services, pods and deployments",
+	Long: `Quickly set up port-forwarding to supported database services, or to any
+service, pod or deployment.
 
 Supported databases:
   - redis    : 6379
   - mongo    : 27017
   - postgres : 5432
 
+Additional aliases can be defined under "aliases" in the config file
+(see "kubectl pocket config"); "kubectl pocket pf list-aliases" shows them
+all. An alias's optional port is the local port.
+
+svc/<name>, pod/<name> and deploy/<name> targets take <port> or
+<local>:<remote> as kubectl does. Without a port, a service's only port or a
+pod's first container port is used. Service ports are mapped to the pod's
+target port, including named ports. --selector forwards to pods by label
+instead, taking only the port.
+
+When several of an alias's services exist, e.g. redis-master and
+redis-replica, pocket asks which one to forward to, with the arrow keys and a
+fuzzy filter. Without a terminal, or with --non-interactive, the first one is
+used; svc/<name> names one directly.
+
+Only ready pods are forwarded to. With --zone, pods on nodes in that zone
+(topology.kubernetes.io/zone) are preferred, e.g. to avoid cross-zone
+traffic.
+
+When a default local port is in use, the next free one is used instead, or a
+random one with --random-port; the mapping printed shows which. A local port
+that was given explicitly must be free.
+
+Several targets, each followed by its optional port, are forwarded at once;
+--all forwards every database "kubectl pocket discover" finds in the
+namespace. A table shows the local address of each, and Ctrl+C stops them all.
+
+A dropped connection is re-dialed. When the pod behind a forward is deleted,
+as in a rollout or an eviction, the target is resolved again and the forward
+moves to a running pod; --max-retries bounds the attempts in a row.
+
+--background detaches once the forwards are up and keeps them running without
+a terminal; "kubectl pocket pf list" shows them and "kubectl pocket pf stop"
+ends them.
+
 Examples:
   kubectl pocket port-forward redis              # localhost:6379 -> redis:6379
   kubectl pocket port-forward redis 16379        # localhost:16379 -> redis:6379
   kubectl pocket port-forward mongo              # localhost:27017 -> mongo:27017
-  kubectl pocket port-forward postgres 15432     # localhost:15432 -> postgres:5432`,
-	Args: cobra.RangeArgs(1, 2),
+  kubectl pocket port-forward postgres 15432     # localhost:15432 -> postgres:5432
+  kubectl pocket pf svc/grafana 3000:3000        # localhost:3000 -> svc/grafana:3000
+  kubectl pocket pf deploy/api 18080:8080        # localhost:18080 -> a pod of deploy/api:8080
+  kubectl pocket pf pod/kafka-0                  # first container port of kafka-0
+  kubectl pocket pf -l app=api 18080:8080        # a ready pod labelled app=api
+  kubectl pocket pf redis postgres 15432 mongo   # three forwards in one process
+  kubectl pocket pf --all                        # every discovered database
+  kubectl pocket pf redis --background           # keep forwarding after exiting
+
+With --ssh-via, <database> is the host:port of a database outside the cluster
+that is only reachable through a bastion. A relay pod opens the SSH tunnel and
+the port-forward is chained through it:
+
+  kubectl pocket port-forward legacy-db.corp:5432 --ssh-via ops@bastion.corp`,
 	RunE: runPortForward,
 }
 
-var pfAddress string
+var pfListAliasesCmd = &cobra.Command{
+	Use:   "list-aliases",
+	Short: "List the built-in and configured port-forward aliases",
+	Long: `List the port-forward aliases: the built-in database aliases and those
+defined under "aliases" in the config file. A configured alias replaces a
+built-in one of the same name.
+
+Examples:
+  kubectl pocket pf list-aliases
+  kubectl pocket config set aliases.grafana.services grafana
+  kubectl pocket config set aliases.grafana.port 3000`,
+	Args: cobra.NoArgs,
+	RunE: runPFListAliases,
+}
+
+// aliasReport is the structured form of a port-forward alias
+type aliasReport struct {
+	Name     string   `json:"name"`
+	Services []string `json:"services"`
+	Port     int      `json:"port"`
+	// Source is "built-in" or "config"
+	Source string `json:"source"`
+}
+
+var (
+	pfAddress    string
+	pfIPFamily   string
+	pfAll        bool
+	pfMaxRetries int
+	pfRandomPort bool
+	pfBackground bool
+	pfSelector   string
+	pfZone       string
+	pfSSH        sshOptions
+)
 
 func init() {
 	// rootCmd.AddCommand is handled in root.go addSubcommands()
-	pfCmd.Flags().StringVar(&pfAddress, "address", "127.0.0.1", "local address to bind")
+	pfCmd.Flags().StringVar(&pfAddress, "address", "127.0.0.1", "local address to bind (an IPv6 literal such as ::1, or localhost for both families)")
+	pfCmd.Flags().StringVar(&pfIPFamily, "ip-family", "", "bind the default loopback address of this family (ipv4 or ipv6)")
+	pfCmd.Flags().BoolVar(&pfAll, "all", false, "forward every database discovered in the namespace")
+	pfCmd.Flags().BoolVar(&pfRandomPort, "random-port", false, "when a default local port is in use, pick a random free one instead of the next")
+	pfCmd.Flags().StringVarP(&pfSelector, "selector", "l", "", "forward to a ready pod matching this label selector, without a service")
+	pfCmd.Flags().StringVar(&pfZone, "zone", "", "prefer pods on nodes in this topology zone")
+	pfCmd.Flags().BoolVar(&pfBackground, "background", false, "detach and keep forwarding in the background (see pf list and pf stop)")
+	pfCmd.Flags().IntVar(&pfMaxRetries, "max-retries", 5, "reconnect attempts in a row before giving up (0 never reconnects)")
+	pfSSH.addFlags(pfCmd)
+	addPodMetadataFlags(pfCmd)
+
+	pfCmd.AddCommand(pfListAliasesCmd, pfListCmd, pfStopCmd)
+	markStructuredOutput(pfListAliasesCmd)
+	markStructuredOutput(pfListCmd)
+}
+
+func runPFListAliases(cmd *cobra.Command, args []string) error {
+	cfg, err := GetConfig()
+	if err != nil {
+		return err
+	}
+
+	var aliases []aliasReport
+	for name, alias := range dbAliases {
+		if _, overridden := cfg.Aliases[name]; !overridden {
+			aliases = append(aliases, aliasReport{Name: name, Services: alias.serviceNames, Port: alias.defaultPort, Source: "built-in"})
+		}
+	}
+	for _, name := range cfg.AliasNames() {
+		alias := cfg.Aliases[name]
+		aliases = append(aliases, aliasReport{Name: name, Services: alias.Services, Port: alias.Port, Source: "config"})
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Name < aliases[j].Name })
+
+	if structured() {
+		return printStructured(aliases)
+	}
+
+	w := newTable()
+	_, _ = fmt.Fprintln(w, "ALIAS\tSERVICES\tPORT\tSOURCE")
+	for _, a := range aliases {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", a.Name, strings.Join(a.Services, ","), a.Port, a.Source)
+	}
+	return w.Flush()
 }
 
 func runPortForward(cmd *cobra.Command, args []string) error {
-	dbType := args[0]
+	if pfIPFamily != "" {
+		family, err := parseIPFamily(pfIPFamily)
+		if err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("address") {
+			if ip := net.ParseIP(pfAddress); ip != nil && ipFamilyOf(ip) != family {
+				return fmt.Errorf("--address %s is not an %s address", pfAddress, family)
+			}
+		} else if family == corev1.IPv6Protocol {
+			pfAddress = "::1"
+		}
+	}
+
+	requests, err := parsePFArgs(args)
+	if pfSelector != "" {
+		requests, err = selectorRequest(args)
+	}
+	switch {
+	case pfSSH.enabled() && (len(args) == 0 || len(args) > 2 || pfAll):
+		return fmt.Errorf("with --ssh-via pass one host:port and an optional local port")
+	case pfSSH.enabled() && pfSelector != "":
+		return fmt.Errorf("--selector cannot be combined with --ssh-via")
+	case pfAll && (len(args) > 0 || pfSelector != ""):
+		return fmt.Errorf("pass either targets, --selector or --all")
+	case !pfAll && len(args) == 0 && pfSelector == "":
+		return fmt.Errorf("a target or --all is required")
+	case err != nil && !pfSSH.enabled():
+		return err
+	}
 
-	alias, ok := dbAliases[dbType]
-	if !ok {
-		return fmt.Errorf("unsupported database: %s (supported: redis, mongo, postgres)", dbType)
+	if os.Getenv(pfBackgroundEnv) != "" {
+		// Errors of the detached process are shown from its log
+		cmd.SilenceUsage = true
+		loadAliasChoices(os.Getenv(pfChoicesEnv))
+	} else if pfBackground {
+		if !pfSSH.enabled() && !pfAll && pfSelector == "" {
+			// The detached process has no terminal to ask on
+			if err := chooseAliasServices(requests); err != nil {
+				return err
+			}
+		}
+		return startBackground(pfTargets(args))
+	}
+	if pfSSH.enabled() {
+		return runSSHPortForward(args)
 	}
 
 	client, err := GetK8sClient()
@@ -67,59 +266,252 @@ func runPortForward(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create k8s client: %w", err)
 	}
 
-	ns := client.Namespace
-	remotePort := alias.defaultPort
-	localPort := alias.defaultPort
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-	// Parse optional local port
-	if len(args) > 1 {
-		localPort, err = strconv.Atoi(args[1])
+	if pfAll {
+		if requests, err = discoverForwards(ctx, client); err != nil {
+			return err
+		}
+	}
+
+	var fwds []*forwardTarget
+	taken := map[int]string{}
+	for _, req := range requests {
+		fwd, err := resolvePortForward(ctx, client, req.target, req.port)
 		if err != nil {
-			return fmt.Errorf("invalid port: %s", args[1])
+			return err
 		}
+		if err := fwd.pickLocalPort(taken); err != nil {
+			return err
+		}
+		taken[fwd.localPort] = fwd.display
+		fwds = append(fwds, fwd)
 	}
 
-	// Find the service
-	var serviceName string
-	for _, name := range alias.serviceNames {
-		_, err := client.Clientset.CoreV1().Services(ns).Get(context.Background(), name, metav1.GetOptions{})
-		if err == nil {
-			serviceName = name
-			break
+	if id := os.Getenv(pfBackgroundEnv); id != "" {
+		var mappings []string
+		for _, fwd := range fwds {
+			mappings = append(mappings, fmt.Sprintf("%s → %s", net.JoinHostPort(pfAddress, strconv.Itoa(fwd.localPort)), fwd.display))
+		}
+		remove, err := recordBackground(id, pfTargets(args), client.Namespace, mappings)
+		if err != nil {
+			return err
+		}
+		defer remove()
+	}
+
+	stopChan := make(chan struct{})
+	var stopOnce sync.Once
+	stop := func() { stopOnce.Do(func() { close(stopChan) }) }
+
+	// Handle interrupt
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		stop()
+	}()
+
+	if len(fwds) == 1 {
+		fwd := fwds[0]
+		label := requests[0].target
+		if label == "" {
+			label = fwd.display
+		}
+		i18n.Printf("🔌 Port-forwarding to %s\n", label)
+		printf("📡 %s → %s → pod/%s:%d\n", net.JoinHostPort(pfAddress, strconv.Itoa(fwd.localPort)), fwd.display, fwd.podName, fwd.podPort)
+		i18n.Printf("💡 Press Ctrl+C to stop\n\n")
+		return forwardWithReconnect(client, fwd.options(client.Namespace, stopChan, humanOut), fwd.resolver(client))
+	}
+
+	i18n.Printf("🔌 Port-forwarding to %d targets\n\n", len(fwds))
+	w := newTable()
+	_, _ = fmt.Fprintln(w, "LOCAL\tTARGET\tPOD")
+	for _, fwd := range fwds {
+		_, _ = fmt.Fprintf(w, "%s\t%s\tpod/%s:%d\n", net.JoinHostPort(pfAddress, strconv.Itoa(fwd.localPort)), fwd.display, fwd.podName, fwd.podPort)
+	}
+	_ = w.Flush()
+	i18n.Printf("\n💡 Press Ctrl+C to stop\n\n")
+
+	return forwardAll(client, fwds, stopChan, stop)
+}
+
+// selectorRequest returns the request for --selector, whose only argument
+// is the optional port
+func selectorRequest(args []string) ([]pfRequest, error) {
+	switch {
+	case len(args) == 0:
+		return []pfRequest{{}}, nil
+	case len(args) == 1 && isPortArg(args[0]):
+		return []pfRequest{{port: args[0]}}, nil
+	}
+	return nil, fmt.Errorf("with --selector pass only a port: <port> or <local>:<remote>")
+}
+
+// pfTargets describes what pf forwards: its arguments, --selector or --all
+func pfTargets(args []string) []string {
+	switch {
+	case pfAll:
+		return []string{"--all"}
+	case pfSelector != "":
+		return append([]string{"--selector", pfSelector}, args...)
+	}
+	return args
+}
+
+// forwardAll runs the port-forwards concurrently until stopChan is closed.
+// If one of them fails for good, stop tears the others down as well.
+func forwardAll(client *k8s.Client, fwds []*forwardTarget, stopChan chan struct{}, stop func()) error {
+	errs := make([]error, len(fwds))
+	var wg sync.WaitGroup
+	for i, fwd := range fwds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// The table above already shows where each forward listens
+			err := forwardWithReconnect(client, fwd.options(client.Namespace, stopChan, io.Discard), fwd.resolver(client))
+			if err != nil {
+				i18n.Printf("❌ Port-forward to %s failed: %v\n", fwd.display, err)
+				errs[i] = fmt.Errorf("port-forward to %s failed: %w", fwd.display, err)
+				stop()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// pfRequest is a port-forward target and its optional port argument
+type pfRequest struct {
+	target string
+	port   string
+}
+
+// parsePFArgs splits pf arguments into targets, each optionally followed by
+// its port argument
+func parsePFArgs(args []string) ([]pfRequest, error) {
+	var requests []pfRequest
+	for _, arg := range args {
+		if !isPortArg(arg) {
+			requests = append(requests, pfRequest{target: arg})
+			continue
+		}
+		if len(requests) == 0 || requests[len(requests)-1].port != "" {
+			return nil, fmt.Errorf("port %s does not follow a target", arg)
+		}
+		requests[len(requests)-1].port = arg
+	}
+	return requests, nil
+}
+
+// isPortArg reports whether arg is <port> or <local>:<remote> rather than a
+// target
+func isPortArg(arg string) bool {
+	for _, part := range strings.SplitN(arg, ":", 2) {
+		if _, err := strconv.Atoi(part); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// discoverForwards returns a request for every discovered database in the
+// client's namespace that has a service
+func discoverForwards(ctx context.Context, client *k8s.Client) ([]pfRequest, error) {
+	i18n.Printf("🔍 Discovering databases in %s\n", i18n.T("namespace %s", client.Namespace))
+	databases, err := client.DiscoverDatabases(ctx, client.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []pfRequest
+	for _, db := range databases {
+		if db.Service == "" {
+			continue
 		}
+		requests = append(requests, pfRequest{target: "svc/" + db.Service, port: strconv.Itoa(int(db.Port))})
+	}
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("no databases with a service found in namespace %s", client.Namespace)
+	}
+	return requests, nil
+}
+
+// runSSHPortForward forwards a local port to host:port through a relay pod
+// that tunnels over SSH via the bastion
+func runSSHPortForward(args []string) error {
+	host, portStr, err := net.SplitHostPort(args[0])
+	if err != nil {
+		return fmt.Errorf("with --ssh-via the target must be host:port ([addr]:port for IPv6): %w", err)
+	}
+	remotePort, err := strconv.Atoi(portStr)
+	if err != nil {
+		return fmt.Errorf("invalid port: %s", portStr)
 	}
 
-	if serviceName == "" {
-		return fmt.Errorf("no %s service found in namespace %s (tried: %s)",
-			dbType, ns, strings.Join(alias.serviceNames, ", "))
+	localPort := remotePort
+	if len(args) > 1 {
+		localPort, err = strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid port: %s", args[1])
+		}
 	}
 
-	// Find pod for service
-	podName, err := findPodForService(client, ns, serviceName)
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+	cfg, err := GetConfig()
 	if err != nil {
-		return fmt.Errorf("failed to find pod: %w", err)
+		return err
 	}
 
-	// Build port-forward URL
-	pfURL := client.Clientset.CoreV1().RESTClient().Post().
-		Resource("pods").
-		Namespace(ns).
-		Name(podName).
-		SubResource("portforward").
-		URL()
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
 
-	// Setup port-forward
-	transport, upgrader, err := spdy.RoundTripperFor(client.Config)
+	tunnel, err := pfSSH.start(ctx, client, cfg, sshTunnelPort, host, remotePort)
 	if err != nil {
-		return fmt.Errorf("failed to create round tripper: %w", err)
+		return err
 	}
+	defer tunnel.close()
 
-	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, pfURL)
+	ns := client.Namespace
+	podName := fmt.Sprintf("pocket-ssh-relay-%d", time.Now().Unix())
+
+	podConfig := k8s.PodConfig{
+		Name:      podName,
+		Namespace: ns,
+		Image:     tunnel.container.Image,
+		Command:   []string{"sleep", "86400"},
+		Purpose:   "tunnel",
+		Resources: tunnel.container.Resources,
+		Sidecars:  []corev1.Container{tunnel.container},
+	}
+	podOpts.applyConfig(cfg)
+	if err := podOpts.applyMetadata(&podConfig); err != nil {
+		return err
+	}
+
+	i18n.Printf("📦 Creating relay pod: %s/%s\n", ns, podName)
+	if _, err := client.CreatePod(ctx, podConfig); err != nil {
+		return fmt.Errorf("failed to create relay pod: %w", err)
+	}
+	defer func() {
+		i18n.Printf("🧹 Cleaning up pod: %s\n", podName)
+		cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cleanupCancel()
+		_ = client.DeletePod(cleanupCtx, ns, podName)
+	}()
+
+	// The main container only starts once the tunnel's startup probe passes
+	i18n.Printf("⏳ Waiting for SSH tunnel via %s...\n", pfSSH.via)
+	if err := client.WaitForPodRunning(ctx, ns, podName, 2*time.Minute); err != nil {
+		return fmt.Errorf("SSH tunnel failed to start: %w", err)
+	}
 
 	stopChan := make(chan struct{}, 1)
-	readyChan := make(chan struct{})
 
-	// Handle interrupt
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
@@ -127,45 +519,487 @@ func runPortForward(cmd *cobra.Command, args []string) error {
 		close(stopChan)
 	}()
 
-	ports := []string{fmt.Sprintf("%d:%d", localPort, remotePort)}
-	pf, err := portforward.New(dialer, ports, stopChan, readyChan, os.Stdout, os.Stderr)
-	if err != nil {
-		return fmt.Errorf("failed to create port-forwarder: %w", err)
+	if id := os.Getenv(pfBackgroundEnv); id != "" {
+		mapping := fmt.Sprintf("%s → %s via %s", net.JoinHostPort(pfAddress, strconv.Itoa(localPort)), args[0], pfSSH.via)
+		remove, err := recordBackground(id, args, ns, []string{mapping})
+		if err != nil {
+			return err
+		}
+		defer remove()
+	}
+
+	i18n.Printf("🔌 Port-forwarding to %s via %s\n", args[0], pfSSH.via)
+	printf("📡 %s → %s → %s\n", net.JoinHostPort(pfAddress, strconv.Itoa(localPort)), podName, args[0])
+	i18n.Printf("💡 Press Ctrl+C to stop\n\n")
+
+	return forwardWithReconnect(client, k8s.PortForwardOptions{
+		Namespace: ns,
+		PodName:   podName,
+		Addresses: []string{pfAddress},
+		Ports:     []string{fmt.Sprintf("%d:%d", localPort, sshTunnelPort)},
+		StopChan:  stopChan,
+		Out:       humanOut,
+		ErrOut:    ioStreams.ErrOut,
+	}, nil)
+}
+
+// podCheckInterval is how often a forwarded pod is checked for replacement
+const podCheckInterval = 2 * time.Second
+
+// maxReconnectDelay caps the backoff between reconnect attempts
+const maxReconnectDelay = 10 * time.Second
+
+// podResolver re-resolves the pod and port pairs of a port-forward
+type podResolver func() (podName string, ports []string, err error)
+
+// forwardWithReconnect runs a port-forward and transparently re-dials when
+// the connection drops, e.g. when an exec-plugin token expired, or when its
+// pod is deleted. Each dial picks up refreshed credentials. With resolve, a
+// pod that went away is replaced by the one it returns, e.g. a new pod of a
+// rollout. It gives up after --max-retries attempts in a row that did not
+// bring the forward back.
+func forwardWithReconnect(client *k8s.Client, opts k8s.PortForwardOptions, resolve podResolver) error {
+	failures, everReady := 0, false
+	for {
+		ready, err := forwardOnce(client, opts, failures > 0)
+		if stopped(opts.StopChan) {
+			return nil
+		}
+		if ready {
+			failures, everReady = 0, true
+		} else if !everReady {
+			// Never came up, e.g. the local port is taken
+			return err
+		}
+
+		for {
+			failures++
+			if failures > pfMaxRetries {
+				return err
+			}
+			i18n.Printf("🔄 Connection to pod/%s lost (%v), reconnecting (%d/%d)...\n", opts.PodName, err, failures, pfMaxRetries)
+
+			select {
+			case <-opts.StopChan:
+				return nil
+			case <-time.After(reconnectDelay(failures)):
+			}
+			if resolve == nil {
+				break
+			}
+			podName, ports, resolveErr := resolve()
+			if resolveErr == nil {
+				opts.PodName, opts.Ports = podName, ports
+				break
+			}
+			err = resolveErr
+		}
+	}
+}
+
+// forwardOnce dials a port-forward and runs it until StopChan is closed, the
+// connection drops or the pod goes away. ready reports whether the forward
+// came up; once it does after a reconnect, a status line says so.
+func forwardOnce(client *k8s.Client, opts k8s.PortForwardOptions, reconnect bool) (ready bool, err error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dialStop := make(chan struct{})
+	podGone := client.PodGone(ctx, opts.Namespace, opts.PodName, podCheckInterval)
+	gone := false
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		select {
+		case <-opts.StopChan:
+		case <-podGone:
+			gone = true
+		case <-ctx.Done():
+			return
+		}
+		close(dialStop)
+	}()
+
+	readyChan := make(chan struct{})
+	if reconnect {
+		go func() {
+			select {
+			case <-readyChan:
+				i18n.Printf("✅ Reconnected to pod/%s\n", opts.PodName)
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	opts.StopChan = dialStop
+	opts.ReadyChan = readyChan
+	err = client.PortForward(opts)
+	cancel()
+	<-done
+
+	select {
+	case <-readyChan:
+		ready = true
+	default:
+	}
+	if gone {
+		err = fmt.Errorf("pod %s is gone", opts.PodName)
 	}
+	return ready, err
+}
+
+// reconnectDelay is the backoff before reconnect attempt n
+func reconnectDelay(n int) time.Duration {
+	delay := time.Second << (n - 1)
+	if delay <= 0 || delay > maxReconnectDelay {
+		return maxReconnectDelay
+	}
+	return delay
+}
+
+// stopped reports whether stopChan is closed
+func stopped(stopChan <-chan struct{}) bool {
+	select {
+	case <-stopChan:
+		return true
+	default:
+		return false
+	}
+}
+
+// forwardTarget is a resolved port-forward: the pod and port that receive
+// connections to the local port
+type forwardTarget struct {
+	// target and portArg are what was resolved, for resolving it again
+	target    string
+	portArg   string
+	podName   string
+	podPort   int
+	localPort int
+	// autoLocal is set when the local port was not given and may be moved
+	// to a free one
+	autoLocal bool
+	// display names what is forwarded, e.g. svc/grafana:3000
+	display string
+}
 
-	fmt.Printf("🔌 Port-forwarding to %s\n", dbType)
-	fmt.Printf("📡 %s:%d → %s:%d\n", pfAddress, localPort, serviceName, remotePort)
-	fmt.Printf("💡 Press Ctrl+C to stop\n\n")
+// options returns the port-forward options of fwd on the --address
+func (fwd *forwardTarget) options(ns string, stopChan chan struct{}, out io.Writer) k8s.PortForwardOptions {
+	return k8s.PortForwardOptions{
+		Namespace: ns,
+		PodName:   fwd.podName,
+		Addresses: []string{pfAddress},
+		Ports:     []string{fmt.Sprintf("%d:%d", fwd.localPort, fwd.podPort)},
+		StopChan:  stopChan,
+		Out:       out,
+		ErrOut:    ioStreams.ErrOut,
+	}
+}
 
-	return pf.ForwardPorts()
+// resolver returns a podResolver that resolves fwd's target again, so that
+// a replaced pod is forwarded to
+func (fwd *forwardTarget) resolver(client *k8s.Client) podResolver {
+	return func() (string, []string, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		next, err := resolvePortForward(ctx, client, fwd.target, fwd.portArg)
+		if err != nil {
+			return "", nil, err
+		}
+		if next.podName != fwd.podName {
+			i18n.Printf("🔁 %s moved to pod/%s\n", fwd.display, next.podName)
+		}
+		fwd.podName, fwd.podPort = next.podName, next.podPort
+		return fwd.podName, []string{fmt.Sprintf("%d:%d", fwd.localPort, fwd.podPort)}, nil
+	}
 }
 
-func findPodForService(client *k8s.Client, ns, serviceName string) (string, error) {
-	svc, err := client.Clientset.CoreV1().Services(ns).Get(context.Background(), serviceName, metav1.GetOptions{})
+// resolvePortForward resolves a port-forward target and its ports. target is
+// an alias, whose optional port argument is the local port, or a pod, svc or
+// deploy reference, whose port argument is <port> or <local>:<remote> as
+// with kubectl. Service ports are mapped to their pods' target ports.
+func resolvePortForward(ctx context.Context, client *k8s.Client, target, portArg string) (*forwardTarget, error) {
+	ref := target
+	localPort, remotePort := 0, 0
+	if target != "" && !strings.Contains(target, "/") {
+		alias, err := resolvePFAlias(target)
+		if err != nil {
+			return nil, err
+		}
+		serviceName, err := findAliasService(ctx, client, target, alias)
+		if err != nil {
+			return nil, err
+		}
+		ref = "svc/" + serviceName
+		remotePort = alias.defaultPort
+		if portArg != "" {
+			if localPort, err = strconv.Atoi(portArg); err != nil {
+				return nil, fmt.Errorf("invalid port: %s", portArg)
+			}
+		}
+	} else if portArg != "" {
+		var err error
+		if localPort, remotePort, err = parsePortPair(portArg); err != nil {
+			return nil, err
+		}
+		// A lone port is the remote port, which the local one defaults to
+		if !strings.Contains(portArg, ":") {
+			localPort = 0
+		}
+	}
+
+	var kind, name string
+	var pods []corev1.Pod
+	if target == "" {
+		// --selector picks pods by label, without a service
+		ref, kind = fmt.Sprintf("pods[%s]", pfSelector), kindPod
+		list, err := client.Clientset.CoreV1().Pods(client.Namespace).List(ctx, metav1.ListOptions{LabelSelector: pfSelector})
+		if err != nil {
+			return nil, err
+		}
+		pods = list.Items
+	} else {
+		var err error
+		if kind, name, err = parseResourceRef(ref, kindPod); err != nil {
+			return nil, err
+		}
+		if pods, err = resolvePods(ctx, client, ref); err != nil {
+			return nil, err
+		}
+	}
+	pod := readyPod(ctx, client, pods)
+	if pod == nil {
+		return nil, fmt.Errorf("no ready pod found for %s", ref)
+	}
+
+	fwd := &forwardTarget{target: target, portArg: portArg, podName: pod.Name, podPort: remotePort}
+	if kind == kindService {
+		svc, err := client.Clientset.CoreV1().Services(client.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		svcPort, err := servicePort(svc, remotePort)
+		if err != nil {
+			return nil, err
+		}
+		if fwd.podPort, err = targetPort(svcPort, pod); err != nil {
+			return nil, err
+		}
+		remotePort = int(svcPort.Port)
+	} else if remotePort == 0 {
+		if remotePort = firstContainerPort(pod); remotePort == 0 {
+			return nil, fmt.Errorf("pod %s declares no container ports; pass <local>:<remote>", pod.Name)
+		}
+		fwd.podPort = remotePort
+	}
+
+	fwd.localPort = localPort
+	if fwd.localPort == 0 {
+		fwd.localPort, fwd.autoLocal = remotePort, true
+	}
+	fwd.display = fmt.Sprintf("%s:%d", ref, remotePort)
+	return fwd, nil
+}
+
+// maxPortSearch bounds how many ports after a taken default one are tried
+const maxPortSearch = 100
+
+// pickLocalPort checks that fwd's local port is free on the --address and
+// not in taken, the ports of earlier forwards. A default port that is in
+// use moves to the next free one, or a random one with --random-port; a
+// port that was asked for is an error.
+func (fwd *forwardTarget) pickLocalPort(taken map[int]string) error {
+	free := func(port int) bool {
+		_, used := taken[port]
+		return !used && portFree(pfAddress, port)
+	}
+	if free(fwd.localPort) {
+		return nil
+	}
+	if !fwd.autoLocal {
+		if other, ok := taken[fwd.localPort]; ok {
+			return fmt.Errorf("%s and %s both use local port %d; pass a different local port", other, fwd.display, fwd.localPort)
+		}
+		return fmt.Errorf("local port %d is already in use on %s", fwd.localPort, pfAddress)
+	}
+
+	port := 0
+	if pfRandomPort {
+		for range maxPortSearch {
+			if p, err := randomPort(); err == nil && free(p) {
+				port = p
+				break
+			}
+		}
+	} else {
+		for p := fwd.localPort + 1; p <= 65535 && p <= fwd.localPort+maxPortSearch; p++ {
+			if free(p) {
+				port = p
+				break
+			}
+		}
+	}
+	if port == 0 {
+		return fmt.Errorf("local port %d is in use and no free port was found; pass one", fwd.localPort)
+	}
+
+	i18n.Printf("⚠️  Local port %d is in use, using %d for %s\n", fwd.localPort, port, fwd.display)
+	fwd.localPort = port
+	return nil
+}
+
+// portFree reports whether port can be bound on address
+func portFree(address string, port int) bool {
+	l, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(port)))
 	if err != nil {
-		return "", err
+		return false
 	}
+	_ = l.Close()
+	return true
+}
 
-	var selectors []string
-	for k, v := range svc.Spec.Selector {
-		selectors = append(selectors, fmt.Sprintf("%s=%s", k, v))
+// randomPort returns a free port on the --address chosen by the system
+func randomPort() (int, error) {
+	l, err := net.Listen("tcp", net.JoinHostPort(pfAddress, "0"))
+	if err != nil {
+		return 0, err
 	}
+	defer func() { _ = l.Close() }()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// aliasChoices remembers the service chosen for an alias in a namespace, so
+// that resolving it again, as after a lost forward, does not ask again
+var aliasChoices sync.Map
 
-	if len(selectors) == 0 {
-		return "", fmt.Errorf("service has no selector")
+// findAliasService returns the service of an alias in the client's
+// namespace. When several of its services exist, the user chooses one, or
+// the first is used without a terminal.
+func findAliasService(ctx context.Context, client *k8s.Client, aliasName string, alias pfAlias) (string, error) {
+	key := client.Namespace + "/" + aliasName
+	if name, ok := aliasChoices.Load(key); ok {
+		return name.(string), nil
 	}
 
-	pods, err := client.Clientset.CoreV1().Pods(ns).List(context.Background(), metav1.ListOptions{
-		LabelSelector: strings.Join(selectors, ","),
-		Limit:         1,
-	})
+	var found []string
+	for _, name := range alias.serviceNames {
+		_, err := client.Clientset.CoreV1().Services(client.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			found = append(found, name)
+		}
+	}
+	if len(found) == 0 {
+		return "", fmt.Errorf("no %s service found in namespace %s (tried: %s)",
+			aliasName, client.Namespace, strings.Join(alias.serviceNames, ", "))
+	}
+
+	i, err := choose(i18n.T("%s services", aliasName), found, i18n.T("pass svc/<name> to choose"))
 	if err != nil {
 		return "", err
 	}
+	aliasChoices.Store(key, found[i])
+	return found[i], nil
+}
+
+// parsePortPair parses <port> or <local>:<remote>
+func parsePortPair(arg string) (local, remote int, err error) {
+	localStr, remoteStr, found := strings.Cut(arg, ":")
+	if !found {
+		remoteStr = localStr
+	}
+	if local, err = strconv.Atoi(localStr); err != nil || local < 0 || local > 65535 {
+		return 0, 0, fmt.Errorf("invalid port: %s (use <port> or <local>:<remote>)", arg)
+	}
+	if remote, err = strconv.Atoi(remoteStr); err != nil || remote <= 0 || remote > 65535 {
+		return 0, 0, fmt.Errorf("invalid port: %s (use <port> or <local>:<remote>)", arg)
+	}
+	return local, remote, nil
+}
+
+// readyPod returns a ready pod that is not being deleted, preferring pods
+// on nodes in the --zone. Nodes that cannot be read count as another zone.
+func readyPod(ctx context.Context, client *k8s.Client, pods []corev1.Pod) *corev1.Pod {
+	var ready []*corev1.Pod
+	for i := range pods {
+		if pods[i].DeletionTimestamp == nil && podReady(&pods[i]) {
+			ready = append(ready, &pods[i])
+		}
+	}
+	if len(ready) == 0 {
+		return nil
+	}
 
-	if len(pods.Items) == 0 {
-		return "", fmt.Errorf("no pods found for service")
+	if pfZone != "" {
+		zones := map[string]string{}
+		for _, pod := range ready {
+			zone, ok := zones[pod.Spec.NodeName]
+			if !ok {
+				if node, err := client.Clientset.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, metav1.GetOptions{}); err == nil {
+					zone = node.Labels[corev1.LabelTopologyZone]
+				}
+				zones[pod.Spec.NodeName] = zone
+			}
+			if zone == pfZone {
+				return pod
+			}
+		}
 	}
+	return ready[0]
+}
 
-	return pods.Items[0].Name, nil
+// podReady reports whether pod is running and its Ready condition is true
+func podReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// servicePort returns svc's port number port, or its only port if port is 0
+func servicePort(svc *corev1.Service, port int) (*corev1.ServicePort, error) {
+	var ports []string
+	for i, sp := range svc.Spec.Ports {
+		if int(sp.Port) == port || (port == 0 && len(svc.Spec.Ports) == 1) {
+			return &svc.Spec.Ports[i], nil
+		}
+		ports = append(ports, strconv.Itoa(int(sp.Port)))
+	}
+	if port == 0 {
+		return nil, fmt.Errorf("service %s has several ports (%s); pass one", svc.Name, strings.Join(ports, ", "))
+	}
+	return nil, fmt.Errorf("service %s has no port %d (ports: %s)", svc.Name, port, strings.Join(ports, ", "))
+}
+
+// targetPort resolves a service port's target port on pod, looking up named
+// ports in its containers
+func targetPort(sp *corev1.ServicePort, pod *corev1.Pod) (int, error) {
+	if sp.TargetPort.Type == intstr.String {
+		for _, c := range pod.Spec.Containers {
+			for _, p := range c.Ports {
+				if p.Name == sp.TargetPort.StrVal {
+					return int(p.ContainerPort), nil
+				}
+			}
+		}
+		return 0, fmt.Errorf("pod %s has no port named %s", pod.Name, sp.TargetPort.StrVal)
+	}
+	if sp.TargetPort.IntVal == 0 {
+		return int(sp.Port), nil
+	}
+	return int(sp.TargetPort.IntVal), nil
+}
+
+// firstContainerPort returns the first port a pod's containers declare, or 0
+func firstContainerPort(pod *corev1.Pod) int {
+	for _, c := range pod.Spec.Containers {
+		if len(c.Ports) > 0 {
+			return int(c.Ports[0].ContainerPort)
+		}
+	}
+	return 0
 }
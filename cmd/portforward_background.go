@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/forwards"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// pfBackgroundEnv passes the id of a background port-forward to the
+// detached process that runs it
+const pfBackgroundEnv = "KUBECTL_POCKET_PF_ID"
+
+// pfChoicesEnv passes the services chosen for aliases to the detached
+// process, as namespace/alias=service pairs separated by commas
+const pfChoicesEnv = "KUBECTL_POCKET_PF_CHOICES"
+
+// pfStartTimeout bounds how long --background waits for the forward to
+// come up
+const pfStartTimeout = time.Minute
+
+var pfListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List port-forwards running in the background",
+	Long: `List the port-forwards started with --background, with their id, process
+and local mappings. Forwards whose process is gone are dropped.
+
+Examples:
+  kubectl pocket pf redis --background
+  kubectl pocket pf list`,
+	Args: cobra.NoArgs,
+	RunE: runPFList,
+}
+
+var pfStopCmd = &cobra.Command{
+	Use:   "stop (<id>... | all)",
+	Short: "Stop port-forwards running in the background",
+	Long: `Stop port-forwards started with --background, by the id shown by
+"kubectl pocket pf list", or all of them.
+
+Examples:
+  kubectl pocket pf stop 3fa2c1
+  kubectl pocket pf stop all`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runPFStop,
+}
+
+// forwardStore returns the store of background port-forwards
+func forwardStore() *forwards.Store {
+	return forwards.New(forwards.DefaultDir())
+}
+
+// startBackground runs this pf invocation again as a detached process and
+// waits until its forwards are up
+func startBackground(args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find executable: %w", err)
+	}
+
+	idBytes := make([]byte, 3)
+	if _, err := rand.Read(idBytes); err != nil {
+		return err
+	}
+	id := hex.EncodeToString(idBytes)
+
+	store := forwardStore()
+	logFile, err := store.CreateLog(id)
+	if err != nil {
+		return fmt.Errorf("failed to create log file: %w", err)
+	}
+
+	var childArgs []string
+	for _, arg := range os.Args[1:] {
+		if arg != "--background" && !strings.HasPrefix(arg, "--background=") {
+			childArgs = append(childArgs, arg)
+		}
+	}
+	child := exec.Command(exe, childArgs...)
+	child.Env = append(os.Environ(), pfBackgroundEnv+"="+id)
+	if choices := encodeAliasChoices(); choices != "" {
+		child.Env = append(child.Env, pfChoicesEnv+"="+choices)
+	}
+	child.Stdout, child.Stderr = logFile, logFile
+	detach(child)
+	err = child.Start()
+	_ = logFile.Close()
+	if err != nil {
+		_ = store.Remove(id)
+		return fmt.Errorf("failed to start background port-forward: %w", err)
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		_ = child.Wait()
+		close(exited)
+	}()
+
+	i18n.Printf("🔌 Starting port-forward to %s in the background\n", strings.Join(args, " "))
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	deadline := time.After(pfStartTimeout)
+	for {
+		select {
+		case <-exited:
+			output, _ := os.ReadFile(store.LogPath(id))
+			_ = store.Remove(id)
+			return fmt.Errorf("background port-forward exited:\n%s", strings.TrimSpace(string(output)))
+		case <-deadline:
+			_ = child.Process.Kill()
+			_ = store.Remove(id)
+			return fmt.Errorf("background port-forward did not come up within %s", pfStartTimeout)
+		case <-ticker.C:
+		}
+
+		record, err := store.Get(id)
+		if err != nil {
+			continue
+		}
+		for _, mapping := range record.Mappings {
+			printf("📡 %s\n", mapping)
+		}
+		i18n.Printf("✅ Running in the background as %s (pid %d), logs in %s\n", id, record.PID, record.LogFile)
+		i18n.Printf("💡 Stop it with: kubectl pocket pf stop %s\n", id)
+		return nil
+	}
+}
+
+// chooseAliasServices lets the user choose among the services of the
+// aliases in requests before detaching, as the detached process cannot ask
+func chooseAliasServices(requests []pfRequest) error {
+	if !interactive() {
+		return nil
+	}
+	var client *k8s.Client
+	for _, req := range requests {
+		if strings.Contains(req.target, "/") {
+			continue
+		}
+		alias, err := resolvePFAlias(req.target)
+		if err != nil {
+			return err
+		}
+		if client == nil {
+			if client, err = GetK8sClient(); err != nil {
+				return fmt.Errorf("failed to create k8s client: %w", err)
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		_, err = findAliasService(ctx, client, req.target, alias)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeAliasChoices renders aliasChoices for pfChoicesEnv
+func encodeAliasChoices() string {
+	var pairs []string
+	aliasChoices.Range(func(key, value any) bool {
+		pairs = append(pairs, key.(string)+"="+value.(string))
+		return true
+	})
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// loadAliasChoices fills aliasChoices from the value of pfChoicesEnv
+func loadAliasChoices(value string) {
+	for _, pair := range strings.Split(value, ",") {
+		if key, service, ok := strings.Cut(pair, "="); ok {
+			aliasChoices.Store(key, service)
+		}
+	}
+}
+
+// recordBackground records this process as the background port-forward id
+// once its mappings are known. The returned func removes the record.
+func recordBackground(id string, args []string, namespace string, mappings []string) (func(), error) {
+	store := forwardStore()
+	err := store.Save(forwards.Record{
+		ID:        id,
+		PID:       os.Getpid(),
+		Args:      args,
+		Namespace: namespace,
+		Mappings:  mappings,
+		LogFile:   store.LogPath(id),
+		StartedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record background port-forward: %w", err)
+	}
+	return func() { _ = store.Remove(id) }, nil
+}
+
+// liveForwards returns the recorded background port-forwards whose process
+// is still running, dropping the others
+func liveForwards(store *forwards.Store) ([]forwards.Record, error) {
+	records, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read background port-forwards: %w", err)
+	}
+	var live []forwards.Record
+	for _, r := range records {
+		if processAlive(r.PID) {
+			live = append(live, r)
+		} else {
+			_ = store.Remove(r.ID)
+		}
+	}
+	return live, nil
+}
+
+func runPFList(cmd *cobra.Command, args []string) error {
+	records, err := liveForwards(forwardStore())
+	if err != nil {
+		return err
+	}
+
+	if structured() {
+		if records == nil {
+			records = []forwards.Record{}
+		}
+		return printStructured(records)
+	}
+	if len(records) == 0 {
+		i18n.Printf("💡 No port-forwards are running in the background\n")
+		return nil
+	}
+
+	w := newTable()
+	_, _ = fmt.Fprintln(w, "ID\tPID\tNAMESPACE\tAGE\tFORWARDS")
+	for _, r := range records {
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n",
+			r.ID, r.PID, r.Namespace, duration.HumanDuration(time.Since(r.StartedAt)), strings.Join(r.Mappings, ", "))
+	}
+	return w.Flush()
+}
+
+func runPFStop(cmd *cobra.Command, args []string) error {
+	store := forwardStore()
+	records, err := liveForwards(store)
+	if err != nil {
+		return err
+	}
+
+	var stop []forwards.Record
+	if len(args) == 1 && args[0] == "all" {
+		stop = records
+	} else {
+		for _, id := range args {
+			found := false
+			for _, r := range records {
+				if r.ID == id {
+					stop, found = append(stop, r), true
+				}
+			}
+			if !found {
+				return fmt.Errorf("no background port-forward %s (see 'kubectl pocket pf list')", id)
+			}
+		}
+	}
+
+	for _, r := range stop {
+		if err := stopProcess(r.PID); err != nil {
+			return fmt.Errorf("failed to stop port-forward %s: %w", r.ID, err)
+		}
+		_ = store.Remove(r.ID)
+		i18n.Printf("🛑 Stopped port-forward %s (%s)\n", r.ID, strings.Join(r.Args, " "))
+	}
+	if len(stop) == 0 {
+		i18n.Printf("💡 No port-forwards are running in the background\n")
+	}
+	return nil
+}
+
+// stopProcess asks the process pid to stop, as Ctrl+C would, and kills it
+// where that is not possible
+func stopProcess(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if err := p.Signal(os.Interrupt); err != nil {
+		return p.Kill()
+	}
+	return nil
+}
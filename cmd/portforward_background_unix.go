@@ -0,0 +1,20 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+)
+
+// detach starts cmd in its own session, so that it outlives the terminal
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// processAlive reports whether the process pid is running
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,27 @@
+//go:build windows
+
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// detachedProcess is DETACHED_PROCESS: the process has no console
+const detachedProcess = 0x00000008
+
+// detach starts cmd without a console, so that it outlives the terminal
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess}
+}
+
+// processAlive reports whether the process pid is running
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = p.Release()
+	return true
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"golang.org/x/term"
+)
+
+// spinnerFrames animate the progress line
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// progressDisplay shows how many tasks of a parallel run are done and which
+// are running, on one line redrawn in place. A nil display shows nothing,
+// so runs whose output is not a terminal print only their summary.
+type progressDisplay struct {
+	mu      sync.Mutex
+	out     *os.File
+	total   int
+	done    int
+	failed  int
+	running []string
+	frame   int
+	// width is the length of the line last drawn, which the next one
+	// overwrites
+	width int
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// startProgress starts the display of a run of total tasks. It returns nil
+// unless human output goes to a terminal that shows emoji.
+func startProgress(total int) *progressDisplay {
+	out, ok := humanOut.(*os.File)
+	if !ok || quiet || plainOutput || !term.IsTerminal(int(out.Fd())) {
+		return nil
+	}
+	p := &progressDisplay{out: out, total: total, stop: make(chan struct{}), stopped: make(chan struct{})}
+	go p.animate()
+	return p
+}
+
+// Started adds a running task
+func (p *progressDisplay) Started(name string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running = append(p.running, name)
+	p.draw()
+}
+
+// Finished counts a task as done, failed when err is set
+func (p *progressDisplay) Finished(name string, err error) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, running := range p.running {
+		if running == name {
+			p.running = append(p.running[:i], p.running[i+1:]...)
+			break
+		}
+	}
+	p.done++
+	if err != nil {
+		p.failed++
+	}
+	p.draw()
+}
+
+// Stop erases the progress line, so the summary is printed in its place
+func (p *progressDisplay) Stop() {
+	if p == nil {
+		return
+	}
+	close(p.stop)
+	<-p.stopped
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, _ = io.WriteString(p.out, "\r"+strings.Repeat(" ", p.width)+"\r")
+}
+
+// animate advances the spinner until Stop
+func (p *progressDisplay) animate() {
+	defer close(p.stopped)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			p.frame = (p.frame + 1) % len(spinnerFrames)
+			p.draw()
+			p.mu.Unlock()
+		}
+	}
+}
+
+// draw redraws the line, cut to the terminal's width. p.mu must be held.
+func (p *progressDisplay) draw() {
+	line := string(spinnerFrames[p.frame]) + " " + i18n.T("%d/%d done", p.done, p.total)
+	if p.failed > 0 {
+		line += ", " + i18n.T("%d failed", p.failed)
+	}
+	if len(p.running) > 0 {
+		line += " · " + i18n.T("running: %s", strings.Join(p.running, ", "))
+	}
+
+	runes := []rune(line)
+	if width, _, err := term.GetSize(int(p.out.Fd())); err == nil && len(runes) > width-1 {
+		runes = append(runes[:max(width-2, 0)], '…')
+	}
+	pad := max(p.width-len(runes), 0)
+	p.width = len(runes)
+	_, _ = io.WriteString(p.out, "\r"+string(runes)+strings.Repeat(" ", pad))
+}
+
+// errInterrupted reports a parallel run stopped with Ctrl+C, after its pods
+// were deleted
+func errInterrupted() error {
+	i18n.Printf("🛑 Interrupted; the run's pods were deleted\n")
+	return fmt.Errorf("interrupted")
+}
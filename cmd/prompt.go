@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"golang.org/x/term"
+)
+
+// confirm asks a yes/no question on the terminal. Without a terminal, or
+// with --non-interactive, it fails, so scripted runs must opt in with
+// skipFlag instead. English answers are accepted in every locale.
+func confirm(question, skipFlag string) (bool, error) {
+	if nonInteractive || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false, fmt.Errorf("cannot ask for confirmation without a terminal; pass %s", skipFlag)
+	}
+
+	i18n.Printf("❓ %s [y/N] ", question)
+	answer, err := bufio.NewReader(ioStreams.In).ReadString('\n')
+	if err != nil {
+		return false, nil
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes" || answer == i18n.T("y") || answer == i18n.T("yes"), nil
+}
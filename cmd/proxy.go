@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// socksProxyImage runs a SOCKS5 server configured by environment variables
+const socksProxyImage = "serjs/go-socks5-proxy:latest"
+
+// socksProxyPort is the port the proxy listens on in the pod
+const socksProxyPort = 1080
+
+var proxyCmd = &cobra.Command{
+	Use:   "proxy",
+	Short: "Open a SOCKS5 proxy into the cluster network",
+	Long: `Run a small SOCKS5 proxy in a temporary pod and port-forward it locally, so
+any local tool can reach ClusterIP services, pod IPs and cluster DNS names
+without a port-forward per service. The pod is deleted on exit.
+
+Use a client that resolves names through the proxy (socks5h), so that
+cluster DNS names such as redis.cache.svc.cluster.local work.
+
+Examples:
+  kubectl pocket proxy
+  curl --socks5-hostname localhost:1080 http://api.payments.svc.cluster.local:8080/healthz
+  kubectl pocket proxy --port 11080 -n payments
+  ALL_PROXY=socks5h://localhost:1080 psql -h pg-svc.payments.svc.cluster.local -U app`,
+	Args: cobra.NoArgs,
+	RunE: runProxy,
+}
+
+var (
+	proxyPort    int
+	proxyAddress string
+	proxyImage   string
+)
+
+func init() {
+	proxyCmd.Flags().IntVar(&proxyPort, "port", socksProxyPort, "local port of the proxy")
+	proxyCmd.Flags().StringVar(&proxyAddress, "address", "127.0.0.1", "local address to bind")
+	proxyCmd.Flags().StringVar(&proxyImage, "image", "", "override the proxy image (default "+socksProxyImage+")")
+	addPodFlags(proxyCmd)
+}
+
+func runProxy(cmd *cobra.Command, args []string) error {
+	if !portFree(proxyAddress, proxyPort) {
+		return fmt.Errorf("local port %d is already in use on %s; pass another with --port", proxyPort, proxyAddress)
+	}
+
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+	cfg, err := GetConfig()
+	if err != nil {
+		return err
+	}
+	if err := preflight(client, append(accessesRunPods, accessPortForward)...); err != nil {
+		return err
+	}
+
+	image := proxyImage
+	if image == "" {
+		image = resolveImage(cfg, "proxy", socksProxyImage)
+	}
+
+	ns := client.Namespace
+	podName := fmt.Sprintf("pocket-proxy-%d", time.Now().Unix())
+	podConfig := k8s.PodConfig{
+		Name:      podName,
+		Namespace: ns,
+		Image:     image,
+		Env: []corev1.EnvVar{
+			{Name: "PROXY_PORT", Value: strconv.Itoa(socksProxyPort)},
+			// Only reachable through the local port-forward
+			{Name: "REQUIRE_AUTH", Value: "false"},
+		},
+		Purpose: "proxy",
+	}
+	podOpts.applyConfig(cfg)
+	if err := podOpts.apply(&podConfig); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	i18n.Printf("📦 Creating proxy pod: %s/%s\n", ns, podName)
+	if _, err := client.CreatePod(ctx, podConfig); err != nil {
+		return fmt.Errorf("failed to create proxy pod: %w", err)
+	}
+	defer func() {
+		i18n.Printf("🧹 Cleaning up pod: %s\n", podName)
+		cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cleanupCancel()
+		_ = client.DeletePod(cleanupCtx, ns, podName)
+	}()
+
+	i18n.Printf("⏳ Waiting for pod to be ready...\n")
+	if err := client.WaitForPodRunning(ctx, ns, podName, 2*time.Minute); err != nil {
+		return fmt.Errorf("proxy pod failed to start: %w", err)
+	}
+
+	stopChan := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		close(stopChan)
+	}()
+
+	address := net.JoinHostPort(proxyAddress, strconv.Itoa(proxyPort))
+	i18n.Printf("🧦 SOCKS5 proxy into the cluster at %s\n", address)
+	i18n.Printf("💡 e.g. curl --socks5-hostname %s http://<service>.<namespace>.svc.cluster.local\n", address)
+	i18n.Printf("💡 Press Ctrl+C to stop\n\n")
+
+	return forwardWithReconnect(client, k8s.PortForwardOptions{
+		Namespace: ns,
+		PodName:   podName,
+		Addresses: []string{proxyAddress},
+		Ports:     []string{fmt.Sprintf("%d:%d", proxyPort, socksProxyPort)},
+		StopChan:  stopChan,
+		Out:       humanOut,
+		ErrOut:    ioStreams.ErrOut,
+	}, nil)
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/bundle"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+var psCmd = &cobra.Command{
+	Use:   "ps",
+	Short: "List live pocket pods with who created them",
+	Long: `List the pods pocket created that still exist, in every namespace unless -n is
+given, with the user, pocket version and command that created each one.
+
+Pods record their creator as annotations: kubectl-pocket/created-by holds the
+user the API server authenticated (a SelfSubjectReview, falling back to the
+kubeconfig user), kubectl-pocket/version the pocket version and
+kubectl-pocket/command the command line, with passwords redacted.
+
+Examples:
+  kubectl pocket ps
+  kubectl pocket ps --mine
+  kubectl pocket ps -n payments -o json`,
+	Args: cobra.NoArgs,
+	RunE: runPs,
+}
+
+var psMine bool
+
+func init() {
+	psCmd.Flags().BoolVar(&psMine, "mine", false, "only list pods created by the current user")
+	markStructuredOutput(psCmd)
+}
+
+// pocketPod is a pod listed by ps
+type pocketPod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Purpose   string `json:"purpose,omitempty"`
+	Phase     string `json:"phase"`
+	CreatedBy string `json:"createdBy,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Command   string `json:"command,omitempty"`
+	Age       string `json:"age"`
+}
+
+func runPs(cmd *cobra.Command, args []string) error {
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	ns := metav1.NamespaceAll
+	if configFlags.Namespace != nil && *configFlags.Namespace != "" {
+		ns = client.Namespace
+	}
+	var me string
+	if psMine {
+		me = client.Creator(ctx)
+	}
+
+	list, err := client.Clientset.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{
+		LabelSelector: k8s.LabelManagedBy + "=kubectl-pocket",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	pods := []pocketPod{}
+	for _, pod := range list.Items {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		createdBy := pod.Annotations[k8s.AnnotationCreatedBy]
+		if psMine && createdBy != me {
+			continue
+		}
+		pods = append(pods, pocketPod{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			Purpose:   pod.Labels[k8s.LabelPurpose],
+			Phase:     string(pod.Status.Phase),
+			CreatedBy: createdBy,
+			Version:   pod.Annotations[k8s.AnnotationVersion],
+			Command:   pod.Annotations[k8s.AnnotationCommand],
+			Age:       duration.HumanDuration(time.Since(pod.CreationTimestamp.Time)),
+		})
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+
+	if structured() {
+		return printStructured(pods)
+	}
+	if len(pods) == 0 {
+		i18n.Printf("✨ No pocket pods running\n")
+		return nil
+	}
+
+	w := newTable()
+	_, _ = fmt.Fprintln(w, "NAMESPACE\tNAME\tPURPOSE\tSTATUS\tCREATED BY\tVERSION\tAGE\tCOMMAND")
+	for _, pod := range pods {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			pod.Namespace, pod.Name, valueOr(pod.Purpose, "-"), pod.Phase,
+			valueOr(pod.CreatedBy, "-"), valueOr(pod.Version, "-"), pod.Age, valueOr(pod.Command, "-"))
+	}
+	return w.Flush()
+}
+
+// setAudit makes client record on the pods it creates who created them, with
+// which version and command
+func setAudit(client *k8s.Client) {
+	args := bundle.NewRedactor().Args(os.Args[1:])
+	client.Audit = &k8s.PodAudit{
+		Version: displayVersion(Version),
+		Command: strings.Join(append([]string{"kubectl", "pocket"}, args...), " "),
+	}
+}
@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var quotaCmd = &cobra.Command{
+	Use:   "quota",
+	Short: "Inspect namespace constraints on pocket's pods",
+}
+
+var quotaCheckCmd = &cobra.Command{
+	Use:   "check [tester]",
+	Short: "Check whether the namespace would admit pocket's pod",
+	Long: `Check the namespace's ResourceQuotas, LimitRanges and Pod Security labels
+against the pod pocket would create, and report which constraint rejects it.
+
+The planned pod is built from the same flags as a test (--cpu, --memory,
+--run-as-user, --allow-root, ...) and the tester's image. The result is
+confirmed with a server-side dry run, which also catches admission webhooks
+and policy engines.
+
+With --adjust, resources are changed where that is safe (raised to a
+LimitRange minimum, lowered to a maximum or to what is left of a quota) and
+the flags that pass are printed. Security settings are never adjusted.
+
+Examples:
+  kubectl pocket quota check
+  kubectl pocket quota check postgres -n payments --memory 1Gi
+  kubectl pocket quota check redis --adjust
+  kubectl pocket quota check postgres -o json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runQuotaCheck,
+}
+
+var (
+	quotaImage  string
+	quotaAdjust bool
+)
+
+// quotaCheckImage is checked when no tester is given
+const quotaCheckImage = "busybox:1.36"
+
+func init() {
+	quotaCmd.AddCommand(quotaCheckCmd)
+
+	quotaCheckCmd.Flags().StringVar(&quotaImage, "image", "", "override the client image")
+	quotaCheckCmd.Flags().BoolVar(&quotaAdjust, "adjust", false, "adjust resources to fit where safe and print the resulting flags")
+	addPodFlags(quotaCheckCmd)
+	markStructuredOutput(quotaCheckCmd)
+}
+
+// quotaReport is the structured result of quota check
+type quotaReport struct {
+	Namespace   string             `json:"namespace"`
+	Pod         string             `json:"pod"`
+	Admitted    bool               `json:"admitted"`
+	DryRunError string             `json:"dryRunError,omitempty"`
+	Constraints []constraintReport `json:"constraints,omitempty"`
+	// AdjustedFlags are the resource flags that pass, with --adjust
+	AdjustedFlags string `json:"adjustedFlags,omitempty"`
+}
+
+// constraintReport is one constraint the planned pod runs into
+type constraintReport struct {
+	Status   string `json:"status"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Message  string `json:"message"`
+	Adjusted string `json:"adjusted,omitempty"`
+}
+
+func runQuotaCheck(cmd *cobra.Command, args []string) error {
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+	podConfig, err := plannedPod(client, args, quotaImage)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	i18n.Printf("🔍 Checking namespace constraints in %s\n", client.Namespace)
+	violations, err := client.CheckPodConstraints(ctx, &podConfig, quotaAdjust)
+	if err != nil {
+		return err
+	}
+
+	blocking := 0
+	report := quotaReport{Namespace: client.Namespace, Pod: podConfig.Name}
+	verdicts := make([]checkStatus, len(violations))
+	for i, v := range violations {
+		status := checkUnhealthy
+		switch {
+		case v.Warning:
+			status = checkWarning
+		case v.Adjusted != "":
+			status = checkHealthy
+		default:
+			blocking++
+		}
+		verdicts[i] = status
+		report.Constraints = append(report.Constraints, constraintReport{
+			Status: status.String(), Kind: v.Kind, Name: v.Name, Message: v.Message, Adjusted: v.Adjusted,
+		})
+	}
+
+	// The dry run is the API server's own verdict, including webhooks
+	dryRunErr := client.DryRunPod(ctx, podConfig)
+	report.Admitted = dryRunErr == nil
+	if dryRunErr != nil {
+		report.DryRunError = dryRunErr.Error()
+	}
+	if quotaAdjust && adjustedAny(violations) {
+		report.AdjustedFlags = resourceFlags(podConfig.Resources)
+	}
+
+	if structured() {
+		if err := printStructured(report); err != nil {
+			return err
+		}
+		if dryRunErr != nil {
+			return checkFailed(fmt.Errorf("pod would be rejected in namespace %s", client.Namespace))
+		}
+		return nil
+	}
+
+	if len(violations) > 0 {
+		w := newTable()
+		_, _ = fmt.Fprintln(w, "\nSTATUS\tKIND\tNAME\tPROBLEM\tADJUSTED")
+		for i, v := range violations {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", verdicts[i].icon(), v.Kind, v.Name, v.Message, valueOr(v.Adjusted, "-"))
+		}
+		_ = w.Flush()
+		printf("\n")
+	}
+
+	if report.AdjustedFlags != "" {
+		i18n.Printf("💡 Adjusted resources: %s\n", report.AdjustedFlags)
+	}
+
+	switch {
+	case dryRunErr != nil:
+		i18n.Printf("❌ The API server rejects the pod:\n%s\n", dryRunErr)
+		if blocking == 0 && !quotaAdjust {
+			i18n.Printf("💡 Not explained by quotas, limit ranges or Pod Security; check admission webhooks and policies\n")
+		}
+		return checkFailed(fmt.Errorf("pod would be rejected in namespace %s", client.Namespace))
+	case blocking > 0:
+		i18n.Printf("⚠️  The dry run passed, but %d constraint(s) above may still reject the pod\n", blocking)
+		return nil
+	}
+
+	i18n.Printf("✅ Namespace %s admits the pod\n", client.Namespace)
+	return nil
+}
+
+// plannedPod builds the pod a test with the optional tester in args would
+// create, from the pod flags and config file. image overrides the tester's
+// image.
+func plannedPod(client *k8s.Client, args []string, image string) (k8s.PodConfig, error) {
+	cfg, err := GetConfig()
+	if err != nil {
+		return k8s.PodConfig{}, err
+	}
+	podOpts.applyConfig(cfg)
+
+	name, testerImage := "check", resolveImage(cfg, "check", quotaCheckImage)
+	if len(args) == 1 {
+		t, ok := tester.Get(args[0])
+		if !ok {
+			return k8s.PodConfig{}, fmt.Errorf("unknown tester %q (available: %s)", args[0], strings.Join(tester.Names(), ", "))
+		}
+		name, testerImage = t.Name(), resolveImage(cfg, t.Name(), t.Image())
+	}
+	if image == "" {
+		image = testerImage
+	}
+
+	podConfig := k8s.PodConfig{
+		Name:      fmt.Sprintf("pocket-%s-%d", name, time.Now().Unix()),
+		Namespace: client.Namespace,
+		Image:     image,
+		Command:   []string{"sleep", "1"},
+	}
+	if err := podOpts.apply(&podConfig); err != nil {
+		return k8s.PodConfig{}, err
+	}
+	return podConfig, nil
+}
+
+func adjustedAny(violations []k8s.ConstraintViolation) bool {
+	for _, v := range violations {
+		if v.Adjusted != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceFlags renders the main container's resources as --cpu and
+// --memory flags, which set equal requests and limits
+func resourceFlags(res corev1.ResourceRequirements) string {
+	var flags []string
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		q, ok := res.Limits[name]
+		if !ok {
+			q, ok = res.Requests[name]
+		}
+		value := `""`
+		if ok {
+			value = q.String()
+		}
+		flags = append(flags, fmt.Sprintf("--%s %s", name, value))
+	}
+	return strings.Join(flags, " ")
+}
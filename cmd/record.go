@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// recordOptions are the session recording flags of interactive commands
+type recordOptions struct {
+	file  string
+	input bool
+}
+
+// addFlags registers the session recording flags on cmd
+func (o *recordOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.file, "record", "", "record the interactive session with timestamps to this file (asciicast v2)")
+	cmd.Flags().BoolVar(&o.input, "record-input", false, "also record what is typed, including passwords typed at prompts")
+}
+
+func (o *recordOptions) enabled() bool {
+	return o.file != ""
+}
+
+// validate rejects --record-input without --record
+func (o *recordOptions) validate() error {
+	if o.input && !o.enabled() {
+		return fmt.Errorf("--record-input needs --record")
+	}
+	return nil
+}
+
+// sessionRecorder writes a terminal session as an asciicast v2 file: a JSON
+// header line, then one [seconds, "o" or "i", data] line per chunk of output
+// or input, so it can be replayed with asciinema or read as is
+type sessionRecorder struct {
+	mu    sync.Mutex
+	file  *os.File
+	start time.Time
+	// pending holds the start of a UTF-8 sequence split across chunks, per
+	// event type
+	pending map[string][]byte
+	err     error
+}
+
+// castHeader is the first line of an asciicast v2 file
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// startRecording creates the recording file of o, readable by the user only
+// as sessions show data, and writes its header. title describes the session.
+func startRecording(o recordOptions, title string) (*sessionRecorder, error) {
+	file, err := os.OpenFile(o.file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+
+	width, height := 80, 24
+	if w, h, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 && h > 0 {
+		width, height = w, h
+	}
+	r := &sessionRecorder{file: file, start: time.Now(), pending: map[string][]byte{}}
+	header := castHeader{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: r.start.Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": os.Getenv("TERM"), "SHELL": os.Getenv("SHELL")},
+	}
+	data, err := json.Marshal(header)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to write recording: %w", err)
+	}
+	i18n.Printf("🎥 Recording the session to %s\n", o.file)
+	return r, nil
+}
+
+// event appends a chunk of output or input. A write error stops the
+// recording but not the session; Close reports it.
+func (r *sessionRecorder) event(kind string, data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	data = append(r.pending[kind], data...)
+	data, r.pending[kind] = splitUTF8(data)
+	if len(data) == 0 {
+		return
+	}
+	line, err := json.Marshal([]any{time.Since(r.start).Seconds(), kind, string(data)})
+	if err == nil {
+		_, err = r.file.Write(append(line, '\n'))
+	}
+	r.err = err
+}
+
+// splitUTF8 splits data before a UTF-8 sequence that is cut off at its end,
+// so that a character written in two chunks is recorded whole
+func splitUTF8(data []byte) (complete, rest []byte) {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				return data[:i], append([]byte(nil), data[i:]...)
+			}
+			break
+		}
+	}
+	return data, nil
+}
+
+// Close ends the recording and reports whether it was written completely
+func (r *sessionRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, kind := range []string{"o", "i"} {
+		if len(r.pending[kind]) > 0 && r.err == nil {
+			line, _ := json.Marshal([]any{time.Since(r.start).Seconds(), kind, string(r.pending[kind])})
+			_, r.err = r.file.Write(append(line, '\n'))
+		}
+	}
+	closeErr := r.file.Close()
+	if r.err != nil {
+		return fmt.Errorf("failed to write recording: %w", r.err)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to write recording: %w", closeErr)
+	}
+	return nil
+}
+
+// streams returns stdin, stdout and stderr recorded through r. Input is
+// only recorded with --record-input.
+func (r *sessionRecorder) streams(o recordOptions, stdin io.Reader, stdout, stderr io.Writer) (io.Reader, io.Writer, io.Writer) {
+	if o.input {
+		stdin = &recordedReader{r: stdin, rec: r}
+	}
+	return stdin, &recordedWriter{w: stdout, rec: r}, &recordedWriter{w: stderr, rec: r}
+}
+
+// recordedWriter records what is written to w as output
+type recordedWriter struct {
+	w   io.Writer
+	rec *sessionRecorder
+}
+
+func (w *recordedWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	if n > 0 {
+		w.rec.event("o", p[:n])
+	}
+	return n, err
+}
+
+// recordedReader records what is read from r as input
+type recordedReader struct {
+	r   io.Reader
+	rec *sessionRecorder
+}
+
+func (r *recordedReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.rec.event("i", p[:n])
+	}
+	return n, err
+}
+
+// recordSession runs session with the standard streams, recorded to the
+// file of o when it is set. title describes the session in the recording.
+func recordSession(o recordOptions, title string, session func(stdin io.Reader, stdout, stderr io.Writer) error) (err error) {
+	if !o.enabled() {
+		return session(ioStreams.In, ioStreams.Out, ioStreams.ErrOut)
+	}
+	rec, err := startRecording(o, title)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := rec.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+	return session(rec.streams(o, ioStreams.In, ioStreams.Out, ioStreams.ErrOut))
+}
+
+// sessionTitle describes what a recorded session is connected to
+func sessionTitle(client *k8s.Client, namespace, what string) string {
+	if client != nil && client.Config != nil {
+		return fmt.Sprintf("%s in namespace %s on %s", what, namespace, client.Config.Host)
+	}
+	return fmt.Sprintf("%s in namespace %s", what, namespace)
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Resource kinds accepted in <kind>/<name> references
+const (
+	kindPod        = "pod"
+	kindService    = "service"
+	kindDeployment = "deployment"
+)
+
+// resourceKindAliases maps accepted spellings to canonical kinds
+var resourceKindAliases = map[string]string{
+	"pod":         kindPod,
+	"pods":        kindPod,
+	"po":          kindPod,
+	"svc":         kindService,
+	"service":     kindService,
+	"services":    kindService,
+	"deploy":      kindDeployment,
+	"deployment":  kindDeployment,
+	"deployments": kindDeployment,
+}
+
+// parseResourceRef splits a kubectl-style "<kind>/<name>" reference. A bare
+// name is returned with defaultKind.
+func parseResourceRef(ref, defaultKind string) (kind, name string, err error) {
+	prefix, rest, found := strings.Cut(ref, "/")
+	if !found {
+		return defaultKind, ref, nil
+	}
+
+	kind, ok := resourceKindAliases[strings.ToLower(prefix)]
+	if !ok || rest == "" {
+		return "", "", fmt.Errorf("invalid resource %q (use pod/<name>, svc/<name> or deploy/<name>)", ref)
+	}
+	return kind, rest, nil
+}
+
+// resolvePods returns the pods behind a pod, service or deployment reference
+// in the client's namespace
+func resolvePods(ctx context.Context, client *k8s.Client, ref string) ([]corev1.Pod, error) {
+	kind, name, err := parseResourceRef(ref, kindPod)
+	if err != nil {
+		return nil, err
+	}
+
+	ns := client.Namespace
+	var selector string
+
+	switch kind {
+	case kindPod:
+		pod, err := client.Clientset.CoreV1().Pods(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return []corev1.Pod{*pod}, nil
+
+	case kindService:
+		svc, err := client.Clientset.CoreV1().Services(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if len(svc.Spec.Selector) == 0 {
+			return nil, fmt.Errorf("service %s has no selector", name)
+		}
+		selector = labels.SelectorFromSet(svc.Spec.Selector).String()
+
+	case kindDeployment:
+		deploy, err := client.Clientset.AppsV1().Deployments(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		s, err := metav1.LabelSelectorAsSelector(deploy.Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		selector = s.String()
+	}
+
+	pods, err := client.Clientset.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("no pods found for %s", ref)
+	}
+	return pods.Items, nil
+}
+
+// resolveDatabaseRef turns a database into a pod, service or deployment
+// reference for resolvePods: <kind>/<name> references are kept, a
+// port-forward alias names one of its services that exist, and
+// anything else is looked up among the discovered databases by service name,
+// then by type. Several databases of the type are chosen among with choose.
+func resolveDatabaseRef(ctx context.Context, client *k8s.Client, name string) (string, error) {
+	if strings.Contains(name, "/") {
+		return name, nil
+	}
+	if alias, err := resolvePFAlias(name); err == nil {
+		if svc, err := findAliasService(ctx, client, name, alias); err == nil {
+			return "svc/" + svc, nil
+		}
+	}
+
+	databases, err := client.DiscoverDatabases(ctx, client.Namespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to discover databases: %w", err)
+	}
+	for _, db := range databases {
+		if db.Service == name {
+			return "svc/" + db.Service, nil
+		}
+	}
+	var services []string
+	for _, db := range databases {
+		if db.Service != "" && db.Type == name {
+			services = append(services, db.Service)
+		}
+	}
+	if len(services) > 0 {
+		i, err := choose(i18n.T("%s services", name), services, i18n.T("pass svc/<name> to choose"))
+		if err != nil {
+			return "", err
+		}
+		return "svc/" + services[i], nil
+	}
+	return "", fmt.Errorf("no database %q found in namespace %s (use an alias, a discovered service or svc/, deploy/ or pod/<name>)", name, client.Namespace)
+}
+
+// workloadContainers returns the containers of a pod or of a deployment's
+// pod template in the client's namespace
+func workloadContainers(ctx context.Context, client *k8s.Client, ref string) ([]corev1.Container, error) {
+	kind, name, err := parseResourceRef(ref, kindDeployment)
+	if err != nil {
+		return nil, err
+	}
+
+	ns := client.Namespace
+	switch kind {
+	case kindPod:
+		pod, err := client.Clientset.CoreV1().Pods(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return pod.Spec.Containers, nil
+	case kindDeployment:
+		deploy, err := client.Clientset.AppsV1().Deployments(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return deploy.Spec.Template.Spec.Containers, nil
+	}
+	return nil, fmt.Errorf("%s has no containers of its own; use deploy/<name> or pod/<name>", ref)
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/enbiyagoral/kubectl-pocket/pkg/config"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
 	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
 	"github.com/spf13/cobra"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -21,34 +23,70 @@ var (
 
 	// K8s client (initialized lazily)
 	k8sClient *k8s.Client
+
+	// User configuration (loaded lazily)
+	pocketConfig *config.Config
 )
 
+// GetConfig returns the user configuration, loading it if needed
+func GetConfig() (*config.Config, error) {
+	if pocketConfig != nil {
+		return pocketConfig, nil
+	}
+
+	var err error
+	pocketConfig, err = config.Load(config.DefaultPath())
+	return pocketConfig, err
+}
+
 // GetK8sClient returns a Kubernetes client, creating one if needed
 func GetK8sClient() (*k8s.Client, error) {
 	if k8sClient != nil {
 		return k8sClient, nil
 	}
 
-	// Get kubeconfig path from configFlags
-	kubeconfig := ""
-	if configFlags != nil && configFlags.KubeConfig != nil {
-		kubeconfig = *configFlags.KubeConfig
+	namespace, err := namespaceOverride()
+	if err != nil {
+		return nil, err
+	}
+	k8sClient, err = k8s.NewClient(configFlags, namespace)
+	if err != nil {
+		return nil, err
 	}
+	setVerbosity(k8sClient)
+	setAudit(k8sClient)
+	setImagePolicy(k8sClient)
+	return k8sClient, nil
+}
 
-	// Get namespace from configFlags
-	namespace := ""
-	if configFlags != nil && configFlags.Namespace != nil {
-		namespace = *configFlags.Namespace
+// namespaceOverride returns the namespace given with -n, falling back to the
+// config file. Empty means the namespace of the kubeconfig context.
+func namespaceOverride() (string, error) {
+	if configFlags != nil && configFlags.Namespace != nil && *configFlags.Namespace != "" {
+		return *configFlags.Namespace, nil
 	}
 
-	var err error
-	k8sClient, err = k8s.NewClient(kubeconfig, namespace)
-	return k8sClient, err
+	cfg, err := GetConfig()
+	if err != nil {
+		return "", err
+	}
+	return cfg.Namespace, nil
+}
+
+// setLocale selects the output language from the environment and the
+// config file
+func setLocale() {
+	configured := ""
+	if cfg, err := GetConfig(); err == nil {
+		configured = cfg.Locale
+	}
+	i18n.SetLocale(i18n.Detect(configured))
 }
 
 // NewRootCmd creates the root command
 func NewRootCmd(streams genericiooptions.IOStreams) *cobra.Command {
 	configFlags = genericclioptions.NewConfigFlags(true)
+	setStreams(streams)
 
 	rootCmd := &cobra.Command{
 		Use:   "pocket",
@@ -57,19 +95,44 @@ func NewRootCmd(streams genericiooptions.IOStreams) *cobra.Command {
 
 It provides quick access to:
   - Database connection testing (MongoDB, PostgreSQL, Redis)
-  - Debug pods (busybox, netshoot)
-  - Port-forward shortcuts
+  - Debug pods (node shells)
+  - Port-forward shortcuts and a SOCKS5 proxy into the cluster
+
+Defaults such as namespace, timeouts, images and port-forward aliases can be
+set in ~/.config/kubectl-pocket/config.yaml (see "kubectl pocket config").
 
 Examples:
   kubectl pocket test mongo mongodb://mongo-svc:27017
   kubectl pocket test postgres postgres://pg-svc:5432/mydb
-  kubectl pocket debug netshoot
+  kubectl pocket debug node worker-1
   kubectl pocket port-forward redis 6379`,
 		Version: fmt.Sprintf("%s (commit: %s, built: %s)", Version, GitCommit, BuildDate),
 	}
 
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		setLocale()
+		return setupOutput(cmd)
+	}
+
 	// Add standard kubectl flags (--kubeconfig, --namespace, --context, --cluster, --user, etc.)
 	configFlags.AddFlags(rootCmd.PersistentFlags())
+	_ = rootCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
+
+	rootCmd.PersistentFlags().StringVar(&imageRegistry, "image-registry", "",
+		"registry mirror for built-in tool images (e.g. mirror.example.com)")
+	rootCmd.PersistentFlags().BoolVar(&verifyImages, "verify-images", false,
+		"refuse to run images not pinned by digest (see 'kubectl pocket images')")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "",
+		"output format: json or yaml (human-readable output then goes to stderr)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false,
+		"print only errors; the exit code reports the result (see README)")
+	addPlainFlags(rootCmd)
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v",
+		"log pod commands and state changes; -vv adds pod specs, -vvv every API request")
+	rootCmd.PersistentFlags().BoolVar(&showAPIUsage, "api-usage", false,
+		"print the Kubernetes API requests the command made, by verb and resource")
+	rootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false,
+		"never prompt: use the first of several matching services and fail where a confirmation is needed")
 
 	// Add subcommands
 	addSubcommands(rootCmd)
@@ -79,8 +142,38 @@ Examples:
 
 // addSubcommands adds all subcommands to the root command
 func addSubcommands(rootCmd *cobra.Command) {
+	addTesterCommands()
+	addQueryCommands()
+	addDumpCommands()
+	addRestoreCommands()
 	rootCmd.AddCommand(testCmd)
 	rootCmd.AddCommand(pfCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(watchboardCmd)
+	rootCmd.AddCommand(chaosCmd)
+	rootCmd.AddCommand(discoverCmd)
+	rootCmd.AddCommand(topCmd)
+	rootCmd.AddCommand(logsCmd)
+	rootCmd.AddCommand(quotaCmd)
+	rootCmd.AddCommand(policyCmd)
+	rootCmd.AddCommand(shareCmd)
+	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(benchCmd)
+	rootCmd.AddCommand(queryCmd)
+	rootCmd.AddCommand(dumpCmd)
+	rootCmd.AddCommand(restoreCmd)
+	rootCmd.AddCommand(proxyCmd)
+	rootCmd.AddCommand(tunnelCmd)
+	rootCmd.AddCommand(sniffCmd)
+	rootCmd.AddCommand(netCmd)
+	rootCmd.AddCommand(debugCmd)
+	rootCmd.AddCommand(attachCmd)
+	rootCmd.AddCommand(agentCmd)
+	rootCmd.AddCommand(cleanCmd)
+	rootCmd.AddCommand(psCmd)
+	rootCmd.AddCommand(imagesCmd)
+	rootCmd.AddCommand(canICmd)
+	rootCmd.AddCommand(upgradeCmd)
 }
 
 // Execute runs the root command
@@ -91,5 +184,19 @@ func Execute() error {
 		ErrOut: os.Stderr,
 	}
 	rootCmd := NewRootCmd(streams)
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	reportStartFailure(humanWriter(streams.ErrOut), err)
+	if metricsErr := exportTestMetrics(); metricsErr != nil {
+		// The test's own error decides the exit code
+		if err == nil {
+			err = metricsErr
+		} else {
+			_, _ = fmt.Fprint(humanWriter(streams.ErrOut), i18n.T("⚠️  Results were not exported: %v\n", metricsErr))
+		}
+	}
+	reportAPIUsage(humanWriter(streams.ErrOut))
+	if err == nil && versionRequested(rootCmd) {
+		notifyNewVersion(streams.ErrOut)
+	}
+	return err
 }
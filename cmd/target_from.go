@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+)
+
+// addFromFlag registers --from on cmd
+func (o *testOptions) addFromFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.from, "from", "",
+		"test the connection a workload is configured with, as deploy/<name> or pod/<name> with an optional :<container>, instead of a target")
+}
+
+// targetArgs requires the target argument unless --from replaces it
+func targetArgs(cmd *cobra.Command, args []string) error {
+	if from := cmd.Flags().Lookup("from"); from != nil && from.Changed {
+		if len(args) > 0 {
+			return fmt.Errorf("--from replaces the target argument; pass one or the other")
+		}
+		return nil
+	}
+	return cobra.ExactArgs(1)(cmd, args)
+}
+
+// targetArg returns the target argument, empty with --from
+func targetArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+// targetFromWorkload finds t's target in the environment of the --from
+// workload, trying its containers in order. display names the workload and
+// variables rather than the connection string, which may hold a password.
+func (o *testOptions) targetFromWorkload(client *k8s.Client, t tester.Tester) (target, display string, err error) {
+	targeter, ok := t.(tester.EnvTargeter)
+	if !ok {
+		return "", "", fmt.Errorf("--from is not supported by %s", t.Name())
+	}
+
+	ref, containerName, _ := strings.Cut(o.from, ":")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	containers, err := workloadContainers(ctx, client, ref)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read %s: %w", ref, err)
+	}
+
+	searched := 0
+	for _, container := range containers {
+		if containerName != "" && container.Name != containerName {
+			continue
+		}
+		searched++
+
+		env, err := client.ContainerEnv(ctx, client.Namespace, container)
+		if err != nil {
+			return "", "", err
+		}
+		if target, vars, ok := targeter.TargetFromEnv(env); ok {
+			return target, fmt.Sprintf("%s (container %s: %s)", ref, container.Name, strings.Join(vars, ", ")), nil
+		}
+	}
+
+	if searched == 0 {
+		return "", "", fmt.Errorf("%s has no container %q", ref, containerName)
+	}
+	return "", "", fmt.Errorf("no %s connection variables found in %s", t.DisplayName(), o.from)
+}
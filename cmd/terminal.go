@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"golang.org/x/term"
+)
+
+// makeRawTerminal switches stdin to raw mode and returns a restore function
+func makeRawTerminal() (func(), error) {
+	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return nil, err
+	}
+	restoreOutput := enableTerminalSequences()
+	return func() {
+		restoreOutput()
+		restoreTerminal(oldState)
+	}, nil
+}
+
+// restoreTerminal restores the terminal to its previous state
+func restoreTerminal(oldState *term.State) {
+	if err := term.Restore(int(os.Stdin.Fd()), oldState); err != nil {
+		// Terminal may already be restored, ignore error
+		_ = err
+	}
+}
+
+// terminalSizeQueue reports the size of the local terminal to a remote TTY
+// session: once at the start and again whenever it is resized
+type terminalSizeQueue struct {
+	ctx   context.Context
+	sizes chan k8s.TerminalSize
+}
+
+// newTerminalSizeQueue watches the size of stdout until ctx is done. It
+// returns nil when stdout is not a terminal.
+func newTerminalSizeQueue(ctx context.Context) k8s.TerminalSizeQueue {
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		return nil
+	}
+	q := &terminalSizeQueue{ctx: ctx, sizes: make(chan k8s.TerminalSize, 1)}
+	q.update()
+	go watchTerminalResize(ctx, q.update)
+	return q
+}
+
+// update queues the current size, replacing a size not read yet
+func (q *terminalSizeQueue) update() {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return
+	}
+	size := k8s.TerminalSize{Width: uint16(width), Height: uint16(height)}
+	select {
+	case <-q.sizes:
+	default:
+	}
+	select {
+	case q.sizes <- size:
+	default:
+	}
+}
+
+// Next blocks until the size changes and returns nil once ctx is done
+func (q *terminalSizeQueue) Next() *k8s.TerminalSize {
+	select {
+	case size := <-q.sizes:
+		return &size
+	case <-q.ctx.Done():
+		return nil
+	}
+}
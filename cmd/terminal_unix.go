@@ -0,0 +1,32 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// enableTerminalSequences is a no-op: Unix terminals interpret the escape
+// sequences remote programs write
+func enableTerminalSequences() (restore func()) {
+	return func() {}
+}
+
+// watchTerminalResize calls resized on every SIGWINCH until ctx is done
+func watchTerminalResize(ctx context.Context, resized func()) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGWINCH)
+	defer signal.Stop(sigCh)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigCh:
+			resized()
+		}
+	}
+}
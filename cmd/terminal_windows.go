@@ -0,0 +1,63 @@
+//go:build windows
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/term"
+)
+
+// resizePollInterval is how often the console size is checked; Windows has
+// no signal for console resizes
+const resizePollInterval = 250 * time.Millisecond
+
+// enableTerminalSequences lets the console interpret the escape sequences
+// remote programs and the picker write, such as colors and cursor movement,
+// on stdout and stderr, and returns a function that restores the previous
+// console modes
+func enableTerminalSequences() (restore func()) {
+	var restores []func()
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		handle := windows.Handle(f.Fd())
+		var mode uint32
+		if err := windows.GetConsoleMode(handle, &mode); err != nil {
+			continue
+		}
+		// Consoles before Windows 10 show the sequences as text
+		if err := windows.SetConsoleMode(handle, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING); err != nil {
+			continue
+		}
+		restores = append(restores, func() { _ = windows.SetConsoleMode(handle, mode) })
+	}
+	return func() {
+		for _, restore := range restores {
+			restore()
+		}
+	}
+}
+
+// watchTerminalResize polls the console size and calls resized whenever it
+// changes, until ctx is done
+func watchTerminalResize(ctx context.Context, resized func()) {
+	ticker := time.NewTicker(resizePollInterval)
+	defer ticker.Stop()
+
+	lastWidth, lastHeight, _ := term.GetSize(int(os.Stdout.Fd()))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			width, height, err := term.GetSize(int(os.Stdout.Fd()))
+			if err != nil || (width == lastWidth && height == lastHeight) {
+				continue
+			}
+			lastWidth, lastHeight = width, height
+			resized()
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/suite"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+)
+
+var testAllCmd = &cobra.Command{
+	Use:   "all",
+	Short: "Test every database discovered in the namespace",
+	Long: `Discover the databases in the namespace (see "kubectl pocket discover") and
+test all of them in parallel, then print a summary with the result and latency
+of each target. Exits non-zero if any test fails.
+
+One probe pod is started per database type and tests run through exec, so the
+latency is the client's own connect-and-query time, without pod startup.
+
+Discovered targets carry no credentials; databases that require
+authentication fail here and can be tested one by one with
+--password-from-secret.
+
+Examples:
+  kubectl pocket test all
+  kubectl pocket test all -n payments --parallel 10`,
+	Args: cobra.NoArgs,
+	RunE: runTestAll,
+}
+
+var (
+	testAllOpts     testOptions
+	testAllParallel int
+)
+
+func init() {
+	testCmd.AddCommand(testAllCmd)
+	markStructuredOutput(testAllCmd)
+	addMetricsOutFlags(testAllCmd)
+	testAllOpts.cmd = testAllCmd
+	testAllOpts.probes = true
+	testAllCmd.Flags().DurationVar(&testAllOpts.timeout, "timeout", 30*time.Second, "timeout of each connection test")
+	testAllCmd.Flags().IntVar(&testAllParallel, "parallel", 5, "maximum number of tests running at once")
+	addPodFlags(testAllCmd)
+}
+
+func runTestAll(cmd *cobra.Command, args []string) error {
+	if testAllParallel < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+
+	runner, err := newRunner("", &testAllOpts)
+	if err != nil {
+		return err
+	}
+
+	// Ctrl+C stops the run; the probe pods are deleted before it returns
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	i18n.Printf("🔍 Discovering databases in %s\n", i18n.T("namespace %s", runner.Namespace))
+	discoverCtx, discoverCancel := context.WithTimeout(ctx, 30*time.Second)
+	databases, err := runnerClient(runner).DiscoverDatabases(discoverCtx, runner.Namespace)
+	discoverCancel()
+	if err != nil {
+		return err
+	}
+
+	checks := make([]*suiteCheck, len(databases))
+	tested := 0
+	for i, db := range databases {
+		target := discoveredTarget(db)
+		checks[i] = &suiteCheck{
+			name:      db.Service,
+			typeName:  db.Type,
+			namespace: db.Namespace,
+			target:    target,
+			display:   target,
+			expect:    suite.ExpectPass,
+			timeout:   runner.Timeout,
+		}
+		t, ok := tester.Get(db.Type)
+		switch {
+		case target == "":
+			checks[i].skipped, checks[i].detail = true, i18n.T("skipped: no service")
+		case !ok:
+			checks[i].err = errors.New(i18n.T("no tester for %s", db.Type))
+		default:
+			checks[i].tester = t
+			tested++
+		}
+	}
+	if tested == 0 {
+		i18n.Printf("💡 No databases found\n")
+		if structured() {
+			return printChecksReport(checks, false)
+		}
+		return nil
+	}
+
+	runSuiteChecks(ctx, runner, checks, testAllParallel)
+	if ctx.Err() != nil {
+		return errInterrupted()
+	}
+
+	failed := 0
+	for _, c := range checks {
+		if status, _ := c.verdict(); status == checkUnhealthy {
+			failed++
+		}
+	}
+	if structured() {
+		if err := printChecksReport(checks, false); err != nil {
+			return err
+		}
+	} else {
+		w := newTable()
+		_, _ = fmt.Fprintln(w, "\nSTATUS\tTYPE\tSERVICE\tTARGET\tLATENCY\tDETAIL")
+		for i, c := range checks {
+			status, detail := c.verdict()
+			latency := "-"
+			if status == checkHealthy {
+				latency = c.latency.Round(time.Millisecond).String()
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				status.icon(), databases[i].Type, valueOr(c.name, "-"), valueOr(c.display, "-"), latency, valueOr(detail, "-"))
+		}
+		_ = w.Flush()
+		printf("\n")
+	}
+
+	if failed > 0 {
+		i18n.Printf("❌ %d of %d database(s) failed\n", failed, tested)
+		return checkFailed(fmt.Errorf("%d of %d database tests failed", failed, tested))
+	}
+	i18n.Printf("✅ All %d database(s) reachable\n", tested)
+	return nil
+}
+
+// firstLine returns the first non-empty line of s
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var certManagerCmd = &cobra.Command{
+	Use:     "cert-manager <issuer>",
+	Aliases: []string{"certmanager"},
+	Short:   "Test cert-manager certificate issuance",
+	Long: `Test cert-manager certificate issuance end-to-end.
+
+Creates a temporary Certificate against the given Issuer (or ClusterIssuer),
+waits for it to become Ready, validates the issued chain and key, and cleans up
+the Certificate and its Secret.
+
+Examples:
+  kubectl pocket test cert-manager selfsigned
+  kubectl pocket test cert-manager internal-ca --cluster-issuer
+  kubectl pocket test cert-manager letsencrypt --cluster-issuer --dns-name smoke.example.com --timeout 5m`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCertManagerTest,
+}
+
+var (
+	certManagerTimeout       time.Duration
+	certManagerClusterIssuer bool
+	certManagerDNSName       string
+)
+
+func init() {
+	testCmd.AddCommand(certManagerCmd)
+	certManagerCmd.Flags().DurationVar(&certManagerTimeout, "timeout", 2*time.Minute, "issuance timeout")
+	certManagerCmd.Flags().BoolVar(&certManagerClusterIssuer, "cluster-issuer", false, "issuer is a ClusterIssuer")
+	certManagerCmd.Flags().StringVar(&certManagerDNSName, "dns-name", "", "DNS name to request (default: pocket-smoke.<namespace>.svc.cluster.local)")
+	markStructuredOutput(certManagerCmd)
+}
+
+// certificateReport is the structured result of test cert-manager
+type certificateReport struct {
+	Issuer     string     `json:"issuer"`
+	IssuerKind string     `json:"issuerKind"`
+	Namespace  string     `json:"namespace"`
+	DNSName    string     `json:"dnsName"`
+	Status     string     `json:"status"`
+	Subject    string     `json:"subject,omitempty"`
+	IssuedBy   string     `json:"issuedBy,omitempty"`
+	NotAfter   *time.Time `json:"notAfter,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// printCertificateReport prints report for -o
+func printCertificateReport(report certificateReport) error {
+	if !structured() {
+		return nil
+	}
+	return printStructured(report)
+}
+
+func runCertManagerTest(cmd *cobra.Command, args []string) error {
+	issuer := args[0]
+
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+
+	certName := fmt.Sprintf("pocket-cert-%d", time.Now().Unix())
+	ns := client.Namespace
+
+	issuerKind := "Issuer"
+	if certManagerClusterIssuer {
+		issuerKind = "ClusterIssuer"
+	}
+
+	dnsName := certManagerDNSName
+	if dnsName == "" {
+		dnsName = fmt.Sprintf("pocket-smoke.%s.svc.cluster.local", ns)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), certManagerTimeout+30*time.Second)
+	defer cancel()
+
+	printf("🔍 Testing certificate issuance: %s/%s\n", issuerKind, issuer)
+	printf("📜 Creating certificate: %s/%s (%s)\n", ns, certName, dnsName)
+
+	certConfig := k8s.CertificateConfig{
+		Name:       certName,
+		Namespace:  ns,
+		SecretName: certName,
+		DNSNames:   []string{dnsName},
+		IssuerName: issuer,
+		IssuerKind: issuerKind,
+	}
+
+	if err := client.CreateCertificate(ctx, certConfig); err != nil {
+		return fmt.Errorf("failed to create certificate (is cert-manager installed?): %w", err)
+	}
+
+	defer func() {
+		printf("🧹 Cleaning up certificate: %s\n", certName)
+		cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cleanupCancel()
+		_ = client.DeleteCertificate(cleanupCtx, ns, certName)
+		_ = client.Clientset.CoreV1().Secrets(ns).Delete(cleanupCtx, certName, metav1.DeleteOptions{})
+	}()
+
+	report := certificateReport{Issuer: issuer, IssuerKind: issuerKind, Namespace: ns, DNSName: dnsName, Status: statusFailed}
+
+	printf("⏳ Waiting for certificate to become Ready...\n")
+	status, err := client.WaitForCertificateReady(ctx, ns, certName, certManagerTimeout)
+	if err != nil {
+		report.Error = err.Error()
+		if status != nil && status.Message != "" {
+			report.Error = status.Reason + ": " + status.Message
+		}
+		if printErr := printCertificateReport(report); printErr != nil {
+			return printErr
+		}
+		printf("❌ Certificate was not issued!\n")
+		if status != nil && status.Message != "" {
+			printf("📝 %s: %s\n", status.Reason, status.Message)
+		}
+		return fmt.Errorf("timeout waiting for certificate: %w", err)
+	}
+
+	secret, err := client.Clientset.CoreV1().Secrets(ns).Get(ctx, certName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get certificate secret: %w", err)
+	}
+
+	leaf, err := verifyIssuedChain(secret.Data["tls.crt"], secret.Data["tls.key"], secret.Data["ca.crt"], dnsName)
+	if err != nil {
+		report.Error = err.Error()
+		if err := printCertificateReport(report); err != nil {
+			return err
+		}
+		printf("❌ Issued certificate is invalid!\n")
+		printf("📝 %v\n", err)
+		return checkFailed(fmt.Errorf("certificate validation failed"))
+	}
+
+	report.Status, report.Subject, report.IssuedBy, report.NotAfter = statusPassed, leaf.Subject.String(), leaf.Issuer.String(), &leaf.NotAfter
+	if err := printCertificateReport(report); err != nil {
+		return err
+	}
+	printf("✅ Certificate issued and valid!\n")
+	printf("📝 Subject: %s\n", leaf.Subject)
+	printf("📝 Issuer:  %s\n", leaf.Issuer)
+	printf("📝 Expires: %s\n", leaf.NotAfter.Format(time.RFC3339))
+	return nil
+}
+
+// verifyIssuedChain checks that the issued key pair matches and that the leaf
+// certificate chains to the issuing CA (or the system roots when no CA is
+// published) and is valid for dnsName. It returns the parsed leaf certificate.
+func verifyIssuedChain(certPEM, keyPEM, caPEM []byte, dnsName string) (*x509.Certificate, error) {
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		return nil, fmt.Errorf("tls.crt and tls.key do not form a valid key pair: %w", err)
+	}
+
+	chain, err := parseCertificates(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tls.crt: %w", err)
+	}
+	leaf := chain[0]
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	var roots *x509.CertPool
+	if len(caPEM) > 0 {
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("failed to parse ca.crt")
+		}
+	}
+
+	_, err = leaf.Verify(x509.VerifyOptions{
+		DNSName:       dnsName,
+		Intermediates: intermediates,
+		Roots:         roots,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("chain verification failed: %w", err)
+	}
+
+	return leaf, nil
+}
+
+// parseCertificates decodes all CERTIFICATE blocks in a PEM bundle
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found")
+	}
+	return certs, nil
+}
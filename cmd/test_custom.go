@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+)
+
+var customCmd = &cobra.Command{
+	Use:   "custom --image <image> (--command <cmd> | -- <args...>)",
+	Short: "Run a custom connection test",
+	Long: `Run a custom connection test from within the cluster.
+
+Creates a temporary pod from any image, runs the given command, and cleans up.
+The test succeeds when the command exits with status 0 and, if --success-regex
+is set, its output matches the expression.
+
+The command is run with /bin/sh -c. Arguments after -- are used as the exact
+container command instead, for images without a shell.
+
+Examples:
+  kubectl pocket test custom --image busybox --command "nc -z -w 5 kafka 9092"
+  kubectl pocket test custom --image curlimages/curl --command "curl -s http://api:8080/health" --success-regex '"status":"ok"'
+  kubectl pocket test custom --image my-registry/probe:1.0 -- /probe --target billing:7000`,
+	RunE: runCustomTest,
+}
+
+var (
+	customOpts         testOptions
+	customCommand      string
+	customSuccessRegex string
+)
+
+func init() {
+	testCmd.AddCommand(customCmd)
+	customOpts.addFlags(customCmd, "")
+	customOpts.addKeepFlag(customCmd)
+	customOpts.addContextsFlags(customCmd)
+	customOpts.addCacheFlags(customCmd)
+	customOpts.addWatchFlags(customCmd)
+	customOpts.addLatencyFlags(customCmd)
+	markStructuredOutput(customCmd)
+	addMetricsOutFlags(customCmd)
+	customCmd.Flags().Lookup("image").Usage = "container image to run"
+	customCmd.Flags().StringVar(&customCommand, "command", "", "shell command to run in the container")
+	customCmd.Flags().StringVar(&customSuccessRegex, "success-regex", "", "regular expression the output must match to succeed")
+	_ = customCmd.MarkFlagRequired("image")
+}
+
+func runCustomTest(cmd *cobra.Command, args []string) error {
+	command, err := customContainerCommand(customCommand, args)
+	if err != nil {
+		return err
+	}
+
+	custom := tester.Custom{
+		ImageName: customOpts.image,
+		Command:   command,
+	}
+	if customSuccessRegex != "" {
+		custom.SuccessRegex, err = regexp.Compile(customSuccessRegex)
+		if err != nil {
+			return fmt.Errorf("invalid --success-regex: %w", err)
+		}
+	}
+
+	return runTester(custom, strings.Join(command, " "), &customOpts)
+}
+
+// customContainerCommand builds the container command from --command or the
+// arguments given after --
+func customContainerCommand(shellCmd string, args []string) ([]string, error) {
+	switch {
+	case shellCmd != "" && len(args) > 0:
+		return nil, fmt.Errorf("use either --command or arguments after --, not both")
+	case shellCmd != "":
+		return []string{"/bin/sh", "-c", shellCmd}, nil
+	case len(args) > 0:
+		return args, nil
+	default:
+		return nil, fmt.Errorf("a command is required: set --command or pass arguments after --")
+	}
+}
@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+)
+
+var externalServiceCmd = &cobra.Command{
+	Use:     "external-service [svc/name]",
+	Aliases: []string{"external-services", "externalname"},
+	Short:   "Test ExternalName services and manually managed endpoints",
+	Long: `Test services whose backends Kubernetes does not manage.
+
+ExternalName services are resolved through cluster DNS from a temporary pod,
+and the external name is connected to on every service port. Services without
+a selector have each manually defined endpoint (Endpoints or EndpointSlices)
+connected to over TCP.
+
+Without an argument every such service in the namespace is checked.
+
+Examples:
+  kubectl pocket test external-service
+  kubectl pocket test external-service svc/legacy-db -n billing`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runExternalServiceTest,
+}
+
+var externalServiceOpts = testOptions{timeout: time.Minute}
+
+// externalServiceProbeImage provides dig and nc
+const externalServiceProbeImage = "nicolaka/netshoot:v0.13"
+
+func init() {
+	testCmd.AddCommand(externalServiceCmd)
+	externalServiceOpts.addFlags(externalServiceCmd, "")
+	markStructuredOutput(externalServiceCmd)
+}
+
+// externalServiceReport is the structured result of one service
+type externalServiceReport struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Type is "ExternalName" or "Endpoints"
+	Type    string   `json:"type"`
+	Target  string   `json:"target"`
+	Status  string   `json:"status"`
+	Details []string `json:"details,omitempty"`
+}
+
+// externalServiceProbe is the in-cluster probe result for one service
+type externalServiceProbe struct {
+	// dns holds the answers for the service's cluster DNS name
+	dns []string
+	// reachable is keyed by port index (ExternalName) or endpoint index
+	reachable map[int]bool
+}
+
+func runExternalServiceTest(cmd *cobra.Command, args []string) error {
+	runner, err := newRunner("external-service", &externalServiceOpts)
+	if err != nil {
+		return err
+	}
+	client := runnerClient(runner)
+
+	name := ""
+	if len(args) == 1 {
+		kind, n, err := parseResourceRef(args[0], kindService)
+		if err != nil {
+			return err
+		}
+		if kind != kindService {
+			return fmt.Errorf("%s is not a service", args[0])
+		}
+		name = n
+	}
+
+	ctx := context.Background()
+
+	printf("🔍 Listing external services in %s\n", client.Namespace)
+	services, err := client.ListExternalServices(ctx, client.Namespace, name)
+	if err != nil {
+		return err
+	}
+	if len(services) == 0 {
+		printf("✅ No ExternalName or selector-less services in %s\n", client.Namespace)
+		if structured() {
+			return printStructured([]externalServiceReport{})
+		}
+		return nil
+	}
+
+	probe := tester.Custom{
+		TesterName: "external-service",
+		ImageName:  externalServiceProbeImage,
+		Command:    []string{"/bin/sh", "-c", externalServiceProbeScript(client.Namespace, services)},
+	}
+
+	runner.Progress = func(step tester.Step, ns, podName string) {
+		switch step {
+		case tester.StepCreatePod:
+			printf("📦 Creating probe pod: %s/%s\n", ns, podName)
+		case tester.StepWaitCompletion:
+			printf("⏳ Probing %d service(s)...\n", len(services))
+		case tester.StepCleanup:
+			printf("🧹 Cleaning up pod: %s\n", podName)
+		}
+	}
+
+	result, err := runner.Run(ctx, probe, "")
+	if err != nil {
+		return err
+	}
+
+	probes := parseExternalServiceProbes(result.Output)
+
+	unhealthy := 0
+	verdicts := make([]checkStatus, len(services))
+	report := make([]externalServiceReport, len(services))
+	for i, svc := range services {
+		verdict, details := evaluateExternalService(svc, probes[i])
+		if verdict == checkUnhealthy {
+			unhealthy++
+		}
+
+		kind, target := "Endpoints", fmt.Sprintf("%d endpoint(s)", len(svc.Endpoints))
+		if svc.ExternalName != "" {
+			kind, target = "ExternalName", svc.ExternalName
+		}
+		verdicts[i] = verdict
+		report[i] = externalServiceReport{
+			Namespace: client.Namespace, Name: svc.Name, Type: kind, Target: target, Status: verdict.String(), Details: details,
+		}
+	}
+
+	if structured() {
+		if err := printStructured(report); err != nil {
+			return err
+		}
+	} else {
+		w := newTable()
+		_, _ = fmt.Fprintln(w, "\nSTATUS\tTYPE\tSERVICE\tTARGET\tDETAILS")
+		for i, svc := range report {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", verdicts[i].icon(), svc.Type, svc.Name, svc.Target, strings.Join(svc.Details, "; "))
+		}
+		_ = w.Flush()
+		printf("\n")
+	}
+
+	if unhealthy > 0 {
+		printf("❌ %d of %d service(s) broken\n", unhealthy, len(services))
+		return checkFailed(fmt.Errorf("%d external service(s) broken", unhealthy))
+	}
+
+	printf("✅ All %d service(s) resolve and connect\n", len(services))
+	return nil
+}
+
+// externalServiceProbeScript builds a shell script that prints
+// "POCKET|dns|<service>|<answers>" and "POCKET|tcp|<service>|<index>|<ok|fail>"
+// lines for every service
+func externalServiceProbeScript(namespace string, services []k8s.ExternalService) string {
+	var b strings.Builder
+	b.WriteString(`dns() {
+  echo "POCKET|dns|$1|$( (dig +short +search "$2" A; dig +short +search "$2" AAAA) | tr '\n' ',')"
+}
+tcp() {
+  if nc -z -w 3 "$3" "$4" >/dev/null 2>&1; then r=ok; else r=fail; fi
+  echo "POCKET|tcp|$1|$2|$r"
+}
+`)
+	for i, svc := range services {
+		if svc.ExternalName != "" {
+			fmt.Fprintf(&b, "dns %d %s\n", i, tester.ShellQuote(svc.Name+"."+namespace+".svc"))
+			for j, port := range svc.Ports {
+				fmt.Fprintf(&b, "tcp %d %d %s %d\n", i, j, tester.ShellQuote(svc.ExternalName), port)
+			}
+			continue
+		}
+		for j, endpoint := range svc.Endpoints {
+			fmt.Fprintf(&b, "tcp %d %d %s %d\n", i, j, tester.ShellQuote(endpoint.Address), endpoint.Port)
+		}
+	}
+	return b.String()
+}
+
+// parseExternalServiceProbes parses the probe script output, keyed by
+// service index
+func parseExternalServiceProbes(output string) map[int]*externalServiceProbe {
+	probes := map[int]*externalServiceProbe{}
+	get := func(index int) *externalServiceProbe {
+		if probes[index] == nil {
+			probes[index] = &externalServiceProbe{reachable: map[int]bool{}}
+		}
+		return probes[index]
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "|")
+		if len(parts) < 4 || parts[0] != "POCKET" {
+			continue
+		}
+		index, err := strconv.Atoi(parts[2])
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case parts[1] == "dns" && len(parts) == 4:
+			for _, answer := range strings.Split(parts[3], ",") {
+				if answer = strings.TrimSuffix(strings.TrimSpace(answer), "."); answer != "" {
+					get(index).dns = append(get(index).dns, answer)
+				}
+			}
+		case parts[1] == "tcp" && len(parts) == 5:
+			if j, err := strconv.Atoi(parts[3]); err == nil {
+				get(index).reachable[j] = parts[4] == "ok"
+			}
+		}
+	}
+	return probes
+}
+
+// evaluateExternalService judges a probe result. An ExternalName service is
+// broken when its name does not resolve to an address or a port refuses
+// connections. A selector-less service is broken without endpoints or when
+// no endpoint accepts connections, and degraded when only some do.
+func evaluateExternalService(svc k8s.ExternalService, probe *externalServiceProbe) (checkStatus, []string) {
+	if probe == nil {
+		probe = &externalServiceProbe{reachable: map[int]bool{}}
+	}
+
+	if svc.ExternalName != "" {
+		var details []string
+		status := checkHealthy
+		if net.ParseIP(svc.ExternalName) != nil {
+			status = checkWarning
+			details = append(details, "externalName is an IP; use a selector-less service with endpoints")
+		}
+
+		var addresses []string
+		for _, answer := range probe.dns {
+			if net.ParseIP(answer) != nil {
+				addresses = append(addresses, answer)
+			}
+		}
+		if len(addresses) == 0 {
+			return checkUnhealthy, append(details, "does not resolve from the cluster")
+		}
+		details = append(details, "resolves to "+strings.Join(addresses, ", "))
+
+		if len(svc.Ports) == 0 {
+			return status, append(details, "no ports to connect to")
+		}
+		for j, port := range svc.Ports {
+			if !probe.reachable[j] {
+				status = checkUnhealthy
+				details = append(details, fmt.Sprintf("port %d unreachable", port))
+			}
+		}
+		return status, details
+	}
+
+	if len(svc.Endpoints) == 0 {
+		return checkUnhealthy, []string{"no endpoints; traffic is dropped"}
+	}
+
+	var down []string
+	notReady := 0
+	for j, endpoint := range svc.Endpoints {
+		if !probe.reachable[j] {
+			down = append(down, net.JoinHostPort(endpoint.Address, strconv.Itoa(int(endpoint.Port))))
+		}
+		if !endpoint.Ready {
+			notReady++
+		}
+	}
+
+	var details []string
+	status := checkHealthy
+	switch {
+	case len(down) == len(svc.Endpoints):
+		status = checkUnhealthy
+		details = append(details, "no endpoint accepts connections")
+	case len(down) > 0:
+		status = checkWarning
+		details = append(details, fmt.Sprintf("%d/%d endpoint(s) down: %s",
+			len(down), len(svc.Endpoints), strings.Join(down, ", ")))
+	default:
+		details = append(details, fmt.Sprintf("%d endpoint(s) reachable", len(svc.Endpoints)))
+	}
+	if notReady > 0 {
+		details = append(details, fmt.Sprintf("%d marked not ready", notReady))
+	}
+	return status, details
+}
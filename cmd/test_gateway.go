@@ -0,0 +1,392 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+)
+
+var gatewayCmd = &cobra.Command{
+	Use:   "gateway <httproute/name|tcproute/name>",
+	Short: "Test a Gateway API route hop by hop",
+	Long: `Test traffic through a Gateway API HTTPRoute or TCPRoute and pinpoint where
+it breaks.
+
+The route's status, parent gateways, listeners and backends are resolved, then
+a temporary pod tests each hop from inside the cluster:
+
+  route              accepted by its gateways, references resolved
+  client → gateway   each matching listener on the gateway's address, sent the
+                     route's hostname and path
+  gateway → backend  each backend Service the route forwards to
+
+The summary names the failing layer: the route configuration, the gateway or
+the backend. A bare name is taken as an HTTPRoute.
+
+Examples:
+  kubectl pocket test gateway httproute/storefront
+  kubectl pocket test gateway tcproute/postgres -n db`,
+	Args: cobra.ExactArgs(1),
+	RunE: runGatewayTest,
+}
+
+var gatewayOpts = testOptions{timeout: time.Minute}
+
+func init() {
+	testCmd.AddCommand(gatewayCmd)
+	gatewayOpts.addFlags(gatewayCmd, "")
+	markStructuredOutput(gatewayCmd)
+}
+
+// Layers a gateway hop can fail in
+const (
+	gatewayLayerRoute   = "route configuration"
+	gatewayLayerGateway = "gateway"
+	gatewayLayerBackend = "backend"
+)
+
+// gatewayHop is one row of the gateway report
+type gatewayHop struct {
+	hop    string
+	target string
+	layer  string
+	status checkStatus
+	detail string
+	// probe is the probe script function testing the hop (http or tcp),
+	// called with the hop's index and args
+	probe string
+	args  []string
+}
+
+// gatewayReport is the structured result of a gateway test
+type gatewayReport struct {
+	Kind      string             `json:"kind"`
+	Namespace string             `json:"namespace"`
+	Name      string             `json:"name"`
+	Hops      []gatewayHopReport `json:"hops"`
+	// Broken lists the layers traffic breaks in
+	Broken []string `json:"broken,omitempty"`
+}
+
+// gatewayHopReport is the structured result of one hop
+type gatewayHopReport struct {
+	Hop    string `json:"hop"`
+	Target string `json:"target"`
+	Layer  string `json:"layer"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// parseRouteRef splits an "httproute/<name>" or "tcproute/<name>" argument
+func parseRouteRef(ref string) (kind, name string, err error) {
+	prefix, rest, found := strings.Cut(ref, "/")
+	if !found {
+		return k8s.KindHTTPRoute, ref, nil
+	}
+	switch strings.ToLower(prefix) {
+	case "httproute", "httproutes":
+		kind = k8s.KindHTTPRoute
+	case "tcproute", "tcproutes":
+		kind = k8s.KindTCPRoute
+	}
+	if kind == "" || rest == "" {
+		return "", "", fmt.Errorf("invalid route %q (use httproute/<name> or tcproute/<name>)", ref)
+	}
+	return kind, rest, nil
+}
+
+func runGatewayTest(cmd *cobra.Command, args []string) error {
+	kind, name, err := parseRouteRef(args[0])
+	if err != nil {
+		return err
+	}
+
+	runner, err := newRunner("gateway", &gatewayOpts)
+	if err != nil {
+		return err
+	}
+	client := runnerClient(runner)
+	ctx := context.Background()
+
+	i18n.Printf("🔍 Resolving %s %s/%s\n", kind, client.Namespace, name)
+	route, err := client.GetGatewayRoute(ctx, client.Namespace, kind, name)
+	if err != nil {
+		return err
+	}
+
+	hops := gatewayHops(route)
+
+	var script strings.Builder
+	script.WriteString(`http() {
+  echo "POCKET|$1|$(curl -sk -o /dev/null -m 5 -w '%{http_code}' --connect-to "$3" "$2")"
+}
+tcp() {
+  if nc -z -w 3 "$2" "$3" >/dev/null 2>&1; then r=ok; else r=fail; fi
+  echo "POCKET|$1|$r"
+}
+`)
+	probes := 0
+	for i, hop := range hops {
+		if hop.probe == "" {
+			continue
+		}
+		fmt.Fprintf(&script, "%s %d", hop.probe, i)
+		for _, arg := range hop.args {
+			script.WriteString(" " + tester.ShellQuote(arg))
+		}
+		script.WriteString("\n")
+		probes++
+	}
+
+	results := map[int]string{}
+	if probes > 0 {
+		probe := tester.Custom{
+			TesterName: "gateway",
+			ImageName:  externalServiceProbeImage,
+			Command:    []string{"/bin/sh", "-c", script.String()},
+		}
+		runner.Progress = func(step tester.Step, ns, podName string) {
+			switch step {
+			case tester.StepCreatePod:
+				i18n.Printf("📦 Creating probe pod: %s/%s\n", ns, podName)
+			case tester.StepWaitCompletion:
+				i18n.Printf("⏳ Probing %d hop(s)...\n", probes)
+			case tester.StepCleanup:
+				i18n.Printf("🧹 Cleaning up pod: %s\n", podName)
+			}
+		}
+		result, err := runner.Run(ctx, probe, "")
+		if err != nil {
+			return err
+		}
+		results = parseGatewayProbes(result.Output)
+	}
+
+	evaluateGatewayHops(hops, results)
+
+	var broken []string
+	for _, hop := range hops {
+		if hop.status == checkUnhealthy && !slices.Contains(broken, hop.layer) {
+			broken = append(broken, hop.layer)
+		}
+	}
+
+	if structured() {
+		report := gatewayReport{Kind: kind, Namespace: route.Namespace, Name: name, Broken: broken}
+		for _, hop := range hops {
+			report.Hops = append(report.Hops, gatewayHopReport{
+				Hop: hop.hop, Target: hop.target, Layer: hop.layer, Status: hop.status.String(), Detail: hop.detail,
+			})
+		}
+		if err := printStructured(report); err != nil {
+			return err
+		}
+	} else {
+		w := newTable()
+		_, _ = fmt.Fprintln(w, "\nSTATUS\tHOP\tTARGET\tDETAILS")
+		for _, hop := range hops {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", hop.status.icon(), hop.hop, hop.target, hop.detail)
+		}
+		_ = w.Flush()
+		printf("\n")
+	}
+
+	if len(broken) > 0 {
+		layers := make([]string, len(broken))
+		for i, layer := range broken {
+			layers[i] = i18n.T(layer)
+		}
+		i18n.Printf("❌ Traffic breaks in the %s\n", strings.Join(layers, ", "))
+		return checkFailed(fmt.Errorf("%s %s is broken", kind, name))
+	}
+	i18n.Printf("✅ Traffic flows through %s %s\n", kind, name)
+	return nil
+}
+
+// gatewayHops lists the hops of a route, with the configuration problems
+// found without probing already judged
+func gatewayHops(route *k8s.GatewayRoute) []*gatewayHop {
+	var hops []*gatewayHop
+	routeName := fmt.Sprintf("%s/%s", strings.ToLower(route.Kind), route.Name)
+
+	if len(route.Parents) == 0 {
+		hops = append(hops, &gatewayHop{hop: "route", target: routeName, layer: gatewayLayerRoute,
+			status: checkUnhealthy, detail: "no parentRefs to a Gateway"})
+	}
+
+	for _, parent := range route.Parents {
+		gwName := parent.Namespace + "/" + parent.Name
+		hop := &gatewayHop{hop: "route", target: routeName + " → " + gwName, layer: gatewayLayerRoute, status: checkHealthy}
+		hops = append(hops, hop)
+		switch {
+		case parent.Gateway == nil:
+			hop.status, hop.detail = checkUnhealthy, fmt.Sprintf("gateway %s does not exist", gwName)
+			continue
+		case !parent.Reported:
+			hop.layer = gatewayLayerGateway
+			hop.status = checkWarning
+			hop.detail = fmt.Sprintf("no status from the controller of GatewayClass %s; is it running?", parent.Gateway.ClassName)
+		case !parent.Accepted:
+			hop.status, hop.detail = checkUnhealthy, "not accepted: "+parent.Message
+		case !parent.ResolvedRefs:
+			hop.status, hop.detail = checkUnhealthy, "references not resolved (missing ReferenceGrant?): "+parent.Message
+		default:
+			hop.detail = "accepted"
+		}
+
+		gw := parent.Gateway
+		gwHop := &gatewayHop{hop: "client → gateway", target: gwName, layer: gatewayLayerGateway, status: checkUnhealthy}
+		listeners := parent.AttachedListeners(route.Kind)
+		switch {
+		case !gw.Programmed && gw.Message != "":
+			gwHop.detail = "gateway not programmed: " + gw.Message
+		case len(gw.Addresses) == 0:
+			gwHop.detail = "gateway has no address"
+		case len(listeners) == 0:
+			gwHop.layer = gatewayLayerRoute
+			gwHop.detail = fmt.Sprintf("no %s listener matches the parentRef's sectionName or port", listenerProtocols(route.Kind))
+		}
+		if gwHop.detail != "" {
+			hops = append(hops, gwHop)
+			continue
+		}
+
+		address := gw.Addresses[0]
+		for _, l := range listeners {
+			hops = append(hops, listenerHop(route, gwName, address, l))
+		}
+	}
+
+	for _, backend := range route.Backends {
+		hop := &gatewayHop{
+			hop:    "gateway → backend",
+			target: fmt.Sprintf("%s/%s:%d", backend.Namespace, backend.Name, backend.Port),
+			layer:  gatewayLayerBackend,
+			status: checkUnhealthy,
+		}
+		hops = append(hops, hop)
+		switch {
+		case backend.Kind != "Service":
+			hop.status, hop.detail = checkWarning, fmt.Sprintf("%s backends are not tested", backend.Kind)
+		case !backend.Found:
+			hop.detail = "service does not exist"
+		case backend.ReadyEndpoints == 0:
+			hop.detail = fmt.Sprintf("no ready endpoints (%d not ready)", backend.Endpoints)
+		default:
+			host := backend.Name + "." + backend.Namespace + ".svc"
+			hop.probe, hop.args = "tcp", []string{host, strconv.Itoa(int(backend.Port))}
+		}
+	}
+	return hops
+}
+
+// listenerHop builds the client → gateway probe of one listener
+func listenerHop(route *k8s.GatewayRoute, gwName, address string, l k8s.GatewayListener) *gatewayHop {
+	port := strconv.Itoa(int(l.Port))
+	hop := &gatewayHop{
+		hop:    "client → gateway",
+		target: fmt.Sprintf("%s (%s %s)", gwName, l.Protocol, net.JoinHostPort(address, port)),
+		layer:  gatewayLayerGateway,
+	}
+	if l.Protocol == "TCP" {
+		hop.probe, hop.args = "tcp", []string{address, port}
+		return hop
+	}
+
+	host := l.Hostname
+	if len(route.Hostnames) > 0 {
+		host = route.Hostnames[0]
+	}
+	// A wildcard hostname matches any single label in its place
+	host = strings.Replace(host, "*", "pocket", 1)
+	if host == "" {
+		host = address
+	}
+	scheme := "http"
+	if l.Protocol == "HTTPS" {
+		scheme = "https"
+	}
+	url := fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, port), route.Path)
+	connectTo := fmt.Sprintf("%s:%s:%s", host, port, net.JoinHostPort(address, port))
+	hop.target = fmt.Sprintf("%s (%s via %s)", gwName, url, net.JoinHostPort(address, port))
+	hop.probe, hop.args = "http", []string{url, connectTo}
+	return hop
+}
+
+// listenerProtocols names the listener protocols a route kind attaches to
+func listenerProtocols(kind string) string {
+	if kind == k8s.KindTCPRoute {
+		return "TCP"
+	}
+	return "HTTP/HTTPS"
+}
+
+// parseGatewayProbes parses "POCKET|<hop>|<result>" lines
+func parseGatewayProbes(output string) map[int]string {
+	results := map[int]string{}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "|")
+		if len(parts) != 3 || parts[0] != "POCKET" {
+			continue
+		}
+		if i, err := strconv.Atoi(parts[1]); err == nil {
+			results[i] = parts[2]
+		}
+	}
+	return results
+}
+
+// evaluateGatewayHops judges the probed hops. The gateway answering 404
+// points at the route's matches; 502-504 point at the backends when they are
+// down, and otherwise at the gateway's own path to them, e.g. a
+// NetworkPolicy blocking the gateway's namespace.
+func evaluateGatewayHops(hops []*gatewayHop, results map[int]string) {
+	for i, hop := range hops {
+		if hop.probe != "tcp" {
+			continue
+		}
+		if results[i] == "ok" {
+			hop.status, hop.detail = checkHealthy, "connected"
+		} else {
+			hop.status, hop.detail = checkUnhealthy, "connection failed"
+		}
+	}
+
+	backendsDown := false
+	for _, hop := range hops {
+		if hop.layer == gatewayLayerBackend && hop.status == checkUnhealthy {
+			backendsDown = true
+		}
+	}
+
+	for i, hop := range hops {
+		if hop.probe != "http" {
+			continue
+		}
+		code, _ := strconv.Atoi(results[i])
+		hop.status = checkUnhealthy
+		switch {
+		case code == 0:
+			hop.detail = "gateway refused or timed out"
+		case code == 404:
+			hop.layer = gatewayLayerRoute
+			hop.detail = "HTTP 404: the gateway matched no rule for this hostname and path"
+		case code >= 502 && code <= 504 && backendsDown:
+			hop.layer = gatewayLayerBackend
+			hop.detail = fmt.Sprintf("HTTP %d: backends are down", code)
+		case code >= 502 && code <= 504:
+			hop.detail = fmt.Sprintf("HTTP %d: backends accept the probe pod but not the gateway (NetworkPolicy on the gateway's namespace?)", code)
+		default:
+			hop.status, hop.detail = checkHealthy, fmt.Sprintf("HTTP %d", code)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+)
+
+// defaultLatencyCount is the number of runs of --latency without --count
+const defaultLatencyCount = 20
+
+// latencyReport is the structured result of a latency measurement
+type latencyReport struct {
+	Tester    string  `json:"tester"`
+	Target    string  `json:"target"`
+	Namespace string  `json:"namespace"`
+	Pod       string  `json:"pod"`
+	Status    string  `json:"status"`
+	Count     int     `json:"count"`
+	Failed    int     `json:"failed"`
+	MinMs     float64 `json:"minMs"`
+	AvgMs     float64 `json:"avgMs"`
+	P95Ms     float64 `json:"p95Ms"`
+	P99Ms     float64 `json:"p99Ms"`
+	MaxMs     float64 `json:"maxMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// addLatencyFlags registers --latency and --count on cmd
+func (o *testOptions) addLatencyFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.latency, "latency", false,
+		fmt.Sprintf("repeat the test from one pod and report latency percentiles (%d runs unless --count is set)", defaultLatencyCount))
+	cmd.Flags().IntVar(&o.count, "count", 0, "number of test runs; implies --latency")
+}
+
+// latencyRuns returns the number of runs for --latency and --count, or 0
+// for a single test
+func (o *testOptions) latencyRuns() int {
+	if o.count > 0 {
+		return o.count
+	}
+	if o.latency {
+		return defaultLatencyCount
+	}
+	return 0
+}
+
+// runTesterLatency tests target count times in a row from one kept probe
+// pod and reports the latency distribution. It fails if any run failed.
+func runTesterLatency(runner *tester.Runner, t tester.Tester, target, display string, count int) error {
+	runner.Progress = probeProgress
+	runner.ProbeLifetime = runner.Timeout * time.Duration(count)
+
+	probe, err := runner.StartProbePod(context.Background(), t)
+	if err != nil {
+		return err
+	}
+	defer probe.Close()
+
+	i18n.Printf("⏱️  Running %d test(s) from %s/%s\n", count, probe.Namespace, probe.Name)
+	latency, err := probe.Latency(context.Background(), target, count)
+	if err != nil {
+		return err
+	}
+	recordTestMetric(metricLabels{tester: t.Name(), target: display, namespace: probe.Namespace}, latency.Failed == 0, latency.Samples...)
+
+	report := latencyReport{
+		Tester:    t.Name(),
+		Target:    display,
+		Namespace: probe.Namespace,
+		Pod:       probe.Name,
+		Status:    statusPassed,
+		Count:     latency.Count(),
+		Failed:    latency.Failed,
+		MinMs:     millis(latency.Min()),
+		AvgMs:     millis(latency.Avg()),
+		P95Ms:     millis(latency.Percentile(95)),
+		P99Ms:     millis(latency.Percentile(99)),
+		MaxMs:     millis(latency.Max()),
+	}
+	if latency.Failed > 0 {
+		report.Status, report.Error = statusFailed, latency.LastErr.Error()
+	}
+	if structured() {
+		if err := printStructured(report); err != nil {
+			return err
+		}
+	}
+
+	if passed := len(latency.Samples); passed > 0 {
+		i18n.Printf("📝 %d passed: min %s  avg %s  p95 %s  p99 %s  max %s\n", passed,
+			roundLatency(latency.Min()), roundLatency(latency.Avg()),
+			roundLatency(latency.Percentile(95)), roundLatency(latency.Percentile(99)), roundLatency(latency.Max()))
+	}
+	if latency.Failed > 0 {
+		i18n.Printf("❌ %d of %d test(s) failed, last error: %v\n", latency.Failed, latency.Count(), latency.LastErr)
+		return checkFailed(fmt.Errorf("%d of %d connection tests failed", latency.Failed, latency.Count()))
+	}
+	i18n.Printf("✅ %s connection successful on all %d test(s)\n", t.DisplayName(), latency.Count())
+	return nil
+}
+
+// millis returns d in milliseconds with microsecond precision
+func millis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// roundLatency rounds d for display, keeping sub-millisecond latencies
+// readable
+func roundLatency(d time.Duration) time.Duration {
+	if d < 10*time.Millisecond {
+		return d.Round(10 * time.Microsecond)
+	}
+	return d.Round(100 * time.Microsecond)
+}
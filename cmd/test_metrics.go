@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var metricsCmd = &cobra.Command{
+	Use:   "metrics <pod|pod/name|svc/name|deploy/name>",
+	Short: "Test that a workload's Prometheus metrics are scrapable",
+	Long: `Test that a workload's Prometheus metrics endpoint is scrapable from within the cluster.
+
+The scrape endpoint is taken from the prometheus.io/scrape, prometheus.io/port,
+prometheus.io/path and prometheus.io/scheme annotations (on the pod, the
+deployment's pod template, or the service). For services without annotations,
+a matching Prometheus Operator ServiceMonitor is used. Flags override both.
+
+A temporary pod fetches the endpoint, then the response is validated against
+the Prometheus text exposition format and sample counts are reported.
+
+Examples:
+  kubectl pocket test metrics my-app-7d9f8b-x2k4p
+  kubectl pocket test metrics deploy/my-app
+  kubectl pocket test metrics svc/my-app
+  kubectl pocket test metrics svc/my-app --port 9090 --path /actuator/prometheus`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMetricsTest,
+}
+
+var (
+	metricsOpts   testOptions
+	metricsPort   int
+	metricsPath   string
+	metricsScheme string
+)
+
+// metricsProbeImage provides curl for fetching metrics
+const metricsProbeImage = "curlimages/curl:8.11.1"
+
+// Conventional Prometheus scrape annotations
+const (
+	annotationScrape = "prometheus.io/scrape"
+	annotationPort   = "prometheus.io/port"
+	annotationPath   = "prometheus.io/path"
+	annotationScheme = "prometheus.io/scheme"
+)
+
+func init() {
+	testCmd.AddCommand(metricsCmd)
+	metricsOpts.addFlags(metricsCmd, "")
+	metricsCmd.Flags().IntVar(&metricsPort, "port", 0, "metrics port (overrides annotations)")
+	metricsCmd.Flags().StringVar(&metricsPath, "path", "", "metrics path (overrides annotations, default /metrics)")
+	metricsCmd.Flags().StringVar(&metricsScheme, "scheme", "", "http or https (overrides annotations, default http)")
+	markStructuredOutput(metricsCmd)
+}
+
+// metricsReport is the structured result of test metrics
+type metricsReport struct {
+	URL    string `json:"url"`
+	Source string `json:"source"`
+	// ScrapeDisabled is set when prometheus.io/scrape is "false"
+	ScrapeDisabled bool     `json:"scrapeDisabled,omitempty"`
+	Status         string   `json:"status"`
+	Samples        int      `json:"samples"`
+	Families       int      `json:"families"`
+	Errors         []string `json:"errors,omitempty"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// scrapeEndpoint is a resolved metrics endpoint
+type scrapeEndpoint struct {
+	host   string
+	port   int
+	path   string
+	scheme string
+	// source describes where the settings came from
+	source string
+	// disabled is set when prometheus.io/scrape is "false"
+	disabled bool
+}
+
+func (e scrapeEndpoint) url() string {
+	return fmt.Sprintf("%s://%s%s", e.scheme, net.JoinHostPort(e.host, strconv.Itoa(e.port)), e.path)
+}
+
+func runMetricsTest(cmd *cobra.Command, args []string) error {
+	runner, err := newRunner("metrics", &metricsOpts)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), metricsOpts.timeout+30*time.Second)
+	defer cancel()
+
+	endpoint, err := resolveScrapeEndpoint(ctx, runnerClient(runner), args[0])
+	if err != nil {
+		return err
+	}
+
+	printf("🔍 Scraping %s (from %s)\n", endpoint.url(), endpoint.source)
+	if endpoint.disabled {
+		printf("⚠️  %s is \"false\"; Prometheus will not scrape this target\n", annotationScrape)
+	}
+
+	probe := tester.Custom{
+		TesterName: "metrics",
+		ImageName:  metricsProbeImage,
+		Command:    []string{"curl", "-sS", "-f", "-k", "-m", "10", endpoint.url()},
+	}
+
+	runner.Progress = func(step tester.Step, ns, podName string) {
+		switch step {
+		case tester.StepCreatePod:
+			printf("📦 Creating probe pod: %s/%s\n", ns, podName)
+		case tester.StepWaitCompletion:
+			printf("⏳ Waiting for scrape...\n")
+		case tester.StepCleanup:
+			printf("🧹 Cleaning up pod: %s\n", podName)
+		}
+	}
+
+	result, err := runner.Run(ctx, probe, endpoint.url())
+	if err != nil {
+		return err
+	}
+
+	report := metricsReport{URL: endpoint.url(), Source: endpoint.source, ScrapeDisabled: endpoint.disabled, Status: statusPassed}
+	if !result.Success {
+		report.Status, report.Error = statusFailed, strings.TrimSpace(result.Output)
+		if err := printMetricsReport(report); err != nil {
+			return err
+		}
+		printf("❌ Metrics endpoint not reachable!\n")
+		if output := strings.TrimSpace(result.Output); output != "" {
+			printf("📝 Error output:\n%s\n", output)
+		}
+		return checkFailed(fmt.Errorf("scrape failed: %w", result.Err))
+	}
+
+	stats := validateExposition(result.Output)
+	report.Samples, report.Families = stats.samples, len(stats.families)
+	if len(stats.errors) > 0 {
+		report.Status, report.Errors = statusFailed, stats.errors
+		if err := printMetricsReport(report); err != nil {
+			return err
+		}
+		printf("❌ Response is not valid Prometheus exposition format!\n")
+		for _, e := range stats.errors {
+			printf("📝 %s\n", e)
+		}
+		return fmt.Errorf("invalid exposition format (%d error(s))", stats.invalid)
+	}
+
+	if stats.samples == 0 {
+		report.Status = statusWarning
+	}
+	if err := printMetricsReport(report); err != nil {
+		return err
+	}
+	if stats.samples == 0 {
+		printf("⚠️  Endpoint is scrapable but exposes no samples\n")
+		return nil
+	}
+
+	printf("✅ Metrics endpoint is scrapable!\n")
+	printf("📝 %d samples across %d metric families\n", stats.samples, len(stats.families))
+	return nil
+}
+
+// printMetricsReport prints report for -o
+func printMetricsReport(report metricsReport) error {
+	if !structured() {
+		return nil
+	}
+	return printStructured(report)
+}
+
+// resolveScrapeEndpoint finds the metrics endpoint for a pod, deployment or
+// service reference
+func resolveScrapeEndpoint(ctx context.Context, client *k8s.Client, ref string) (*scrapeEndpoint, error) {
+	kind, name, err := parseResourceRef(ref, kindPod)
+	if err != nil {
+		return nil, err
+	}
+
+	ns := client.Namespace
+	endpoint := &scrapeEndpoint{path: "/metrics", scheme: "http"}
+	var annotations map[string]string
+
+	switch kind {
+	case kindPod:
+		pod, err := client.Clientset.CoreV1().Pods(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		annotations = pod.Annotations
+		endpoint.host = pod.Status.PodIP
+		endpoint.source = "pod/" + name
+
+	case kindDeployment:
+		deploy, err := client.Clientset.AppsV1().Deployments(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector, err := metav1.LabelSelectorAsSelector(deploy.Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		pod, err := client.FirstRunningPod(ctx, ns, selector.String())
+		if err != nil {
+			return nil, err
+		}
+		annotations = deploy.Spec.Template.Annotations
+		endpoint.host = pod.Status.PodIP
+		endpoint.source = fmt.Sprintf("deployment/%s (pod %s)", name, pod.Name)
+
+	case kindService:
+		svc, err := client.Clientset.CoreV1().Services(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		annotations = svc.Annotations
+		endpoint.host = fmt.Sprintf("%s.%s.svc", name, ns)
+		endpoint.source = "service/" + name
+
+		if annotations[annotationPort] == "" && metricsPort == 0 {
+			monitor, err := client.FindServiceMonitorEndpoint(ctx, ns, svc.Labels)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up ServiceMonitors: %w", err)
+			}
+			if monitor != nil {
+				for _, port := range svc.Spec.Ports {
+					if port.Name == monitor.Port {
+						endpoint.port = int(port.Port)
+					}
+				}
+				endpoint.path = monitor.Path
+				endpoint.scheme = monitor.Scheme
+				endpoint.source = fmt.Sprintf("service/%s (ServiceMonitor %s)", name, monitor.ServiceMonitor)
+			}
+		}
+	}
+
+	if endpoint.host == "" {
+		return nil, fmt.Errorf("%s has no IP yet", ref)
+	}
+
+	endpoint.disabled = annotations[annotationScrape] == "false"
+	if port, err := strconv.Atoi(annotations[annotationPort]); err == nil {
+		endpoint.port = port
+	}
+	if path := annotations[annotationPath]; path != "" {
+		endpoint.path = path
+	}
+	if scheme := annotations[annotationScheme]; scheme != "" {
+		endpoint.scheme = scheme
+	}
+
+	if metricsPort != 0 {
+		endpoint.port = metricsPort
+	}
+	if metricsPath != "" {
+		endpoint.path = metricsPath
+	}
+	if metricsScheme != "" {
+		endpoint.scheme = metricsScheme
+	}
+
+	if endpoint.port == 0 {
+		return nil, fmt.Errorf("no metrics port found for %s: set the %s annotation or use --port", ref, annotationPort)
+	}
+	if !strings.HasPrefix(endpoint.path, "/") {
+		endpoint.path = "/" + endpoint.path
+	}
+	return endpoint, nil
+}
@@ -0,0 +1,429 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/parallel"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/suite"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+)
+
+var testSuiteCmd = &cobra.Command{
+	Use:   "suite -f <file>",
+	Short: "Run the checks declared in a suite file",
+	Long: `Run the connection checks declared in a YAML suite file concurrently and
+print an aggregated report. Exits non-zero if any check does not meet its
+expectation, so a suite works as a post-deploy smoke test.
+
+Checks of the same type, namespace and password secret share one probe pod;
+each check then runs through exec in that pod.
+
+  parallel: 4            # optional, overridden by --parallel
+  timeout: 20s           # default timeout of each check
+  checks:
+    - name: orders-db
+      type: postgres
+      target: secret://orders/DATABASE_URL
+    - name: cache
+      type: redis
+      from: deploy/api
+      passwordFromSecret: redis-auth
+    - name: payments-isolated
+      type: postgres
+      namespace: frontend
+      target: postgres://pg.payments:5432/app
+      expect: fail       # must NOT connect, e.g. blocked by a NetworkPolicy
+      timeout: 5s
+
+Examples:
+  kubectl pocket test suite -f checks.yaml
+  kubectl pocket test suite -f checks.yaml -n staging --parallel 8`,
+	Args: cobra.NoArgs,
+	RunE: runTestSuite,
+}
+
+var (
+	testSuiteFile     string
+	testSuiteParallel int
+	testSuiteOpts     testOptions
+)
+
+func init() {
+	testCmd.AddCommand(testSuiteCmd)
+	markStructuredOutput(testSuiteCmd)
+	addMetricsOutFlags(testSuiteCmd)
+	testSuiteOpts.cmd = testSuiteCmd
+	testSuiteOpts.probes = true
+	testSuiteCmd.Flags().StringVarP(&testSuiteFile, "file", "f", "", "suite file, or - for stdin")
+	testSuiteCmd.Flags().IntVar(&testSuiteParallel, "parallel", 4, "maximum number of checks running at once")
+	testSuiteCmd.Flags().DurationVar(&testSuiteOpts.timeout, "timeout", 30*time.Second, "default timeout of each check")
+	addPodFlags(testSuiteCmd)
+	_ = testSuiteCmd.MarkFlagRequired("file")
+}
+
+// suiteCheck is one check of a run, with its outcome
+type suiteCheck struct {
+	name string
+	// typeName is the tester name, also set when no such tester exists
+	typeName  string
+	tester    tester.Tester
+	namespace string
+	// target is the resolved connection string; display is what to print
+	target         string
+	display        string
+	passwordSecret string
+	expect         string
+	timeout        time.Duration
+
+	// skipped checks are reported but not run
+	skipped bool
+	// connected is the connection result; latency covers the client only
+	connected bool
+	latency   time.Duration
+	detail    string
+	// err is set when the check could not run at all
+	err error
+}
+
+// met reports whether the check ran and met its expectation
+func (c *suiteCheck) met() bool {
+	if c.err != nil || c.skipped {
+		return false
+	}
+	return c.connected == (c.expect != suite.ExpectFail)
+}
+
+func runTestSuite(cmd *cobra.Command, args []string) error {
+	s, err := suite.Load(testSuiteFile)
+	if err != nil {
+		return err
+	}
+
+	parallel := testSuiteParallel
+	if s.Parallel > 0 && !cmd.Flags().Changed("parallel") {
+		parallel = s.Parallel
+	}
+	if parallel < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+	if s.Timeout != nil && !cmd.Flags().Changed("timeout") {
+		testSuiteOpts.timeout = s.Timeout.Duration
+	}
+
+	runner, err := newRunner("", &testSuiteOpts)
+	if err != nil {
+		return err
+	}
+
+	i18n.Printf("📋 Running %d check(s) from %s\n", len(s.Checks), testSuiteFile)
+	checks := make([]*suiteCheck, len(s.Checks))
+	for i, c := range s.Checks {
+		checks[i] = resolveSuiteCheck(runner, c)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	runSuiteChecks(ctx, runner, checks, parallel)
+	if ctx.Err() != nil {
+		return errInterrupted()
+	}
+	return reportSuiteChecks(checks)
+}
+
+// resolveSuiteCheck looks up the check's tester and resolves its target.
+// Failures are recorded on the check, so one bad entry does not stop the
+// suite.
+func resolveSuiteCheck(runner *tester.Runner, c suite.Check) *suiteCheck {
+	check := &suiteCheck{
+		name:           c.DisplayName(),
+		typeName:       c.Type,
+		namespace:      valueOr(c.Namespace, runner.Namespace),
+		passwordSecret: c.PasswordFromSecret,
+		expect:         c.Expected(),
+		timeout:        runner.Timeout,
+	}
+	if c.Timeout != nil {
+		check.timeout = c.Timeout.Duration
+	}
+
+	t, ok := tester.Get(c.Type)
+	if !ok {
+		check.err = fmt.Errorf("unknown tester %q (available: %s)", c.Type, strings.Join(tester.Names(), ", "))
+		return check
+	}
+	check.tester = t
+
+	client := *runnerClient(runner)
+	client.Namespace = check.namespace
+	opts := testOptions{from: c.From}
+	if c.From != "" {
+		check.target, check.display, check.err = opts.targetFromWorkload(&client, t)
+	} else {
+		check.target, check.display, check.err = resolveTarget(&client, c.Target)
+	}
+	return check
+}
+
+// suiteGroup is a set of checks sharing a probe pod
+type suiteGroup struct {
+	namespace      string
+	tester         tester.Tester
+	passwordSecret string
+	checks         []*suiteCheck
+}
+
+// runSuiteChecks runs the checks through one probe pod per group, at most
+// workers pod starts and checks at a time. When ctx is canceled, checks
+// that have not run fail with its error and the probe pods are deleted
+// before it returns.
+func runSuiteChecks(ctx context.Context, base *tester.Runner, checks []*suiteCheck, workers int) {
+	var groups []*suiteGroup
+	index := map[string]*suiteGroup{}
+	for _, c := range checks {
+		if c.err != nil || c.skipped {
+			continue
+		}
+		key := c.namespace + "/" + c.tester.Name() + "/" + c.passwordSecret
+		g, ok := index[key]
+		if !ok {
+			g = &suiteGroup{namespace: c.namespace, tester: c.tester, passwordSecret: c.passwordSecret}
+			index[key] = g
+			groups = append(groups, g)
+		}
+		g.checks = append(g.checks, c)
+	}
+
+	tasks := len(groups)
+	for _, g := range groups {
+		tasks += len(g.checks)
+	}
+	progress := startProgress(tasks)
+	if progress != nil {
+		// The progress line shows the pods being started instead
+		base.Progress = nil
+	} else {
+		base.Progress = func(step tester.Step, ns, podName string) {
+			switch step {
+			case tester.StepCreatePod:
+				i18n.Printf("📦 Creating probe pod: %s/%s\n", ns, podName)
+			case tester.StepCleanup:
+				i18n.Printf("🧹 Cleaning up pod: %s\n", podName)
+			}
+		}
+	}
+
+	pool := parallel.New(workers, progress)
+	for _, g := range groups {
+		pool.Spawn(func() { runSuiteGroup(ctx, base, g, pool) })
+	}
+	pool.Wait()
+	progress.Stop()
+	if ctx.Err() != nil {
+		// An interrupted run says nothing about the targets
+		return
+	}
+
+	for _, c := range checks {
+		if c.skipped {
+			continue
+		}
+		labels := metricLabels{tester: c.typeName, target: c.display, namespace: c.namespace}
+		status, _ := c.verdict()
+		if c.connected {
+			recordTestMetric(labels, status == checkHealthy, c.latency)
+		} else {
+			recordTestMetric(labels, status == checkHealthy)
+		}
+	}
+}
+
+// runSuiteGroup starts the group's probe pod and runs its checks on pool
+func runSuiteGroup(ctx context.Context, base *tester.Runner, g *suiteGroup, pool *parallel.Pool) {
+	fail := func(err error) {
+		for _, c := range g.checks {
+			c.err = err
+		}
+	}
+
+	runner := *base
+	client := *runnerClient(base)
+	client.Namespace = g.namespace
+	runner.Client, runner.Namespace = &client, g.namespace
+	// The probe pod's own timeout must cover the slowest check, and the pod
+	// must live through all of them
+	runner.ProbeLifetime = 0
+	for _, c := range g.checks {
+		runner.Timeout = max(runner.Timeout, c.timeout)
+		runner.ProbeLifetime += c.timeout
+	}
+
+	t := g.tester
+	if g.passwordSecret != "" {
+		opts := testOptions{passwordSecret: g.passwordSecret}
+		injected, err := opts.injectPassword(&runner, t)
+		if err != nil {
+			fail(err)
+			return
+		}
+		t = injected
+	}
+
+	var probe *tester.ProbePod
+	err := pool.Do(ctx, parallel.Task{
+		Name: i18n.T("%s pod in %s", t.Name(), g.namespace),
+		Run: func(ctx context.Context) (err error) {
+			probe, err = runner.StartProbePod(ctx, t)
+			return err
+		},
+	})
+	if err != nil {
+		fail(err)
+		return
+	}
+	defer probe.Close()
+
+	var wg sync.WaitGroup
+	for _, c := range g.checks {
+		wg.Add(1)
+		ran := false
+		task := parallel.Task{Name: c.name, Timeout: c.timeout, Run: func(checkCtx context.Context) error {
+			ran = true
+			start := time.Now()
+			result, err := probe.Probe(checkCtx, c.target)
+			c.latency = time.Since(start)
+			switch {
+			case err != nil && ctx.Err() != nil:
+				c.err = ctx.Err()
+			case err != nil && checkCtx.Err() != nil:
+				// A connection that hangs until the timeout did not connect
+				c.detail = i18n.T("timed out after %s", c.timeout)
+			case err != nil:
+				c.err = err
+			case result.Success:
+				c.connected = true
+			default:
+				c.detail = firstLine(result.Output)
+				if c.detail == "" {
+					c.detail = result.Err.Error()
+				}
+			}
+			if status, detail := c.verdict(); status == checkUnhealthy {
+				return errors.New(detail)
+			}
+			return nil
+		}}
+		pool.Go(ctx, task, func(err error) {
+			defer wg.Done()
+			if !ran {
+				c.err = err
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// verdict judges the check and explains the judgement
+func (c *suiteCheck) verdict() (checkStatus, string) {
+	switch {
+	case c.skipped:
+		return checkWarning, c.detail
+	case c.err != nil:
+		return checkUnhealthy, c.err.Error()
+	case !c.met() && c.connected:
+		return checkUnhealthy, i18n.T("connected, but expected to fail")
+	case !c.met():
+		return checkUnhealthy, c.detail
+	}
+	return checkHealthy, c.detail
+}
+
+// report returns the check's structured result
+func (c *suiteCheck) report() checkReport {
+	status, detail := c.verdict()
+	r := checkReport{
+		Name:      c.name,
+		Type:      c.typeName,
+		Namespace: c.namespace,
+		Target:    c.display,
+		Expect:    c.expect,
+		Status:    status.String(),
+		Detail:    detail,
+	}
+	if c.skipped {
+		r.Status = statusSkipped
+	} else if c.err == nil {
+		r.LatencyMs = c.latency.Milliseconds()
+	}
+	return r
+}
+
+// printChecksReport writes the checks' structured results
+func printChecksReport(checks []*suiteCheck, withExpect bool) error {
+	report := checksReport{Checks: []checkReport{}}
+	for _, c := range checks {
+		r := c.report()
+		if !withExpect {
+			r.Expect = ""
+		}
+		switch r.Status {
+		case statusPassed:
+			report.Passed++
+		case statusSkipped:
+			report.Skipped++
+		default:
+			report.Failed++
+		}
+		report.Checks = append(report.Checks, r)
+	}
+	return printStructured(report)
+}
+
+// reportSuiteChecks prints the checks' outcomes and fails unless all ran
+// and met their expectations
+func reportSuiteChecks(checks []*suiteCheck) error {
+	failed, ran := 0, 0
+	for _, c := range checks {
+		if status, _ := c.verdict(); status == checkUnhealthy {
+			failed++
+		}
+		if !c.skipped {
+			ran++
+		}
+	}
+	if structured() {
+		if err := printChecksReport(checks, true); err != nil {
+			return err
+		}
+	} else {
+		w := newTable()
+		_, _ = fmt.Fprintln(w, "\nSTATUS\tCHECK\tTYPE\tTARGET\tEXPECT\tLATENCY\tDETAIL")
+		for _, c := range checks {
+			r := c.report()
+			status, _ := c.verdict()
+			latency := "-"
+			if r.LatencyMs > 0 {
+				latency = c.latency.Round(time.Millisecond).String()
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				status.icon(), c.name, valueOr(r.Type, "-"), valueOr(c.display, "-"), c.expect, latency, valueOr(r.Detail, "-"))
+		}
+		_ = w.Flush()
+		printf("\n")
+	}
+
+	if failed > 0 {
+		i18n.Printf("❌ %d of %d check(s) failed\n", failed, ran)
+		return checkFailed(fmt.Errorf("%d of %d checks failed", failed, ran))
+	}
+	i18n.Printf("✅ All %d check(s) passed\n", ran)
+	return nil
+}
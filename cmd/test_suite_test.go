@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestSuiteGroupsGetTheirOwnProbePods(t *testing.T) {
+	secret := func(name string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "payments"},
+			Data:       map[string][]byte{defaultPasswordKey: []byte("secret")},
+		}
+	}
+	clientset := fake.NewClientset(secret("reader"), secret("writer"))
+
+	// Pods start running as soon as they are created, as if the kubelet
+	// were instantaneous
+	var mu sync.Mutex
+	var created []string
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod)
+		pod.Status.Phase = corev1.PodRunning
+		mu.Lock()
+		created = append(created, pod.Name)
+		mu.Unlock()
+		return false, nil, nil
+	})
+
+	client := k8s.NewClientForClientset(clientset, nil, nil, "payments")
+	runner := tester.NewRunner(client, 5*time.Second)
+	postgres, _ := tester.Get("postgres")
+	checks := []*suiteCheck{
+		{name: "reader", namespace: "payments", tester: postgres, target: "postgres://reader@pg:5432/app", passwordSecret: "reader", timeout: time.Second},
+		{name: "writer", namespace: "payments", tester: postgres, target: "postgres://writer@pg:5432/app", passwordSecret: "writer", timeout: time.Second},
+	}
+
+	runSuiteChecks(context.Background(), runner, checks, 2)
+
+	if len(created) != 2 || created[0] == created[1] {
+		t.Fatalf("created pods %q, want two distinct pods", created)
+	}
+	for _, c := range checks {
+		// Without a REST config the probes cannot exec, which shows that
+		// both pods started
+		if !errors.Is(c.err, k8s.ErrNoRESTConfig) {
+			t.Errorf("check %s error = %v, want %v", c.name, c.err, k8s.ErrNoRESTConfig)
+		}
+	}
+	pods, err := clientset.CoreV1().Pods("payments").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods.Items) != 0 {
+		t.Errorf("%d pods left after the suite, want 0", len(pods.Items))
+	}
+}
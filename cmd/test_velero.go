@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var veleroCmd = &cobra.Command{
+	Use:     "velero",
+	Aliases: []string{"backup"},
+	Short:   "Verify that Velero backups and restores work",
+	Long: `Verify the Velero backup stack before a restore is needed.
+
+Three checks are run:
+  - Storage: every BackupStorageLocation is Available, meaning Velero could
+    reach the bucket with its credentials from inside the cluster
+  - Schedules: no schedule is paused, invalid or without a recent backup
+  - Round trip: a scratch namespace with a ConfigMap is backed up, restored
+    under a new name and compared (skip with --skip-roundtrip)
+
+The scratch namespaces, the Restore and the Backup (including its data in
+object storage) are deleted afterwards.
+
+Examples:
+  kubectl pocket test velero
+  kubectl pocket test velero --storage-location secondary --timeout 10m
+  kubectl pocket test velero --skip-roundtrip --max-schedule-age 8h`,
+	Args: cobra.NoArgs,
+	RunE: runVeleroTest,
+}
+
+var (
+	veleroNamespace       string
+	veleroStorageLocation string
+	veleroMaxScheduleAge  time.Duration
+	veleroSkipRoundTrip   bool
+	veleroTimeout         time.Duration
+)
+
+// veleroProbeKey is the ConfigMap key compared after the round trip
+const veleroProbeKey = "checkedAt"
+
+func init() {
+	testCmd.AddCommand(veleroCmd)
+	veleroCmd.Flags().StringVar(&veleroNamespace, "velero-namespace", "velero", "namespace Velero is installed in")
+	veleroCmd.Flags().StringVar(&veleroStorageLocation, "storage-location", "", "backup storage location for the round trip (default: Velero's default)")
+	veleroCmd.Flags().DurationVar(&veleroMaxScheduleAge, "max-schedule-age", 25*time.Hour, "report schedules whose last backup is older than this")
+	veleroCmd.Flags().BoolVar(&veleroSkipRoundTrip, "skip-roundtrip", false, "only check storage locations and schedules")
+	veleroCmd.Flags().DurationVar(&veleroTimeout, "timeout", 5*time.Minute, "timeout for each of the backup and the restore")
+	markStructuredOutput(veleroCmd)
+}
+
+// veleroReport is the structured result of test velero
+type veleroReport struct {
+	Namespace string        `json:"namespace"`
+	Status    string        `json:"status"`
+	Checks    []veleroCheck `json:"checks"`
+}
+
+// veleroCheck is the result of one storage location, schedule or the round
+// trip
+type veleroCheck struct {
+	// Kind is "storage", "schedule" or "roundtrip"
+	Kind   string `json:"kind"`
+	Name   string `json:"name,omitempty"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// add records a check in the report
+func (r *veleroReport) add(kind, name string, status checkStatus, detail string) {
+	r.Checks = append(r.Checks, veleroCheck{Kind: kind, Name: name, Status: status.String(), Detail: detail})
+}
+
+func runVeleroTest(cmd *cobra.Command, args []string) error {
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*veleroTimeout+time.Minute)
+	defer cancel()
+
+	printf("🔍 Verifying Velero in namespace %s\n", veleroNamespace)
+
+	report := &veleroReport{Namespace: veleroNamespace, Status: statusPassed, Checks: []veleroCheck{}}
+	failures := 0
+	if !checkStorageLocations(ctx, client, report) {
+		failures++
+	}
+	if !checkSchedules(ctx, client, report) {
+		failures++
+	}
+	if !veleroSkipRoundTrip {
+		if err := veleroRoundTrip(ctx, client); err != nil {
+			printf("❌ Backup/restore round trip failed!\n")
+			printf("📝 %v\n", err)
+			report.add("roundtrip", "", checkUnhealthy, err.Error())
+			failures++
+		} else {
+			printf("✅ Backup/restore round trip succeeded\n")
+			report.add("roundtrip", "", checkHealthy, "")
+		}
+	}
+
+	if failures > 0 {
+		report.Status = statusFailed
+	}
+	if structured() {
+		if err := printStructured(report); err != nil {
+			return err
+		}
+	}
+	if failures > 0 {
+		return checkFailed(fmt.Errorf("%d Velero check(s) failed", failures))
+	}
+	printf("✅ Backup stack is healthy!\n")
+	return nil
+}
+
+// checkStorageLocations reports each storage location and returns false if
+// any is unavailable
+func checkStorageLocations(ctx context.Context, client *k8s.Client, report *veleroReport) bool {
+	locations, err := client.ListBackupStorageLocations(ctx, veleroNamespace)
+	if err != nil {
+		printf("❌ Failed to list backup storage locations (is Velero installed?): %v\n", err)
+		report.add("storage", "", checkUnhealthy, err.Error())
+		return false
+	}
+	if len(locations) == 0 {
+		printf("❌ No backup storage locations configured\n")
+		report.add("storage", "", checkUnhealthy, "no backup storage locations configured")
+		return false
+	}
+
+	ok := true
+	for _, location := range locations {
+		name := location.Name
+		if location.Default {
+			name += " (default)"
+		}
+		target := fmt.Sprintf("%s bucket %s", location.Provider, location.Bucket)
+
+		if location.Phase != "Available" {
+			ok = false
+			printf("❌ Storage %s: %s is %s\n", name, target, valueOr(location.Phase, "not validated yet"))
+			if location.Message != "" {
+				printf("📝 %s\n", location.Message)
+			}
+			report.add("storage", location.Name, checkUnhealthy,
+				fmt.Sprintf("%s is %s", target, valueOr(location.Message, valueOr(location.Phase, "not validated yet"))))
+			continue
+		}
+
+		validated := "never"
+		if !location.LastValidation.IsZero() {
+			validated = time.Since(location.LastValidation).Round(time.Second).String() + " ago"
+		}
+		printf("✅ Storage %s: %s is available (validated %s)\n", name, target, validated)
+		report.add("storage", location.Name, checkHealthy, fmt.Sprintf("%s is available (validated %s)", target, validated))
+	}
+	return ok
+}
+
+// checkSchedules reports each schedule and returns false if any is stale,
+// paused or invalid
+func checkSchedules(ctx context.Context, client *k8s.Client, report *veleroReport) bool {
+	schedules, err := client.ListBackupSchedules(ctx, veleroNamespace)
+	if err != nil {
+		printf("❌ Failed to list schedules: %v\n", err)
+		report.add("schedule", "", checkUnhealthy, err.Error())
+		return false
+	}
+	if len(schedules) == 0 {
+		printf("⚠️  No backup schedules configured\n")
+		report.add("schedule", "", checkWarning, "no backup schedules configured")
+		return true
+	}
+
+	ok := true
+	for _, schedule := range schedules {
+		label := fmt.Sprintf("Schedule %s (%s)", schedule.Name, schedule.Schedule)
+
+		var status checkStatus
+		var detail string
+		switch {
+		case schedule.Phase == k8s.VeleroPhaseFailedValidation:
+			status, detail = checkUnhealthy, "failed validation"
+		case schedule.Paused:
+			status, detail = checkUnhealthy, "is paused"
+		case schedule.LastBackup.IsZero():
+			status, detail = checkWarning, "has not run yet"
+			if time.Since(schedule.Created) > veleroMaxScheduleAge {
+				status, detail = checkUnhealthy, "has never run"
+			}
+		case time.Since(schedule.LastBackup) > veleroMaxScheduleAge:
+			status, detail = checkUnhealthy, fmt.Sprintf("is stale: last backup %s ago", time.Since(schedule.LastBackup).Round(time.Minute))
+		default:
+			detail = fmt.Sprintf("last backup %s ago", time.Since(schedule.LastBackup).Round(time.Minute))
+		}
+
+		switch status {
+		case checkUnhealthy:
+			ok = false
+			printf("❌ %s %s\n", label, detail)
+		case checkWarning:
+			printf("⚠️  %s %s\n", label, detail)
+		default:
+			printf("✅ %s: %s\n", label, detail)
+		}
+		report.add("schedule", schedule.Name, status, detail)
+	}
+	return ok
+}
+
+// veleroRoundTrip backs up a scratch namespace, restores it under a new name
+// and checks that the restored ConfigMap matches
+func veleroRoundTrip(ctx context.Context, client *k8s.Client) error {
+	name := fmt.Sprintf("pocket-velero-%d", time.Now().Unix())
+	restoredNamespace := name + "-restored"
+	checkedAt := time.Now().UTC().Format(time.RFC3339)
+
+	labels := map[string]string{
+		"app.kubernetes.io/managed-by": "kubectl-pocket",
+		"kubectl-pocket/temporary":     "true",
+	}
+
+	printf("📦 Creating scratch namespace: %s\n", name)
+	_, err := client.Clientset.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create scratch namespace: %w", err)
+	}
+
+	defer func() {
+		printf("🧹 Cleaning up backup %s and scratch namespaces\n", name)
+		cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cleanupCancel()
+		_ = client.DeleteRestore(cleanupCtx, veleroNamespace, name)
+		_ = client.DeleteBackup(cleanupCtx, veleroNamespace, name)
+		_ = client.Clientset.CoreV1().Namespaces().Delete(cleanupCtx, name, metav1.DeleteOptions{})
+		_ = client.Clientset.CoreV1().Namespaces().Delete(cleanupCtx, restoredNamespace, metav1.DeleteOptions{})
+	}()
+
+	_, err = client.Clientset.CoreV1().ConfigMaps(name).Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "pocket-probe", Labels: labels},
+		Data:       map[string]string{veleroProbeKey: checkedAt},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create probe ConfigMap: %w", err)
+	}
+
+	printf("⏳ Backing up %s...\n", name)
+	if err := client.CreateBackup(ctx, veleroNamespace, name, name, veleroStorageLocation); err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+	if err := waitForVelero(ctx, client, k8s.VeleroBackupGVR, name); err != nil {
+		return fmt.Errorf("backup: %w", err)
+	}
+
+	printf("⏳ Restoring into %s...\n", restoredNamespace)
+	if err := client.CreateRestore(ctx, veleroNamespace, name, name, map[string]string{name: restoredNamespace}); err != nil {
+		return fmt.Errorf("failed to create restore: %w", err)
+	}
+	if err := waitForVelero(ctx, client, k8s.VeleroRestoreGVR, name); err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+
+	restored, err := client.Clientset.CoreV1().ConfigMaps(restoredNamespace).Get(ctx, "pocket-probe", metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("restored ConfigMap not found: %w", err)
+	}
+	if restored.Data[veleroProbeKey] != checkedAt {
+		return fmt.Errorf("restored ConfigMap does not match: got %q, want %q", restored.Data[veleroProbeKey], checkedAt)
+	}
+	return nil
+}
+
+// waitForVelero waits for a Backup or Restore and turns anything but
+// Completed into an error
+func waitForVelero(ctx context.Context, client *k8s.Client, gvr schema.GroupVersionResource, name string) error {
+	op, err := client.WaitForVeleroOperation(ctx, gvr, veleroNamespace, name, veleroTimeout)
+	if err != nil {
+		if op != nil && op.Phase != "" {
+			return fmt.Errorf("%w (phase %s)", err, op.Phase)
+		}
+		return err
+	}
+
+	if op.Phase == k8s.VeleroPhaseCompleted {
+		if op.Warnings > 0 {
+			printf("⚠️  %s %s completed with %d warning(s)\n", gvr.Resource, name, op.Warnings)
+		}
+		return nil
+	}
+
+	detail := op.FailureReason
+	if len(op.ValidationErrors) > 0 {
+		detail = op.ValidationErrors[0]
+	}
+	if detail == "" && op.Errors > 0 {
+		detail = fmt.Sprintf("%d error(s); run 'velero %s describe %s --details'", op.Errors, strings.TrimSuffix(gvr.Resource, "s"), name)
+	}
+	return fmt.Errorf("finished %s: %s", op.Phase, valueOr(detail, "no details reported"))
+}
+
+// valueOr returns s, or fallback when s is empty
+func valueOr(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
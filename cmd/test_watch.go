@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+)
+
+// watchStats summarizes a --watch session
+type watchStats struct {
+	started  time.Time
+	checks   int
+	failures int
+	// flaps counts changes between passing and failing
+	flaps int
+	// outageStart is set while the target is failing
+	outageStart   time.Time
+	longestOutage time.Duration
+	lastOK        bool
+}
+
+// record adds one test result taken at start
+func (s *watchStats) record(start time.Time, ok bool) {
+	if s.checks > 0 && ok != s.lastOK {
+		s.flaps++
+	}
+	s.checks++
+	s.lastOK = ok
+
+	switch {
+	case !ok && s.outageStart.IsZero():
+		s.failures++
+		s.outageStart = start
+	case !ok:
+		s.failures++
+	case !s.outageStart.IsZero():
+		s.longestOutage = max(s.longestOutage, start.Sub(s.outageStart))
+		s.outageStart = time.Time{}
+	}
+}
+
+// probeProgress reports the steps of a kept probe pod
+func probeProgress(step tester.Step, ns, podName string) {
+	switch step {
+	case tester.StepCreatePod:
+		i18n.Printf("📦 Creating probe pod: %s/%s\n", ns, podName)
+	case tester.StepScheduling:
+		i18n.Printf("📅 Waiting for a node...\n")
+	case tester.StepPulling:
+		i18n.Printf("📥 Pulling image and starting container...\n")
+	case tester.StepWaitRunning:
+		i18n.Printf("⏳ Waiting for probe pod to be ready...\n")
+	case tester.StepCleanup:
+		i18n.Printf("🧹 Cleaning up pod: %s\n", podName)
+	case tester.StepKeep:
+		printKept(ns, podName)
+	case tester.StepUseAgent:
+		printUsingAgent(ns, podName)
+	}
+}
+
+// sessionLifetime bounds the probe pods of commands that run until they
+// are interrupted, should pocket be killed before it can delete them
+const sessionLifetime = k8s.DefaultActiveDeadline
+
+// runTesterWatch tests target every interval through one kept probe pod
+// until interrupted, then prints a summary. It fails if any test failed.
+func runTesterWatch(runner *tester.Runner, t tester.Tester, target string, interval time.Duration) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+	go func() {
+		select {
+		case <-sigCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	runner.Progress = probeProgress
+	runner.ProbeLifetime = sessionLifetime
+
+	probe, err := runner.StartProbePod(ctx, t)
+	if err != nil {
+		return err
+	}
+	defer probe.Close()
+
+	i18n.Printf("👀 Testing every %s, press Ctrl+C to stop\n", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	stats := &watchStats{started: time.Now()}
+	for {
+		start := time.Now()
+		result, err := probe.Probe(ctx, target)
+		if ctx.Err() != nil {
+			return printWatchSummary(t, stats)
+		}
+
+		ok := err == nil && result.Success
+		stats.record(start, ok)
+		if ok {
+			i18n.Printf("  %s  OK    %s\n", start.Format("15:04:05"), time.Since(start).Round(time.Millisecond))
+		} else {
+			reason := "connection failed"
+			if err != nil {
+				reason = err.Error()
+			} else if result.Err != nil {
+				reason = result.Err.Error()
+			}
+			i18n.Printf("  %s  FAIL  %s\n", start.Format("15:04:05"), reason)
+		}
+
+		select {
+		case <-ctx.Done():
+			return printWatchSummary(t, stats)
+		case <-ticker.C:
+		}
+	}
+}
+
+// printWatchSummary prints the session's totals and fails if any test failed
+func printWatchSummary(t tester.Tester, stats *watchStats) error {
+	if !stats.outageStart.IsZero() {
+		stats.longestOutage = max(stats.longestOutage, time.Since(stats.outageStart))
+	}
+
+	printf("\n")
+	i18n.Printf("📝 %d test(s) over %s: %d failed, %d flap(s)\n",
+		stats.checks, time.Since(stats.started).Round(time.Second), stats.failures, stats.flaps)
+	if stats.failures == 0 {
+		i18n.Printf("✅ %s connection stayed up\n", t.DisplayName())
+		return nil
+	}
+	i18n.Printf("📝 Longest outage: %s\n", stats.longestOutage.Round(time.Millisecond))
+	return checkFailed(fmt.Errorf("%d of %d connection tests failed", stats.failures, stats.checks))
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+)
+
+var webhookCmd = &cobra.Command{
+	Use:     "webhook",
+	Aliases: []string{"webhooks"},
+	Short:   "Test admission webhook reachability",
+	Long: `Test that every admission webhook is reachable from within the cluster.
+
+Enumerates validating and mutating webhook configurations, then probes each
+webhook endpoint from a temporary pod: TLS handshake, HTTP response on the
+webhook path, serving certificate expiry, and whether the certificate is
+trusted by the webhook's caBundle.
+
+An unreachable webhook with failurePolicy Fail blocks every matching API
+request, so this is a quick first check when applies start failing.
+
+Examples:
+  kubectl pocket test webhook
+  kubectl pocket test webhook --timeout 3m`,
+	Args: cobra.NoArgs,
+	RunE: runWebhookTest,
+}
+
+var webhookOpts = testOptions{timeout: 2 * time.Minute}
+
+// webhookProbeImage provides curl and openssl for probing endpoints
+const webhookProbeImage = "nicolaka/netshoot:v0.13"
+
+// certExpiryWarning is how close to expiry a serving certificate gets flagged
+const certExpiryWarning = 7 * 24 * time.Hour
+
+func init() {
+	testCmd.AddCommand(webhookCmd)
+	webhookOpts.addFlags(webhookCmd, "")
+	markStructuredOutput(webhookCmd)
+}
+
+// webhookReport is the structured result of one webhook
+type webhookReport struct {
+	Kind          string   `json:"kind"`
+	Configuration string   `json:"configuration"`
+	Name          string   `json:"name"`
+	Endpoint      string   `json:"endpoint,omitempty"`
+	FailurePolicy string   `json:"failurePolicy"`
+	Status        string   `json:"status"`
+	Details       []string `json:"details,omitempty"`
+}
+
+// webhookProbe is the in-cluster probe result for one webhook
+type webhookProbe struct {
+	httpCode int
+	cert     *x509.Certificate
+}
+
+func runWebhookTest(cmd *cobra.Command, args []string) error {
+	runner, err := newRunner("webhook", &webhookOpts)
+	if err != nil {
+		return err
+	}
+	client := runnerClient(runner)
+
+	ctx := context.Background()
+
+	printf("🔍 Listing admission webhooks\n")
+	webhooks, err := client.ListWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(webhooks) == 0 {
+		printf("✅ No admission webhooks configured\n")
+		if structured() {
+			return printStructured([]webhookReport{})
+		}
+		return nil
+	}
+
+	probe := tester.Custom{
+		TesterName: "webhook",
+		ImageName:  webhookProbeImage,
+		Command:    []string{"/bin/sh", "-c", webhookProbeScript(webhooks)},
+	}
+
+	runner.Progress = func(step tester.Step, ns, podName string) {
+		switch step {
+		case tester.StepCreatePod:
+			printf("📦 Creating probe pod: %s/%s\n", ns, podName)
+		case tester.StepWaitCompletion:
+			printf("⏳ Probing %d webhook(s)...\n", len(webhooks))
+		case tester.StepCleanup:
+			printf("🧹 Cleaning up pod: %s\n", podName)
+		}
+	}
+
+	result, err := runner.Run(ctx, probe, "")
+	if err != nil {
+		return err
+	}
+
+	probes := parseWebhookProbes(result.Output)
+
+	unhealthy := 0
+	verdicts := make([]checkStatus, len(webhooks))
+	report := make([]webhookReport, len(webhooks))
+	for i, wh := range webhooks {
+		verdict, details := evaluateWebhook(wh, probes[i])
+		if verdict == checkUnhealthy {
+			unhealthy++
+		}
+		verdicts[i] = verdict
+		report[i] = webhookReport{
+			Kind: wh.Kind, Configuration: wh.Configuration, Name: wh.Name, Endpoint: wh.URL,
+			FailurePolicy: wh.FailurePolicy, Status: verdict.String(), Details: details,
+		}
+	}
+
+	if structured() {
+		if err := printStructured(report); err != nil {
+			return err
+		}
+	} else {
+		w := newTable()
+		_, _ = fmt.Fprintln(w, "\nSTATUS\tKIND\tWEBHOOK\tENDPOINT\tPOLICY\tDETAILS")
+		for i, wh := range report {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s/%s\t%s\t%s\t%s\n", verdicts[i].icon(), wh.Kind, wh.Configuration, wh.Name,
+				valueOr(wh.Endpoint, "-"), wh.FailurePolicy, strings.Join(wh.Details, "; "))
+		}
+		_ = w.Flush()
+		printf("\n")
+	}
+
+	if unhealthy > 0 {
+		printf("❌ %d of %d webhook(s) unhealthy\n", unhealthy, len(webhooks))
+		return checkFailed(fmt.Errorf("%d webhook(s) unhealthy", unhealthy))
+	}
+
+	printf("✅ All %d webhook(s) reachable\n", len(webhooks))
+	return nil
+}
+
+// webhookProbeScript builds a shell script that probes every webhook and
+// prints one "POCKET|<index>|<http-code>|<base64 serving cert>" line each.
+// The webhooks are probed at once, so a cluster full of unreachable ones
+// still reports within about ten seconds; each probe writes its own file,
+// as lines holding a certificate are too long to be written atomically.
+func webhookProbeScript(webhooks []k8s.Webhook) string {
+	var b strings.Builder
+	b.WriteString(`dir=$(mktemp -d)
+probe() {
+  code=$(curl -sk -o /dev/null -w '%{http_code}' --max-time 5 -X POST -H 'Content-Type: application/json' -d '{}' "$2")
+  cert=$(echo | timeout 5 openssl s_client -connect "$3" -servername "$4" 2>/dev/null | openssl x509 -outform PEM 2>/dev/null | base64 | tr -d '\n')
+  echo "POCKET|$1|$code|$cert" > "$dir/$1"
+}
+`)
+	for i, wh := range webhooks {
+		if wh.URL == "" || wh.Host == "" {
+			continue
+		}
+		hostPort := net.JoinHostPort(wh.Host, strconv.Itoa(wh.Port))
+		fmt.Fprintf(&b, "probe %d %s %s %s &\n", i, tester.ShellQuote(wh.URL), tester.ShellQuote(hostPort), tester.ShellQuote(wh.ServerName))
+	}
+	b.WriteString("wait\ncat \"$dir\"/* 2>/dev/null\n")
+	return b.String()
+}
+
+// parseWebhookProbes parses the probe script output, keyed by webhook index
+func parseWebhookProbes(output string) map[int]*webhookProbe {
+	probes := map[int]*webhookProbe{}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "|")
+		if len(parts) != 4 || parts[0] != "POCKET" {
+			continue
+		}
+		index, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+
+		probe := &webhookProbe{}
+		probe.httpCode, _ = strconv.Atoi(parts[2])
+		if pemData, err := base64.StdEncoding.DecodeString(parts[3]); err == nil {
+			if certs, err := parseCertificates(pemData); err == nil {
+				probe.cert = certs[0]
+			}
+		}
+		probes[index] = probe
+	}
+	return probes
+}
+
+// evaluateWebhook judges a probe result. A webhook is unhealthy when it is
+// unreachable, answers with a server error, or its certificate would be
+// rejected by the API server. Any other HTTP answer proves reachability, since
+// webhooks commonly reject the empty probe body with a 4xx.
+func evaluateWebhook(wh k8s.Webhook, probe *webhookProbe) (checkStatus, []string) {
+	if wh.URL == "" {
+		return checkWarning, []string{"no endpoint configured"}
+	}
+	if probe == nil || probe.httpCode == 0 {
+		return checkUnhealthy, []string{"unreachable"}
+	}
+
+	status := checkHealthy
+	details := []string{fmt.Sprintf("HTTP %d", probe.httpCode)}
+	if probe.httpCode >= 500 {
+		status = checkUnhealthy
+	}
+
+	if probe.cert == nil {
+		return checkUnhealthy, append(details, "TLS handshake failed")
+	}
+
+	remaining := time.Until(probe.cert.NotAfter)
+	switch {
+	case remaining <= 0:
+		return checkUnhealthy, append(details, "certificate expired "+probe.cert.NotAfter.Format("2006-01-02"))
+	case remaining < certExpiryWarning:
+		status = max(status, checkWarning)
+		details = append(details, "certificate expires "+probe.cert.NotAfter.Format("2006-01-02"))
+	}
+
+	var roots *x509.CertPool
+	if len(wh.CABundle) > 0 {
+		roots = x509.NewCertPool()
+		roots.AppendCertsFromPEM(wh.CABundle)
+	}
+	if _, err := probe.cert.Verify(x509.VerifyOptions{DNSName: wh.ServerName, Roots: roots}); err != nil {
+		return checkUnhealthy, append(details, "certificate not trusted by caBundle")
+	}
+
+	return status, details
+}
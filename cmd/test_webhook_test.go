@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+)
+
+func TestWebhookProbeScriptProbesAtOnce(t *testing.T) {
+	// Every webhook hangs for a second; openssl finds no certificate
+	bin := t.TempDir()
+	stubs := map[string]string{
+		"curl":    "sleep 1; printf 503",
+		"openssl": "cat >/dev/null",
+		"timeout": `shift; exec "$@"`,
+	}
+	for name, body := range stubs {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var webhooks []k8s.Webhook
+	for i := range 8 {
+		webhooks = append(webhooks, k8s.Webhook{
+			Name: fmt.Sprintf("hook-%d", i), URL: fmt.Sprintf("https://hook-%d.svc:443/validate", i),
+			Host: fmt.Sprintf("hook-%d.svc", i), Port: 443, ServerName: fmt.Sprintf("hook-%d.svc", i),
+		})
+	}
+
+	cmd := exec.Command("/bin/sh", "-c", webhookProbeScript(webhooks))
+	cmd.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	start := time.Now()
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("script failed: %v", err)
+	}
+	if took := time.Since(start); took > 4*time.Second {
+		t.Errorf("probing %d webhooks took %s, want them probed at once", len(webhooks), took)
+	}
+
+	probes := parseWebhookProbes(string(out))
+	for i := range webhooks {
+		if probes[i] == nil || probes[i].httpCode != 503 {
+			t.Errorf("webhook %d probe = %+v, want HTTP 503", i, probes[i])
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+// tlsMountPath is where the TLS Secrets are mounted in test pods
+const tlsMountPath = "/etc/pocket/tls"
+
+// Secret keys read by the TLS flags
+const (
+	defaultCAKey = "ca.crt"
+	certKey      = corev1.TLSCertKey
+	keyKey       = corev1.TLSPrivateKeyKey
+)
+
+// tlsOptions are the TLS flags of database tests
+type tlsOptions struct {
+	// enabled is --tls, implied by the other flags
+	enabled bool
+	// caSecret is --ca-from-secret as <name>[:<key>]
+	caSecret string
+	// certSecret is --cert-from-secret, a kubernetes.io/tls Secret
+	certSecret string
+	insecure   bool
+}
+
+// addFlags registers the TLS flags on cmd
+func (o *tlsOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.enabled, "tls", false, "connect with TLS; implied by the other TLS flags")
+	cmd.Flags().StringVar(&o.caSecret, "ca-from-secret", "",
+		"verify the server with the CA certificate in a Secret as <name>[:<key>] (default key \""+defaultCAKey+"\")")
+	cmd.Flags().StringVar(&o.certSecret, "cert-from-secret", "",
+		"authenticate with the client certificate in a kubernetes.io/tls Secret ("+certKey+" and "+keyKey+")")
+	cmd.Flags().BoolVar(&o.insecure, "insecure-skip-verify", false, "connect with TLS without verifying the server certificate")
+	_ = cmd.RegisterFlagCompletionFunc("ca-from-secret", completeSecrets)
+	_ = cmd.RegisterFlagCompletionFunc("cert-from-secret", completeSecrets)
+}
+
+// set reports whether any TLS flag was given
+func (o *tlsOptions) set() bool {
+	return o.enabled || o.caSecret != "" || o.certSecret != "" || o.insecure
+}
+
+// injectTLS mounts the TLS Secrets into the runner's pods and returns the
+// tester to run, whose client connects with TLS
+func (o *testOptions) injectTLS(runner *tester.Runner, t tester.Tester) (tester.Tester, error) {
+	connector, ok := t.(tester.TLSConnector)
+	if !ok {
+		return nil, fmt.Errorf("--tls is not supported by %s", t.Name())
+	}
+	// Volumes cannot be added to an existing pod
+	if runner.FromPod != "" || runner.Agent != nil {
+		return nil, fmt.Errorf("TLS flags cannot be combined with --from-pod or --reuse")
+	}
+
+	var tls tester.TLS
+	var volumes []corev1.Volume
+	var mounts []corev1.VolumeMount
+	mount := func(volume, secret string, items ...corev1.KeyToPath) {
+		dir := path.Join(tlsMountPath, volume)
+		volumes = append(volumes, corev1.Volume{
+			Name: "pocket-tls-" + volume,
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+				SecretName: secret,
+				Items:      items,
+				// Group-readable through FSGroup, as libpq refuses keys
+				// that others may read
+				DefaultMode: ptr.To(int32(0o440)),
+			}},
+		})
+		mounts = append(mounts, corev1.VolumeMount{Name: "pocket-tls-" + volume, MountPath: dir, ReadOnly: true})
+	}
+
+	if o.tls.caSecret != "" {
+		name, key, _ := strings.Cut(o.tls.caSecret, ":")
+		if key == "" {
+			key = defaultCAKey
+		}
+		if name == "" {
+			return nil, fmt.Errorf("invalid --ca-from-secret %q (use <name>[:<key>])", o.tls.caSecret)
+		}
+		if err := checkSecretKey(runnerClient(runner), runner.Namespace, name, key); err != nil {
+			return nil, err
+		}
+		mount("ca", name, corev1.KeyToPath{Key: key, Path: defaultCAKey})
+		tls.CAFile = path.Join(tlsMountPath, "ca", defaultCAKey)
+	}
+	if o.tls.certSecret != "" {
+		for _, key := range []string{certKey, keyKey} {
+			if err := checkSecretKey(runnerClient(runner), runner.Namespace, o.tls.certSecret, key); err != nil {
+				return nil, err
+			}
+		}
+		mount("client", o.tls.certSecret, corev1.KeyToPath{Key: certKey, Path: certKey}, corev1.KeyToPath{Key: keyKey, Path: keyKey})
+		tls.CertFile = path.Join(tlsMountPath, "client", certKey)
+		tls.KeyFile = path.Join(tlsMountPath, "client", keyKey)
+	}
+	tls.InsecureSkipVerify = o.tls.insecure
+
+	connected, env := connector.WithTLS(tls)
+	configure := runner.ConfigurePod
+	runner.ConfigurePod = func(podConfig *k8s.PodConfig) error {
+		if configure != nil {
+			if err := configure(podConfig); err != nil {
+				return err
+			}
+		}
+		podConfig.Env = append(podConfig.Env, env...)
+		podConfig.Volumes = append(podConfig.Volumes, volumes...)
+		podConfig.VolumeMounts = append(podConfig.VolumeMounts, mounts...)
+		if len(volumes) > 0 && podConfig.FSGroup == nil {
+			podConfig.FSGroup = ptr.To(k8s.DefaultRunAsUser)
+		}
+		return nil
+	}
+	return connected, nil
+}
+
+// configureTester applies the credential and TLS flags to the runner and
+// returns the tester to run
+func (o *testOptions) configureTester(runner *tester.Runner, t tester.Tester) (tester.Tester, error) {
+	var err error
+	if o.passwordSecret != "" {
+		if t, err = o.injectPassword(runner, t); err != nil {
+			return nil, err
+		}
+	}
+	if o.tls.set() {
+		if t, err = o.injectTLS(runner, t); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Show resource usage of databases",
+}
+
+var topDBCmd = &cobra.Command{
+	Use:   "db (<database> | <kind>/<name>)",
+	Short: "Show CPU, memory and volume usage of a database's pods",
+	Long: `Show a health snapshot of the pods behind a database: their status,
+restarts, CPU and memory usage from metrics-server next to their limits, and
+the usage of the PersistentVolumeClaims they mount.
+
+<database> is a port-forward alias (redis, mongo, postgres or one from the
+config file) or the service or type of a database "kubectl pocket discover"
+finds, chosen among on the terminal when several services match; svc/,
+deploy/ and pod/ references name the pods directly.
+
+Volume usage is read from the kubelets through the API server's node proxy,
+which needs nodes/proxy access; without it only the claims' capacity is
+shown.
+
+Examples:
+  kubectl pocket top db postgres
+  kubectl pocket top db svc/redis-master -n cache
+  kubectl pocket top db pod/mongo-0 -o json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePortForward,
+	RunE:              runTopDB,
+}
+
+func init() {
+	topCmd.AddCommand(topDBCmd)
+	markStructuredOutput(topDBCmd)
+}
+
+// podTop is the resource usage of a database pod
+type podTop struct {
+	Pod      string `json:"pod"`
+	Node     string `json:"node,omitempty"`
+	Phase    string `json:"phase"`
+	Ready    string `json:"ready"`
+	Restarts int32  `json:"restarts"`
+	// Usage is unset when metrics-server is not available
+	CPUMillis   *int64 `json:"cpuMillis,omitempty"`
+	MemoryBytes *int64 `json:"memoryBytes,omitempty"`
+	// Limits are unset when a container has none
+	CPULimitMillis   *int64            `json:"cpuLimitMillis,omitempty"`
+	MemoryLimitBytes *int64            `json:"memoryLimitBytes,omitempty"`
+	Volumes          []k8s.VolumeUsage `json:"volumes,omitempty"`
+}
+
+func runTopDB(cmd *cobra.Command, args []string) error {
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ref, err := resolveDatabaseRef(ctx, client, args[0])
+	if err != nil {
+		return err
+	}
+
+	i18n.Printf("🔍 Collecting usage of %s in %s\n", ref, client.Namespace)
+	pods, err := resolvePods(ctx, client, ref)
+	if err != nil {
+		return fmt.Errorf("failed to resolve pods: %w", err)
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+
+	claims, err := client.ClaimUsage(ctx, client.Namespace, pods)
+	if err != nil {
+		return fmt.Errorf("failed to get volume usage: %w", err)
+	}
+
+	report := make([]podTop, 0, len(pods))
+	metricsMissing, statsMissing := false, false
+	for _, pod := range pods {
+		top := newPodTop(pod)
+		if cpu, mem, err := client.PodUsage(ctx, pod.Namespace, pod.Name); err == nil {
+			top.CPUMillis, top.MemoryBytes = &cpu, &mem
+		} else {
+			metricsMissing = true
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil {
+				continue
+			}
+			usage := claims[volume.PersistentVolumeClaim.ClaimName]
+			statsMissing = statsMissing || usage.UsedBytes < 0
+			top.Volumes = append(top.Volumes, usage)
+		}
+		report = append(report, top)
+	}
+
+	if structured() {
+		return printStructured(report)
+	}
+
+	w := newTable()
+	_, _ = fmt.Fprintln(w, "\nPOD\tSTATUS\tREADY\tRESTARTS\tCPU\tMEMORY\tVOLUMES")
+	for _, top := range report {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n", top.Pod, top.Phase, top.Ready, top.Restarts,
+			formatUsage(top.CPUMillis, top.CPULimitMillis, formatMillicores),
+			formatUsage(top.MemoryBytes, top.MemoryLimitBytes, formatBytes),
+			formatVolumes(top.Volumes))
+	}
+	_ = w.Flush()
+
+	if metricsMissing {
+		i18n.Printf("\n⚠️  metrics-server is not available; CPU and memory usage are unknown\n")
+	}
+	if statsMissing {
+		i18n.Printf("\n⚠️  Volume usage needs nodes/proxy access; showing capacity only\n")
+	}
+	return nil
+}
+
+// newPodTop fills the status and limits of pod
+func newPodTop(pod corev1.Pod) podTop {
+	top := podTop{Pod: pod.Name, Node: pod.Spec.NodeName, Phase: string(pod.Status.Phase)}
+	ready := 0
+	for _, status := range pod.Status.ContainerStatuses {
+		top.Restarts += status.RestartCount
+		if status.Ready {
+			ready++
+		}
+	}
+	top.Ready = fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers))
+
+	var cpu, memory int64
+	cpuSet, memorySet := true, true
+	for _, container := range pod.Spec.Containers {
+		if q, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
+			cpu += q.MilliValue()
+		} else {
+			cpuSet = false
+		}
+		if q, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
+			memory += q.Value()
+		} else {
+			memorySet = false
+		}
+	}
+	if cpuSet {
+		top.CPULimitMillis = &cpu
+	}
+	if memorySet {
+		top.MemoryLimitBytes = &memory
+	}
+	return top
+}
+
+// formatUsage renders usage against limit, e.g. "312.0 MiB / 1.0 GiB (30%)"
+func formatUsage(usage, limit *int64, format func(int64) string) string {
+	used := "n/a"
+	if usage != nil {
+		used = format(*usage)
+	}
+	if limit == nil {
+		return used + " / -"
+	}
+	if usage == nil || *limit == 0 {
+		return used + " / " + format(*limit)
+	}
+	return fmt.Sprintf("%s / %s (%d%%)", used, format(*limit), *usage*100 / *limit)
+}
+
+func formatMillicores(n int64) string {
+	return fmt.Sprintf("%dm", n)
+}
+
+// formatVolumes renders the usage of each claim, e.g.
+// "data-pg-0 12.3 GiB / 50.0 GiB (24%)"
+func formatVolumes(volumes []k8s.VolumeUsage) string {
+	if len(volumes) == 0 {
+		return "-"
+	}
+	parts := make([]string, len(volumes))
+	for i, volume := range volumes {
+		var used *int64
+		if volume.UsedBytes >= 0 {
+			used = &volume.UsedBytes
+		}
+		parts[i] = volume.Claim + " " + formatUsage(used, &volume.CapacityBytes, formatBytes)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	"github.com/spf13/cobra"
+)
+
+// socatImage relays TCP connections with socat, its entrypoint
+const socatImage = "alpine/socat:1.8.0.0"
+
+// tunnelRelayPort is the port the relay listens on in the pod; above 1024,
+// as the pod does not run as root
+const tunnelRelayPort = 10000
+
+var tunnelCmd = &cobra.Command{
+	Use:   "tunnel <external-host:port> [local-port]",
+	Short: "Reach an external host from the cluster's network through a relay pod",
+	Long: `Run a socat relay in a temporary pod and port-forward it locally, so that
+a host only reachable from the cluster's network, such as a managed database
+in its VPC, is reachable on localhost without a bastion. The local port
+defaults to the remote one. The pod is deleted on exit.
+
+Examples:
+  kubectl pocket tunnel mydb.abc123.eu-west-1.rds.amazonaws.com:5432
+  kubectl pocket tunnel cache.abc123.euw1.cache.amazonaws.com:6379 16379
+  kubectl pocket tunnel 10.20.0.15:27017 -n payments --service-account tunnel`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runTunnel,
+}
+
+var (
+	tunnelAddress string
+	tunnelImage   string
+)
+
+func init() {
+	tunnelCmd.Flags().StringVar(&tunnelAddress, "address", "127.0.0.1", "local address to bind")
+	tunnelCmd.Flags().StringVar(&tunnelImage, "image", "", "override the relay image (default "+socatImage+")")
+	addPodFlags(tunnelCmd)
+}
+
+func runTunnel(cmd *cobra.Command, args []string) error {
+	host, portStr, err := net.SplitHostPort(args[0])
+	if err != nil || host == "" {
+		return fmt.Errorf("the target must be host:port ([addr]:port for IPv6), got %q", args[0])
+	}
+	remotePort, err := strconv.Atoi(portStr)
+	if err != nil || remotePort < 1 || remotePort > 65535 {
+		return fmt.Errorf("invalid port: %s", portStr)
+	}
+	localPort := remotePort
+	if len(args) > 1 {
+		if localPort, err = strconv.Atoi(args[1]); err != nil || localPort < 1 || localPort > 65535 {
+			return fmt.Errorf("invalid port: %s", args[1])
+		}
+	}
+	if !portFree(tunnelAddress, localPort) {
+		return fmt.Errorf("local port %d is already in use on %s; pass another as [local-port]", localPort, tunnelAddress)
+	}
+
+	client, err := GetK8sClient()
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+	cfg, err := GetConfig()
+	if err != nil {
+		return err
+	}
+	if err := preflight(client, append(accessesRunPods, accessPortForward)...); err != nil {
+		return err
+	}
+
+	image := tunnelImage
+	if image == "" {
+		image = resolveImage(cfg, "tunnel", socatImage)
+	}
+
+	ns := client.Namespace
+	podName := fmt.Sprintf("pocket-tunnel-%d", time.Now().Unix())
+	podConfig := k8s.PodConfig{
+		Name:      podName,
+		Namespace: ns,
+		Image:     image,
+		Command: []string{"socat",
+			fmt.Sprintf("TCP-LISTEN:%d,fork,reuseaddr", tunnelRelayPort),
+			"TCP:" + net.JoinHostPort(host, portStr)},
+		Purpose: "tunnel",
+	}
+	podOpts.applyConfig(cfg)
+	if err := podOpts.apply(&podConfig); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	i18n.Printf("📦 Creating relay pod: %s/%s\n", ns, podName)
+	if _, err := client.CreatePod(ctx, podConfig); err != nil {
+		return fmt.Errorf("failed to create relay pod: %w", err)
+	}
+	defer func() {
+		i18n.Printf("🧹 Cleaning up pod: %s\n", podName)
+		cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cleanupCancel()
+		_ = client.DeletePod(cleanupCtx, ns, podName)
+	}()
+
+	i18n.Printf("⏳ Waiting for pod to be ready...\n")
+	if err := client.WaitForPodRunning(ctx, ns, podName, 2*time.Minute); err != nil {
+		return fmt.Errorf("relay pod failed to start: %w", err)
+	}
+
+	stopChan := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		close(stopChan)
+	}()
+
+	// socat only connects to the host when a connection arrives, so a
+	// host the cluster cannot reach shows up as connections being closed
+	address := net.JoinHostPort(tunnelAddress, strconv.Itoa(localPort))
+	i18n.Printf("🚇 Tunnel to %s through the cluster at %s\n", args[0], address)
+	printf("📡 %s → %s → %s\n", address, podName, args[0])
+	i18n.Printf("💡 Press Ctrl+C to stop\n\n")
+
+	return forwardWithReconnect(client, k8s.PortForwardOptions{
+		Namespace: ns,
+		PodName:   podName,
+		Addresses: []string{tunnelAddress},
+		Ports:     []string{fmt.Sprintf("%d:%d", localPort, tunnelRelayPort)},
+		StopChan:  stopChan,
+		Out:       humanOut,
+		ErrOut:    ioStreams.ErrOut,
+	}, nil)
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/release"
+	"github.com/spf13/cobra"
+)
+
+// versionCheckTimeout bounds the new version check of --version, which must
+// not hold up the command
+const versionCheckTimeout = 3 * time.Second
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade kubectl-pocket to the latest release",
+	Long: `Replace the running kubectl-pocket with the latest release from GitHub.
+The archive built for this OS and architecture is downloaded, checked against
+the release's checksums.txt and unpacked over the current executable.
+
+Installations managed by Krew are left alone; upgrade them with
+"kubectl krew upgrade pocket". GITHUB_TOKEN, when set, is sent to the GitHub
+API to raise its rate limit.
+
+Examples:
+  kubectl pocket upgrade
+  kubectl pocket upgrade --check
+  kubectl pocket upgrade --to v0.9.0 --yes`,
+	Args: cobra.NoArgs,
+	RunE: runUpgrade,
+}
+
+var (
+	upgradeCheck bool
+	upgradeTo    string
+	upgradeYes   bool
+)
+
+func init() {
+	upgradeCmd.Flags().BoolVar(&upgradeCheck, "check", false, "only report whether a new version is available")
+	upgradeCmd.Flags().StringVar(&upgradeTo, "to", "", "install this release tag instead of the latest, e.g. v0.9.0")
+	upgradeCmd.Flags().BoolVarP(&upgradeYes, "yes", "y", false, "upgrade without asking")
+}
+
+func runUpgrade(cmd *cobra.Command, args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find executable: %w", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return fmt.Errorf("failed to find executable: %w", err)
+	}
+	if krewManaged(exe) {
+		return fmt.Errorf("%s is managed by Krew; run: kubectl krew upgrade pocket", exe)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	client := release.New()
+
+	var rel *release.Release
+	if upgradeTo != "" {
+		i18n.Printf("🔍 Looking up release %s\n", upgradeTo)
+		rel, err = client.Get(ctx, upgradeTo)
+	} else {
+		i18n.Printf("🔍 Checking for a new version\n")
+		rel, err = client.Latest(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get release: %w", err)
+	}
+
+	if upgradeTo == "" && !release.Newer(rel.Tag, Version) && Version != "dev" {
+		i18n.Printf("✅ %s is the latest version\n", displayVersion(Version))
+		return nil
+	}
+	if upgradeCheck {
+		i18n.Printf("💡 %s is available (current %s): %s\n", rel.Tag, displayVersion(Version), rel.URL)
+		return nil
+	}
+
+	if !upgradeYes {
+		ok, err := confirm(i18n.T("Replace %s (%s) with %s?", exe, displayVersion(Version), rel.Tag), "--yes")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+	}
+
+	i18n.Printf("⬇️  Downloading %s\n", release.ArchiveName(runtime.GOOS, runtime.GOARCH))
+	binary, err := client.Download(ctx, rel, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", rel.Tag, err)
+	}
+	if err := release.Replace(exe, binary); err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return fmt.Errorf("failed to replace %s: %w (run again with write access to its directory)", exe, err)
+		}
+		return fmt.Errorf("failed to replace %s: %w", exe, err)
+	}
+	i18n.Printf("✅ Upgraded to %s\n", rel.Tag)
+	return nil
+}
+
+// krewManaged reports whether exe lives in Krew's store, where Krew tracks
+// the installed version itself
+func krewManaged(exe string) bool {
+	root := os.Getenv("KREW_ROOT")
+	if root == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			root = filepath.Join(home, ".krew")
+		}
+	}
+	if root != "" {
+		if resolved, err := filepath.EvalSymlinks(root); err == nil {
+			root = resolved
+		}
+		if strings.HasPrefix(exe, filepath.Join(root, "store")+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return strings.Contains(filepath.ToSlash(exe), "/.krew/store/")
+}
+
+// displayVersion renders a build version with its leading v
+func displayVersion(version string) string {
+	if version == "dev" || strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
+
+// versionRequested reports whether the command line asked for --version
+func versionRequested(rootCmd *cobra.Command) bool {
+	flag := rootCmd.Flags().Lookup("version")
+	return flag != nil && flag.Changed
+}
+
+// notifyNewVersion prints a notice to w when a release newer than this
+// build exists. Failures are ignored, as the check is only a courtesy.
+func notifyNewVersion(w io.Writer) {
+	ctx, cancel := context.WithTimeout(context.Background(), versionCheckTimeout)
+	defer cancel()
+	rel, err := release.New().Latest(ctx)
+	if err != nil || !release.Newer(rel.Tag, Version) {
+		return
+	}
+	upgrade := "kubectl pocket upgrade"
+	if exe, err := os.Executable(); err == nil && krewManaged(exe) {
+		upgrade = "kubectl krew upgrade pocket"
+	}
+	_, _ = fmt.Fprint(w, i18n.T("💡 %s is available (current %s); run: %s\n", rel.Tag, displayVersion(Version), upgrade))
+}
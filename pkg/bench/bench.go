@@ -0,0 +1,79 @@
+// Package bench builds the commands of database benchmark tools and parses
+// their reports for "pocket bench", and does the same for iperf3 for
+// "pocket net bench".
+package bench
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+)
+
+// Options shapes a benchmark run. Zero values select the tool's defaults.
+type Options struct {
+	// Clients is the number of concurrent connections
+	Clients int
+	// Requests is the total number of operations
+	Requests int
+	// Duration is how long the benchmark runs; tools that support it use
+	// it instead of Requests
+	Duration time.Duration
+}
+
+// Result is the summary of one benchmarked operation
+type Result struct {
+	// Name is the operation, e.g. SET or SELECT 1
+	Name string
+	// Operations is the number of operations completed
+	Operations int64
+	// Throughput is in operations per second
+	Throughput float64
+	// Latencies are zero when the tool does not report them
+	Avg time.Duration
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+	Max time.Duration
+}
+
+// Tool is a benchmark tool shipped in a database client image
+type Tool interface {
+	// Name is the command name (e.g. "redis")
+	Name() string
+	// Tester is the connection tester whose image provides the tool
+	Tester() tester.Tester
+	// Defaults fills the options left zero with the tool's defaults and
+	// rejects options the tool does not support
+	Defaults(opts Options) (Options, error)
+	// Command returns the container command that benchmarks target
+	Command(target string, opts Options) ([]string, error)
+	// Parse extracts the results from the tool's output
+	Parse(output string) ([]Result, error)
+}
+
+// Tools returns the supported benchmark tools
+func Tools() []Tool {
+	return []Tool{Redis{}, Postgres{}, Mongo{}}
+}
+
+// quote quotes s for safe use as a single POSIX shell word
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// millis parses a latency in milliseconds
+func millis(s string) (time.Duration, error) {
+	ms, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid latency %q", s)
+	}
+	return msDuration(ms), nil
+}
+
+// msDuration converts milliseconds to a duration
+func msDuration(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
@@ -0,0 +1,64 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Throughput is the summary of an iperf3 run
+type Throughput struct {
+	// SentBitsPerSecond and ReceivedBitsPerSecond are measured at the
+	// client and the server
+	SentBitsPerSecond     float64
+	ReceivedBitsPerSecond float64
+	// Retransmits are TCP segments the sender had to send again
+	Retransmits int64
+	// Bytes is the amount of data received
+	Bytes int64
+	// Seconds is how long the test ran
+	Seconds float64
+}
+
+// IperfCommand returns the iperf3 client command that measures throughput
+// to server for seconds over parallel streams, reporting as JSON
+func IperfCommand(server string, seconds, parallel int) []string {
+	return []string{"iperf3", "--client", server, "--json", "--time", fmt.Sprint(seconds), "--parallel", fmt.Sprint(parallel)}
+}
+
+// ParseIperf extracts the summary from iperf3's JSON report
+func ParseIperf(output string) (*Throughput, error) {
+	// Anything before the report, such as a warning, is skipped
+	if i := strings.Index(output, "{"); i > 0 {
+		output = output[i:]
+	}
+
+	var report struct {
+		Error string `json:"error"`
+		End   struct {
+			SumSent struct {
+				Seconds       float64 `json:"seconds"`
+				BitsPerSecond float64 `json:"bits_per_second"`
+				Retransmits   int64   `json:"retransmits"`
+			} `json:"sum_sent"`
+			SumReceived struct {
+				Bytes         int64   `json:"bytes"`
+				BitsPerSecond float64 `json:"bits_per_second"`
+			} `json:"sum_received"`
+		} `json:"end"`
+	}
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		return nil, fmt.Errorf("failed to parse iperf3 report: %w", err)
+	}
+	if report.Error != "" {
+		return nil, fmt.Errorf("iperf3: %s", report.Error)
+	}
+
+	return &Throughput{
+		SentBitsPerSecond:     report.End.SumSent.BitsPerSecond,
+		ReceivedBitsPerSecond: report.End.SumReceived.BitsPerSecond,
+		Retransmits:           report.End.SumSent.Retransmits,
+		Bytes:                 report.End.SumReceived.Bytes,
+		Seconds:               report.End.SumSent.Seconds,
+	}, nil
+}
@@ -0,0 +1,122 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+)
+
+// mongoMarker starts the line with a client's totals
+const mongoMarker = "@@pocket-bench "
+
+// mongoClientScript is the mongosh load loop of one client. It pings the
+// server, which loads the connection and command path without touching
+// data, and reports progress every 5s. Placeholders: client, duration in
+// milliseconds (0 for none), operation count (0 for none).
+const mongoClientScript = `const client = %d, durationMs = %d, count = %d;
+const now = () => Number(process.hrtime.bigint()) / 1e6;
+const start = now(), lat = [];
+let lastReport = start, lastOps = 0;
+while ((durationMs > 0 && now() - start < durationMs) || (count > 0 && lat.length < count)) {
+  const s = now();
+  db.runCommand({ping: 1});
+  lat.push(now() - s);
+  if (now() - lastReport >= 5000) {
+    print("client " + client + ": " + Math.round((now() - start) / 1000) + "s, " + Math.round((lat.length - lastOps) * 1000 / (now() - lastReport)) + " ops/s");
+    lastReport = now(); lastOps = lat.length;
+  }
+}
+const seconds = (now() - start) / 1000;
+lat.sort((a, b) => a - b);
+const pct = p => lat.length ? lat[Math.max(Math.ceil(p / 100 * lat.length), 1) - 1] : 0;
+print("` + mongoMarker + `" + JSON.stringify({ops: lat.length, seconds: seconds,
+  avg: lat.reduce((a, b) => a + b, 0) / Math.max(lat.length, 1), p50: pct(50), p95: pct(95), p99: pct(99), max: pct(100)}));`
+
+// mongoClient is the totals line of one client
+type mongoClient struct {
+	Ops     int64   `json:"ops"`
+	Seconds float64 `json:"seconds"`
+	Avg     float64 `json:"avg"`
+	P50     float64 `json:"p50"`
+	P95     float64 `json:"p95"`
+	P99     float64 `json:"p99"`
+	Max     float64 `json:"max"`
+}
+
+// Mongo benchmarks ping round trips with concurrent mongosh clients
+type Mongo struct{}
+
+// Name implements Tool
+func (Mongo) Name() string { return "mongo" }
+
+// Tester implements Tool
+func (Mongo) Tester() tester.Tester { return tester.Mongo{} }
+
+// Defaults implements Tool
+func (Mongo) Defaults(opts Options) (Options, error) {
+	if opts.Requests > 0 && opts.Duration > 0 {
+		return opts, fmt.Errorf("--requests and --duration cannot be combined")
+	}
+	if opts.Clients == 0 {
+		opts.Clients = 4
+	}
+	if opts.Requests == 0 && opts.Duration == 0 {
+		opts.Duration = 10 * time.Second
+	}
+	if opts.Requests > 0 && opts.Requests < opts.Clients {
+		return opts, fmt.Errorf("--requests must be at least --clients")
+	}
+	return opts, nil
+}
+
+// Command implements Tool. The clients run as parallel mongosh processes.
+func (Mongo) Command(target string, opts Options) ([]string, error) {
+	var lines []string
+	for client := 1; client <= opts.Clients; client++ {
+		script := fmt.Sprintf(mongoClientScript, client, opts.Duration.Milliseconds(), opts.Requests/opts.Clients)
+		lines = append(lines, fmt.Sprintf(`mongosh %s --quiet --eval %s & pids="$pids $!"`, quote(target), quote(script)))
+	}
+	// Fail if any client failed
+	lines = append(lines, `rc=0; for pid in $pids; do wait "$pid" || rc=1; done; exit $rc`)
+	return []string{"sh", "-c", strings.Join(lines, "\n")}, nil
+}
+
+// Parse implements Tool. Throughput is the sum over the clients; the
+// percentiles are those of the slowest client.
+func (Mongo) Parse(output string) ([]Result, error) {
+	result := Result{Name: "ping"}
+	var clients int
+	var weightedAvg float64
+	for _, line := range strings.Split(output, "\n") {
+		data, ok := strings.CutPrefix(strings.TrimSpace(line), mongoMarker)
+		if !ok {
+			continue
+		}
+		var c mongoClient
+		if err := json.Unmarshal([]byte(data), &c); err != nil {
+			return nil, fmt.Errorf("unexpected client totals %q: %w", data, err)
+		}
+
+		clients++
+		result.Operations += c.Ops
+		if c.Seconds > 0 {
+			result.Throughput += float64(c.Ops) / c.Seconds
+		}
+		weightedAvg += c.Avg * float64(c.Ops)
+		result.P50 = max(result.P50, msDuration(c.P50))
+		result.P95 = max(result.P95, msDuration(c.P95))
+		result.P99 = max(result.P99, msDuration(c.P99))
+		result.Max = max(result.Max, msDuration(c.Max))
+	}
+
+	if clients == 0 {
+		return nil, fmt.Errorf("no results in mongosh output")
+	}
+	if result.Operations > 0 {
+		result.Avg = msDuration(weightedAvg / float64(result.Operations))
+	}
+	return []Result{result}, nil
+}
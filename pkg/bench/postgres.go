@@ -0,0 +1,96 @@
+package bench
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+)
+
+// postgresScript is the pgbench transaction. It only reads, so the
+// benchmark needs no pgbench tables and changes nothing in the database.
+const postgresScript = "SELECT 1;"
+
+// Postgres benchmarks SELECT 1 round trips with pgbench
+type Postgres struct{}
+
+// Name implements Tool
+func (Postgres) Name() string { return "postgres" }
+
+// Tester implements Tool
+func (Postgres) Tester() tester.Tester { return tester.Postgres{} }
+
+// Defaults implements Tool
+func (Postgres) Defaults(opts Options) (Options, error) {
+	if opts.Requests > 0 && opts.Duration > 0 {
+		return opts, fmt.Errorf("--requests and --duration cannot be combined")
+	}
+	if opts.Clients == 0 {
+		opts.Clients = 4
+	}
+	if opts.Requests == 0 && opts.Duration == 0 {
+		opts.Duration = 10 * time.Second
+	}
+	if opts.Requests > 0 && opts.Requests < opts.Clients {
+		return opts, fmt.Errorf("--requests must be at least --clients")
+	}
+	return opts, nil
+}
+
+// Command implements Tool
+func (Postgres) Command(target string, opts Options) ([]string, error) {
+	args := []string{"pgbench", "-n", "-f", "/tmp/pocket-bench.sql", "-c", strconv.Itoa(opts.Clients),
+		"-j", strconv.Itoa(min(opts.Clients, 4)), "-P", "5"}
+	if opts.Duration > 0 {
+		args = append(args, "-T", strconv.Itoa(max(int(opts.Duration.Seconds()), 1)))
+	} else {
+		// pgbench counts transactions per client
+		args = append(args, "-t", strconv.Itoa(opts.Requests/opts.Clients))
+	}
+	args = append(args, target)
+
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quote(arg)
+	}
+	script := fmt.Sprintf("echo %s > /tmp/pocket-bench.sql && exec %s", quote(postgresScript), strings.Join(quoted, " "))
+	return []string{"sh", "-c", script}, nil
+}
+
+// Parse implements Tool. pgbench reports the average latency only.
+func (Postgres) Parse(output string) ([]Result, error) {
+	result := Result{Name: strings.TrimSuffix(postgresScript, ";")}
+	found := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "number of transactions actually processed:"):
+			value := strings.Fields(strings.TrimPrefix(line, "number of transactions actually processed:"))
+			if len(value) > 0 {
+				result.Operations, _ = strconv.ParseInt(strings.Split(value[0], "/")[0], 10, 64)
+			}
+		case strings.HasPrefix(line, "latency average ="):
+			value := strings.Fields(strings.TrimPrefix(line, "latency average ="))
+			if len(value) > 0 {
+				d, err := millis(value[0])
+				if err != nil {
+					return nil, err
+				}
+				result.Avg = d
+			}
+		case strings.HasPrefix(line, "tps ="):
+			value := strings.Fields(strings.TrimPrefix(line, "tps ="))
+			if len(value) > 0 {
+				result.Throughput, _ = strconv.ParseFloat(value[0], 64)
+				found = true
+			}
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no results in pgbench output")
+	}
+	return []Result{result}, nil
+}
@@ -0,0 +1,119 @@
+package bench
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/tester"
+)
+
+// Redis benchmarks SET and GET with redis-benchmark
+type Redis struct{}
+
+// Name implements Tool
+func (Redis) Name() string { return "redis" }
+
+// Tester implements Tool
+func (Redis) Tester() tester.Tester { return tester.Redis{} }
+
+// Defaults implements Tool
+func (Redis) Defaults(opts Options) (Options, error) {
+	if opts.Duration > 0 {
+		return opts, fmt.Errorf("redis-benchmark runs a fixed number of --requests, not a --duration")
+	}
+	if opts.Clients == 0 {
+		opts.Clients = 50
+	}
+	if opts.Requests == 0 {
+		opts.Requests = 100000
+	}
+	return opts, nil
+}
+
+// Command implements Tool
+func (Redis) Command(target string, opts Options) ([]string, error) {
+	t, err := tester.ParseRedisTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"redis-benchmark", "-h", t.Host, "-p", t.Port,
+		"-c", strconv.Itoa(opts.Clients), "-n", strconv.Itoa(opts.Requests), "-t", "set,get"}
+	if t.User != "" {
+		args = append(args, "--user", t.User)
+	}
+	if t.Password != "" {
+		args = append(args, "-a", t.Password)
+	}
+	if t.DB != "" {
+		args = append(args, "--dbnum", t.DB)
+	}
+	if t.TLS {
+		args = append(args, "--tls")
+	}
+	return args, nil
+}
+
+// Parse implements Tool. It reads the "====== <test> ======" sections of
+// redis-benchmark 7, ending in throughput and latency summaries.
+func (Redis) Parse(output string) ([]Result, error) {
+	var results []Result
+	var current *Result
+	var latencyHeader []string
+
+	for _, line := range strings.Split(output, "\n") {
+		// Progress lines are redrawn with carriage returns
+		if i := strings.LastIndex(line, "\r"); i >= 0 {
+			line = line[i+1:]
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(line, "======"):
+			results = append(results, Result{Name: strings.TrimSpace(strings.Trim(line, "="))})
+			current = &results[len(results)-1]
+			latencyHeader = nil
+		case current == nil:
+		case strings.Contains(line, "requests completed in"):
+			current.Operations, _ = strconv.ParseInt(strings.Fields(line)[0], 10, 64)
+		case strings.HasPrefix(line, "throughput summary:"):
+			fields := strings.Fields(strings.TrimPrefix(line, "throughput summary:"))
+			if len(fields) > 0 {
+				current.Throughput, _ = strconv.ParseFloat(fields[0], 64)
+			}
+		case strings.HasPrefix(line, "avg") && strings.Contains(line, "p99"):
+			latencyHeader = strings.Fields(line)
+		case latencyHeader != nil:
+			if err := current.setLatencies(latencyHeader, strings.Fields(line)); err != nil {
+				return nil, err
+			}
+			latencyHeader = nil
+		}
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no results in redis-benchmark output")
+	}
+	return results, nil
+}
+
+// setLatencies sets the latencies of a redis-benchmark latency summary
+func (r *Result) setLatencies(header, values []string) error {
+	if len(values) != len(header) {
+		return fmt.Errorf("unexpected latency summary %q", strings.Join(values, " "))
+	}
+	fields := map[string]*time.Duration{"avg": &r.Avg, "p50": &r.P50, "p95": &r.P95, "p99": &r.P99, "max": &r.Max}
+	for i, name := range header {
+		field, ok := fields[name]
+		if !ok {
+			continue
+		}
+		d, err := millis(values[i])
+		if err != nil {
+			return err
+		}
+		*field = d
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+// Package bundle packages a check run into a redacted, shareable bundle
+// that can be replayed with "pocket run --from-bundle".
+package bundle
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Version is the bundle format version
+const Version = 1
+
+// markdownFence opens the machine-readable block of a markdown bundle
+const markdownFence = "```json pocket-bundle"
+
+// Bundle describes one check run
+type Bundle struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Server is the API server the check ran against
+	Server    string   `json:"server"`
+	Namespace string   `json:"namespace"`
+	Check     Check    `json:"check"`
+	Success   bool     `json:"success"`
+	Error     string   `json:"error,omitempty"`
+	Timings   []Timing `json:"timings"`
+	Pods      []Pod    `json:"pods"`
+	// Console is what the check printed
+	Console string `json:"console"`
+}
+
+// Check is the pocket command that ran
+type Check struct {
+	// Command is the command path below pocket, e.g. ["test", "redis"]
+	Command []string `json:"command"`
+	// Args are the remaining arguments and flags
+	Args []string `json:"args"`
+}
+
+// CommandLine renders the check as a kubectl pocket command line
+func (c Check) CommandLine() string {
+	parts := append([]string{"kubectl", "pocket"}, c.Command...)
+	for _, arg := range c.Args {
+		if arg == "" || strings.ContainsAny(arg, " \t'\"$&|;<>()*?") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Timing is a point in the run
+type Timing struct {
+	Step string    `json:"step"`
+	At   time.Time `json:"at"`
+}
+
+// Pod is a pod the check created
+type Pod struct {
+	Manifest *corev1.Pod `json:"manifest"`
+	Logs     string      `json:"logs,omitempty"`
+	Events   []Event     `json:"events,omitempty"`
+}
+
+// Event is a Kubernetes event about a pod
+type Event struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Reason  string    `json:"reason"`
+	Message string    `json:"message"`
+}
+
+// Write saves the bundle to path: a gzipped tar archive for .tar.gz and
+// .tgz, gist-able markdown otherwise
+func (b *Bundle) Write(path string) error {
+	var data []byte
+	var err error
+	if strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz") {
+		data, err = b.archive()
+	} else {
+		data, err = b.Markdown()
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return nil
+}
+
+// Markdown renders the bundle for humans, with the full bundle embedded in
+// a fenced block for Read
+func (b *Bundle) Markdown() ([]byte, error) {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode bundle: %w", err)
+	}
+
+	var w bytes.Buffer
+	result := "✅ passed"
+	if !b.Success {
+		result = "❌ failed"
+	}
+	fmt.Fprintf(&w, "# pocket check: %s %s\n\n", strings.Join(b.Check.Command, " "), result)
+	fmt.Fprintf(&w, "| | |\n|---|---|\n")
+	fmt.Fprintf(&w, "| Command | `%s` |\n", b.Check.CommandLine())
+	if b.Error != "" {
+		fmt.Fprintf(&w, "| Error | %s |\n", tableCell(b.Error))
+	}
+	fmt.Fprintf(&w, "| Cluster | %s |\n", b.Server)
+	fmt.Fprintf(&w, "| Namespace | %s |\n", b.Namespace)
+	fmt.Fprintf(&w, "| Recorded | %s |\n\n", b.CreatedAt.Format(time.RFC3339))
+
+	fmt.Fprintf(&w, "## Reproduce\n\nSave this file and run:\n\n```bash\nkubectl pocket run --from-bundle <file>\n```\n\n")
+
+	if len(b.Timings) > 0 {
+		fmt.Fprintf(&w, "## Timings\n\n| Step | Elapsed |\n|---|---|\n")
+		start := b.Timings[0].At
+		for _, t := range b.Timings {
+			fmt.Fprintf(&w, "| %s | %s |\n", tableCell(t.Step), t.At.Sub(start).Round(time.Millisecond))
+		}
+		fmt.Fprintln(&w)
+	}
+
+	for _, pod := range b.Pods {
+		if pod.Manifest == nil {
+			continue
+		}
+		manifest, err := yaml.Marshal(pod.Manifest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode pod: %w", err)
+		}
+		fmt.Fprintf(&w, "## Pod %s\n\n```yaml\n%s```\n\n", pod.Manifest.Name, manifest)
+		if pod.Logs != "" {
+			fmt.Fprintf(&w, "### Logs\n\n```text\n%s\n```\n\n", strings.TrimRight(pod.Logs, "\n"))
+		}
+		if len(pod.Events) > 0 {
+			fmt.Fprintf(&w, "### Events\n\n| Time | Type | Reason | Message |\n|---|---|---|---|\n")
+			for _, e := range pod.Events {
+				fmt.Fprintf(&w, "| %s | %s | %s | %s |\n", e.Time.Format("15:04:05"), e.Type, e.Reason, tableCell(e.Message))
+			}
+			fmt.Fprintln(&w)
+		}
+	}
+
+	if b.Console != "" {
+		fmt.Fprintf(&w, "## Console output\n\n```text\n%s\n```\n\n", strings.TrimRight(b.Console, "\n"))
+	}
+
+	fmt.Fprintf(&w, "<details><summary>Bundle data</summary>\n\n%s\n%s\n```\n\n</details>\n", markdownFence, data)
+	return w.Bytes(), nil
+}
+
+// archiveFile is a file in a bundle archive
+type archiveFile struct {
+	name string
+	data []byte
+}
+
+// archive packs the bundle as bundle.json plus readable copies of its parts
+func (b *Bundle) archive() ([]byte, error) {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode bundle: %w", err)
+	}
+	markdown, err := b.Markdown()
+	if err != nil {
+		return nil, err
+	}
+
+	files := []archiveFile{
+		{"bundle.json", data},
+		{"README.md", markdown},
+		{"console.txt", []byte(b.Console)},
+	}
+	for _, pod := range b.Pods {
+		if pod.Manifest == nil {
+			continue
+		}
+		manifest, err := yaml.Marshal(pod.Manifest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode pod: %w", err)
+		}
+		files = append(files,
+			archiveFile{pod.Manifest.Name + ".yaml", manifest},
+			archiveFile{pod.Manifest.Name + ".log", []byte(pod.Logs)},
+		)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		header := &tar.Header{Name: f.name, Mode: 0o600, Size: int64(len(f.data)), ModTime: b.CreatedAt}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Read loads a bundle written by Write, in either format
+func Read(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	var raw []byte
+	switch {
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		raw, err = fromArchive(data)
+	case bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")):
+		raw = data
+	default:
+		raw, err = fromMarkdown(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var b Bundle
+	if err := json.Unmarshal(raw, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+	if b.Version > Version {
+		return nil, fmt.Errorf("bundle version %d is newer than this pocket supports (%d); upgrade pocket", b.Version, Version)
+	}
+	if len(b.Check.Command) == 0 {
+		return nil, fmt.Errorf("bundle has no check to run")
+	}
+	return &b, nil
+}
+
+func fromArchive(data []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("bundle archive has no bundle.json")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle archive: %w", err)
+		}
+		if header.Name == "bundle.json" {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+func fromMarkdown(data []byte) ([]byte, error) {
+	var raw bytes.Buffer
+	inBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case !inBlock && strings.TrimSpace(line) == markdownFence:
+			inBlock = true
+		case inBlock && strings.TrimSpace(line) == "```":
+			return raw.Bytes(), nil
+		case inBlock:
+			raw.WriteString(line)
+			raw.WriteByte('\n')
+		}
+	}
+	return nil, fmt.Errorf("no pocket bundle data found (expected a %q block)", markdownFence)
+}
+
+// tableCell makes text safe for a markdown table cell
+func tableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
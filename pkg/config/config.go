@@ -0,0 +1,342 @@
+// Package config loads and saves the kubectl-pocket configuration file.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/i18n"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// EnvPath overrides the config file location
+const EnvPath = "KUBECTL_POCKET_CONFIG"
+
+// DefaultAPIBudget is the API request budget of a command when none is
+// configured
+const DefaultAPIBudget = 500
+
+// Config holds user defaults for kubectl-pocket
+type Config struct {
+	// Namespace is used when --namespace is not given
+	Namespace string `json:"namespace,omitempty"`
+	// Timeout is the default test timeout
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Keepalive is how long an abandoned shell pod lives
+	Keepalive *metav1.Duration `json:"keepalive,omitempty"`
+	// ImageRegistry is prepended to built-in tool images
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+	// VerifyImages refuses to run images not pinned by digest
+	VerifyImages bool `json:"verifyImages,omitempty"`
+	// Locale selects the output language, e.g. "tr"
+	Locale string `json:"locale,omitempty"`
+	// APIBudget is the number of API requests a command may make before
+	// pocket warns; 0 uses DefaultAPIBudget
+	APIBudget int `json:"apiBudget,omitempty"`
+	// Testers holds per-tester overrides, keyed by tester name
+	Testers map[string]TesterConfig `json:"testers,omitempty"`
+	// Aliases defines extra port-forward aliases, keyed by alias name
+	Aliases map[string]Alias `json:"aliases,omitempty"`
+	// PodLabels and PodAnnotations are added to every pod pocket creates
+	PodLabels      map[string]string `json:"podLabels,omitempty"`
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+}
+
+// TesterConfig holds overrides for a single tester
+type TesterConfig struct {
+	Image   string           `json:"image,omitempty"`
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// Alias is a port-forward alias
+type Alias struct {
+	// Services are tried in order until one exists
+	Services []string `json:"services,omitempty"`
+	Port     int      `json:"port,omitempty"`
+}
+
+// DefaultPath returns the config file path, honoring KUBECTL_POCKET_CONFIG
+// and XDG_CONFIG_HOME
+func DefaultPath() string {
+	if env := os.Getenv(EnvPath); env != "" {
+		return env
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "kubectl-pocket", "config.yaml")
+}
+
+// Load reads the config file at path. A missing file yields an empty config.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Save writes the config to path, creating parent directories as needed
+func (c *Config) Save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// TimeoutFor returns the configured timeout for a tester, falling back to
+// the global timeout. Zero means not configured.
+func (c *Config) TimeoutFor(tester string) time.Duration {
+	if tc, ok := c.Testers[tester]; ok && tc.Timeout != nil {
+		return tc.Timeout.Duration
+	}
+	if c.Timeout != nil {
+		return c.Timeout.Duration
+	}
+	return 0
+}
+
+// RequestBudget returns the configured API request budget of a command
+func (c *Config) RequestBudget() int {
+	if c.APIBudget > 0 {
+		return c.APIBudget
+	}
+	return DefaultAPIBudget
+}
+
+// ImageFor returns the image to use for a tester: the configured override,
+// or the built-in image rewritten to the configured registry
+func (c *Config) ImageFor(tester, builtin string) string {
+	if tc, ok := c.Testers[tester]; ok && tc.Image != "" {
+		return tc.Image
+	}
+	return RewriteImage(c.ImageRegistry, builtin)
+}
+
+// RewriteImage moves image to registry. Docker Hub official images gain the
+// "library/" path so they resolve on common pull-through mirrors, e.g.
+// "redis:7-alpine" becomes "mirror.example.com/library/redis:7-alpine".
+func RewriteImage(registry, image string) string {
+	registry = strings.TrimSuffix(registry, "/")
+	if registry == "" {
+		return image
+	}
+
+	name := image
+	if first, rest, ok := strings.Cut(image, "/"); ok && isRegistryHost(first) {
+		name = rest
+	}
+	if !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	return registry + "/" + name
+}
+
+// isRegistryHost reports whether an image path component names a registry
+func isRegistryHost(component string) bool {
+	return strings.ContainsAny(component, ".:") || component == "localhost"
+}
+
+// Keys lists the settable keys, for help output
+var Keys = []string{
+	"namespace",
+	"timeout",
+	"keepalive",
+	"imageRegistry",
+	"verifyImages",
+	"locale",
+	"apiBudget",
+	"testers.<name>.image",
+	"testers.<name>.timeout",
+	"aliases.<name>.services",
+	"aliases.<name>.port",
+	"podLabels.<key>",
+	"podAnnotations.<key>",
+}
+
+// Set updates a setting by its dotted key. An empty value clears it.
+func (c *Config) Set(key, value string) error {
+	// Label and annotation keys may themselves contain dots
+	if name, ok := strings.CutPrefix(key, "podLabels."); ok && name != "" {
+		c.PodLabels = setMapValue(c.PodLabels, name, value)
+		return nil
+	}
+	if name, ok := strings.CutPrefix(key, "podAnnotations."); ok && name != "" {
+		c.PodAnnotations = setMapValue(c.PodAnnotations, name, value)
+		return nil
+	}
+
+	parts := strings.Split(key, ".")
+
+	switch {
+	case key == "namespace":
+		c.Namespace = value
+	case key == "timeout":
+		d, err := parseDuration(value)
+		if err != nil {
+			return err
+		}
+		c.Timeout = d
+	case key == "keepalive":
+		d, err := parseDuration(value)
+		if err != nil {
+			return err
+		}
+		c.Keepalive = d
+	case key == "imageRegistry":
+		c.ImageRegistry = value
+	case key == "verifyImages":
+		c.VerifyImages = false
+		if value != "" {
+			verify, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid boolean: %s", value)
+			}
+			c.VerifyImages = verify
+		}
+	case key == "locale":
+		if value != "" && !i18n.Supported(value) {
+			return fmt.Errorf("unsupported locale %q (supported: %s)", value, strings.Join(i18n.Locales(), ", "))
+		}
+		c.Locale = value
+	case key == "apiBudget":
+		c.APIBudget = 0
+		if value != "" {
+			budget, err := strconv.Atoi(value)
+			if err != nil || budget < 1 {
+				return fmt.Errorf("invalid API budget: %s", value)
+			}
+			c.APIBudget = budget
+		}
+	case len(parts) == 3 && parts[0] == "testers":
+		return c.setTester(parts[1], parts[2], value)
+	case len(parts) == 3 && parts[0] == "aliases":
+		return c.setAlias(parts[1], parts[2], value)
+	default:
+		return fmt.Errorf("unknown key %q (valid keys: %s)", key, strings.Join(Keys, ", "))
+	}
+	return nil
+}
+
+func (c *Config) setTester(name, field, value string) error {
+	tc := c.Testers[name]
+	switch field {
+	case "image":
+		tc.Image = value
+	case "timeout":
+		d, err := parseDuration(value)
+		if err != nil {
+			return err
+		}
+		tc.Timeout = d
+	default:
+		return fmt.Errorf("unknown tester setting %q (valid: image, timeout)", field)
+	}
+
+	if c.Testers == nil {
+		c.Testers = map[string]TesterConfig{}
+	}
+	c.Testers[name] = tc
+	if tc == (TesterConfig{}) {
+		delete(c.Testers, name)
+	}
+	return nil
+}
+
+func (c *Config) setAlias(name, field, value string) error {
+	alias := c.Aliases[name]
+	switch field {
+	case "services":
+		alias.Services = nil
+		for _, svc := range strings.Split(value, ",") {
+			if svc = strings.TrimSpace(svc); svc != "" {
+				alias.Services = append(alias.Services, svc)
+			}
+		}
+	case "port":
+		alias.Port = 0
+		if value != "" {
+			port, err := strconv.Atoi(value)
+			if err != nil || port < 1 || port > 65535 {
+				return fmt.Errorf("invalid port: %s", value)
+			}
+			alias.Port = port
+		}
+	default:
+		return fmt.Errorf("unknown alias setting %q (valid: services, port)", field)
+	}
+
+	if c.Aliases == nil {
+		c.Aliases = map[string]Alias{}
+	}
+	c.Aliases[name] = alias
+	if len(alias.Services) == 0 && alias.Port == 0 {
+		delete(c.Aliases, name)
+	}
+	return nil
+}
+
+// setMapValue sets or, for an empty value, deletes key in m
+func setMapValue(m map[string]string, key, value string) map[string]string {
+	if value == "" {
+		delete(m, key)
+		if len(m) == 0 {
+			return nil
+		}
+		return m
+	}
+	if m == nil {
+		m = map[string]string{}
+	}
+	m[key] = value
+	return m
+}
+
+// AliasNames returns the configured alias names, sorted
+func (c *Config) AliasNames() []string {
+	names := make([]string, 0, len(c.Aliases))
+	for name := range c.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func parseDuration(value string) (*metav1.Duration, error) {
+	if value == "" {
+		return nil, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid duration: %s", value)
+	}
+	return &metav1.Duration{Duration: d}, nil
+}
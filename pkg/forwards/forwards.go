@@ -0,0 +1,135 @@
+// Package forwards records port-forwards running in the background, one
+// JSON file per forward, so that they can be listed and stopped later.
+package forwards
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Record is a port-forward running in the background
+type Record struct {
+	ID  string `json:"id"`
+	PID int    `json:"pid"`
+	// Args are the pf arguments, e.g. ["redis", "16379"]
+	Args      []string `json:"args"`
+	Namespace string   `json:"namespace"`
+	// Mappings describe each forward, e.g. 127.0.0.1:6379 → svc/redis:6379
+	Mappings  []string  `json:"mappings"`
+	LogFile   string    `json:"logFile"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// Store is a directory of records
+type Store struct {
+	dir string
+}
+
+// DefaultDir returns the records directory under the user cache directory
+func DefaultDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "kubectl-pocket", "forwards")
+}
+
+// New returns a store backed by the directory dir
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// LogPath is where the forward id writes its output
+func (s *Store) LogPath(id string) string {
+	return filepath.Join(s.dir, id+".log")
+}
+
+// CreateLog creates the log file of the forward id, creating the directory
+// as needed
+func (s *Store) CreateLog(id string) (*os.File, error) {
+	if err := s.ensureDir(); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(s.LogPath(id), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+}
+
+// Save writes r, creating the directory as needed
+func (s *Store) Save(r Record) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := s.ensureDir(); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(r.ID), data, 0o600)
+}
+
+// Get returns the record of the forward id
+func (s *Store) Get(id string) (Record, error) {
+	var r Record
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return r, fmt.Errorf("no background port-forward %s", id)
+	}
+	if err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("failed to parse forward %s: %w", id, err)
+	}
+	return r, nil
+}
+
+// List returns all records, oldest first. Unreadable records are skipped.
+func (s *Store) List() ([]Record, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) || s.dir == "" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		if r, err := s.Get(id); err == nil {
+			records = append(records, r)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].StartedAt.Before(records[j].StartedAt) })
+	return records, nil
+}
+
+// Remove deletes the record of the forward id and its log
+func (s *Store) Remove(id string) error {
+	_ = os.Remove(s.LogPath(id))
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+func (s *Store) ensureDir() error {
+	if s.dir == "" {
+		return fmt.Errorf("no cache directory for background port-forwards")
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create forwards directory: %w", err)
+	}
+	return nil
+}
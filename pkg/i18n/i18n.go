@@ -0,0 +1,101 @@
+// Package i18n translates user-facing output. Messages are looked up by
+// their English format string, so untranslated messages fall back to
+// English as written at the call site.
+package i18n
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// EnvLocale selects the output language, overriding the config file
+const EnvLocale = "KUBECTL_POCKET_LANG"
+
+// DefaultLocale is used when no supported locale is selected
+const DefaultLocale = "en"
+
+// catalogs maps a locale to translations keyed by English format string
+var catalogs = map[string]map[string]string{
+	DefaultLocale: {},
+	"tr":          turkish,
+}
+
+// current is the active catalog
+var current = catalogs[DefaultLocale]
+
+// output receives Printf
+var output io.Writer = os.Stdout
+
+// Locales returns the supported locales, sorted
+func Locales() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Supported reports whether locale, e.g. "tr" or "tr_TR.UTF-8", has a
+// catalog
+func Supported(locale string) bool {
+	_, ok := catalogs[normalize(locale)]
+	return ok
+}
+
+// SetLocale switches the output language. Unsupported locales select
+// English.
+func SetLocale(locale string) {
+	catalog, ok := catalogs[normalize(locale)]
+	if !ok {
+		catalog = catalogs[DefaultLocale]
+	}
+	current = catalog
+}
+
+// Detect picks the locale from KUBECTL_POCKET_LANG, then the configured
+// locale, then the POSIX locale variables
+func Detect(configured string) string {
+	candidates := []string{os.Getenv(EnvLocale), configured, os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")}
+	for _, locale := range candidates {
+		if locale != "" {
+			return normalize(locale)
+		}
+	}
+	return DefaultLocale
+}
+
+// normalize reduces a POSIX or BCP 47 locale to its language, e.g.
+// "tr_TR.UTF-8" and "tr-TR" to "tr". The C and POSIX locales are English.
+func normalize(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "_-.@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if locale == "c" || locale == "posix" {
+		return DefaultLocale
+	}
+	return locale
+}
+
+// T translates format and formats it with args like fmt.Sprintf.
+// Translations may reorder arguments with explicit indexes such as %[2]s.
+func T(format string, args ...any) string {
+	if translated, ok := current[format]; ok {
+		format = translated
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// SetOutput sets where Printf writes; the default is stdout
+func SetOutput(w io.Writer) {
+	output = w
+}
+
+// Printf translates format and writes it to the output set by SetOutput
+func Printf(format string, args ...any) {
+	_, _ = fmt.Fprint(output, T(format, args...))
+}
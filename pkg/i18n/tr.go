@@ -0,0 +1,201 @@
+package i18n
+
+// turkish is the Turkish (tr) catalog
+var turkish = map[string]string{
+	"📦 Creating test pod: %s/%s\n":                                "📦 Test pod'u oluşturuluyor: %s/%s\n",
+	"⏳ Waiting for connection test...\n":                          "⏳ Bağlantı testi bekleniyor...\n",
+	"🧹 Cleaning up pod: %s\n":                                     "🧹 Pod temizleniyor: %s\n",
+	"\n🧹 Cleaning up pod: %s\n":                                   "\n🧹 Pod temizleniyor: %s\n",
+	"✅ %s connection successful (cached result from %s ago)\n":    "✅ %s bağlantısı başarılı (%s önceki sonuç önbellekten)\n",
+	"💡 No successful result within %s, running test\n":            "💡 Son %s içinde başarılı sonuç yok, test çalıştırılıyor\n",
+	"🔍 Testing %s connection: %s\n":                               "🔍 %s bağlantısı test ediliyor: %s\n",
+	"✅ %s connection successful!\n":                               "✅ %s bağlantısı başarılı!\n",
+	"📝 Output:\n%s\n":                                             "📝 Çıktı:\n%s\n",
+	"❌ %s connection failed!\n":                                   "❌ %s bağlantısı başarısız!\n",
+	"📝 Error output:\n%s\n":                                       "📝 Hata çıktısı:\n%s\n",
+	"📦 Creating pod: %s/%s\n":                                     "📦 Pod oluşturuluyor: %s/%s\n",
+	"⏳ Waiting for pod to be ready...\n":                          "⏳ Pod'un hazır olması bekleniyor...\n",
+	"✅ Connected! %s\n\n":                                         "✅ Bağlandı! %s\n\n",
+	"🚀 Starting %s shell: %s\n":                                   "🚀 %s kabuğu başlatılıyor: %s\n",
+	"🔌 Port-forwarding to %s\n":                                   "🔌 %s için port yönlendirme\n",
+	"💡 Press Ctrl+C to stop\n\n":                                  "💡 Durdurmak için Ctrl+C'ye basın\n\n",
+	"📦 Creating relay pod: %s/%s\n":                               "📦 Aktarma pod'u oluşturuluyor: %s/%s\n",
+	"⏳ Waiting for SSH tunnel via %s...\n":                        "⏳ %s üzerinden SSH tüneli bekleniyor...\n",
+	"🔌 Port-forwarding to %s via %s\n":                            "🔌 %[2]s üzerinden %[1]s için port yönlendirme\n",
+	"🔄 Connection to pod/%s lost (%v), reconnecting (%d/%d)...\n": "🔄 pod/%s ile bağlantı koptu (%v), yeniden bağlanılıyor (%d/%d)...\n",
+	"🔍 Checking namespace constraints in %s\n":                    "🔍 %s namespace'indeki kısıtlamalar kontrol ediliyor\n",
+	"💡 Adjusted resources: %s\n":                                  "💡 Ayarlanan kaynaklar: %s\n",
+	"❌ The API server rejects the pod:\n%s\n":                     "❌ API sunucusu pod'u reddediyor:\n%s\n",
+	"💡 Not explained by quotas, limit ranges or Pod Security; check admission webhooks and policies\n": "💡 Kotalar, limit aralıkları veya Pod Security ile açıklanamıyor; admission webhook'larını ve politikaları kontrol edin\n",
+	"⚠️  The dry run passed, but %d constraint(s) above may still reject the pod\n":                    "⚠️  Deneme çalıştırması geçti, ancak yukarıdaki %d kısıtlama pod'u yine de reddedebilir\n",
+	"✅ Namespace %s admits the pod\n":          "✅ %s namespace'i pod'u kabul ediyor\n",
+	"🎯 Primary pod: %s/%s (node %s)\n":         "🎯 Birincil pod: %s/%s (node %s)\n",
+	"Delete pod %s/%s to drill failover?":      "Failover tatbikatı için %s/%s pod'u silinsin mi?",
+	"❓ %s [y/N] ":                              "❓ %s [e/H] ",
+	"y":                                        "e",
+	"yes":                                      "evet",
+	"💡 Aborted, nothing was deleted\n":         "💡 İptal edildi, hiçbir şey silinmedi\n",
+	"📦 Creating probe pod: %s/%s\n":            "📦 Yoklama pod'u oluşturuluyor: %s/%s\n",
+	"⏳ Waiting for probe pod to be ready...\n": "⏳ Yoklama pod'unun hazır olması bekleniyor...\n",
+	"🔍 Checking baseline %s connection: %s\n":  "🔍 Başlangıç %s bağlantısı kontrol ediliyor: %s\n",
+	"❌ Baseline connection failed; not deleting anything\n":    "❌ Başlangıç bağlantısı başarısız; hiçbir şey silinmiyor\n",
+	"💥 Deleting pod %s\n":                                      "💥 %s pod'u siliniyor\n",
+	"📝 Primary is now %s (node %s)\n":                          "📝 Yeni birincil: %s (node %s)\n",
+	"  %s  OK    %s\n":                                         "  %s  TAMAM %s\n",
+	"  %s  FAIL  %s\n":                                         "  %s  HATA  %s\n",
+	"❌ Connectivity did not recover (%d of %d tests failed)\n": "❌ Bağlantı düzelmedi (%[2]d testten %[1]d tanesi başarısız)\n",
+	"✅ No interruption observed across %d tests\n":             "✅ %d test boyunca kesinti gözlenmedi\n",
+	"✅ Connectivity recovered\n":                               "✅ Bağlantı düzeldi\n",
+	"📝 Time to recovery: %s after deletion\n":                  "📝 Düzelme süresi: silmeden %s sonra\n",
+	"📝 Outage window:    %s (%d of %d tests failed)\n":         "📝 Kesinti süresi:   %[1]s (%[3]d testten %[2]d tanesi başarısız)\n",
+	"🔍 Discovering databases in %s\n":                          "🔍 %s içinde veritabanları aranıyor\n",
+	"namespace %s":                                             "%s namespace'i",
+	"all namespaces":                                           "tüm namespace'ler",
+	"\n💡 Test with:\n":                                         "\n💡 Test etmek için:\n",
+	"⚠️  No known_hosts file found; the bastion's host key will not be verified\n": "⚠️  known_hosts dosyası bulunamadı; bastion'ın host anahtarı doğrulanmayacak\n",
+	"🔐 Tunneling through %s to %s\n":                                               "🔐 %s üzerinden %s adresine tünel açılıyor\n",
+	"💡 Using %s ClusterIP %s of service %s\n":                                      "💡 %[3]s servisinin %[1]s ClusterIP adresi %[2]s kullanılıyor\n",
+	"skipped: no service":                                                          "atlandı: servis yok",
+	"no tester for %s":                                                             "%s için test aracı yok",
+	"❌ %d of %d database(s) failed\n":                                              "❌ %[2]d veritabanından %[1]d tanesi başarısız\n",
+	"✅ All %d database(s) reachable\n":                                             "✅ %d veritabanının tümüne erişilebiliyor\n",
+	"🔍 Simulating admission of %s in %s\n":                                         "🔍 %[2]s içinde %[1]s için admission simülasyonu yapılıyor\n",
+	"\n📝 Admission would change the pod:\n":                                        "\n📝 Admission pod'u şöyle değiştirir:\n",
+	"✅ Admission allows the pod in %s\n":                                           "✅ Admission %s içinde pod'a izin veriyor\n",
+	"❌ Denied by %s\n":                                                             "❌ %s tarafından reddedildi\n",
+	"💡 Add the required labels with --pod-label key=value (or podLabels in the config file)\n":                "💡 Gerekli etiketleri --pod-label key=value ile (veya yapılandırma dosyasındaki podLabels ile) ekleyin\n",
+	"💡 Add the required annotations with --pod-annotation key=value (or podAnnotations in the config file)\n": "💡 Gerekli annotation'ları --pod-annotation key=value ile (veya yapılandırma dosyasındaki podAnnotations ile) ekleyin\n",
+	"💡 Choose the pod's user with --run-as-user, or --allow-root where root is allowed\n":                     "💡 Pod'un kullanıcısını --run-as-user ile seçin, root'a izin verilen yerlerde --allow-root kullanın\n",
+	"💡 Use an allowed image with --image, or set imageRegistry in the config file\n":                          "💡 --image ile izin verilen bir imaj kullanın veya yapılandırma dosyasında imageRegistry ayarlayın\n",
+	"💡 Adjust resources with --cpu and --memory, or run 'kubectl pocket quota check --adjust'\n":              "💡 Kaynakları --cpu ve --memory ile ayarlayın veya 'kubectl pocket quota check --adjust' çalıştırın\n",
+	"📋 Running %d check(s) from %s\n":               "📋 %[2]s dosyasından %[1]d kontrol çalıştırılıyor\n",
+	"timed out after %s":                            "%s sonra zaman aşımı",
+	"connected, but expected to fail":               "bağlandı, ancak başarısız olması bekleniyordu",
+	"❌ %d of %d check(s) failed\n":                  "❌ %[2]d kontrolden %[1]d tanesi başarısız\n",
+	"✅ All %d check(s) passed\n":                    "✅ %d kontrolün tümü başarılı\n",
+	"🔍 Resolving %s %s/%s\n":                        "🔍 %s %s/%s çözümleniyor\n",
+	"⏳ Probing %d hop(s)...\n":                      "⏳ %d atlama test ediliyor...\n",
+	"route configuration":                           "route yapılandırması",
+	"gateway":                                       "gateway",
+	"backend":                                       "backend",
+	"❌ Traffic breaks in the %s\n":                  "❌ Trafik şurada kesiliyor: %s\n",
+	"✅ Traffic flows through %s %s\n":               "✅ Trafik %s %s üzerinden akıyor\n",
+	"👀 Testing every %s, press Ctrl+C to stop\n":    "👀 Her %s bir test ediliyor, durdurmak için Ctrl+C'ye basın\n",
+	"📝 %d test(s) over %s: %d failed, %d flap(s)\n": "📝 %[2]s boyunca %[1]d test: %[3]d başarısız, %[4]d durum değişikliği\n",
+	"✅ %s connection stayed up\n":                   "✅ %s bağlantısı kesintisiz sürdü\n",
+	"📝 Longest outage: %s\n":                        "📝 En uzun kesinti: %s\n",
+	"\n📊 API requests: %d (%d throttled)\n":         "\n📊 API istekleri: %d (%d tanesi kısıtlandı)\n",
+	"⚠️  This command made %d API requests, over the budget of %d (apiBudget in the config file)\n":       "⚠️  Bu komut %d API isteği yaptı, %d olan bütçenin üzerinde (yapılandırma dosyasında apiBudget)\n",
+	"⚠️  The API server throttled %d request(s); run with --api-usage for details\n":                      "⚠️  API sunucusu %d isteği kısıtladı; ayrıntılar için --api-usage ile çalıştırın\n",
+	"⏱️  Running %d test(s) from %s/%s\n":                                                                 "⏱️  %[2]s/%[3]s üzerinden %[1]d test çalıştırılıyor\n",
+	"📝 %d passed: min %s  avg %s  p95 %s  p99 %s  max %s\n":                                               "📝 %d başarılı: min %s  ort %s  p95 %s  p99 %s  maks %s\n",
+	"❌ %d of %d test(s) failed, last error: %v\n":                                                         "❌ %d/%d test başarısız, son hata: %v\n",
+	"✅ %s connection successful on all %d test(s)\n":                                                      "✅ %s bağlantısı %d testin tamamında başarılı\n",
+	"🏁 Benchmarking %s with %d client(s) for %s: %s\n":                                                    "🏁 %[1]s %[2]d istemciyle %[3]s boyunca ölçülüyor: %[4]s\n",
+	"🏁 Benchmarking %s with %d client(s), %d request(s): %s\n":                                            "🏁 %[1]s %[2]d istemci ve %[3]d istekle ölçülüyor: %[4]s\n",
+	"📊 Benchmark summary:\n":                                                                              "📊 Performans özeti:\n",
+	"⏳ Waiting for query result...\n":                                                                     "⏳ Sorgu sonucu bekleniyor...\n",
+	"🔎 Querying %s: %s\n":                                                                                 "🔎 %s sorgulanıyor: %s\n",
+	"📜 Running script %s\n":                                                                               "📜 Betik çalıştırılıyor: %s\n",
+	"💾 Dumping %s: %s\n":                                                                                  "💾 %s dökümü alınıyor: %s\n",
+	"✅ Wrote %s to %s in %s\n":                                                                            "✅ %[1]s, %[3]s içinde %[2]s dosyasına yazıldı\n",
+	"Restore %s (%s) into %s %s? Existing data may be overwritten.":                                       "%[1]s (%[2]s) %[3]s %[4]s içine geri yüklensin mi? Mevcut veriler değişebilir.",
+	"💡 Aborted, nothing was restored\n":                                                                   "💡 İptal edildi, hiçbir şey geri yüklenmedi\n",
+	"📤 Restoring %s into %s: %s\n":                                                                        "📤 %[1]s, %[2]s içine geri yükleniyor: %[3]s\n",
+	"📤 Sent %s of %s (%d%%)\n":                                                                            "📤 %[2]s içinden %[1]s gönderildi (%%%[3]d)\n",
+	"✅ Restored %s in %s\n":                                                                               "✅ %[1]s, %[2]s içinde geri yüklendi\n",
+	"🔌 Port-forwarding to %d targets\n\n":                                                                 "🔌 %d hedef için port yönlendirme\n\n",
+	"\n💡 Press Ctrl+C to stop\n\n":                                                                        "\n💡 Durdurmak için Ctrl+C'ye basın\n\n",
+	"❌ Port-forward to %s failed: %v\n":                                                                   "❌ %s için port yönlendirme başarısız: %v\n",
+	"⚠️  Local port %d is in use, using %d for %s\n":                                                      "⚠️  Yerel port %[1]d kullanımda, %[3]s için %[2]d kullanılıyor\n",
+	"✅ Reconnected to pod/%s\n":                                                                           "✅ pod/%s ile yeniden bağlanıldı\n",
+	"🔁 %s moved to pod/%s\n":                                                                              "🔁 %s, pod/%s üzerine taşındı\n",
+	"🔌 Starting port-forward to %s in the background\n":                                                   "🔌 %s için port yönlendirme arka planda başlatılıyor\n",
+	"✅ Running in the background as %s (pid %d), logs in %s\n":                                            "✅ Arka planda %s olarak çalışıyor (pid %d), günlükler: %s\n",
+	"💡 Stop it with: kubectl pocket pf stop %s\n":                                                         "💡 Durdurmak için: kubectl pocket pf stop %s\n",
+	"💡 No port-forwards are running in the background\n":                                                  "💡 Arka planda çalışan port yönlendirme yok\n",
+	"🛑 Stopped port-forward %s (%s)\n":                                                                    "🛑 %s port yönlendirmesi durduruldu (%s)\n",
+	"📦 Creating proxy pod: %s/%s\n":                                                                       "📦 Proxy pod'u oluşturuluyor: %s/%s\n",
+	"🧦 SOCKS5 proxy into the cluster at %s\n":                                                             "🧦 Kümeye SOCKS5 proxy: %s\n",
+	"💡 e.g. curl --socks5-hostname %s http://<service>.<namespace>.svc.cluster.local\n":                   "💡 örn. curl --socks5-hostname %s http://<servis>.<namespace>.svc.cluster.local\n",
+	"⚠️  Could not add an ephemeral container (%v); capturing on node %s instead\n":                       "⚠️  Geçici konteyner eklenemedi (%v); bunun yerine %s düğümünde yakalanıyor\n",
+	"📦 Adding capture container %s to pod %s\n":                                                           "📦 %[2]s pod'una %[1]s yakalama konteyneri ekleniyor\n",
+	"📦 Creating capture pod on node %s: %s/%s\n":                                                          "📦 %s düğümünde yakalama pod'u oluşturuluyor: %s/%s\n",
+	"🦈 Capturing traffic of %s (Ctrl+C to stop)\n":                                                        "🦈 %s trafiği yakalanıyor (durdurmak için Ctrl+C)\n",
+	"⏳ Waiting for pods to be ready...\n":                                                                 "⏳ Pod'ların hazır olması bekleniyor...\n",
+	"⚠️  Both pods run on node %s; pass --from-node and --to-node to measure between nodes\n":             "⚠️  İki pod da %s düğümünde çalışıyor; düğümler arası ölçüm için --from-node ve --to-node verin\n",
+	"🏁 Measuring throughput from %s to %s for %s...\n":                                                    "🏁 %s → %s aktarım hızı %s boyunca ölçülüyor...\n",
+	"\n📊 Throughput from %s/%s to %s/%s:\n":                                                               "\n📊 %s/%s → %s/%s aktarım hızı:\n",
+	"   Sent:        %s\n":                                                                                "   Gönderilen:  %s\n",
+	"   Received:    %s\n":                                                                                "   Alınan:      %s\n",
+	"   Retransmits: %d\n":                                                                                "   Yeniden gönderim: %d\n",
+	"   Transferred: %s in %.1fs\n":                                                                       "   Aktarılan:   %.1[2]fs içinde %[1]s\n",
+	"📦 Creating debug pod on node %s: %s/%s\n":                                                            "📦 %s düğümünde hata ayıklama pod'u oluşturuluyor: %s/%s\n",
+	"✅ Connected to node %s as root. Exit the shell to clean up.\n\n":                                     "✅ %s düğümüne root olarak bağlanıldı. Temizlemek için kabuktan çıkın.\n\n",
+	"📦 Adding debug container %s to pod %s\n":                                                             "📦 %[2]s pod'una %[1]s hata ayıklama konteyneri ekleniyor\n",
+	"✅ Attached to %s in pod %s. If you don't see a prompt, press Enter.\n\n":                             "✅ %[2]s pod'undaki %[1]s konteynerine bağlanıldı. İstem görünmüyorsa Enter'a basın.\n\n",
+	"🎯 Running tests from pod %s/%s\n":                                                                    "🎯 Testler %s/%s pod'undan çalıştırılıyor\n",
+	"✅ Attached to pod %s/%s. Exit the shell to detach; the pod keeps running.\n\n":                       "✅ %s/%s pod'una bağlanıldı. Ayrılmak için kabuktan çıkın; pod çalışmaya devam eder.\n\n",
+	"📌 Kept pod %s/%s; reattach with: kubectl pocket attach %s\n":                                         "📌 %s/%s pod'u tutuldu; yeniden bağlanmak için: kubectl pocket attach %s\n",
+	"💡 No running agent pod in %s, using a temporary pod (start one with 'kubectl pocket agent start')\n": "💡 %s içinde çalışan ajan pod'u yok, geçici pod kullanılıyor (başlatmak için: 'kubectl pocket agent start')\n",
+	"♻️  Using agent pod: %s/%s\n":                                                                        "♻️  Ajan pod'u kullanılıyor: %s/%s\n",
+	"✅ Agent pod %s/%s is already running\n":                                                              "✅ %s/%s ajan pod'u zaten çalışıyor\n",
+	"📦 Creating agent pod: %s/%s\n":                                                                       "📦 Ajan pod'u oluşturuluyor: %s/%s\n",
+	"✅ Agent pod %s/%s is running with %s; pass --reuse to tests to use it\n":                             "✅ %s/%s ajan pod'u %s ile çalışıyor; kullanmak için testlere --reuse verin\n",
+	"💡 No agent pod in %s\n":                                                                              "💡 %s içinde ajan pod'u yok\n",
+	"🧹 Deleted agent pod %s/%s\n":                                                                         "🧹 %s/%s ajan pod'u silindi\n",
+	"would delete":                                                                                        "silinecek",
+	"deleted":                                                                                             "silindi",
+	"✨ No pocket pods to clean up\n":                                                                      "✨ Temizlenecek pocket pod'u yok\n",
+	"💡 Dry run: %d pod(s) would be deleted\n":                                                             "💡 Deneme çalıştırması: %d pod silinecek\n",
+	"🧹 Deleted %d pod(s)\n":                                                                               "🧹 %d pod silindi\n",
+	"\n📋 Events of pod %s/%s:\n":                                                                          "\n📋 %s/%s pod'unun olayları:\n",
+	"💡 The test pod could not start, so the connection was not tested; this is a cluster problem, not a failed connection\n": "💡 Test pod'u başlatılamadığı için bağlantı test edilmedi; bu bir küme sorunudur, başarısız bir bağlantı değil\n",
+	"🔁 Attempt %d/%d succeeded in %s\n":                                         "🔁 Deneme %d/%d %s içinde başarılı oldu\n",
+	"🔁 Attempt %d/%d failed in %s: %s; retrying in %s\n":                        "🔁 Deneme %d/%d %s içinde başarısız oldu: %s; %s sonra yeniden denenecek\n",
+	"🔁 Attempt %d/%d failed in %s: %s\n":                                        "🔁 Deneme %d/%d %s içinde başarısız oldu: %s\n",
+	"📅 Waiting for a node...\n":                                                 "📅 Düğüm bekleniyor...\n",
+	"📥 Pulling image and starting container...\n":                               "📥 İmaj çekiliyor ve konteyner başlatılıyor...\n",
+	"🔍 Testing %s connection in %d contexts: %s\n":                              "🔍 %s bağlantısı %d bağlamda test ediliyor: %s\n",
+	"❌ %s connection failed in %d of %d contexts\n":                             "❌ %s bağlantısı %d/%d bağlamda başarısız oldu\n",
+	"✅ %s connection successful in all %d contexts\n":                           "✅ %s bağlantısı %d bağlamın tümünde başarılı\n",
+	"🔐 Checking permissions in %s\n":                                            "🔐 %s içindeki izinler denetleniyor\n",
+	"❌ %d of %d permission(s) missing\n":                                        "❌ %d/%d izin eksik\n",
+	"✅ All %d permission(s) granted\n":                                          "✅ %d iznin tümü verilmiş\n",
+	"🚇 Tunnel to %s through the cluster at %s\n":                                "🚇 %s tüneli küme üzerinden %s adresinde\n",
+	"🔍 Collecting usage of %s in %s\n":                                          "🔍 %[2]s içinde %[1]s kullanımı toplanıyor\n",
+	"\n⚠️  metrics-server is not available; CPU and memory usage are unknown\n": "\n⚠️  metrics-server kullanılamıyor; CPU ve bellek kullanımı bilinmiyor\n",
+	"\n⚠️  Volume usage needs nodes/proxy access; showing capacity only\n":      "\n⚠️  Birim kullanımı nodes/proxy erişimi gerektirir; yalnızca kapasite gösteriliyor\n",
+	"📜 Logs of %s: %v\n":                                                        "📜 %s günlükleri: %v\n",
+	"⚠️  Results were not exported: %v\n":                                       "⚠️  Sonuçlar dışa aktarılamadı: %v\n",
+	"💡 Several %s match: %s; using %s (%s)\n":                                   "💡 Birden fazla %s eşleşiyor: %s; %s kullanılıyor (%s)\n",
+	"Several %s match; choose one":                                              "Birden fazla %s eşleşiyor; birini seçin",
+	"(↑/↓ to move, type to filter, Enter to choose, Esc to cancel)":             "(↑/↓ ile gezin, filtrelemek için yazın, seçmek için Enter, iptal için Esc)",
+	"  no match":                                "  eşleşme yok",
+	"%s services":                               "%s servisi",
+	"pass svc/<name> to choose":                 "seçmek için svc/<ad> verin",
+	"🔍 Looking up release %s\n":                 "🔍 %s sürümü aranıyor\n",
+	"🔍 Checking for a new version\n":            "🔍 Yeni sürüm denetleniyor\n",
+	"✅ %s is the latest version\n":              "✅ %s en son sürüm\n",
+	"💡 %s is available (current %s): %s\n":      "💡 %s yayınlandı (mevcut %s): %s\n",
+	"Replace %s (%s) with %s?":                  "%s (%s), %s ile değiştirilsin mi?",
+	"⬇️  Downloading %s\n":                      "⬇️  %s indiriliyor\n",
+	"✅ Upgraded to %s\n":                        "✅ %s sürümüne yükseltildi\n",
+	"💡 %s is available (current %s); run: %s\n": "💡 %s yayınlandı (mevcut %s); çalıştırın: %s\n",
+	"🎥 Recording the session to %s\n":           "🎥 Oturum %s dosyasına kaydediliyor\n",
+	"🎭 Testing as %s in namespace %s (service account %s, %d labels)\n": "🎭 %s olarak %s ad alanında test ediliyor (servis hesabı %s, %d etiket)\n",
+	"%d/%d done":   "%d/%d tamamlandı",
+	"%d failed":    "%d başarısız",
+	"running: %s":  "çalışıyor: %s",
+	"%s pod in %s": "%[2]s içinde %[1]s pod'u",
+	"🛑 Interrupted; the run's pods were deleted\n": "🛑 Kesildi; çalıştırmanın pod'ları silindi\n",
+	"✨ No pocket pods running\n":                   "✨ Çalışan pocket pod'u yok\n",
+	"built-in":                                     "yerleşik",
+	"config":                                       "yapılandırma",
+	"no":                                           "hayır",
+	"\n⚠️  %d image(s) are not pinned by digest; --verify-images refuses to run them\n": "\n⚠️  %d imaj digest ile sabitlenmemiş; --verify-images bunları çalıştırmayı reddeder\n",
+	"⚠️  %s is not pinned by digest; --verify-images refuses to run it\n":               "⚠️  %s digest ile sabitlenmemiş; --verify-images onu çalıştırmayı reddeder\n",
+	"Run this check?":              "Bu kontrol çalıştırılsın mı?",
+	"💡 Aborted, nothing was run\n": "💡 İptal edildi, hiçbir şey çalıştırılmadı\n",
+	"💡 No databases found\n":       "💡 Veritabanı bulunamadı\n",
+}
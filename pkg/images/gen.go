@@ -0,0 +1,171 @@
+//go:build ignore
+
+// gen resolves the digest of every image in manifest.json from its registry
+// and writes them back. Run it with "go generate ./pkg/images" when adding or
+// bumping a built-in image, and commit the result.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const manifestFile = "manifest.json"
+
+// manifestTypes are accepted so multi-arch images resolve to their index
+var manifestTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+func main() {
+	data, err := os.ReadFile(manifestFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	manifest := map[string]string{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		log.Fatalf("failed to parse %s: %v", manifestFile, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	for ref := range manifest {
+		digest, err := resolve(ctx, ref)
+		if err != nil {
+			log.Fatalf("failed to resolve %s: %v", ref, err)
+		}
+		if manifest[ref] != digest {
+			log.Printf("%s: %s", ref, digest)
+		}
+		manifest[ref] = digest
+	}
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(manifestFile, out.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// resolve returns the digest ref's tag points to
+func resolve(ctx context.Context, ref string) (string, error) {
+	registry, repository, tag := parseRef(ref)
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", registry, repository, tag)
+
+	resp, err := head(ctx, manifestURL, "")
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := anonymousToken(ctx, resp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return "", err
+		}
+		if resp, err = head(ctx, manifestURL, token); err != nil {
+			return "", err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry returned %s", resp.Status)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if !strings.HasPrefix(digest, "sha256:") {
+		return "", fmt.Errorf("registry returned no digest")
+	}
+	return digest, nil
+}
+
+// parseRef splits an image reference into its registry, repository and tag,
+// applying Docker Hub's defaults
+func parseRef(ref string) (registry, repository, tag string) {
+	registry, repository = "registry-1.docker.io", ref
+	if first, rest, ok := strings.Cut(ref, "/"); ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		registry, repository = first, rest
+	}
+	tag = "latest"
+	if i := strings.LastIndex(repository, ":"); i > 0 {
+		repository, tag = repository[:i], repository[i+1:]
+	}
+	if registry == "registry-1.docker.io" && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	return registry, repository, tag
+}
+
+func head(ctx context.Context, target, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestTypes, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	return resp, nil
+}
+
+// anonymousToken gets a pull token from the realm of a Bearer challenge
+func anonymousToken(ctx context.Context, challenge string) (string, error) {
+	params, ok := strings.CutPrefix(challenge, "Bearer ")
+	if !ok {
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+	query := url.Values{}
+	var realm string
+	for _, param := range strings.Split(params, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		value = strings.Trim(value, `"`)
+		if key == "realm" {
+			realm = value
+		} else {
+			query.Set(key, value)
+		}
+	}
+	if realm == "" {
+		return "", fmt.Errorf("authentication challenge has no realm")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request returned %s", resp.Status)
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
@@ -0,0 +1,57 @@
+// Package images pins the tool images pocket runs by digest, so a tag moved
+// upstream does not change what runs in the cluster. The manifest maps each
+// built-in image reference to its digest; "go generate" resolves the digests
+// of every listed reference from its registry.
+package images
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+)
+
+//go:generate go run gen.go
+
+//go:embed manifest.json
+var manifestData []byte
+
+// manifest maps built-in image references to their digests
+var manifest = func() map[string]string {
+	m := map[string]string{}
+	if err := json.Unmarshal(manifestData, &m); err != nil {
+		panic("invalid image manifest: " + err.Error())
+	}
+	return m
+}()
+
+// Listed reports whether the manifest has an entry for ref, with or without
+// a digest
+func Listed(ref string) bool {
+	_, ok := manifest[ref]
+	return ok
+}
+
+// Digest returns the digest ref is pinned to in the manifest, or "" if it is
+// not pinned
+func Digest(ref string) string {
+	return manifest[ref]
+}
+
+// Pin returns ref pinned to its digest from the manifest, e.g.
+// "redis:7-alpine@sha256:…". References without a digest in the manifest
+// are returned as given.
+func Pin(ref string) string {
+	if Pinned(ref) {
+		return ref
+	}
+	if digest := manifest[ref]; digest != "" {
+		return ref + "@" + digest
+	}
+	return ref
+}
+
+// Pinned reports whether ref names an image by digest
+func Pinned(ref string) bool {
+	_, digest, ok := strings.Cut(ref, "@")
+	return ok && strings.Contains(digest, ":")
+}
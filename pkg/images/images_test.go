@@ -0,0 +1,56 @@
+package images
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPinned(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want bool
+	}{
+		{"redis:7-alpine", false},
+		{"mirror.example.com:5000/library/redis:7-alpine", false},
+		{"redis:7-alpine@sha256:0123abcd", true},
+		{"redis@sha256:0123abcd", true},
+		{"redis@", false},
+	}
+	for _, tt := range tests {
+		if got := Pinned(tt.ref); got != tt.want {
+			t.Errorf("Pinned(%q) = %v, want %v", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestPin(t *testing.T) {
+	saved := manifest
+	defer func() { manifest = saved }()
+	manifest = map[string]string{"redis:7-alpine": "sha256:0123abcd", "busybox:1.36": ""}
+
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"redis:7-alpine", "redis:7-alpine@sha256:0123abcd"},
+		{"redis:7-alpine@sha256:ffff", "redis:7-alpine@sha256:ffff"},
+		{"busybox:1.36", "busybox:1.36"},
+		{"mongo:7", "mongo:7"},
+	}
+	for _, tt := range tests {
+		if got := Pin(tt.ref); got != tt.want {
+			t.Errorf("Pin(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestManifestEntries(t *testing.T) {
+	for ref, digest := range manifest {
+		if !strings.Contains(ref, ":") || Pinned(ref) {
+			t.Errorf("manifest entry %q must be a tagged reference without a digest", ref)
+		}
+		if digest != "" && !strings.HasPrefix(digest, "sha256:") {
+			t.Errorf("manifest digest of %s is %q, want sha256:<hex>", ref, digest)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ResourceAccess names an action on a resource for an access review
+type ResourceAccess struct {
+	Namespace   string
+	Verb        string
+	Group       string
+	Resource    string
+	Subresource string
+}
+
+// CanI asks the API server whether the current user may perform access. The
+// reason is the authorizer's explanation, if any.
+func (c *Client) CanI(ctx context.Context, access ResourceAccess) (allowed bool, reason string, err error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   access.Namespace,
+				Verb:        access.Verb,
+				Group:       access.Group,
+				Resource:    access.Resource,
+				Subresource: access.Subresource,
+			},
+		},
+	}
+
+	var result *authorizationv1.SelfSubjectAccessReview
+	err = retryOnCredentialExpiry(func() error {
+		var err error
+		result, err = c.Clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		return err
+	})
+	if err != nil {
+		return false, "", err
+	}
+	return result.Status.Allowed, result.Status.Reason, nil
+}
+
+// FullResource returns the resource with its group and subresource, e.g.
+// "pods/exec" or "deployments.apps"
+func (a ResourceAccess) FullResource() string {
+	resource := a.Resource
+	if a.Group != "" {
+		resource += "." + a.Group
+	}
+	if a.Subresource != "" {
+		resource += "/" + a.Subresource
+	}
+	return resource
+}
+
+// String describes access as "kubectl auth can-i" takes it, e.g.
+// "create pods/exec"
+func (a ResourceAccess) String() string {
+	return a.Verb + " " + a.FullResource()
+}
+
+// DeniedAccess is an access the current user is not allowed
+type DeniedAccess struct {
+	ResourceAccess
+	// Reason is the authorizer's explanation, if any
+	Reason string
+}
+
+// MissingAccess reviews accesses concurrently and returns those the current
+// user is denied, in the order given
+func (c *Client) MissingAccess(ctx context.Context, accesses []ResourceAccess) ([]DeniedAccess, error) {
+	type review struct {
+		allowed bool
+		reason  string
+		err     error
+	}
+	reviews := make([]review, len(accesses))
+	var wg sync.WaitGroup
+	for i, access := range accesses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := &reviews[i]
+			r.allowed, r.reason, r.err = c.CanI(ctx, access)
+		}()
+	}
+	wg.Wait()
+
+	var denied []DeniedAccess
+	for i, r := range reviews {
+		if r.err != nil {
+			return nil, fmt.Errorf("failed to review %s: %w", accesses[i], r.err)
+		}
+		if !r.allowed {
+			denied = append(denied, DeniedAccess{ResourceAccess: accesses[i], Reason: r.reason})
+		}
+	}
+	return denied, nil
+}
+
+// AccessError reports the accesses a command needs but is denied
+type AccessError struct {
+	Namespace string
+	Denied    []DeniedAccess
+}
+
+func (e *AccessError) Error() string {
+	missing := make([]string, len(e.Denied))
+	for i, d := range e.Denied {
+		missing[i] = d.String()
+	}
+	return fmt.Sprintf("not allowed to %s in namespace %s", strings.Join(missing, ", "), e.Namespace)
+}
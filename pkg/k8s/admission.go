@@ -0,0 +1,393 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// AdmissionSimulation is the outcome of a server-side dry run of a pod
+type AdmissionSimulation struct {
+	// Submitted is the pod as sent
+	Submitted *corev1.Pod
+	// Admitted is the pod as the API server would store it; nil when denied
+	Admitted *corev1.Pod
+	// Denial explains a rejection; nil when the pod was admitted
+	Denial *AdmissionDenial
+	// Warnings are admission warnings, e.g. from Kyverno audit policies or
+	// Gatekeeper constraints in warn mode
+	Warnings []string
+	// Mutations are the changes admission made to the pod
+	Mutations []PodMutation
+}
+
+// AdmissionDenial is a rejected dry run
+type AdmissionDenial struct {
+	// Source names what rejected the pod, e.g. a webhook or policy
+	Source string `json:"source"`
+	// Violations are the individual policy messages
+	Violations []PolicyViolation `json:"violations,omitempty"`
+	// Message is the API server's full message
+	Message string `json:"message"`
+}
+
+// PolicyViolation is one failed policy rule
+type PolicyViolation struct {
+	// Policy is a Kyverno policy or Gatekeeper constraint; empty when the
+	// source does not name one
+	Policy  string `json:"policy,omitempty"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+// PodMutation is one change admission made to a pod
+type PodMutation struct {
+	Field string `json:"field"`
+	// From and To are empty when the field was added or removed
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+var (
+	webhookDenied   = regexp.MustCompile(`admission webhook "([^"]+)" denied the request:\s*`)
+	webhookFailed   = regexp.MustCompile(`failed calling webhook "([^"]+)"`)
+	policyDenied    = regexp.MustCompile(`ValidatingAdmissionPolicy '([^']+)' with binding '[^']+' denied request:\s*`)
+	podSecurity     = regexp.MustCompile(`violates PodSecurity "([^"]+)":\s*`)
+	quotaExceeded   = regexp.MustCompile(`exceeded quota: ([^,]+),\s*`)
+	gatekeeperEntry = regexp.MustCompile(`^\[([^\]]+)\]\s*(.*)$`)
+)
+
+// warningCollector records warning headers of API responses
+type warningCollector struct {
+	mu       sync.Mutex
+	warnings []string
+}
+
+// HandleWarningHeader implements rest.WarningHandler
+func (w *warningCollector) HandleWarningHeader(code int, agent, text string) {
+	if code != 299 || text == "" {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.warnings = append(w.warnings, text)
+}
+
+// SimulatePodAdmission dry-runs creating the pod for config and reports
+// whether admission would deny or change it. Only failures to reach the
+// API server are returned as errors.
+func (c *Client) SimulatePodAdmission(ctx context.Context, config PodConfig) (*AdmissionSimulation, error) {
+	if c.Config == nil {
+		return nil, ErrNoRESTConfig
+	}
+	warnings := &warningCollector{}
+	restConfig := rest.CopyConfig(c.Config)
+	restConfig.WarningHandler = warnings
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	pod := NewPod(config)
+	c.stampAudit(ctx, pod)
+	var admitted *corev1.Pod
+	err = retryOnCredentialExpiry(func() error {
+		var err error
+		admitted, err = clientset.CoreV1().Pods(config.Namespace).Create(ctx, pod, metav1.CreateOptions{
+			DryRun: []string{metav1.DryRunAll},
+		})
+		return err
+	})
+
+	sim := &AdmissionSimulation{Submitted: pod, Warnings: warnings.warnings}
+	var status apierrors.APIStatus
+	switch {
+	case err == nil:
+		sim.Admitted = admitted
+		sim.Mutations = podMutations(pod, admitted)
+	case errors.As(err, &status):
+		sim.Denial = parseDenial(status.Status().Message)
+	default:
+		return nil, fmt.Errorf("failed to dry-run pod: %w", err)
+	}
+	return sim, nil
+}
+
+// parseDenial names what rejected a pod and splits Kyverno and Gatekeeper
+// messages into their policies
+func parseDenial(message string) *AdmissionDenial {
+	denial := &AdmissionDenial{Source: "API server", Message: message}
+	detail := message
+
+	switch {
+	case webhookDenied.MatchString(message):
+		loc := webhookDenied.FindStringSubmatchIndex(message)
+		name := message[loc[2]:loc[3]]
+		denial.Source = "webhook " + name
+		detail = message[loc[1]:]
+		switch {
+		case strings.Contains(name, "kyverno"):
+			denial.Violations = kyvernoViolations(detail)
+		case strings.Contains(name, "gatekeeper"):
+			denial.Violations = gatekeeperViolations(detail)
+		}
+	case webhookFailed.MatchString(message):
+		denial.Source = "webhook " + webhookFailed.FindStringSubmatch(message)[1] + " (unreachable)"
+	case policyDenied.MatchString(message):
+		loc := policyDenied.FindStringSubmatchIndex(message)
+		denial.Source = "ValidatingAdmissionPolicy " + message[loc[2]:loc[3]]
+		detail = message[loc[1]:]
+	case podSecurity.MatchString(message):
+		loc := podSecurity.FindStringSubmatchIndex(message)
+		denial.Source = "Pod Security " + message[loc[2]:loc[3]]
+		detail = message[loc[1]:]
+	case quotaExceeded.MatchString(message):
+		loc := quotaExceeded.FindStringSubmatchIndex(message)
+		denial.Source = "ResourceQuota " + message[loc[2]:loc[3]]
+		detail = message[loc[1]:]
+	case strings.Contains(message, "LimitRange") || strings.Contains(message, "per Container is"):
+		denial.Source = "LimitRange"
+	}
+
+	if len(denial.Violations) == 0 {
+		denial.Violations = []PolicyViolation{{Message: strings.TrimSpace(detail)}}
+	}
+	return denial
+}
+
+// kyvernoViolations parses Kyverno's denial format:
+//
+//	resource Pod/ns/name was blocked due to the following policies
+//
+//	require-labels:
+//	  check-team: 'validation error: label team is required. ...'
+func kyvernoViolations(detail string) []PolicyViolation {
+	var violations []PolicyViolation
+	policy := ""
+	for _, line := range strings.Split(detail, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "resource ") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && strings.HasSuffix(trimmed, ":") {
+			policy = strings.TrimSuffix(trimmed, ":")
+			continue
+		}
+		rule, message, ok := strings.Cut(trimmed, ": ")
+		if !ok || policy == "" {
+			continue
+		}
+		violations = append(violations, PolicyViolation{
+			Policy:  policy,
+			Rule:    rule,
+			Message: strings.Trim(message, "'\""),
+		})
+	}
+	return violations
+}
+
+// gatekeeperViolations parses Gatekeeper's "[constraint] message" lines
+func gatekeeperViolations(detail string) []PolicyViolation {
+	var violations []PolicyViolation
+	for _, line := range strings.Split(detail, "\n") {
+		if m := gatekeeperEntry.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			violations = append(violations, PolicyViolation{Policy: m[1], Message: m[2]})
+		}
+	}
+	return violations
+}
+
+// podMutations compares the fields admission webhooks commonly change.
+// Changes the API server itself makes (service account token volumes,
+// default tolerations and scheduler) are left out.
+func podMutations(submitted, admitted *corev1.Pod) []PodMutation {
+	var mutations []PodMutation
+	mutations = append(mutations, mapMutations("metadata.labels", submitted.Labels, admitted.Labels)...)
+	mutations = append(mutations, mapMutations("metadata.annotations", submitted.Annotations, admitted.Annotations)...)
+
+	before, after := &submitted.Spec, &admitted.Spec
+	mutations = append(mutations, containerMutations("spec.initContainers", before.InitContainers, after.InitContainers)...)
+	mutations = append(mutations, containerMutations("spec.containers", before.Containers, after.Containers)...)
+
+	var volumes []string
+	for _, v := range after.Volumes {
+		if !isServiceAccountVolume(v.Name) && !hasVolume(before.Volumes, v.Name) {
+			volumes = append(volumes, v.Name)
+		}
+	}
+	if len(volumes) > 0 {
+		mutations = append(mutations, PodMutation{Field: "spec.volumes", To: strings.Join(volumes, ", ")})
+	}
+
+	if before.ServiceAccountName != "" || after.ServiceAccountName != "default" {
+		mutations = append(mutations, valueMutation("spec.serviceAccountName", before.ServiceAccountName, after.ServiceAccountName)...)
+	}
+	mutations = append(mutations, valueMutation("spec.securityContext", before.SecurityContext, after.SecurityContext)...)
+	mutations = append(mutations, mapMutations("spec.nodeSelector", before.NodeSelector, after.NodeSelector)...)
+	mutations = append(mutations, valueMutation("spec.affinity", before.Affinity, after.Affinity)...)
+	mutations = append(mutations, valueMutation("spec.tolerations", before.Tolerations, withoutDefaultTolerations(after.Tolerations))...)
+	mutations = append(mutations, valueMutation("spec.imagePullSecrets", before.ImagePullSecrets, after.ImagePullSecrets)...)
+	mutations = append(mutations, valueMutation("spec.priorityClassName", before.PriorityClassName, after.PriorityClassName)...)
+	mutations = append(mutations, valueMutation("spec.runtimeClassName", before.RuntimeClassName, after.RuntimeClassName)...)
+	return mutations
+}
+
+func mapMutations(field string, before, after map[string]string) []PodMutation {
+	keys := map[string]bool{}
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var mutations []PodMutation
+	for _, k := range sorted {
+		if before[k] != after[k] {
+			mutations = append(mutations, PodMutation{Field: field + "." + k, From: before[k], To: after[k]})
+		}
+	}
+	return mutations
+}
+
+func containerMutations(field string, before, after []corev1.Container) []PodMutation {
+	var mutations []PodMutation
+	for _, a := range after {
+		prefix := field + "[" + a.Name + "]"
+		b := findContainer(before, a.Name)
+		if b == nil {
+			mutations = append(mutations, PodMutation{Field: prefix, To: a.Image})
+			continue
+		}
+		mutations = append(mutations, valueMutation(prefix+".image", b.Image, a.Image)...)
+		mutations = append(mutations, valueMutation(prefix+".securityContext", b.SecurityContext, a.SecurityContext)...)
+		mutations = append(mutations, valueMutation(prefix+".resources", b.Resources, a.Resources)...)
+		mutations = append(mutations, valueMutation(prefix+".env", b.Env, a.Env)...)
+
+		var mounts []string
+		for _, m := range a.VolumeMounts {
+			if !isServiceAccountVolume(m.Name) && !hasMount(b.VolumeMounts, m.Name) {
+				mounts = append(mounts, m.Name)
+			}
+		}
+		if len(mounts) > 0 {
+			mutations = append(mutations, PodMutation{Field: prefix + ".volumeMounts", To: strings.Join(mounts, ", ")})
+		}
+	}
+	for _, b := range before {
+		if findContainer(after, b.Name) == nil {
+			mutations = append(mutations, PodMutation{Field: field + "[" + b.Name + "]", From: b.Image})
+		}
+	}
+	return mutations
+}
+
+// valueMutation reports a change of any value, rendered as compact JSON
+func valueMutation(field string, before, after any) []PodMutation {
+	if isEmpty(before) && isEmpty(after) {
+		return nil
+	}
+	if equality.Semantic.DeepEqual(before, after) {
+		return nil
+	}
+	return []PodMutation{{Field: field, From: compactJSON(before), To: compactJSON(after)}}
+}
+
+// isEmpty reports whether v is nil, points to a zero value or is an empty
+// slice or map. The API server defaults some unset structs to empty ones.
+func isEmpty(v any) bool {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return true
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	case reflect.Invalid:
+		return true
+	}
+	return rv.IsZero()
+}
+
+func compactJSON(v any) string {
+	if isEmpty(v) {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func findContainer(containers []corev1.Container, name string) *corev1.Container {
+	for i := range containers {
+		if containers[i].Name == name {
+			return &containers[i]
+		}
+	}
+	return nil
+}
+
+func hasVolume(volumes []corev1.Volume, name string) bool {
+	for _, v := range volumes {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasMount(mounts []corev1.VolumeMount, name string) bool {
+	for _, m := range mounts {
+		if m.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isServiceAccountVolume matches the projected token volume the
+// ServiceAccount admission plugin adds
+func isServiceAccountVolume(name string) bool {
+	return strings.HasPrefix(name, "kube-api-access-")
+}
+
+// withoutDefaultTolerations drops the not-ready and unreachable tolerations
+// the DefaultTolerationSeconds admission plugin adds
+func withoutDefaultTolerations(tolerations []corev1.Toleration) []corev1.Toleration {
+	var kept []corev1.Toleration
+	for _, t := range tolerations {
+		isDefault := (t.Key == corev1.TaintNodeNotReady || t.Key == corev1.TaintNodeUnreachable) &&
+			t.Operator == corev1.TolerationOpExists && t.Effect == corev1.TaintEffectNoExecute &&
+			t.TolerationSeconds != nil && *t.TolerationSeconds == 300
+		if !isDefault {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,165 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/pager"
+)
+
+// ListPageSize is the chunk size of paginated cluster-wide lists
+const ListPageSize = 500
+
+// listPages calls each for every item of a list, fetched in chunks of
+// ListPageSize so cluster-wide lists on large clusters stay small
+func listPages(ctx context.Context, list func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error), each func(obj runtime.Object)) error {
+	p := pager.New(pager.ListPageFunc(list))
+	p.PageSize = ListPageSize
+	return p.EachListItem(ctx, metav1.ListOptions{}, func(obj runtime.Object) error {
+		each(obj)
+		return nil
+	})
+}
+
+// APIRequest identifies a kind of Kubernetes API request
+type APIRequest struct {
+	// Verb is the authorization verb, e.g. get, list or create
+	Verb string
+	// Resource is the resource with its subresource, e.g. pods/log; for
+	// non-resource requests it is the path
+	Resource string
+}
+
+// APICount is the number of requests of one kind
+type APICount struct {
+	APIRequest
+	Count int
+}
+
+// APIUsage counts the API requests a client makes
+type APIUsage struct {
+	mu        sync.Mutex
+	counts    map[APIRequest]int
+	total     int
+	throttled int
+}
+
+// Total returns the number of requests made
+func (u *APIUsage) Total() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.total
+}
+
+// Throttled returns the number of requests the API server rejected with 429
+// Too Many Requests
+func (u *APIUsage) Throttled() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.throttled
+}
+
+// Counts returns the requests by verb and resource, most frequent first
+func (u *APIUsage) Counts() []APICount {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	counts := make([]APICount, 0, len(u.counts))
+	for req, n := range u.counts {
+		counts = append(counts, APICount{APIRequest: req, Count: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		if counts[i].Resource != counts[j].Resource {
+			return counts[i].Resource < counts[j].Resource
+		}
+		return counts[i].Verb < counts[j].Verb
+	})
+	return counts
+}
+
+// wrap returns a transport that counts requests through rt
+func (u *APIUsage) wrap(rt http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := rt.RoundTrip(req)
+
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		if u.counts == nil {
+			u.counts = map[APIRequest]int{}
+		}
+		u.counts[describeRequest(req)]++
+		u.total++
+		if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+			u.throttled++
+		}
+		return resp, err
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// describeRequest derives the verb and resource of a request from its
+// method and path, as the API server's authorizer does
+func describeRequest(req *http.Request) APIRequest {
+	path := strings.Trim(req.URL.Path, "/")
+	parts := strings.Split(path, "/")
+
+	// Skip /api/<version> or /apis/<group>/<version>
+	switch {
+	case len(parts) >= 3 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) >= 4 && parts[0] == "apis":
+		parts = parts[3:]
+	default:
+		return APIRequest{Verb: strings.ToLower(req.Method), Resource: "/" + path}
+	}
+	// A namespaced resource, unless the request is for the namespace itself
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		parts = parts[2:]
+	}
+
+	resource, name := parts[0], ""
+	if len(parts) >= 2 {
+		name = parts[1]
+	}
+	if len(parts) >= 3 {
+		resource += "/" + parts[2]
+	}
+
+	verb := ""
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		switch {
+		case name != "":
+			verb = "get"
+		case req.URL.Query().Get("watch") == "true" || req.URL.Query().Get("watch") == "1":
+			verb = "watch"
+		default:
+			verb = "list"
+		}
+	case http.MethodPost:
+		verb = "create"
+	case http.MethodPut:
+		verb = "update"
+	case http.MethodPatch:
+		verb = "patch"
+	case http.MethodDelete:
+		verb = "delete"
+		if name == "" {
+			verb = "deletecollection"
+		}
+	default:
+		verb = strings.ToLower(req.Method)
+	}
+	return APIRequest{Verb: verb, Resource: resource}
+}
@@ -0,0 +1,126 @@
+package k8s
+
+import (
+	"context"
+	"sync"
+	"unicode/utf8"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Annotations pocket puts on the pods it creates, so they can be traced
+// back to the person and command that created them
+const (
+	// AnnotationCreatedBy is the user that created the pod
+	AnnotationCreatedBy = "kubectl-pocket/created-by"
+	// AnnotationVersion is the pocket version that created the pod
+	AnnotationVersion = "kubectl-pocket/version"
+	// AnnotationCommand is the command line that created the pod, with
+	// passwords redacted
+	AnnotationCommand = "kubectl-pocket/command"
+)
+
+// maxCommandAnnotation bounds the length of AnnotationCommand
+const maxCommandAnnotation = 1024
+
+// PodAudit describes who creates pods through a client and how
+type PodAudit struct {
+	// Version is the pocket version
+	Version string
+	// Command is the command line, already redacted
+	Command string
+	// User names the creator; empty asks the API server with a
+	// SelfSubjectReview and falls back to the kubeconfig user
+	User string
+
+	once sync.Once
+}
+
+// kubeconfigUser returns the user of the current kubeconfig context, with
+// --context and --user applied
+func kubeconfigUser(loader clientcmd.ClientConfig) string {
+	raw, err := loader.RawConfig()
+	if merging, ok := loader.(clientcmd.OverridingClientConfig); ok {
+		raw, err = merging.MergedRawConfig()
+	}
+	if err != nil {
+		return ""
+	}
+	if kubeContext, ok := raw.Contexts[raw.CurrentContext]; ok {
+		return kubeContext.AuthInfo
+	}
+	return ""
+}
+
+// WhoAmI returns the username the API server authenticates the client as,
+// impersonation included. It needs Kubernetes 1.28 or later.
+func (c *Client) WhoAmI(ctx context.Context) (string, error) {
+	var review *authenticationv1.SelfSubjectReview
+	err := retryOnCredentialExpiry(func() error {
+		var err error
+		review, err = c.Clientset.AuthenticationV1().SelfSubjectReviews().Create(ctx, &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{})
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return review.Status.UserInfo.Username, nil
+}
+
+// Creator returns the user recorded as the creator of the client's pods:
+// Audit.User if set, else who the API server says the client is, else the
+// impersonated or kubeconfig user. It is resolved once per Audit.
+func (c *Client) Creator(ctx context.Context) string {
+	if c.Audit == nil {
+		return c.resolveCreator(ctx)
+	}
+	c.Audit.once.Do(func() {
+		if c.Audit.User == "" {
+			c.Audit.User = c.resolveCreator(ctx)
+		}
+	})
+	return c.Audit.User
+}
+
+func (c *Client) resolveCreator(ctx context.Context) string {
+	if user, err := c.WhoAmI(ctx); err == nil && user != "" {
+		return user
+	}
+	switch {
+	case c.Config != nil && c.Config.Impersonate.UserName != "":
+		return c.Config.Impersonate.UserName
+	case c.kubeconfigUser != "":
+		return c.kubeconfigUser
+	default:
+		return "unknown"
+	}
+}
+
+// stampAudit adds the client's audit annotations to pod, without replacing
+// annotations the caller set
+func (c *Client) stampAudit(ctx context.Context, pod *corev1.Pod) {
+	if c.Audit == nil {
+		return
+	}
+	annotations := map[string]string{AnnotationCreatedBy: c.Creator(ctx)}
+	if c.Audit.Version != "" {
+		annotations[AnnotationVersion] = c.Audit.Version
+	}
+	if command := c.Audit.Command; command != "" {
+		if len(command) > maxCommandAnnotation {
+			cut := maxCommandAnnotation - 3
+			for cut > 0 && !utf8.RuneStart(command[cut]) {
+				cut--
+			}
+			command = command[:cut] + "..."
+		}
+		annotations[AnnotationCommand] = command
+	}
+	for k, v := range pod.Annotations {
+		annotations[k] = v
+	}
+	pod.Annotations = annotations
+}
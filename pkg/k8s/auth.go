@@ -0,0 +1,43 @@
+package k8s
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// maxAuthRetries bounds how many consecutive Unauthorized responses are
+// retried before giving up
+const maxAuthRetries = 3
+
+// IsCredentialExpiry reports whether err is an authentication failure that
+// a new request can recover from. When a kubeconfig uses an exec credential
+// plugin, client-go re-invokes the plugin after a 401, so the next request
+// carries a fresh token.
+func IsCredentialExpiry(err error) bool {
+	return apierrors.IsUnauthorized(err)
+}
+
+// retryOnCredentialExpiry runs fn, retrying while it fails with an expired
+// credential
+func retryOnCredentialExpiry(fn func() error) error {
+	err := fn()
+	for i := 0; i < maxAuthRetries && IsCredentialExpiry(err); i++ {
+		err = fn()
+	}
+	return err
+}
+
+// authRetrier lets polling loops ride out credential rotation: a few
+// consecutive Unauthorized errors are treated as transient
+type authRetrier struct {
+	failures int
+}
+
+// transient reports whether err should be retried on the next poll
+func (a *authRetrier) transient(err error) bool {
+	if !IsCredentialExpiry(err) {
+		a.failures = 0
+		return false
+	}
+	a.failures++
+	return a.failures <= maxAuthRetries
+}
@@ -0,0 +1,117 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// CertificateGVR identifies cert-manager Certificate resources
+var CertificateGVR = schema.GroupVersionResource{
+	Group:    "cert-manager.io",
+	Version:  "v1",
+	Resource: "certificates",
+}
+
+// CertificateConfig holds configuration for creating a cert-manager Certificate
+type CertificateConfig struct {
+	Name       string
+	Namespace  string
+	SecretName string
+	DNSNames   []string
+	IssuerName string
+	IssuerKind string
+}
+
+// CertificateStatus summarizes the Ready condition of a Certificate
+type CertificateStatus struct {
+	Ready   bool
+	Reason  string
+	Message string
+}
+
+// CreateCertificate creates a new cert-manager Certificate
+func (c *Client) CreateCertificate(ctx context.Context, config CertificateConfig) error {
+	dnsNames := make([]interface{}, 0, len(config.DNSNames))
+	for _, name := range config.DNSNames {
+		dnsNames = append(dnsNames, name)
+	}
+
+	cert := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Certificate",
+			"metadata": map[string]interface{}{
+				"name":      config.Name,
+				"namespace": config.Namespace,
+				"labels": map[string]interface{}{
+					"app.kubernetes.io/managed-by": "kubectl-pocket",
+					"kubectl-pocket/temporary":     "true",
+				},
+			},
+			"spec": map[string]interface{}{
+				"secretName": config.SecretName,
+				"commonName": config.DNSNames[0],
+				"dnsNames":   dnsNames,
+				"duration":   "1h",
+				"issuerRef": map[string]interface{}{
+					"name":  config.IssuerName,
+					"kind":  config.IssuerKind,
+					"group": "cert-manager.io",
+				},
+			},
+		},
+	}
+
+	_, err := c.Dynamic.Resource(CertificateGVR).Namespace(config.Namespace).Create(ctx, cert, metav1.CreateOptions{})
+	return err
+}
+
+// DeleteCertificate deletes a Certificate by name
+func (c *Client) DeleteCertificate(ctx context.Context, namespace, name string) error {
+	return c.Dynamic.Resource(CertificateGVR).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+// GetCertificateStatus returns the Ready condition of a Certificate
+func (c *Client) GetCertificateStatus(ctx context.Context, namespace, name string) (*CertificateStatus, error) {
+	cert, err := c.Dynamic.Resource(CertificateGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	status := &CertificateStatus{}
+	conditions, _, err := unstructured.NestedSlice(cert.Object, "status", "conditions")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conditions: %w", err)
+	}
+	for _, raw := range conditions {
+		cond, ok := raw.(map[string]interface{})
+		if !ok || cond["type"] != "Ready" {
+			continue
+		}
+		status.Ready = cond["status"] == "True"
+		status.Reason, _ = cond["reason"].(string)
+		status.Message, _ = cond["message"].(string)
+	}
+	return status, nil
+}
+
+// WaitForCertificateReady waits until the Certificate reports Ready=True.
+// The last observed status is returned even when the wait times out.
+func (c *Client) WaitForCertificateReady(ctx context.Context, namespace, name string, timeout time.Duration) (*CertificateStatus, error) {
+	var last *CertificateStatus
+	err := wait.PollUntilContextTimeout(ctx, 2*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		status, err := c.GetCertificateStatus(ctx, namespace, name)
+		if err != nil {
+			return false, err
+		}
+		last = status
+		return status.Ready, nil
+	})
+	return last, err
+}
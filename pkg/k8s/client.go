@@ -1,89 +1,108 @@
+// Package k8s is the Kubernetes client pocket runs its pods with. Client
+// implements PodManager, Execer and PortForwarder; create it with NewClient
+// from kubectl-style flags or with NewClientForClientset from clientsets
+// built elsewhere. Together with package tester it is pocket's library
+// surface, whose exported API only changes compatibly within a major
+// version.
 package k8s
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
 // Client wraps the Kubernetes clientset and config
 type Client struct {
-	Clientset  *kubernetes.Clientset
+	Clientset  kubernetes.Interface
+	Dynamic    dynamic.Interface
 	Config     *rest.Config
 	Namespace  string
 	Kubeconfig string
+	// Usage counts the API requests made through the client
+	Usage *APIUsage
+	// Logf, if set, receives verbose logs up to Verbosity, one of the Log
+	// levels
+	Logf      func(format string, args ...any)
+	Verbosity int
+	// Audit, if set, is recorded on every pod the client creates as
+	// annotations naming its creator, pocket version and command
+	Audit *PodAudit
+	// VerifyImages refuses to create pods and ephemeral containers whose
+	// images are not pinned by digest, returning ErrUnpinnedImage
+	VerifyImages bool
+
+	// kubeconfigUser is the kubeconfig user, the creator recorded when the
+	// API server cannot say
+	kubeconfigUser string
 }
 
-// NewClient creates a new Kubernetes client
-func NewClient(kubeconfig, namespace string) (*Client, error) {
-	config, err := buildConfig(kubeconfig)
+// NewClient creates a new Kubernetes client from the standard kubectl
+// flags: kubeconfig, context, cluster, user, impersonation and request
+// timeout. namespace overrides the namespace of the kubeconfig context.
+func NewClient(getter genericclioptions.RESTClientGetter, namespace string) (*Client, error) {
+	config, err := getter.ToRESTConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build config: %w", err)
 	}
+	client := &Client{Usage: &APIUsage{}}
+	config.Wrap(client.Usage.wrap)
+	config.Wrap(client.traceRequests)
 
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create clientset: %w", err)
 	}
 
-	// Get namespace from config if not specified
-	if namespace == "" {
-		namespace = getNamespaceFromConfig(kubeconfig)
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
 	}
 
-	return &Client{
-		Clientset:  clientset,
-		Config:     config,
-		Namespace:  namespace,
-		Kubeconfig: kubeconfig,
-	}, nil
-}
-
-// buildConfig creates a rest.Config from kubeconfig or in-cluster config
-func buildConfig(kubeconfig string) (*rest.Config, error) {
-	// Try in-cluster config first if no kubeconfig specified
-	if kubeconfig == "" {
-		if config, err := rest.InClusterConfig(); err == nil {
-			return config, nil
+	loader := getter.ToRawKubeConfigLoader()
+	// Get namespace from the kubeconfig context, or the pod's own namespace
+	// in-cluster, if not specified
+	if namespace == "" {
+		if namespace, _, err = loader.Namespace(); err != nil || namespace == "" {
+			namespace = "default"
 		}
-		// Fall back to default kubeconfig location
-		kubeconfig = defaultKubeconfigPath()
 	}
 
-	return clientcmd.BuildConfigFromFlags("", kubeconfig)
+	client.Clientset = clientset
+	client.Dynamic = dynamicClient
+	client.Config = config
+	client.Namespace = namespace
+	client.Kubeconfig = loader.ConfigAccess().GetDefaultFilename()
+	client.kubeconfigUser = kubeconfigUser(loader)
+	return client, nil
 }
 
-// defaultKubeconfigPath returns the default kubeconfig path
-func defaultKubeconfigPath() string {
-	if env := os.Getenv("KUBECONFIG"); env != "" {
-		return env
+// NewClientForClientset creates a client from clientsets built by the
+// caller, such as fake clientsets in tests of code that embeds pocket.
+// dynamicClient may be nil when footprints and custom resources such as
+// certificates, Gateway API routes and Velero backups are not used. config
+// is only needed for exec, attach, port-forward and admission simulation,
+// which return ErrNoRESTConfig without it. Unlike with NewClient, API usage
+// and verbose request logs are not recorded for requests made through the
+// clientsets.
+func NewClientForClientset(clientset kubernetes.Interface, dynamicClient dynamic.Interface, config *rest.Config, namespace string) *Client {
+	if namespace == "" {
+		namespace = "default"
 	}
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return ""
+	return &Client{
+		Clientset: clientset,
+		Dynamic:   dynamicClient,
+		Config:    config,
+		Namespace: namespace,
+		Usage:     &APIUsage{},
 	}
-	return filepath.Join(home, ".kube", "config")
 }
 
-// getNamespaceFromConfig reads the current namespace from kubeconfig
-func getNamespaceFromConfig(kubeconfig string) string {
-	if kubeconfig == "" {
-		kubeconfig = defaultKubeconfigPath()
-	}
-
-	config, err := clientcmd.LoadFromFile(kubeconfig)
-	if err != nil {
-		return "default"
-	}
-
-	ctx, ok := config.Contexts[config.CurrentContext]
-	if !ok || ctx.Namespace == "" {
-		return "default"
-	}
-
-	return ctx.Namespace
+// DefaultNamespace returns the namespace of the client's kubeconfig context
+// or the one it was created for
+func (c *Client) DefaultNamespace() string {
+	return c.Namespace
 }
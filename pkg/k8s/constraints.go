@@ -0,0 +1,645 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Kinds of namespace constraints reported by CheckPodConstraints
+const (
+	ConstraintResourceQuota = "ResourceQuota"
+	ConstraintLimitRange    = "LimitRange"
+	ConstraintPodSecurity   = "PodSecurity"
+)
+
+// podSecurityLabelPrefix prefixes the Pod Security admission namespace labels
+const podSecurityLabelPrefix = "pod-security.kubernetes.io/"
+
+// ConstraintViolation is a namespace constraint a planned pod breaks
+type ConstraintViolation struct {
+	Kind string
+	// Name is the constraint object, or the Pod Security mode and level
+	// (e.g. "enforce=restricted")
+	Name    string
+	Message string
+	// Warning is set when the constraint only warns or audits and would
+	// not reject the pod
+	Warning bool
+	// Adjusted describes how CheckPodConstraints changed the pod config to
+	// satisfy the constraint; empty when it did not
+	Adjusted string
+}
+
+// minAdjustedResources are the smallest values the main container is
+// shrunk to; client tools do not start with less
+var minAdjustedResources = corev1.ResourceList{
+	corev1.ResourceCPU:    resource.MustParse("10m"),
+	corev1.ResourceMemory: resource.MustParse("32Mi"),
+}
+
+// fallbackResources are set on the main container when a quota requires a
+// resource the pod config leaves out
+var fallbackResources = corev1.ResourceList{
+	corev1.ResourceCPU:    resource.MustParse("100m"),
+	corev1.ResourceMemory: resource.MustParse("256Mi"),
+}
+
+// CheckPodConstraints evaluates the namespace's LimitRanges, ResourceQuotas
+// and Pod Security admission labels against the pod config would create.
+//
+// With adjust, the main container's resources are changed where that is
+// safe: raised to a LimitRange minimum, lowered to a maximum or to what is
+// left of a quota, or set when a quota requires them. Requests and limits
+// are kept equal. Security settings are never changed.
+func (c *Client) CheckPodConstraints(ctx context.Context, config *PodConfig, adjust bool) ([]ConstraintViolation, error) {
+	ns := config.Namespace
+
+	limitRanges, err := c.Clientset.CoreV1().LimitRanges(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list limit ranges: %w", err)
+	}
+	quotas, err := c.Clientset.CoreV1().ResourceQuotas(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource quotas: %w", err)
+	}
+
+	var violations []ConstraintViolation
+	violations = append(violations, checkLimitRanges(limitRanges.Items, config, adjust)...)
+	violations = append(violations, checkResourceQuotas(quotas.Items, limitRanges.Items, config, adjust)...)
+
+	namespace, err := c.Clientset.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
+	switch {
+	case apierrors.IsForbidden(err):
+		// Namespaces are cluster-scoped and often not readable; a dry run
+		// still covers Pod Security
+	case err != nil:
+		return nil, fmt.Errorf("failed to get namespace: %w", err)
+	default:
+		violations = append(violations, checkPodSecurity(namespace.Labels, NewPod(*config))...)
+	}
+	return violations, nil
+}
+
+// plannedContainer is a container of the planned pod. Only the main
+// container is adjusted; sidecars and extra containers belong to the
+// feature that added them.
+type plannedContainer struct {
+	name      string
+	resources *corev1.ResourceRequirements
+	main      bool
+}
+
+func plannedContainers(config *PodConfig) []plannedContainer {
+	containers := []plannedContainer{{name: "main", resources: &config.Resources, main: true}}
+	for i := range config.Sidecars {
+		containers = append(containers, plannedContainer{name: config.Sidecars[i].Name, resources: &config.Sidecars[i].Resources})
+	}
+	for i := range config.Containers {
+		containers = append(containers, plannedContainer{name: config.Containers[i].Name, resources: &config.Containers[i].Resources})
+	}
+	return containers
+}
+
+// defaultedResources returns the requests and limits a container ends up
+// with after API defaulting and LimitRange defaults
+func defaultedResources(res corev1.ResourceRequirements, ranges []corev1.LimitRange) corev1.ResourceRequirements {
+	out := corev1.ResourceRequirements{Requests: corev1.ResourceList{}, Limits: corev1.ResourceList{}}
+	for name, q := range res.Requests {
+		out.Requests[name] = q
+	}
+	for name, q := range res.Limits {
+		out.Limits[name] = q
+		// The API server defaults a missing request to the limit
+		if _, ok := out.Requests[name]; !ok {
+			out.Requests[name] = q
+		}
+	}
+
+	for _, lr := range ranges {
+		for _, item := range lr.Spec.Limits {
+			if item.Type != corev1.LimitTypeContainer {
+				continue
+			}
+			for name, q := range item.Default {
+				if _, ok := out.Limits[name]; !ok {
+					out.Limits[name] = q
+				}
+			}
+			for name, q := range item.DefaultRequest {
+				if _, ok := out.Requests[name]; !ok {
+					out.Requests[name] = q
+				}
+			}
+		}
+	}
+	return out
+}
+
+// checkLimitRanges checks every container against Container limits and the
+// pod's totals against Pod limits
+func checkLimitRanges(ranges []corev1.LimitRange, config *PodConfig, adjust bool) []ConstraintViolation {
+	var violations []ConstraintViolation
+	for _, lr := range ranges {
+		for _, item := range lr.Spec.Limits {
+			switch item.Type {
+			case corev1.LimitTypeContainer:
+				for _, container := range plannedContainers(config) {
+					for _, v := range checkContainerLimits(item, ranges, container, adjust) {
+						v.Kind, v.Name = ConstraintLimitRange, lr.Name
+						violations = append(violations, v)
+					}
+				}
+			case corev1.LimitTypePod:
+				for _, message := range checkPodLimits(item, ranges, config) {
+					violations = append(violations, ConstraintViolation{Kind: ConstraintLimitRange, Name: lr.Name, Message: message})
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// checkContainerLimits checks one container against a Container limit,
+// adjusting the main container when asked to
+func checkContainerLimits(item corev1.LimitRangeItem, ranges []corev1.LimitRange, container plannedContainer, adjust bool) []ConstraintViolation {
+	var violations []ConstraintViolation
+	report := func(message, adjusted string) {
+		if !adjust || !container.main {
+			adjusted = ""
+		}
+		violations = append(violations, ConstraintViolation{
+			Message:  fmt.Sprintf("container %q: %s", container.name, message),
+			Adjusted: adjusted,
+		})
+	}
+	fix := adjust && container.main
+
+	for _, name := range sortedResourceNames(item.Min, item.Max, item.MaxLimitRequestRatio) {
+		res := defaultedResources(*container.resources, ranges)
+		request, hasRequest := res.Requests[name]
+		limit, hasLimit := res.Limits[name]
+
+		if min, ok := item.Min[name]; ok {
+			switch {
+			case !hasRequest:
+				report(fmt.Sprintf("no %s request; minimum is %s", name, min.String()),
+					fmt.Sprintf("set %s to %s", name, min.String()))
+				if fix {
+					setResource(container.resources, name, min)
+				}
+			case request.Cmp(min) < 0:
+				report(fmt.Sprintf("%s request %s is below the minimum %s", name, request.String(), min.String()),
+					fmt.Sprintf("raised %s to %s", name, min.String()))
+				if fix {
+					setResource(container.resources, name, min)
+				}
+			case hasLimit && limit.Cmp(min) < 0:
+				report(fmt.Sprintf("%s limit %s is below the minimum %s", name, limit.String(), min.String()),
+					fmt.Sprintf("raised %s to %s", name, min.String()))
+				if fix {
+					setResource(container.resources, name, min)
+				}
+			}
+		}
+
+		if max, ok := item.Max[name]; ok {
+			switch {
+			case !hasLimit:
+				report(fmt.Sprintf("no %s limit; maximum is %s", name, max.String()),
+					fmt.Sprintf("set %s to %s", name, max.String()))
+				if fix {
+					setResource(container.resources, name, max)
+				}
+			case limit.Cmp(max) > 0:
+				report(fmt.Sprintf("%s limit %s is above the maximum %s", name, limit.String(), max.String()),
+					fmt.Sprintf("lowered %s to %s", name, max.String()))
+				if fix {
+					setResource(container.resources, name, max)
+				}
+			}
+		}
+
+		if ratio, ok := item.MaxLimitRequestRatio[name]; ok {
+			res = defaultedResources(*container.resources, ranges)
+			request, hasRequest = res.Requests[name]
+			limit, hasLimit = res.Limits[name]
+			if hasRequest && hasLimit && request.MilliValue() > 0 &&
+				float64(limit.MilliValue())/float64(request.MilliValue()) > float64(ratio.MilliValue())/1000 {
+				report(fmt.Sprintf("%s limit/request ratio exceeds %s", name, ratio.String()),
+					fmt.Sprintf("set %s request equal to the limit %s", name, limit.String()))
+				if fix {
+					setResource(container.resources, name, limit)
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// checkPodLimits checks the pod's summed resources against a Pod limit
+func checkPodLimits(item corev1.LimitRangeItem, ranges []corev1.LimitRange, config *PodConfig) []string {
+	requests, limits := corev1.ResourceList{}, corev1.ResourceList{}
+	missingRequest, missingLimit := map[corev1.ResourceName]bool{}, map[corev1.ResourceName]bool{}
+	for _, container := range plannedContainers(config) {
+		res := defaultedResources(*container.resources, ranges)
+		for _, name := range sortedResourceNames(item.Min, item.Max) {
+			addResource(requests, missingRequest, res.Requests, name)
+			addResource(limits, missingLimit, res.Limits, name)
+		}
+	}
+
+	var messages []string
+	for _, name := range sortedResourceNames(item.Min, item.Max) {
+		if min, ok := item.Min[name]; ok {
+			request := requests[name]
+			if missingRequest[name] || request.Cmp(min) < 0 {
+				messages = append(messages, fmt.Sprintf("pod %s requests total %s, below the minimum %s", name, request.String(), min.String()))
+			}
+		}
+		if max, ok := item.Max[name]; ok {
+			limit := limits[name]
+			if missingLimit[name] {
+				messages = append(messages, fmt.Sprintf("every container needs a %s limit; pod maximum is %s", name, max.String()))
+			} else if limit.Cmp(max) > 0 {
+				messages = append(messages, fmt.Sprintf("pod %s limits total %s, above the maximum %s", name, limit.String(), max.String()))
+			}
+		}
+	}
+	return messages
+}
+
+// checkResourceQuotas checks the pod against the quotas that apply to it
+func checkResourceQuotas(quotas []corev1.ResourceQuota, ranges []corev1.LimitRange, config *PodConfig, adjust bool) []ConstraintViolation {
+	var violations []ConstraintViolation
+	for _, quota := range quotas {
+		if !quotaMatchesPod(quota, NewPod(*config)) {
+			continue
+		}
+
+		for _, quotaName := range sortedResourceNames(quota.Spec.Hard) {
+			hard := quota.Spec.Hard[quotaName]
+			remaining := hard.DeepCopy()
+			if used, ok := quota.Status.Used[quotaName]; ok {
+				remaining.Sub(used)
+			}
+
+			if quotaName == corev1.ResourcePods || quotaName == "count/pods" {
+				if remaining.Sign() <= 0 {
+					violations = append(violations, ConstraintViolation{
+						Kind:    ConstraintResourceQuota,
+						Name:    quota.Name,
+						Message: fmt.Sprintf("%s: all %s pods in use", quotaName, hard.String()),
+					})
+				}
+				continue
+			}
+
+			name, isLimit, ok := quotaComputeResource(quotaName)
+			if !ok {
+				continue
+			}
+			if v, found := checkQuotaResource(quotaName, name, isLimit, hard, remaining, ranges, config, adjust); found {
+				v.Kind, v.Name = ConstraintResourceQuota, quota.Name
+				violations = append(violations, v)
+			}
+		}
+	}
+	return violations
+}
+
+// checkQuotaResource checks one compute resource tracked by a quota
+func checkQuotaResource(quotaName, name corev1.ResourceName, isLimit bool, hard, remaining resource.Quantity,
+	ranges []corev1.LimitRange, config *PodConfig, adjust bool) (ConstraintViolation, bool) {
+	kind := "request"
+	if isLimit {
+		kind = "limit"
+	}
+
+	var total resource.Quantity
+	var missing []string
+	for _, container := range plannedContainers(config) {
+		res := defaultedResources(*container.resources, ranges)
+		list := res.Requests
+		if isLimit {
+			list = res.Limits
+		}
+		q, ok := list[name]
+		if !ok {
+			missing = append(missing, container.name)
+			continue
+		}
+		total.Add(q)
+	}
+
+	if len(missing) > 0 {
+		v := ConstraintViolation{
+			Message: fmt.Sprintf("%s: quota requires a %s %s on every container; missing on %s",
+				quotaName, name, kind, strings.Join(missing, ", ")),
+		}
+		fallback, ok := fallbackResources[name]
+		if adjust && ok && len(missing) == 1 && missing[0] == "main" {
+			setResource(&config.Resources, name, fallback)
+			v.Adjusted = fmt.Sprintf("set %s to %s", name, fallback.String())
+		}
+		return v, true
+	}
+
+	if total.Cmp(remaining) <= 0 {
+		return ConstraintViolation{}, false
+	}
+
+	left := nonNegative(remaining)
+	v := ConstraintViolation{
+		Message: fmt.Sprintf("%s: pod needs %s but only %s of %s is left",
+			quotaName, total.String(), left.String(), hard.String()),
+	}
+	if !adjust {
+		return v, true
+	}
+
+	// Shrink the main container by the overshoot, if it stays usable and
+	// within the LimitRange minimums
+	res := defaultedResources(config.Resources, ranges)
+	current := res.Requests[name]
+	if isLimit {
+		current = res.Limits[name]
+	}
+	shrunk := current.DeepCopy()
+	shrunk.Sub(total)
+	shrunk.Add(remaining)
+
+	floor, ok := minAdjustedResources[name]
+	if !ok || shrunk.Cmp(floor) < 0 || shrunk.Cmp(limitRangeMin(ranges, name)) < 0 {
+		return v, true
+	}
+
+	setResource(&config.Resources, name, shrunk)
+	v.Adjusted = fmt.Sprintf("lowered %s to %s", name, shrunk.String())
+	return v, true
+}
+
+// quotaComputeResource maps a quota resource name to the container resource
+// it sums and whether it sums limits rather than requests
+func quotaComputeResource(name corev1.ResourceName) (corev1.ResourceName, bool, bool) {
+	switch name {
+	case corev1.ResourceCPU, corev1.ResourceRequestsCPU:
+		return corev1.ResourceCPU, false, true
+	case corev1.ResourceLimitsCPU:
+		return corev1.ResourceCPU, true, true
+	case corev1.ResourceMemory, corev1.ResourceRequestsMemory:
+		return corev1.ResourceMemory, false, true
+	case corev1.ResourceLimitsMemory:
+		return corev1.ResourceMemory, true, true
+	case corev1.ResourceEphemeralStorage, corev1.ResourceRequestsEphemeralStorage:
+		return corev1.ResourceEphemeralStorage, false, true
+	case corev1.ResourceLimitsEphemeralStorage:
+		return corev1.ResourceEphemeralStorage, true, true
+	}
+	return "", false, false
+}
+
+// quotaMatchesPod reports whether a quota's scopes select the pod. Scopes
+// pocket cannot evaluate are treated as not matching.
+func quotaMatchesPod(quota corev1.ResourceQuota, pod *corev1.Pod) bool {
+	var selectors []corev1.ScopedResourceSelectorRequirement
+	for _, scope := range quota.Spec.Scopes {
+		selectors = append(selectors, corev1.ScopedResourceSelectorRequirement{
+			ScopeName: scope,
+			Operator:  corev1.ScopeSelectorOpExists,
+		})
+	}
+	if quota.Spec.ScopeSelector != nil {
+		selectors = append(selectors, quota.Spec.ScopeSelector.MatchExpressions...)
+	}
+
+	for _, selector := range selectors {
+		var matches bool
+		switch selector.ScopeName {
+		case corev1.ResourceQuotaScopeTerminating:
+			matches = pod.Spec.ActiveDeadlineSeconds != nil
+		case corev1.ResourceQuotaScopeNotTerminating:
+			matches = pod.Spec.ActiveDeadlineSeconds == nil
+		case corev1.ResourceQuotaScopeBestEffort:
+			matches = isBestEffort(pod)
+		case corev1.ResourceQuotaScopeNotBestEffort:
+			matches = !isBestEffort(pod)
+		case corev1.ResourceQuotaScopePriorityClass:
+			class := pod.Spec.PriorityClassName
+			switch selector.Operator {
+			case corev1.ScopeSelectorOpExists:
+				matches = class != ""
+			case corev1.ScopeSelectorOpDoesNotExist:
+				matches = class == ""
+			case corev1.ScopeSelectorOpIn:
+				matches = slices.Contains(selector.Values, class)
+			case corev1.ScopeSelectorOpNotIn:
+				matches = !slices.Contains(selector.Values, class)
+			}
+			if !matches {
+				return false
+			}
+			continue
+		default:
+			return false
+		}
+
+		if selector.Operator == corev1.ScopeSelectorOpDoesNotExist {
+			matches = !matches
+		}
+		if !matches {
+			return false
+		}
+	}
+	return true
+}
+
+// isBestEffort reports whether no container sets CPU or memory resources
+func isBestEffort(pod *corev1.Pod) bool {
+	containers := append(slices.Clone(pod.Spec.InitContainers), pod.Spec.Containers...)
+	for _, container := range containers {
+		for _, list := range []corev1.ResourceList{container.Resources.Requests, container.Resources.Limits} {
+			if _, ok := list[corev1.ResourceCPU]; ok {
+				return false
+			}
+			if _, ok := list[corev1.ResourceMemory]; ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// limitRangeMin returns the largest Container minimum for a resource
+func limitRangeMin(ranges []corev1.LimitRange, name corev1.ResourceName) resource.Quantity {
+	var min resource.Quantity
+	for _, lr := range ranges {
+		for _, item := range lr.Spec.Limits {
+			if q, ok := item.Min[name]; ok && item.Type == corev1.LimitTypeContainer && q.Cmp(min) > 0 {
+				min = q
+			}
+		}
+	}
+	return min
+}
+
+// baselineCapabilities may be added under the baseline Pod Security level
+var baselineCapabilities = []corev1.Capability{
+	"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD",
+	"NET_BIND_SERVICE", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT",
+}
+
+// checkPodSecurity checks the pod against each Pod Security mode set on the
+// namespace. Only enforce rejects pods; warn and audit are reported as
+// warnings.
+func checkPodSecurity(labels map[string]string, pod *corev1.Pod) []ConstraintViolation {
+	var violations []ConstraintViolation
+	for _, mode := range []string{"enforce", "warn", "audit"} {
+		level := labels[podSecurityLabelPrefix+mode]
+		problems := podSecurityProblems(pod, level)
+		if len(problems) == 0 {
+			continue
+		}
+		violations = append(violations, ConstraintViolation{
+			Kind:    ConstraintPodSecurity,
+			Name:    mode + "=" + level,
+			Message: strings.Join(problems, "; "),
+			Warning: mode != "enforce",
+		})
+	}
+	return violations
+}
+
+// podSecurityProblems lists the checks of a Pod Security level the pod
+// fails. Only the fields pocket sets are checked.
+func podSecurityProblems(pod *corev1.Pod, level string) []string {
+	if level != "baseline" && level != "restricted" {
+		return nil
+	}
+	restricted := level == "restricted"
+
+	var problems []string
+	if pod.Spec.HostNetwork || pod.Spec.HostPID || pod.Spec.HostIPC {
+		problems = append(problems, "host namespaces are not allowed")
+	}
+
+	podSC := pod.Spec.SecurityContext
+	if podSC == nil {
+		podSC = &corev1.PodSecurityContext{}
+	}
+
+	containers := append(slices.Clone(pod.Spec.InitContainers), pod.Spec.Containers...)
+	for _, container := range containers {
+		sc := container.SecurityContext
+		if sc == nil {
+			sc = &corev1.SecurityContext{}
+		}
+		fail := func(format string, args ...any) {
+			problems = append(problems, fmt.Sprintf("container %q: ", container.Name)+fmt.Sprintf(format, args...))
+		}
+
+		if sc.Privileged != nil && *sc.Privileged {
+			fail("privileged")
+		}
+
+		var added []corev1.Capability
+		if sc.Capabilities != nil {
+			added = sc.Capabilities.Add
+		}
+		for _, capability := range added {
+			allowed := slices.Contains(baselineCapabilities, capability)
+			if restricted {
+				allowed = capability == "NET_BIND_SERVICE"
+			}
+			if !allowed {
+				fail("adds capability %s", capability)
+			}
+		}
+
+		if !restricted {
+			continue
+		}
+
+		runAsNonRoot := sc.RunAsNonRoot
+		if runAsNonRoot == nil {
+			runAsNonRoot = podSC.RunAsNonRoot
+		}
+		if runAsNonRoot == nil || !*runAsNonRoot {
+			fail("runAsNonRoot != true")
+		}
+		if (sc.RunAsUser != nil && *sc.RunAsUser == 0) || (sc.RunAsUser == nil && podSC.RunAsUser != nil && *podSC.RunAsUser == 0) {
+			fail("runAsUser=0")
+		}
+		if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+			fail("allowPrivilegeEscalation != false")
+		}
+		if sc.Capabilities == nil || !slices.Contains(sc.Capabilities.Drop, "ALL") {
+			fail("capabilities do not drop ALL")
+		}
+
+		seccomp := sc.SeccompProfile
+		if seccomp == nil {
+			seccomp = podSC.SeccompProfile
+		}
+		if seccomp == nil || (seccomp.Type != corev1.SeccompProfileTypeRuntimeDefault && seccomp.Type != corev1.SeccompProfileTypeLocalhost) {
+			fail("seccompProfile must be RuntimeDefault or Localhost")
+		}
+	}
+	return problems
+}
+
+// sortedResourceNames returns the keys of the lists, sorted and without
+// duplicates
+func sortedResourceNames(lists ...corev1.ResourceList) []corev1.ResourceName {
+	seen := map[corev1.ResourceName]bool{}
+	var names []corev1.ResourceName
+	for _, list := range lists {
+		for name := range list {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// addResource adds a container's value to a pod total, or marks it missing
+func addResource(total corev1.ResourceList, missing map[corev1.ResourceName]bool, list corev1.ResourceList, name corev1.ResourceName) {
+	q, ok := list[name]
+	if !ok {
+		missing[name] = true
+		return
+	}
+	sum := total[name]
+	sum.Add(q)
+	total[name] = sum
+}
+
+// setResource sets a container's request and limit to the same value, as
+// pocket's --cpu and --memory do
+func setResource(res *corev1.ResourceRequirements, name corev1.ResourceName, q resource.Quantity) {
+	if res.Requests == nil {
+		res.Requests = corev1.ResourceList{}
+	}
+	if res.Limits == nil {
+		res.Limits = corev1.ResourceList{}
+	}
+	res.Requests[name] = q
+	res.Limits[name] = q
+}
+
+func nonNegative(q resource.Quantity) resource.Quantity {
+	if q.Sign() < 0 {
+		return resource.Quantity{Format: q.Format}
+	}
+	return q
+}
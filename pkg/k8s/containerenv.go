@@ -0,0 +1,149 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// envReference matches $(VAR) references in env values
+var envReference = regexp.MustCompile(`\$\$|\$\(([A-Za-z_][A-Za-z0-9_.-]*)\)`)
+
+// ContainerEnv returns a container's environment variables as the kubelet
+// sets them: envFrom sources first, then env, with Secret and ConfigMap
+// references read and $(VAR) references expanded. Field and resource
+// references are skipped.
+func (c *Client) ContainerEnv(ctx context.Context, namespace string, container corev1.Container) (map[string]string, error) {
+	sources := &envSources{client: c, namespace: namespace}
+	env := map[string]string{}
+
+	for _, from := range container.EnvFrom {
+		var data map[string]string
+		var err error
+		switch {
+		case from.SecretRef != nil:
+			data, err = sources.secret(ctx, from.SecretRef.Name, from.SecretRef.Optional)
+		case from.ConfigMapRef != nil:
+			data, err = sources.configMap(ctx, from.ConfigMapRef.Name, from.ConfigMapRef.Optional)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range data {
+			env[from.Prefix+k] = v
+		}
+	}
+
+	for _, e := range container.Env {
+		if e.ValueFrom == nil {
+			env[e.Name] = expandEnv(e.Value, env)
+			continue
+		}
+
+		var data map[string]string
+		var key string
+		var err error
+		switch ref := e.ValueFrom; {
+		case ref.SecretKeyRef != nil:
+			key = ref.SecretKeyRef.Key
+			data, err = sources.secret(ctx, ref.SecretKeyRef.Name, ref.SecretKeyRef.Optional)
+		case ref.ConfigMapKeyRef != nil:
+			key = ref.ConfigMapKeyRef.Key
+			data, err = sources.configMap(ctx, ref.ConfigMapKeyRef.Name, ref.ConfigMapKeyRef.Optional)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if value, ok := data[key]; ok {
+			env[e.Name] = value
+		}
+	}
+	return env, nil
+}
+
+// expandEnv expands $(VAR) references to variables defined so far, leaving
+// unknown references as they are, like the kubelet
+func expandEnv(value string, env map[string]string) string {
+	return envReference.ReplaceAllStringFunc(value, func(match string) string {
+		if match == "$$" {
+			return "$"
+		}
+		if v, ok := env[match[2:len(match)-1]]; ok {
+			return v
+		}
+		return match
+	})
+}
+
+// envSources reads and caches the Secrets and ConfigMaps a container's
+// environment refers to
+type envSources struct {
+	client     *Client
+	namespace  string
+	secrets    map[string]map[string]string
+	configMaps map[string]map[string]string
+}
+
+func (s *envSources) secret(ctx context.Context, name string, optional *bool) (map[string]string, error) {
+	if data, ok := s.secrets[name]; ok {
+		return data, nil
+	}
+
+	var secret *corev1.Secret
+	err := retryOnCredentialExpiry(func() error {
+		var err error
+		secret, err = s.client.Clientset.CoreV1().Secrets(s.namespace).Get(ctx, name, metav1.GetOptions{})
+		return err
+	})
+	if apierrors.IsNotFound(err) && optional != nil && *optional {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret %s: %w", name, err)
+	}
+
+	data := map[string]string{}
+	for k, v := range secret.Data {
+		data[k] = string(v)
+	}
+	if s.secrets == nil {
+		s.secrets = map[string]map[string]string{}
+	}
+	s.secrets[name] = data
+	return data, nil
+}
+
+func (s *envSources) configMap(ctx context.Context, name string, optional *bool) (map[string]string, error) {
+	if data, ok := s.configMaps[name]; ok {
+		return data, nil
+	}
+
+	var configMap *corev1.ConfigMap
+	err := retryOnCredentialExpiry(func() error {
+		var err error
+		configMap, err = s.client.Clientset.CoreV1().ConfigMaps(s.namespace).Get(ctx, name, metav1.GetOptions{})
+		return err
+	})
+	if apierrors.IsNotFound(err) && optional != nil && *optional {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config map %s: %w", name, err)
+	}
+
+	data := map[string]string{}
+	for k, v := range configMap.Data {
+		data[k] = v
+	}
+	if s.configMaps == nil {
+		s.configMaps = map[string]map[string]string{}
+	}
+	s.configMaps[name] = data
+	return data, nil
+}
@@ -0,0 +1,384 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DatabaseKind describes how to recognise one kind of database
+type DatabaseKind struct {
+	// Type matches the tester name
+	Type string
+	Port int32
+	// Names are matched case-insensitively in port names, images and app
+	// labels
+	Names []string
+}
+
+// DatabaseKinds are the databases DiscoverDatabases recognises
+var DatabaseKinds = []DatabaseKind{
+	{Type: "redis", Port: 6379, Names: []string{"redis", "valkey", "keydb"}},
+	{Type: "postgres", Port: 5432, Names: []string{"postgres", "timescale", "postgis"}},
+	{Type: "mongo", Port: 27017, Names: []string{"mongo"}},
+}
+
+// Ways a database was recognised
+const (
+	MatchedByPort  = "port"
+	MatchedByLabel = "label"
+	MatchedByImage = "image"
+)
+
+// appLabels identify what a workload runs
+var appLabels = []string{"app.kubernetes.io/name", "app", "k8s-app"}
+
+// sidecarNames mark ports and images that belong next to a database rather
+// than to it, e.g. a redis-exporter or a metrics port
+var sidecarNames = []string{"exporter", "metrics", "sentinel", "bouncer"}
+
+// Database is a database found in the cluster
+type Database struct {
+	Type      string `json:"type"`
+	Namespace string `json:"namespace"`
+	// Service is empty for a StatefulSet without a governing service
+	Service string `json:"service,omitempty"`
+	Port    int32  `json:"port"`
+	// Workload is the StatefulSet or Deployment behind it, e.g.
+	// statefulset/redis-master
+	Workload string `json:"workload,omitempty"`
+	Ready    int32  `json:"ready"`
+	Replicas int32  `json:"replicas"`
+	// MatchedBy is how it was recognised: port, label or image
+	MatchedBy string `json:"matchedBy"`
+}
+
+// workload is a StatefulSet or Deployment reduced to what discovery needs
+type workload struct {
+	ref       string
+	namespace string
+	labels    labels.Set
+	template  corev1.PodTemplateSpec
+	service   string
+	ready     int32
+	replicas  int32
+}
+
+// DiscoverDatabases finds Services and StatefulSets that look like
+// databases, by port, well-known labels or image. An empty namespace
+// searches all namespaces.
+func (c *Client) DiscoverDatabases(ctx context.Context, namespace string) ([]Database, error) {
+	var services []corev1.Service
+	err := listPages(ctx, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return c.Clientset.CoreV1().Services(namespace).List(ctx, opts)
+	}, func(obj runtime.Object) { services = append(services, *obj.(*corev1.Service)) })
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	workloads, err := c.listWorkloads(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []Database
+	// StatefulSets already reported through a service
+	covered := map[string]bool{}
+	// Services no known workload backs, counted from their endpoints
+	var unbacked []int
+
+	for _, svc := range services {
+		if len(svc.Spec.Selector) == 0 {
+			continue
+		}
+		backing := backingWorkload(&svc, workloads)
+		kind, port, matchedBy, ok := matchService(&svc, backing)
+		if !ok {
+			continue
+		}
+
+		db := Database{Type: kind.Type, Namespace: svc.Namespace, Service: svc.Name, Port: port, MatchedBy: matchedBy}
+		if backing != nil {
+			db.Workload, db.Ready, db.Replicas = backing.ref, backing.ready, backing.replicas
+			covered[backing.namespace+"/"+backing.ref] = true
+		} else {
+			unbacked = append(unbacked, len(found))
+		}
+		found = append(found, db)
+	}
+	if err := c.countReadyEndpoints(ctx, namespace, found, unbacked); err != nil {
+		return nil, err
+	}
+
+	for _, w := range workloads {
+		if !strings.HasPrefix(w.ref, "statefulset/") || covered[w.namespace+"/"+w.ref] {
+			continue
+		}
+		kind, matchedBy, ok := matchWorkload(&w)
+		if !ok {
+			continue
+		}
+		found = append(found, Database{
+			Type:      kind.Type,
+			Namespace: w.namespace,
+			Service:   w.service,
+			Port:      containerPort(w.template.Spec.Containers, kind),
+			Workload:  w.ref,
+			Ready:     w.ready,
+			Replicas:  w.replicas,
+			MatchedBy: matchedBy,
+		})
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		a, b := found[i], found[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Service+a.Workload < b.Service+b.Workload
+	})
+	return found, nil
+}
+
+// listWorkloads returns the StatefulSets and Deployments in a namespace
+func (c *Client) listWorkloads(ctx context.Context, namespace string) ([]workload, error) {
+	var statefulSets []appsv1.StatefulSet
+	err := listPages(ctx, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return c.Clientset.AppsV1().StatefulSets(namespace).List(ctx, opts)
+	}, func(obj runtime.Object) { statefulSets = append(statefulSets, *obj.(*appsv1.StatefulSet)) })
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	var deployments []appsv1.Deployment
+	err = listPages(ctx, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return c.Clientset.AppsV1().Deployments(namespace).List(ctx, opts)
+	}, func(obj runtime.Object) { deployments = append(deployments, *obj.(*appsv1.Deployment)) })
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	var workloads []workload
+	for _, sts := range statefulSets {
+		workloads = append(workloads, workload{
+			ref:       "statefulset/" + sts.Name,
+			namespace: sts.Namespace,
+			labels:    sts.Labels,
+			template:  sts.Spec.Template,
+			service:   sts.Spec.ServiceName,
+			ready:     sts.Status.ReadyReplicas,
+			replicas:  replicas(sts.Spec.Replicas),
+		})
+	}
+	for _, deploy := range deployments {
+		workloads = append(workloads, workload{
+			ref:       "deployment/" + deploy.Name,
+			namespace: deploy.Namespace,
+			labels:    deploy.Labels,
+			template:  deploy.Spec.Template,
+			ready:     deploy.Status.ReadyReplicas,
+			replicas:  replicas(deploy.Spec.Replicas),
+		})
+	}
+	return workloads, nil
+}
+
+// replicas defaults an unset replica count to 1, as the API server does
+func replicas(n *int32) int32 {
+	if n == nil {
+		return 1
+	}
+	return *n
+}
+
+// backingWorkload returns the workload whose pods a service selects
+func backingWorkload(svc *corev1.Service, workloads []workload) *workload {
+	selector := labels.SelectorFromSet(svc.Spec.Selector)
+	for i := range workloads {
+		w := &workloads[i]
+		if w.namespace == svc.Namespace && selector.Matches(labels.Set(w.template.Labels)) {
+			return w
+		}
+	}
+	return nil
+}
+
+// endpointBulkThreshold is the number of services above which endpoints
+// are listed once for the whole namespace instead of per service
+const endpointBulkThreshold = 10
+
+// countReadyEndpoints fills the ready and total addresses of the databases
+// at indexes, whose services no known workload backs
+func (c *Client) countReadyEndpoints(ctx context.Context, namespace string, dbs []Database, indexes []int) error {
+	if len(indexes) <= endpointBulkThreshold {
+		for _, i := range indexes {
+			endpoints, err := c.serviceEndpoints(ctx, dbs[i].Namespace, dbs[i].Service)
+			if err != nil {
+				return fmt.Errorf("failed to list endpoints of %s: %w", dbs[i].Service, err)
+			}
+			dbs[i].Ready, dbs[i].Replicas = countAddresses(endpoints)
+		}
+		return nil
+	}
+
+	byService := map[string][]ServiceEndpoint{}
+	err := listPages(ctx, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return c.Clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, opts)
+	}, func(obj runtime.Object) {
+		slice := obj.(*discoveryv1.EndpointSlice)
+		key := slice.Namespace + "/" + slice.Labels[discoveryv1.LabelServiceName]
+		byService[key] = append(byService[key], sliceEndpoints(slice)...)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list endpoints: %w", err)
+	}
+	for _, i := range indexes {
+		dbs[i].Ready, dbs[i].Replicas = countAddresses(byService[dbs[i].Namespace+"/"+dbs[i].Service])
+	}
+	return nil
+}
+
+// countAddresses counts the ready and total distinct addresses
+func countAddresses(endpoints []ServiceEndpoint) (ready, total int32) {
+	seen := map[string]bool{}
+	for _, e := range endpoints {
+		if seen[e.Address] {
+			continue
+		}
+		seen[e.Address] = true
+		total++
+		if e.Ready {
+			ready++
+		}
+	}
+	return ready, total
+}
+
+// matchService recognises a database service by its ports, then by the
+// labels and images of the service and the workload behind it
+func matchService(svc *corev1.Service, backing *workload) (DatabaseKind, int32, string, bool) {
+	for _, port := range svc.Spec.Ports {
+		name := port.Name + " " + port.TargetPort.StrVal
+		if isSidecar(name) {
+			continue
+		}
+		for _, kind := range DatabaseKinds {
+			if port.Port == kind.Port || containsAny(name, kind.Names) {
+				return kind, port.Port, MatchedByPort, true
+			}
+		}
+	}
+
+	var kind DatabaseKind
+	var matchedBy string
+	var ok bool
+	if kind, ok = matchLabels(svc.Labels); ok {
+		matchedBy = MatchedByLabel
+	} else if backing != nil {
+		kind, matchedBy, ok = matchWorkload(backing)
+	}
+	if !ok {
+		return DatabaseKind{}, 0, "", false
+	}
+
+	// Without a recognisable port, only a service with a single non-sidecar
+	// port is unambiguous
+	var candidates []int32
+	for _, port := range svc.Spec.Ports {
+		if !isSidecar(port.Name + " " + port.TargetPort.StrVal) {
+			candidates = append(candidates, port.Port)
+		}
+	}
+	if len(candidates) != 1 {
+		return DatabaseKind{}, 0, "", false
+	}
+	return kind, candidates[0], matchedBy, true
+}
+
+// matchWorkload recognises a database workload by its labels or images
+func matchWorkload(w *workload) (DatabaseKind, string, bool) {
+	if kind, ok := matchLabels(w.labels); ok {
+		return kind, MatchedByLabel, true
+	}
+	if kind, ok := matchLabels(w.template.Labels); ok {
+		return kind, MatchedByLabel, true
+	}
+	for _, c := range w.template.Spec.Containers {
+		repo := imageRepository(c.Image)
+		if isSidecar(repo) {
+			continue
+		}
+		for _, kind := range DatabaseKinds {
+			if containsAny(repo, kind.Names) {
+				return kind, MatchedByImage, true
+			}
+		}
+	}
+	return DatabaseKind{}, "", false
+}
+
+// matchLabels recognises a database from well-known app labels
+func matchLabels(set map[string]string) (DatabaseKind, bool) {
+	if isSidecar(set["app.kubernetes.io/component"]) {
+		return DatabaseKind{}, false
+	}
+	for _, key := range appLabels {
+		value, ok := set[key]
+		if !ok || isSidecar(value) {
+			continue
+		}
+		for _, kind := range DatabaseKinds {
+			if containsAny(value, kind.Names) {
+				return kind, true
+			}
+		}
+	}
+	return DatabaseKind{}, false
+}
+
+// containerPort returns the port a database container listens on, falling
+// back to the kind's default port
+func containerPort(containers []corev1.Container, kind DatabaseKind) int32 {
+	for _, c := range containers {
+		for _, port := range c.Ports {
+			if port.ContainerPort == kind.Port || containsAny(port.Name, kind.Names) {
+				return port.ContainerPort
+			}
+		}
+	}
+	return kind.Port
+}
+
+// imageRepository returns the last path element of an image reference
+// without tag or digest, e.g. "postgresql" for bitnami/postgresql:16
+func imageRepository(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, "/"); i >= 0 {
+		image = image[i+1:]
+	}
+	image, _, _ = strings.Cut(image, ":")
+	return image
+}
+
+func isSidecar(s string) bool {
+	return containsAny(s, sidecarNames)
+}
+
+func containsAny(s string, substrings []string) bool {
+	s = strings.ToLower(s)
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
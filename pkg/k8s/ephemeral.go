@@ -0,0 +1,67 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// AddEphemeralContainer adds container to a running pod and waits until it
+// runs. Ephemeral containers share the pod's network namespace, and its
+// target container's process namespace if TargetContainerName is set. They
+// cannot be removed; they stay in the pod spec once their process exits.
+func (c *Client) AddEphemeralContainer(ctx context.Context, namespace, podName string, container corev1.EphemeralContainer, timeout time.Duration) error {
+	if err := c.verifyImage(container.Image); err != nil {
+		return err
+	}
+	pods := c.Clientset.CoreV1().Pods(namespace)
+	c.logf(LogCommands, "ephemeral container %s in %s/%s runs %s: %s", container.Name, namespace, podName, container.Image,
+		quoteCommand(append(append([]string{}, container.Command...), container.Args...)))
+	c.logSpec("ephemeral container "+container.Name, container)
+	err := retryOnCredentialExpiry(func() error {
+		pod, err := pods.Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, container)
+		_, err = pods.UpdateEphemeralContainers(ctx, podName, pod, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add ephemeral container: %w", err)
+	}
+
+	var auth authRetrier
+	var waiting string
+	err = wait.PollUntilContextTimeout(ctx, time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		pod, err := pods.Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			if auth.transient(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		for _, status := range pod.Status.EphemeralContainerStatuses {
+			if status.Name != container.Name {
+				continue
+			}
+			switch {
+			case status.State.Running != nil:
+				return true, nil
+			case status.State.Terminated != nil:
+				return false, fmt.Errorf("ephemeral container %s exited: %s", container.Name, status.State.Terminated.Reason)
+			case status.State.Waiting != nil:
+				waiting = status.State.Waiting.Reason
+			}
+		}
+		return false, nil
+	})
+	if err != nil && waiting != "" {
+		return fmt.Errorf("ephemeral container %s did not start (%s): %w", container.Name, waiting, err)
+	}
+	return err
+}
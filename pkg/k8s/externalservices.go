@@ -0,0 +1,121 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ExternalService is a Service whose backends are not selected pods: either
+// an ExternalName alias or a selector-less Service with manually managed
+// endpoints
+type ExternalService struct {
+	Name string
+	// ExternalName is set for ExternalName services
+	ExternalName string
+	Ports        []int32
+	// Endpoints are the manually managed backends of a selector-less service
+	Endpoints []ServiceEndpoint
+}
+
+// ServiceEndpoint is one backend address and port
+type ServiceEndpoint struct {
+	Address string
+	Port    int32
+	Ready   bool
+}
+
+// ListExternalServices returns the ExternalName and selector-less services
+// in a namespace, or only the named one when name is set
+func (c *Client) ListExternalServices(ctx context.Context, namespace, name string) ([]ExternalService, error) {
+	var services []corev1.Service
+	if name != "" {
+		svc, err := c.Clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if !isExternalService(svc) {
+			return nil, fmt.Errorf("service %s selects pods; its endpoints are managed by Kubernetes", name)
+		}
+		services = []corev1.Service{*svc}
+	} else {
+		list, err := c.Clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, svc := range list.Items {
+			// The API server maintains default/kubernetes itself
+			if svc.Namespace == metav1.NamespaceDefault && svc.Name == "kubernetes" {
+				continue
+			}
+			if isExternalService(&svc) {
+				services = append(services, svc)
+			}
+		}
+	}
+
+	result := make([]ExternalService, 0, len(services))
+	for _, svc := range services {
+		external := ExternalService{Name: svc.Name}
+		for _, port := range svc.Spec.Ports {
+			external.Ports = append(external.Ports, port.Port)
+		}
+
+		if svc.Spec.Type == corev1.ServiceTypeExternalName {
+			external.ExternalName = svc.Spec.ExternalName
+		} else {
+			endpoints, err := c.serviceEndpoints(ctx, namespace, svc.Name)
+			if err != nil {
+				return nil, err
+			}
+			external.Endpoints = endpoints
+		}
+		result = append(result, external)
+	}
+	return result, nil
+}
+
+// isExternalService reports whether a service's backends are maintained by
+// hand rather than by a pod selector. Headless services without a selector
+// count too.
+func isExternalService(svc *corev1.Service) bool {
+	return svc.Spec.Type == corev1.ServiceTypeExternalName || len(svc.Spec.Selector) == 0
+}
+
+// serviceEndpoints collects the addresses in a service's EndpointSlices.
+// Manually created Endpoints objects are mirrored into EndpointSlices by the
+// control plane, so both are covered.
+func (c *Client) serviceEndpoints(ctx context.Context, namespace, service string) ([]ServiceEndpoint, error) {
+	slices, err := c.Clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + service,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []ServiceEndpoint
+	for i := range slices.Items {
+		endpoints = append(endpoints, sliceEndpoints(&slices.Items[i])...)
+	}
+	return endpoints, nil
+}
+
+// sliceEndpoints lists every address and port of an EndpointSlice
+func sliceEndpoints(slice *discoveryv1.EndpointSlice) []ServiceEndpoint {
+	var endpoints []ServiceEndpoint
+	for _, endpoint := range slice.Endpoints {
+		ready := endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+		for _, address := range endpoint.Addresses {
+			for _, port := range slice.Ports {
+				if port.Port == nil {
+					continue
+				}
+				endpoints = append(endpoints, ServiceEndpoint{Address: address, Port: *port.Port, Ready: ready})
+			}
+		}
+	}
+	return endpoints
+}
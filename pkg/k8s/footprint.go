@@ -0,0 +1,124 @@
+package k8s
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// PodMetricsGVR identifies metrics-server pod metrics
+var PodMetricsGVR = schema.GroupVersionResource{
+	Group:    "metrics.k8s.io",
+	Version:  "v1beta1",
+	Resource: "pods",
+}
+
+// Footprint is the cost of running a temporary pod
+type Footprint struct {
+	// SchedulingLatency is the time from creation until the pod was scheduled
+	SchedulingLatency time.Duration
+	// ImagePullTime is zero when the image was already cached
+	ImagePullTime time.Duration
+	ImageCached   bool
+	// ImageSize is in bytes; zero when the kubelet did not report it
+	ImageSize int64
+	// Peak usage observed from metrics-server; UsageSampled is false when
+	// no sample could be taken
+	PeakCPUMillis   int64
+	PeakMemoryBytes int64
+	UsageSampled    bool
+}
+
+var (
+	pulledDurationRe = regexp.MustCompile(`pulled image .* in ([0-9.]+[a-zµ]+)`)
+	imageSizeRe      = regexp.MustCompile(`Image size: ([0-9]+) bytes`)
+)
+
+// CollectFootprint fills scheduling and image pull details for a pod from
+// its conditions and events
+func (c *Client) CollectFootprint(ctx context.Context, pod *corev1.Pod, fp *Footprint) error {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionTrue {
+			fp.SchedulingLatency = cond.LastTransitionTime.Sub(pod.CreationTimestamp.Time)
+		}
+	}
+
+	events, err := c.Clientset.CoreV1().Events(pod.Namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("involvedObject.name", pod.Name).String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events.Items {
+		if event.Reason != "Pulled" {
+			continue
+		}
+		if strings.Contains(event.Message, "already present on machine") {
+			fp.ImageCached = true
+			continue
+		}
+		if m := pulledDurationRe.FindStringSubmatch(event.Message); m != nil {
+			fp.ImagePullTime, _ = time.ParseDuration(m[1])
+		}
+		if m := imageSizeRe.FindStringSubmatch(event.Message); m != nil {
+			fp.ImageSize, _ = strconv.ParseInt(m[1], 10, 64)
+		}
+	}
+	return nil
+}
+
+// PodUsage returns the current CPU (millicores) and memory (bytes) usage of
+// a pod as reported by metrics-server
+func (c *Client) PodUsage(ctx context.Context, namespace, name string) (cpuMillis, memoryBytes int64, err error) {
+	metrics, err := c.Dynamic.Resource(PodMetricsGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	containers, _, _ := unstructured.NestedSlice(metrics.Object, "containers")
+	for _, raw := range containers {
+		container, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		usage, _, _ := unstructured.NestedStringMap(container, "usage")
+		if q, err := resource.ParseQuantity(usage["cpu"]); err == nil {
+			cpuMillis += q.MilliValue()
+		}
+		if q, err := resource.ParseQuantity(usage["memory"]); err == nil {
+			memoryBytes += q.Value()
+		}
+	}
+	return cpuMillis, memoryBytes, nil
+}
+
+// SampleUsage polls PodUsage every interval until ctx is done, recording the
+// peak values in fp
+func (c *Client) SampleUsage(ctx context.Context, namespace, name string, interval time.Duration, fp *Footprint) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if cpu, mem, err := c.PodUsage(ctx, namespace, name); err == nil {
+			fp.UsageSampled = true
+			fp.PeakCPUMillis = max(fp.PeakCPUMillis, cpu)
+			fp.PeakMemoryBytes = max(fp.PeakMemoryBytes, mem)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,364 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Gateway API route kinds
+const (
+	KindHTTPRoute = "HTTPRoute"
+	KindTCPRoute  = "TCPRoute"
+)
+
+// GatewayGVR identifies Gateway API Gateway resources
+var GatewayGVR = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1",
+	Resource: "gateways",
+}
+
+// HTTPRouteGVR identifies Gateway API HTTPRoute resources
+var HTTPRouteGVR = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1",
+	Resource: "httproutes",
+}
+
+// TCPRouteGVR identifies Gateway API TCPRoute resources, still experimental
+var TCPRouteGVR = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1alpha2",
+	Resource: "tcproutes",
+}
+
+// GatewayRoute is an HTTPRoute or TCPRoute with the gateways it attaches to
+// and the backends it sends traffic to
+type GatewayRoute struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Hostnames []string
+	// Path is the first path an HTTPRoute matches, "/" when it matches all
+	Path     string
+	Parents  []RouteParent
+	Backends []RouteBackend
+}
+
+// RouteParent is a gateway a route attaches to, with the route's status
+// as reported by that gateway's controller
+type RouteParent struct {
+	Namespace   string
+	Name        string
+	SectionName string
+	Port        int32
+	// Reported is false until the controller writes the route's status
+	Reported     bool
+	Accepted     bool
+	ResolvedRefs bool
+	// Message explains the first condition that is not true
+	Message string
+	// Gateway is nil when the gateway does not exist
+	Gateway *Gateway
+}
+
+// Gateway is a Gateway API gateway
+type Gateway struct {
+	Namespace string
+	Name      string
+	ClassName string
+	Addresses []string
+	// Programmed is set once the controller configured the data plane
+	Programmed bool
+	// Message explains why the gateway is not programmed
+	Message   string
+	Listeners []GatewayListener
+}
+
+// GatewayListener is a port a gateway accepts traffic on
+type GatewayListener struct {
+	Name     string
+	Protocol string
+	Hostname string
+	Port     int32
+}
+
+// RouteBackend is a backend a route forwards to
+type RouteBackend struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Port      int32
+	// Found is set when a backing Service exists
+	Found bool
+	// Endpoints and ReadyEndpoints count the Service's endpoints
+	Endpoints      int
+	ReadyEndpoints int
+}
+
+// GetGatewayRoute returns a route with its parent gateways and backends
+// resolved. kind is KindHTTPRoute or KindTCPRoute.
+func (c *Client) GetGatewayRoute(ctx context.Context, namespace, kind, name string) (*GatewayRoute, error) {
+	gvr := HTTPRouteGVR
+	if kind == KindTCPRoute {
+		gvr = TCPRouteGVR
+	}
+	obj, err := c.Dynamic.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if meta.IsNoMatchError(err) || (apierrors.IsNotFound(err) && !isObjectNotFound(err)) {
+		return nil, fmt.Errorf("%s is not installed in the cluster (Gateway API %s CRD missing)", gvr.Resource, gvr.Version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s: %w", kind, name, err)
+	}
+
+	route := &GatewayRoute{Kind: kind, Namespace: namespace, Name: name, Path: "/"}
+	route.Hostnames, _, _ = unstructured.NestedStringSlice(obj.Object, "spec", "hostnames")
+
+	statuses, _, _ := unstructured.NestedSlice(obj.Object, "status", "parents")
+	parentRefs, _, _ := unstructured.NestedSlice(obj.Object, "spec", "parentRefs")
+	for _, raw := range parentRefs {
+		ref, ok := raw.(map[string]interface{})
+		if !ok || !isKind(ref, "gateway.networking.k8s.io", "Gateway") {
+			continue
+		}
+		parent := RouteParent{Namespace: stringField(ref, "namespace", namespace), Name: stringField(ref, "name", "")}
+		parent.SectionName = stringField(ref, "sectionName", "")
+		if port, ok := ref["port"].(int64); ok {
+			parent.Port = int32(port)
+		}
+		parent.applyStatus(statuses, namespace)
+
+		gw, err := c.getGateway(ctx, parent.Namespace, parent.Name)
+		if err != nil {
+			return nil, err
+		}
+		parent.Gateway = gw
+		route.Parents = append(route.Parents, parent)
+	}
+
+	rules, _, _ := unstructured.NestedSlice(obj.Object, "spec", "rules")
+	seen := map[string]bool{}
+	pathFound := false
+	for _, rawRule := range rules {
+		rule, ok := rawRule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		matches, _, _ := unstructured.NestedSlice(rule, "matches")
+		for _, rawMatch := range matches {
+			match, ok := rawMatch.(map[string]interface{})
+			if !ok || pathFound {
+				continue
+			}
+			path, _, _ := unstructured.NestedMap(match, "path")
+			if value := stringField(path, "value", ""); value != "" && stringField(path, "type", "") != "RegularExpression" {
+				route.Path, pathFound = value, true
+			}
+		}
+		refs, _, _ := unstructured.NestedSlice(rule, "backendRefs")
+		for _, raw := range refs {
+			ref, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			backend := RouteBackend{
+				Kind:      stringField(ref, "kind", "Service"),
+				Namespace: stringField(ref, "namespace", namespace),
+				Name:      stringField(ref, "name", ""),
+			}
+			if port, ok := ref["port"].(int64); ok {
+				backend.Port = int32(port)
+			}
+			key := fmt.Sprintf("%s/%s/%s/%d", backend.Kind, backend.Namespace, backend.Name, backend.Port)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			if isKind(ref, "", "Service") {
+				if err := c.resolveRouteBackend(ctx, &backend); err != nil {
+					return nil, err
+				}
+			}
+			route.Backends = append(route.Backends, backend)
+		}
+	}
+	return route, nil
+}
+
+// AttachedListeners returns the gateway listeners a route of kind can
+// attach to through this parent reference
+func (p RouteParent) AttachedListeners(kind string) []GatewayListener {
+	if p.Gateway == nil {
+		return nil
+	}
+	var listeners []GatewayListener
+	for _, l := range p.Gateway.Listeners {
+		switch {
+		case p.SectionName != "" && l.Name != p.SectionName:
+			continue
+		case p.Port != 0 && l.Port != p.Port:
+			continue
+		case kind == KindHTTPRoute && l.Protocol != "HTTP" && l.Protocol != "HTTPS":
+			continue
+		case kind == KindTCPRoute && l.Protocol != "TCP":
+			continue
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners
+}
+
+// applyStatus fills the parent's conditions from the route status written
+// by the gateway's controller
+func (p *RouteParent) applyStatus(statuses []interface{}, routeNamespace string) {
+	for _, raw := range statuses {
+		status, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		ref, _, _ := unstructured.NestedMap(status, "parentRef")
+		if stringField(ref, "name", "") != p.Name || stringField(ref, "namespace", routeNamespace) != p.Namespace ||
+			stringField(ref, "sectionName", "") != p.SectionName {
+			continue
+		}
+
+		// Controllers that do not report ResolvedRefs resolved them anyway
+		p.Reported, p.ResolvedRefs = true, true
+		conditions, _, _ := unstructured.NestedSlice(status, "conditions")
+		for _, rawCond := range conditions {
+			cond, ok := rawCond.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			isTrue := stringField(cond, "status", "") == string(metav1.ConditionTrue)
+			switch stringField(cond, "type", "") {
+			case "Accepted":
+				p.Accepted = isTrue
+			case "ResolvedRefs":
+				p.ResolvedRefs = isTrue
+			default:
+				continue
+			}
+			if !isTrue && p.Message == "" {
+				p.Message = strings.TrimSpace(stringField(cond, "reason", "") + ": " + stringField(cond, "message", ""))
+			}
+		}
+		return
+	}
+}
+
+// getGateway returns a gateway, or nil when it does not exist
+func (c *Client) getGateway(ctx context.Context, namespace, name string) (*Gateway, error) {
+	obj, err := c.Dynamic.Resource(GatewayGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gateway %s/%s: %w", namespace, name, err)
+	}
+
+	gw := &Gateway{Namespace: namespace, Name: name}
+	gw.ClassName, _, _ = unstructured.NestedString(obj.Object, "spec", "gatewayClassName")
+
+	listeners, _, _ := unstructured.NestedSlice(obj.Object, "spec", "listeners")
+	for _, raw := range listeners {
+		l, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		listener := GatewayListener{
+			Name:     stringField(l, "name", ""),
+			Protocol: stringField(l, "protocol", ""),
+			Hostname: stringField(l, "hostname", ""),
+		}
+		if port, ok := l["port"].(int64); ok {
+			listener.Port = int32(port)
+		}
+		gw.Listeners = append(gw.Listeners, listener)
+	}
+
+	addresses, _, _ := unstructured.NestedSlice(obj.Object, "status", "addresses")
+	for _, raw := range addresses {
+		if address, ok := raw.(map[string]interface{}); ok {
+			if value := stringField(address, "value", ""); value != "" {
+				gw.Addresses = append(gw.Addresses, value)
+			}
+		}
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, raw := range conditions {
+		cond, ok := raw.(map[string]interface{})
+		if !ok || stringField(cond, "type", "") != "Programmed" {
+			continue
+		}
+		gw.Programmed = stringField(cond, "status", "") == string(metav1.ConditionTrue)
+		if !gw.Programmed {
+			gw.Message = strings.TrimSpace(stringField(cond, "reason", "") + ": " + stringField(cond, "message", ""))
+		}
+	}
+	return gw, nil
+}
+
+// resolveRouteBackend looks up a Service backend and counts its endpoints
+func (c *Client) resolveRouteBackend(ctx context.Context, backend *RouteBackend) error {
+	_, err := c.Clientset.CoreV1().Services(backend.Namespace).Get(ctx, backend.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get service %s/%s: %w", backend.Namespace, backend.Name, err)
+	}
+	backend.Found = true
+
+	endpoints, err := c.serviceEndpoints(ctx, backend.Namespace, backend.Name)
+	if err != nil {
+		return err
+	}
+	// Slices list target ports, not the service port, so count addresses
+	addresses := map[string]bool{}
+	for _, endpoint := range endpoints {
+		if addresses[endpoint.Address] {
+			continue
+		}
+		addresses[endpoint.Address] = true
+		backend.Endpoints++
+		if endpoint.Ready {
+			backend.ReadyEndpoints++
+		}
+	}
+	return nil
+}
+
+// isKind reports whether a Gateway API object reference points at kind in
+// group, applying the API's defaults for omitted fields
+func isKind(ref map[string]interface{}, group, kind string) bool {
+	defaultGroup := ""
+	if kind == "Gateway" {
+		defaultGroup = "gateway.networking.k8s.io"
+	}
+	return stringField(ref, "kind", kind) == kind && stringField(ref, "group", defaultGroup) == group
+}
+
+// isObjectNotFound tells a missing object apart from a missing resource
+// type, which the API server also reports as NotFound
+func isObjectNotFound(err error) bool {
+	return !strings.Contains(err.Error(), "could not find the requested resource")
+}
+
+// stringField returns a string field of an unstructured map, or fallback
+// when it is missing or empty
+func stringField(obj map[string]interface{}, field, fallback string) string {
+	if value, ok := obj[field].(string); ok && value != "" {
+		return value
+	}
+	return fallback
+}
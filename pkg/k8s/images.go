@@ -0,0 +1,33 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/images"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ErrUnpinnedImage is returned by clients with VerifyImages set for pods
+// whose images are not pinned by digest
+var ErrUnpinnedImage = errors.New("image is not pinned by digest")
+
+// verifyPodImages checks, when VerifyImages is set, that every container of
+// pod runs an image pinned by digest
+func (c *Client) verifyPodImages(pod *corev1.Pod) error {
+	for _, list := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range list {
+			if err := c.verifyImage(container.Image); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Client) verifyImage(image string) error {
+	if c.VerifyImages && !images.Pinned(image) {
+		return fmt.Errorf("%w: %s (use name:tag@sha256:<digest>)", ErrUnpinnedImage, image)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodManager creates the temporary pods pocket runs, follows them until
+// they start or finish, and cleans them up
+type PodManager interface {
+	CreatePod(ctx context.Context, config PodConfig) (*corev1.Pod, error)
+	DeletePod(ctx context.Context, namespace, name string) error
+	WatchPodRunning(ctx context.Context, namespace, name string, timeout time.Duration, onStage PodStageFunc) error
+	WatchPodCompletion(ctx context.Context, namespace, name string, timeout time.Duration, onStage PodStageFunc) (*corev1.Pod, error)
+	GetPodLogs(ctx context.Context, namespace, name string) (string, error)
+	AddEphemeralContainer(ctx context.Context, namespace, podName string, container corev1.EphemeralContainer, timeout time.Duration) error
+}
+
+// Execer runs commands in, and attaches to, the containers of running pods
+type Execer interface {
+	Exec(ctx context.Context, opts ExecOptions) error
+	Attach(ctx context.Context, opts ExecOptions) error
+	ExecOutput(ctx context.Context, namespace, podName, container string, command []string) (output string, exitOK bool, err error)
+}
+
+// PodInspector reads the state, events and resource footprint of pods
+type PodInspector interface {
+	GetPod(ctx context.Context, namespace, name string) (*corev1.Pod, error)
+	PodEvents(ctx context.Context, namespace, name string) ([]corev1.Event, error)
+	CollectFootprint(ctx context.Context, pod *corev1.Pod, fp *Footprint) error
+	SampleUsage(ctx context.Context, namespace, name string, interval time.Duration, fp *Footprint)
+	// DefaultNamespace is the namespace used when none is given
+	DefaultNamespace() string
+}
+
+// PortForwarder forwards local ports to pods
+type PortForwarder interface {
+	PortForward(opts PortForwardOptions) error
+}
+
+var (
+	_ PodManager    = (*Client)(nil)
+	_ Execer        = (*Client)(nil)
+	_ PodInspector  = (*Client)(nil)
+	_ PortForwarder = (*Client)(nil)
+)
+
+// ErrNoRESTConfig is returned by calls that need a Client's REST config
+// when it has none, as when created with NewClientForClientset for a fake
+// clientset: exec, attach and port-forward connect to the API server
+// directly rather than through the clientset
+var ErrNoRESTConfig = errors.New("client has no REST config for streaming connections")
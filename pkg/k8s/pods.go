@@ -1,16 +1,25 @@
 package k8s
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+	"k8s.io/utils/ptr"
 )
 
 // PodConfig holds configuration for creating a pod
@@ -23,72 +32,325 @@ type PodConfig struct {
 	Env       []corev1.EnvVar
 	TTY       bool
 	Stdin     bool
+	// Labels and Annotations are added to the pod; pocket's own labels
+	// cannot be overridden
+	Labels      map[string]string
+	Annotations map[string]string
+	// ImagePullSecrets are names of Secrets used to pull Image
+	ImagePullSecrets []string
+	// ServiceAccountName is the pod's identity; empty uses the namespace default
+	ServiceAccountName string
+	// Scheduling controls
+	NodeName     string
+	NodeSelector map[string]string
+	Tolerations  []corev1.Toleration
+	Affinity     *corev1.Affinity
+	// HostNetwork and HostPID run the pod in its node's network and
+	// process namespaces
+	HostNetwork bool
+	HostPID     bool
+	// Volumes are mounted into the main container at VolumeMounts
+	Volumes      []corev1.Volume
+	VolumeMounts []corev1.VolumeMount
+	// FSGroup, if set, owns the pod's volumes and is a supplemental group
+	// of its containers, so files with group-only permissions are readable
+	FSGroup *int64
+	// Resources are the container's requests and limits
+	Resources corev1.ResourceRequirements
+	// SecurityContext defaults to RestrictedSecurityContext(DefaultRunAsUser)
+	// and also applies to sidecars without their own
+	SecurityContext *corev1.SecurityContext
+	// Sidecars run next to the main container for the pod's lifetime and
+	// start before it (native sidecars, Kubernetes 1.29+)
+	Sidecars []corev1.Container
+	// Containers run next to the main container, e.g. one per tool
+	Containers []corev1.Container
+	// Purpose says what the pod is for, e.g. test or shell, as LabelPurpose
+	Purpose string
+	// Kept marks a pod left running after its command with LabelKept
+	Kept bool
+	// ActiveDeadline bounds how long the pod may run before Kubernetes
+	// stops it, so pods pocket abandons stop using quota; 0 uses
+	// DefaultActiveDeadline
+	ActiveDeadline time.Duration
+	// NeverReady keeps the pod out of Service endpoints with a readiness
+	// gate nothing sets, for pods carrying an application's labels
+	NeverReady bool
+}
+
+// DefaultActiveDeadline is the longest a pod without its own deadline runs
+const DefaultActiveDeadline = 24 * time.Hour
+
+// Labels pocket puts on the pods it creates
+const (
+	// LabelManagedBy is "kubectl-pocket" on everything pocket creates
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+	// LabelPurpose says what a pod is for
+	LabelPurpose = "kubectl-pocket/purpose"
+	// LabelTester names the tester a pod runs
+	LabelTester = "kubectl-pocket/tester"
+	// LabelKept marks pods kept with --keep
+	LabelKept = "kubectl-pocket/kept"
+	// LabelCreatedAt is the Unix time the pod was created by pocket
+	LabelCreatedAt = "kubectl-pocket/created-at"
+)
+
+// ConditionNeverReady is the readiness gate of NeverReady pods
+const ConditionNeverReady corev1.PodConditionType = "kubectl-pocket/never-ready"
+
+// DefaultRunAsUser is the UID pods run as by default ("nobody")
+const DefaultRunAsUser int64 = 65534
+
+// RestrictedSecurityContext returns a container security context that
+// satisfies the restricted Pod Security Standard
+func RestrictedSecurityContext(uid int64) *corev1.SecurityContext {
+	return &corev1.SecurityContext{
+		RunAsNonRoot:             ptr.To(true),
+		RunAsUser:                ptr.To(uid),
+		AllowPrivilegeEscalation: ptr.To(false),
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
 }
 
 // CreatePod creates a new pod with the given configuration
 func (c *Client) CreatePod(ctx context.Context, config PodConfig) (*corev1.Pod, error) {
-	pod := &corev1.Pod{
+	pod := NewPod(config)
+	if err := c.verifyPodImages(pod); err != nil {
+		return nil, err
+	}
+	c.stampAudit(ctx, pod)
+	c.logPodCommand(pod)
+	c.logSpec("pod "+pod.Namespace+"/"+pod.Name, pod)
+
+	var created *corev1.Pod
+	err := retryOnCredentialExpiry(func() error {
+		var err error
+		created, err = c.Clientset.CoreV1().Pods(config.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+		return err
+	})
+	return created, err
+}
+
+// DryRunPod submits the pod config to the API server without persisting it,
+// running every admission check a real create would
+func (c *Client) DryRunPod(ctx context.Context, config PodConfig) error {
+	pod := NewPod(config)
+	if err := c.verifyPodImages(pod); err != nil {
+		return err
+	}
+	c.stampAudit(ctx, pod)
+	return retryOnCredentialExpiry(func() error {
+		_, err := c.Clientset.CoreV1().Pods(config.Namespace).Create(ctx, pod, metav1.CreateOptions{
+			DryRun: []string{metav1.DryRunAll},
+		})
+		return err
+	})
+}
+
+// NewPod builds the pod CreatePod would create from config
+func NewPod(config PodConfig) *corev1.Pod {
+	var pullSecrets []corev1.LocalObjectReference
+	for _, name := range config.ImagePullSecrets {
+		pullSecrets = append(pullSecrets, corev1.LocalObjectReference{Name: name})
+	}
+
+	securityContext := config.SecurityContext
+	if securityContext == nil {
+		securityContext = RestrictedSecurityContext(DefaultRunAsUser)
+	}
+
+	labels := map[string]string{}
+	for k, v := range config.Labels {
+		labels[k] = v
+	}
+	labels[LabelManagedBy] = "kubectl-pocket"
+	labels["kubectl-pocket/temporary"] = "true"
+	if config.Purpose != "" {
+		labels[LabelPurpose] = config.Purpose
+	}
+	if config.Kept {
+		labels[LabelKept] = "true"
+	}
+	labels[LabelCreatedAt] = strconv.FormatInt(time.Now().Unix(), 10)
+
+	var podSecurityContext *corev1.PodSecurityContext
+	if config.FSGroup != nil {
+		podSecurityContext = &corev1.PodSecurityContext{FSGroup: config.FSGroup}
+	}
+
+	deadline := config.ActiveDeadline
+	if deadline <= 0 {
+		deadline = DefaultActiveDeadline
+	}
+
+	var readinessGates []corev1.PodReadinessGate
+	if config.NeverReady {
+		readinessGates = []corev1.PodReadinessGate{{ConditionType: ConditionNeverReady}}
+	}
+
+	var initContainers []corev1.Container
+	for _, sidecar := range config.Sidecars {
+		sidecar.RestartPolicy = ptr.To(corev1.ContainerRestartPolicyAlways)
+		if sidecar.SecurityContext == nil {
+			sidecar.SecurityContext = securityContext
+		}
+		initContainers = append(initContainers, sidecar)
+	}
+
+	containers := []corev1.Container{
+		{
+			Name:            "main",
+			Image:           config.Image,
+			Command:         config.Command,
+			Args:            config.Args,
+			Env:             config.Env,
+			TTY:             config.TTY,
+			Stdin:           config.Stdin,
+			Resources:       config.Resources,
+			VolumeMounts:    config.VolumeMounts,
+			SecurityContext: securityContext,
+		},
+	}
+	for _, container := range config.Containers {
+		if container.SecurityContext == nil {
+			container.SecurityContext = securityContext
+		}
+		containers = append(containers, container)
+	}
+
+	return &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      config.Name,
-			Namespace: config.Namespace,
-			Labels: map[string]string{
-				"app.kubernetes.io/managed-by": "kubectl-pocket",
-				"kubectl-pocket/temporary":     "true",
-			},
+			Name:        config.Name,
+			Namespace:   config.Namespace,
+			Labels:      labels,
+			Annotations: config.Annotations,
 		},
 		Spec: corev1.PodSpec{
-			RestartPolicy: corev1.RestartPolicyNever,
-			Containers: []corev1.Container{
-				{
-					Name:    "main",
-					Image:   config.Image,
-					Command: config.Command,
-					Args:    config.Args,
-					Env:     config.Env,
-					TTY:     config.TTY,
-					Stdin:   config.Stdin,
-				},
-			},
+			RestartPolicy:         corev1.RestartPolicyNever,
+			ActiveDeadlineSeconds: ptr.To(int64(deadline.Seconds())),
+			ImagePullSecrets:      pullSecrets,
+			ServiceAccountName:    config.ServiceAccountName,
+			NodeName:              config.NodeName,
+			NodeSelector:          config.NodeSelector,
+			Tolerations:           config.Tolerations,
+			Affinity:              config.Affinity,
+			HostNetwork:           config.HostNetwork,
+			HostPID:               config.HostPID,
+			Volumes:               config.Volumes,
+			SecurityContext:       podSecurityContext,
+			InitContainers:        initContainers,
+			Containers:            containers,
+			ReadinessGates:        readinessGates,
 		},
 	}
-
-	return c.Clientset.CoreV1().Pods(config.Namespace).Create(ctx, pod, metav1.CreateOptions{})
 }
 
 // DeletePod deletes a pod by name
 func (c *Client) DeletePod(ctx context.Context, namespace, name string) error {
-	return c.Clientset.CoreV1().Pods(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	return retryOnCredentialExpiry(func() error {
+		return c.Clientset.CoreV1().Pods(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	})
 }
 
-// WaitForPodRunning waits until the pod is in Running state
-func (c *Client) WaitForPodRunning(ctx context.Context, namespace, name string, timeout time.Duration) error {
+// WaitForPodDeleted waits until the pod is gone
+func (c *Client) WaitForPodDeleted(ctx context.Context, namespace, name string, timeout time.Duration) error {
+	var auth authRetrier
 	return wait.PollUntilContextTimeout(ctx, time.Second, timeout, true, func(ctx context.Context) (bool, error) {
-		pod, err := c.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
-		if err != nil {
+		_, err := c.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			return true, nil
+		case err != nil && !auth.transient(err):
 			return false, err
 		}
-		return pod.Status.Phase == corev1.PodRunning, nil
+		return false, nil
 	})
 }
 
-// WaitForPodCompletion waits until the pod completes (Succeeded or Failed)
-func (c *Client) WaitForPodCompletion(ctx context.Context, namespace, name string, timeout time.Duration) (*corev1.Pod, error) {
-	var resultPod *corev1.Pod
-	err := wait.PollUntilContextTimeout(ctx, time.Second, timeout, true, func(ctx context.Context) (bool, error) {
-		pod, err := c.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
-		if err != nil {
-			return false, err
+// PendingReason explains why a pod has not started, from its scheduling
+// condition or a waiting container. It returns "" for pods past Pending.
+func PendingReason(pod *corev1.Pod) string {
+	if pod == nil || pod.Status.Phase != corev1.PodPending {
+		return ""
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse {
+			return strings.TrimSpace(cond.Reason + ": " + cond.Message)
 		}
-		resultPod = pod
-		return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed, nil
-	})
-	return resultPod, err
+	}
+	statuses := append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if waiting := status.State.Waiting; waiting != nil && waiting.Reason != "" {
+			return strings.TrimSpace(fmt.Sprintf("container %s %s: %s", status.Name, waiting.Reason, waiting.Message))
+		}
+	}
+	return "pod is pending"
+}
+
+// GetPod reads a pod
+func (c *Client) GetPod(ctx context.Context, namespace, name string) (*corev1.Pod, error) {
+	return c.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
 }
 
 // GetPodLogs retrieves logs from a pod
 func (c *Client) GetPodLogs(ctx context.Context, namespace, name string) (string, error) {
-	req := c.Clientset.CoreV1().Pods(namespace).GetLogs(name, &corev1.PodLogOptions{})
-	logs, err := req.Stream(ctx)
+	return c.getPodLogs(ctx, namespace, name, &corev1.PodLogOptions{})
+}
+
+// PodEvents returns the events about a pod, oldest first
+func (c *Client) PodEvents(ctx context.Context, namespace, name string) ([]corev1.Event, error) {
+	var events *corev1.EventList
+	err := retryOnCredentialExpiry(func() error {
+		var err error
+		events, err = c.Clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("involvedObject.name", name).String(),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := events.Items
+	sort.SliceStable(items, func(i, j int) bool { return EventTime(items[i]).Before(EventTime(items[j])) })
+	return items, nil
+}
+
+// EventTime returns when an event last happened
+func EventTime(event corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// TailPodLogs returns the last lines of a pod's logs
+func (c *Client) TailPodLogs(ctx context.Context, namespace, name string, lines int64) (string, error) {
+	return c.getPodLogs(ctx, namespace, name, &corev1.PodLogOptions{TailLines: &lines})
+}
+
+// StreamPodLogs opens a stream of a pod's logs, which follows them with
+// opts.Follow until ctx is done. The caller closes it.
+func (c *Client) StreamPodLogs(ctx context.Context, namespace, name string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	var logs io.ReadCloser
+	err := retryOnCredentialExpiry(func() error {
+		var err error
+		logs, err = c.Clientset.CoreV1().Pods(namespace).GetLogs(name, opts).Stream(ctx)
+		return err
+	})
+	return logs, err
+}
+
+func (c *Client) getPodLogs(ctx context.Context, namespace, name string, opts *corev1.PodLogOptions) (string, error) {
+	logs, err := c.StreamPodLogs(ctx, namespace, name, opts)
 	if err != nil {
 		return "", err
 	}
@@ -116,10 +378,23 @@ type ExecOptions struct {
 	Stdout    io.Writer
 	Stderr    io.Writer
 	TTY       bool
+	// Resize, if set, reports the local terminal size to a TTY session
+	Resize TerminalSizeQueue
 }
 
+// TerminalSizeQueue reports terminal size changes; Next blocks until the
+// size changes and returns nil when there are no more
+type TerminalSizeQueue = remotecommand.TerminalSizeQueue
+
+// TerminalSize is the width and height of a terminal
+type TerminalSize = remotecommand.TerminalSize
+
 // Exec executes a command in a pod
 func (c *Client) Exec(ctx context.Context, opts ExecOptions) error {
+	c.logf(LogCommands, "exec in %s/%s[%s]: %s", opts.Namespace, opts.PodName, opts.Container, quoteCommand(opts.Command))
+	if c.Config == nil {
+		return ErrNoRESTConfig
+	}
 	req := c.Clientset.CoreV1().RESTClient().Post().
 		Resource("pods").
 		Name(opts.PodName).
@@ -140,9 +415,88 @@ func (c *Client) Exec(ctx context.Context, opts ExecOptions) error {
 	}
 
 	return exec.StreamWithContext(ctx, remotecommand.StreamOptions{
-		Stdin:  opts.Stdin,
-		Stdout: opts.Stdout,
-		Stderr: opts.Stderr,
-		Tty:    opts.TTY,
+		Stdin:             opts.Stdin,
+		Stdout:            opts.Stdout,
+		Stderr:            opts.Stderr,
+		Tty:               opts.TTY,
+		TerminalSizeQueue: opts.Resize,
 	})
 }
+
+// Attach attaches to the main process of a running container, such as an
+// ephemeral container started with Stdin and TTY. opts.Command is ignored.
+func (c *Client) Attach(ctx context.Context, opts ExecOptions) error {
+	c.logf(LogCommands, "attach to %s/%s[%s]", opts.Namespace, opts.PodName, opts.Container)
+	if c.Config == nil {
+		return ErrNoRESTConfig
+	}
+	req := c.Clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(opts.PodName).
+		Namespace(opts.Namespace).
+		SubResource("attach").
+		VersionedParams(&corev1.PodAttachOptions{
+			Container: opts.Container,
+			Stdin:     opts.Stdin != nil,
+			Stdout:    opts.Stdout != nil,
+			Stderr:    opts.Stderr != nil && !opts.TTY,
+			TTY:       opts.TTY,
+		}, scheme.ParameterCodec)
+
+	attach, err := remotecommand.NewSPDYExecutor(c.Config, "POST", req.URL())
+	if err != nil {
+		return fmt.Errorf("failed to create executor: %w", err)
+	}
+
+	stderr := opts.Stderr
+	if opts.TTY {
+		// A TTY merges stderr into stdout
+		stderr = nil
+	}
+	return attach.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:             opts.Stdin,
+		Stdout:            opts.Stdout,
+		Stderr:            stderr,
+		Tty:               opts.TTY,
+		TerminalSizeQueue: opts.Resize,
+	})
+}
+
+// FirstRunningPod returns the first Running pod matching the label selector
+func (c *Client) FirstRunningPod(ctx context.Context, namespace, selector string) (*corev1.Pod, error) {
+	pods, err := c.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == corev1.PodRunning {
+			return &pods.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no running pods match %s", selector)
+}
+
+// ExecOutput runs a non-interactive command in a pod and returns its
+// combined stdout and stderr. exitOK is false when the command ran but
+// exited non-zero; err reports failures to run it at all.
+func (c *Client) ExecOutput(ctx context.Context, namespace, podName, container string, command []string) (output string, exitOK bool, err error) {
+	var buf bytes.Buffer
+	err = c.Exec(ctx, ExecOptions{
+		Namespace: namespace,
+		PodName:   podName,
+		Container: container,
+		Command:   command,
+		Stdout:    &buf,
+		Stderr:    &buf,
+	})
+
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) {
+		return buf.String(), false, nil
+	}
+	if err != nil {
+		return buf.String(), false, err
+	}
+	return buf.String(), true, nil
+}
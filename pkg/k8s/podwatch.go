@@ -0,0 +1,140 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+// PodStage is a step of a pod's startup
+type PodStage string
+
+const (
+	// PodScheduling means the pod waits for a node
+	PodScheduling PodStage = "scheduling"
+	// PodPulling means the pod has a node that pulls its images and
+	// creates its containers
+	PodPulling PodStage = "pulling"
+	// PodStarted means the pod's containers started; it may have finished
+	PodStarted PodStage = "started"
+)
+
+// PodStageFunc is called when a watched pod moves to a new stage
+type PodStageFunc func(stage PodStage, pod *corev1.Pod)
+
+// StageOf returns the startup stage pod is in
+func StageOf(pod *corev1.Pod) PodStage {
+	switch {
+	case pod.Status.Phase != corev1.PodPending:
+		return PodStarted
+	case pod.Spec.NodeName == "":
+		return PodScheduling
+	default:
+		return PodPulling
+	}
+}
+
+// WaitForPodRunning waits until the pod is in Running state
+func (c *Client) WaitForPodRunning(ctx context.Context, namespace, name string, timeout time.Duration) error {
+	return c.WatchPodRunning(ctx, namespace, name, timeout, nil)
+}
+
+// WatchPodRunning waits until the pod is in Running state and tells
+// onStage, if set, as it moves through its startup stages. It fails early
+// if the pod finishes without running.
+func (c *Client) WatchPodRunning(ctx context.Context, namespace, name string, timeout time.Duration, onStage PodStageFunc) error {
+	_, err := c.watchPod(ctx, namespace, name, timeout, onStage, func(pod *corev1.Pod) (bool, error) {
+		switch pod.Status.Phase {
+		case corev1.PodRunning:
+			return true, nil
+		case corev1.PodSucceeded, corev1.PodFailed:
+			return false, fmt.Errorf("pod %s %s instead of running", name, strings.ToLower(string(pod.Status.Phase)))
+		}
+		return false, nil
+	})
+	return err
+}
+
+// WaitForPodCompletion waits until the pod completes (Succeeded or Failed)
+func (c *Client) WaitForPodCompletion(ctx context.Context, namespace, name string, timeout time.Duration) (*corev1.Pod, error) {
+	return c.WatchPodCompletion(ctx, namespace, name, timeout, nil)
+}
+
+// WatchPodCompletion waits until the pod completes (Succeeded or Failed)
+// and tells onStage, if set, as it moves through its startup stages. The
+// pod is returned as last seen, also on timeout.
+func (c *Client) WatchPodCompletion(ctx context.Context, namespace, name string, timeout time.Duration, onStage PodStageFunc) (*corev1.Pod, error) {
+	return c.watchPod(ctx, namespace, name, timeout, onStage, func(pod *corev1.Pod) (bool, error) {
+		return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed, nil
+	})
+}
+
+// watchPod watches the pod until done returns true, instead of polling, so
+// state changes are seen immediately. It returns the pod as last seen.
+func (c *Client) watchPod(ctx context.Context, namespace, name string, timeout time.Duration, onStage PodStageFunc, done func(pod *corev1.Pod) (bool, error)) (*corev1.Pod, error) {
+	ctx, cancel := watchtools.ContextWithOptionalTimeout(ctx, timeout)
+	defer cancel()
+
+	exists := func(store cache.Store) (bool, error) {
+		_, found, err := store.GetByKey(namespace + "/" + name)
+		if err != nil {
+			return false, err
+		}
+		if !found {
+			return false, apierrors.NewNotFound(corev1.Resource("pods"), name)
+		}
+		return false, nil
+	}
+
+	var last *corev1.Pod
+	var stage PodStage
+	var state string
+	_, err := watchtools.UntilWithSync(ctx, c.podListWatch(namespace, name), &corev1.Pod{}, exists, func(event watch.Event) (bool, error) {
+		if event.Type == watch.Deleted {
+			return false, fmt.Errorf("pod %s was deleted", name)
+		}
+		pod, ok := event.Object.(*corev1.Pod)
+		if !ok {
+			return false, nil
+		}
+		last = pod
+		if current := podState(pod); current != state {
+			state = current
+			c.logf(LogCommands, "pod %s/%s: %s", namespace, name, state)
+		}
+		if current := StageOf(pod); onStage != nil && current != stage {
+			stage = current
+			onStage(stage, pod)
+		}
+		return done(pod)
+	})
+	return last, err
+}
+
+// podListWatch lists and watches the single pod namespace/name
+func (c *Client) podListWatch(namespace, name string) cache.ListerWatcher {
+	selector := fields.OneTermEqualSelector("metadata.name", name).String()
+	pods := c.Clientset.CoreV1().Pods(namespace)
+	// Fake clientsets cannot stream the initial list as watch events; the
+	// wrapper tells the reflector to list first for them
+	return cache.ToListWatcherWithWatchListSemantics(&cache.ListWatch{
+		ListWithContextFunc: func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = selector
+			return pods.List(ctx, options)
+		},
+		WatchFuncWithContext: func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = selector
+			return pods.Watch(ctx, options)
+		},
+	}, c.Clientset)
+}
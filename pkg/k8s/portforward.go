@@ -0,0 +1,85 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// PortForwardOptions holds options for forwarding local ports to a pod
+type PortForwardOptions struct {
+	Namespace string
+	PodName   string
+	// Addresses are the local addresses to listen on
+	Addresses []string
+	// Ports are "local:remote" port pairs
+	Ports []string
+	// StopChan stops forwarding when closed
+	StopChan <-chan struct{}
+	// ReadyChan is closed once the listeners are up
+	ReadyChan chan struct{}
+	Out       io.Writer
+	ErrOut    io.Writer
+}
+
+// PortForward forwards local ports to a pod until StopChan is closed or the
+// connection is lost. Each call dials a new connection, so credentials from
+// exec plugins are refreshed on reconnect.
+func (c *Client) PortForward(opts PortForwardOptions) error {
+	if c.Config == nil {
+		return ErrNoRESTConfig
+	}
+	pfURL := c.Clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(opts.Namespace).
+		Name(opts.PodName).
+		SubResource("portforward").
+		URL()
+
+	transport, upgrader, err := spdy.RoundTripperFor(c.Config)
+	if err != nil {
+		return fmt.Errorf("failed to create round tripper: %w", err)
+	}
+
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, pfURL)
+
+	pf, err := portforward.NewOnAddresses(dialer, opts.Addresses, opts.Ports, opts.StopChan, opts.ReadyChan, opts.Out, opts.ErrOut)
+	if err != nil {
+		return fmt.Errorf("failed to create port-forwarder: %w", err)
+	}
+
+	return pf.ForwardPorts()
+}
+
+// PodGone returns a channel that is closed once the pod is deleted, is
+// terminating or has stopped running, as when a rollout or an eviction
+// replaces it. The pod is checked every interval until ctx is done; errors
+// other than NotFound are ignored.
+func (c *Client) PodGone(ctx context.Context, namespace, name string, interval time.Duration) <-chan struct{} {
+	gone := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			pod, err := c.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) || (err == nil && (pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning)) {
+				close(gone)
+				return
+			}
+		}
+	}()
+	return gone
+}
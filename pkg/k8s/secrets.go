@@ -0,0 +1,77 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateSecret creates a temporary Opaque secret
+func (c *Client) CreateSecret(ctx context.Context, namespace, name string, data map[string][]byte) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "kubectl-pocket",
+				"kubectl-pocket/temporary":     "true",
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+
+	return retryOnCredentialExpiry(func() error {
+		_, err := c.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+		return err
+	})
+}
+
+// DeleteSecret deletes a secret by name
+func (c *Client) DeleteSecret(ctx context.Context, namespace, name string) error {
+	return retryOnCredentialExpiry(func() error {
+		return c.Clientset.CoreV1().Secrets(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	})
+}
+
+// GetSecretValue returns the value of one key of a secret
+func (c *Client) GetSecretValue(ctx context.Context, namespace, name, key string) ([]byte, error) {
+	var secret *corev1.Secret
+	err := retryOnCredentialExpiry(func() error {
+		var err error
+		secret, err = c.Clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s has no key %q", namespace, name, key)
+	}
+	return value, nil
+}
+
+// GetConfigMapValue returns the value of one key of a config map
+func (c *Client) GetConfigMapValue(ctx context.Context, namespace, name, key string) (string, error) {
+	var configMap *corev1.ConfigMap
+	err := retryOnCredentialExpiry(func() error {
+		var err error
+		configMap, err = c.Clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if value, ok := configMap.Data[key]; ok {
+		return value, nil
+	}
+	if value, ok := configMap.BinaryData[key]; ok {
+		return string(value), nil
+	}
+	return "", fmt.Errorf("config map %s/%s has no key %q", namespace, name, key)
+}
@@ -0,0 +1,77 @@
+package k8s
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ServiceMonitorGVR identifies Prometheus Operator ServiceMonitor resources
+var ServiceMonitorGVR = schema.GroupVersionResource{
+	Group:    "monitoring.coreos.com",
+	Version:  "v1",
+	Resource: "servicemonitors",
+}
+
+// MonitorEndpoint is the scrape configuration of a ServiceMonitor endpoint
+type MonitorEndpoint struct {
+	ServiceMonitor string
+	// Port is the name of the Service port to scrape
+	Port   string
+	Path   string
+	Scheme string
+}
+
+// FindServiceMonitorEndpoint returns the first ServiceMonitor endpoint in the
+// namespace whose selector matches a Service with the given labels. It
+// returns nil when none matches or the ServiceMonitor CRD is not installed.
+func (c *Client) FindServiceMonitorEndpoint(ctx context.Context, namespace string, serviceLabels map[string]string) (*MonitorEndpoint, error) {
+	list, err := c.Dynamic.Resource(ServiceMonitorGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, sm := range list.Items {
+		selectorMap, found, _ := unstructured.NestedMap(sm.Object, "spec", "selector")
+		if !found {
+			continue
+		}
+
+		var selector metav1.LabelSelector
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(selectorMap, &selector); err != nil {
+			continue
+		}
+		sel, err := metav1.LabelSelectorAsSelector(&selector)
+		if err != nil || !sel.Matches(labels.Set(serviceLabels)) {
+			continue
+		}
+
+		endpoints, _, _ := unstructured.NestedSlice(sm.Object, "spec", "endpoints")
+		for _, raw := range endpoints {
+			ep, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			endpoint := &MonitorEndpoint{ServiceMonitor: sm.GetName(), Path: "/metrics", Scheme: "http"}
+			endpoint.Port, _ = ep["port"].(string)
+			if path, ok := ep["path"].(string); ok && path != "" {
+				endpoint.Path = path
+			}
+			if scheme, ok := ep["scheme"].(string); ok && scheme != "" {
+				endpoint.Scheme = scheme
+			}
+			return endpoint, nil
+		}
+	}
+
+	return nil, nil
+}
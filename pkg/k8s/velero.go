@@ -0,0 +1,213 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Velero resources
+var (
+	VeleroBackupGVR                = veleroGVR("backups")
+	VeleroRestoreGVR               = veleroGVR("restores")
+	VeleroScheduleGVR              = veleroGVR("schedules")
+	VeleroBackupStorageLocationGVR = veleroGVR("backupstoragelocations")
+	VeleroDeleteBackupRequestGVR   = veleroGVR("deletebackuprequests")
+)
+
+func veleroGVR(resource string) schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: "velero.io", Version: "v1", Resource: resource}
+}
+
+// Velero backup and restore phases
+const (
+	VeleroPhaseCompleted        = "Completed"
+	VeleroPhasePartiallyFailed  = "PartiallyFailed"
+	VeleroPhaseFailed           = "Failed"
+	VeleroPhaseFailedValidation = "FailedValidation"
+)
+
+// BackupStorageLocation summarizes a Velero object store
+type BackupStorageLocation struct {
+	Name     string
+	Provider string
+	Bucket   string
+	Default  bool
+	// Phase is Available once Velero has validated access to the bucket
+	Phase          string
+	Message        string
+	LastValidation time.Time
+}
+
+// BackupSchedule summarizes a Velero Schedule
+type BackupSchedule struct {
+	Name       string
+	Schedule   string
+	Paused     bool
+	Phase      string
+	LastBackup time.Time
+	Created    time.Time
+}
+
+// VeleroOperation is the state of a Backup or Restore
+type VeleroOperation struct {
+	Phase         string
+	FailureReason string
+	Errors        int64
+	Warnings      int64
+	// ValidationErrors is set when Phase is FailedValidation
+	ValidationErrors []string
+}
+
+// Done reports whether the operation reached a terminal phase
+func (o *VeleroOperation) Done() bool {
+	switch o.Phase {
+	case VeleroPhaseCompleted, VeleroPhasePartiallyFailed, VeleroPhaseFailed, VeleroPhaseFailedValidation:
+		return true
+	}
+	return false
+}
+
+// ListBackupStorageLocations returns the storage locations in Velero's namespace
+func (c *Client) ListBackupStorageLocations(ctx context.Context, namespace string) ([]BackupStorageLocation, error) {
+	list, err := c.Dynamic.Resource(VeleroBackupStorageLocationGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make([]BackupStorageLocation, 0, len(list.Items))
+	for _, item := range list.Items {
+		location := BackupStorageLocation{Name: item.GetName()}
+		location.Provider, _, _ = unstructured.NestedString(item.Object, "spec", "provider")
+		location.Bucket, _, _ = unstructured.NestedString(item.Object, "spec", "objectStorage", "bucket")
+		location.Default, _, _ = unstructured.NestedBool(item.Object, "spec", "default")
+		location.Phase, _, _ = unstructured.NestedString(item.Object, "status", "phase")
+		location.Message, _, _ = unstructured.NestedString(item.Object, "status", "message")
+		location.LastValidation = nestedTime(item.Object, "status", "lastValidationTime")
+		locations = append(locations, location)
+	}
+	return locations, nil
+}
+
+// ListBackupSchedules returns the schedules in Velero's namespace
+func (c *Client) ListBackupSchedules(ctx context.Context, namespace string) ([]BackupSchedule, error) {
+	list, err := c.Dynamic.Resource(VeleroScheduleGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make([]BackupSchedule, 0, len(list.Items))
+	for _, item := range list.Items {
+		schedule := BackupSchedule{Name: item.GetName(), Created: item.GetCreationTimestamp().Time}
+		schedule.Schedule, _, _ = unstructured.NestedString(item.Object, "spec", "schedule")
+		schedule.Paused, _, _ = unstructured.NestedBool(item.Object, "spec", "paused")
+		schedule.Phase, _, _ = unstructured.NestedString(item.Object, "status", "phase")
+		schedule.LastBackup = nestedTime(item.Object, "status", "lastBackup")
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// CreateBackup creates a Velero Backup of a single namespace. An empty
+// storageLocation uses Velero's default location.
+func (c *Client) CreateBackup(ctx context.Context, veleroNamespace, name, includeNamespace, storageLocation string) error {
+	spec := map[string]interface{}{
+		"includedNamespaces": []interface{}{includeNamespace},
+		"ttl":                "1h0m0s",
+	}
+	if storageLocation != "" {
+		spec["storageLocation"] = storageLocation
+	}
+
+	backup := newVeleroObject("Backup", veleroNamespace, name, spec)
+	_, err := c.Dynamic.Resource(VeleroBackupGVR).Namespace(veleroNamespace).Create(ctx, backup, metav1.CreateOptions{})
+	return err
+}
+
+// CreateRestore creates a Velero Restore of backupName, renaming namespaces
+// according to namespaceMapping
+func (c *Client) CreateRestore(ctx context.Context, veleroNamespace, name, backupName string, namespaceMapping map[string]string) error {
+	mapping := map[string]interface{}{}
+	for from, to := range namespaceMapping {
+		mapping[from] = to
+	}
+
+	restore := newVeleroObject("Restore", veleroNamespace, name, map[string]interface{}{
+		"backupName":       backupName,
+		"namespaceMapping": mapping,
+	})
+	_, err := c.Dynamic.Resource(VeleroRestoreGVR).Namespace(veleroNamespace).Create(ctx, restore, metav1.CreateOptions{})
+	return err
+}
+
+// DeleteBackup asks Velero to delete a Backup together with its data in
+// object storage. Deleting the Backup resource directly would leave the data
+// behind.
+func (c *Client) DeleteBackup(ctx context.Context, veleroNamespace, name string) error {
+	request := newVeleroObject("DeleteBackupRequest", veleroNamespace, name+"-delete", map[string]interface{}{
+		"backupName": name,
+	})
+	_, err := c.Dynamic.Resource(VeleroDeleteBackupRequestGVR).Namespace(veleroNamespace).Create(ctx, request, metav1.CreateOptions{})
+	return err
+}
+
+// DeleteRestore deletes a Restore by name
+func (c *Client) DeleteRestore(ctx context.Context, veleroNamespace, name string) error {
+	return c.Dynamic.Resource(VeleroRestoreGVR).Namespace(veleroNamespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+// WaitForVeleroOperation waits until a Backup or Restore reaches a terminal
+// phase. The last observed state is returned even when the wait times out.
+func (c *Client) WaitForVeleroOperation(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string, timeout time.Duration) (*VeleroOperation, error) {
+	var last *VeleroOperation
+	err := wait.PollUntilContextTimeout(ctx, 2*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		obj, err := c.Dynamic.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		op := &VeleroOperation{}
+		op.Phase, _, _ = unstructured.NestedString(obj.Object, "status", "phase")
+		op.FailureReason, _, _ = unstructured.NestedString(obj.Object, "status", "failureReason")
+		op.Errors, _, _ = unstructured.NestedInt64(obj.Object, "status", "errors")
+		op.Warnings, _, _ = unstructured.NestedInt64(obj.Object, "status", "warnings")
+		op.ValidationErrors, _, _ = unstructured.NestedStringSlice(obj.Object, "status", "validationErrors")
+		last = op
+		return op.Done(), nil
+	})
+	if err != nil {
+		return last, fmt.Errorf("%s %s did not finish: %w", gvr.Resource, name, err)
+	}
+	return last, nil
+}
+
+func newVeleroObject(kind, namespace, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "velero.io/v1",
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+				"labels": map[string]interface{}{
+					"app.kubernetes.io/managed-by": "kubectl-pocket",
+					"kubectl-pocket/temporary":     "true",
+				},
+			},
+			"spec": spec,
+		},
+	}
+}
+
+// nestedTime parses an RFC 3339 timestamp field, returning the zero time
+// when it is missing or malformed
+func nestedTime(obj map[string]interface{}, fields ...string) time.Time {
+	value, _, _ := unstructured.NestedString(obj, fields...)
+	t, _ := time.Parse(time.RFC3339, value)
+	return t
+}
@@ -0,0 +1,106 @@
+package k8s
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Verbosity levels of a Client's Logf
+const (
+	// LogCommands logs the commands run in pods and pod state changes
+	LogCommands = 1
+	// LogSpecs also logs the specs of the pods and containers created
+	LogSpecs = 2
+	// LogRequests also logs every API request with its status and latency
+	LogRequests = 3
+)
+
+// v reports whether messages of level are logged
+func (c *Client) v(level int) bool {
+	return c.Logf != nil && c.Verbosity >= level
+}
+
+// logf logs a message of level
+func (c *Client) logf(level int, format string, args ...any) {
+	if c.v(level) {
+		c.Logf(format, args...)
+	}
+}
+
+// logSpec logs obj as YAML at LogSpecs
+func (c *Client) logSpec(what string, obj any) {
+	if !c.v(LogSpecs) {
+		return
+	}
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		c.Logf("%s: %v", what, err)
+		return
+	}
+	c.Logf("%s:\n%s", what, strings.TrimRight(string(data), "\n"))
+}
+
+// logPodCommand logs the command of pod's main container at LogCommands
+func (c *Client) logPodCommand(pod *corev1.Pod) {
+	if !c.v(LogCommands) || len(pod.Spec.Containers) == 0 {
+		return
+	}
+	main := pod.Spec.Containers[0]
+	c.Logf("pod %s/%s runs %s: %s", pod.Namespace, pod.Name, main.Image, quoteCommand(append(append([]string{}, main.Command...), main.Args...)))
+}
+
+// traceRequests returns a transport that logs requests through rt at
+// LogRequests
+func (c *Client) traceRequests(rt http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if !c.v(LogRequests) {
+			return rt.RoundTrip(req)
+		}
+		start := time.Now()
+		resp, err := rt.RoundTrip(req)
+		took := time.Since(start).Round(time.Millisecond)
+		if err != nil {
+			c.Logf("%s %s failed in %s: %v", req.Method, req.URL.RequestURI(), took, err)
+		} else {
+			c.Logf("%s %s %s in %s", req.Method, req.URL.RequestURI(), resp.Status, took)
+		}
+		return resp, err
+	})
+}
+
+// podState summarizes the parts of pod's status a wait depends on, so
+// that changes can be logged
+func podState(pod *corev1.Pod) string {
+	parts := []string{"phase=" + string(pod.Status.Phase)}
+	if pod.Spec.NodeName != "" {
+		parts = append(parts, "node="+pod.Spec.NodeName)
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		state := "running"
+		switch {
+		case status.State.Waiting != nil:
+			state = status.State.Waiting.Reason
+		case status.State.Terminated != nil:
+			state = status.State.Terminated.Reason
+		}
+		parts = append(parts, status.Name+"="+state)
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteCommand joins command for display, quoting arguments that contain
+// spaces or quotes
+func quoteCommand(command []string) string {
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
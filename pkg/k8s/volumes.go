@@ -0,0 +1,103 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VolumeUsage is the usage of a PersistentVolumeClaim
+type VolumeUsage struct {
+	Claim string `json:"claim"`
+	// UsedBytes is -1 when the kubelet's stats could not be read
+	UsedBytes     int64 `json:"usedBytes"`
+	CapacityBytes int64 `json:"capacityBytes"`
+}
+
+// statsSummary is the part of the kubelet's /stats/summary pocket reads
+type statsSummary struct {
+	Pods []struct {
+		Volumes []struct {
+			PVCRef *struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"pvcRef"`
+			UsedBytes     *int64 `json:"usedBytes"`
+			CapacityBytes *int64 `json:"capacityBytes"`
+		} `json:"volume"`
+	} `json:"pods"`
+}
+
+// ClaimUsage returns the usage of the PersistentVolumeClaims mounted by pods,
+// keyed by claim name. Used bytes come from the stats summary of the
+// kubelets the pods run on, read through the API server's node proxy, which
+// needs nodes/proxy access; without it only the claims' capacity is known.
+func (c *Client) ClaimUsage(ctx context.Context, namespace string, pods []corev1.Pod) (map[string]VolumeUsage, error) {
+	usage := map[string]VolumeUsage{}
+	nodes := map[string]bool{}
+	for _, pod := range pods {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil {
+				continue
+			}
+			name := volume.PersistentVolumeClaim.ClaimName
+			pvc, err := c.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+			usage[name] = VolumeUsage{Claim: name, UsedBytes: -1, CapacityBytes: capacity.Value()}
+			if pod.Spec.NodeName != "" {
+				nodes[pod.Spec.NodeName] = true
+			}
+		}
+	}
+
+	for node := range nodes {
+		summary, err := c.nodeStats(ctx, node)
+		if err != nil {
+			// Capacity alone is still worth showing
+			continue
+		}
+		for _, pod := range summary.Pods {
+			for _, volume := range pod.Volumes {
+				if volume.PVCRef == nil || volume.PVCRef.Namespace != namespace || volume.UsedBytes == nil {
+					continue
+				}
+				u, ok := usage[volume.PVCRef.Name]
+				if !ok {
+					continue
+				}
+				u.UsedBytes = *volume.UsedBytes
+				if volume.CapacityBytes != nil {
+					u.CapacityBytes = *volume.CapacityBytes
+				}
+				usage[volume.PVCRef.Name] = u
+			}
+		}
+	}
+	return usage, nil
+}
+
+// nodeStats reads the kubelet's stats summary of node
+func (c *Client) nodeStats(ctx context.Context, node string) (*statsSummary, error) {
+	if c.Config == nil {
+		return nil, ErrNoRESTConfig
+	}
+	data, err := c.Clientset.CoreV1().RESTClient().Get().
+		Resource("nodes").
+		Name(node).
+		SubResource("proxy").
+		Suffix("stats", "summary").
+		DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var summary statsSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
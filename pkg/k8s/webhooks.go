@@ -0,0 +1,98 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Webhook describes a single admission webhook endpoint
+type Webhook struct {
+	// Kind is "validating" or "mutating"
+	Kind          string
+	Configuration string
+	Name          string
+	URL           string
+	Host          string
+	Port          int
+	// ServerName is the name the serving certificate must be valid for
+	ServerName    string
+	CABundle      []byte
+	FailurePolicy string
+}
+
+// ListWebhooks returns all validating and mutating admission webhooks
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+
+	validating, err := c.Clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list validating webhooks: %w", err)
+	}
+	for _, cfg := range validating.Items {
+		for _, wh := range cfg.Webhooks {
+			webhooks = append(webhooks, newWebhook("validating", cfg.Name, wh.Name, wh.ClientConfig, wh.FailurePolicy))
+		}
+	}
+
+	mutating, err := c.Clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mutating webhooks: %w", err)
+	}
+	for _, cfg := range mutating.Items {
+		for _, wh := range cfg.Webhooks {
+			webhooks = append(webhooks, newWebhook("mutating", cfg.Name, wh.Name, wh.ClientConfig, wh.FailurePolicy))
+		}
+	}
+
+	return webhooks, nil
+}
+
+// newWebhook resolves a webhook client config into a probe-able endpoint
+func newWebhook(kind, configuration, name string, cc admissionregistrationv1.WebhookClientConfig, policy *admissionregistrationv1.FailurePolicyType) Webhook {
+	wh := Webhook{
+		Kind:          kind,
+		Configuration: configuration,
+		Name:          name,
+		CABundle:      cc.CABundle,
+		FailurePolicy: string(admissionregistrationv1.Fail),
+	}
+	if policy != nil {
+		wh.FailurePolicy = string(*policy)
+	}
+
+	if svc := cc.Service; svc != nil {
+		port := 443
+		if svc.Port != nil {
+			port = int(*svc.Port)
+		}
+		path := ""
+		if svc.Path != nil {
+			path = *svc.Path
+		}
+		wh.ServerName = fmt.Sprintf("%s.%s.svc", svc.Name, svc.Namespace)
+		wh.Host = wh.ServerName
+		wh.Port = port
+		wh.URL = fmt.Sprintf("https://%s%s", net.JoinHostPort(wh.Host, strconv.Itoa(port)), path)
+		return wh
+	}
+
+	if cc.URL != nil {
+		wh.URL = *cc.URL
+		if u, err := url.Parse(*cc.URL); err == nil {
+			wh.Host = u.Hostname()
+			wh.ServerName = u.Hostname()
+			wh.Port = 443
+			if p, err := strconv.Atoi(u.Port()); err == nil {
+				wh.Port = p
+			}
+		}
+	}
+
+	return wh
+}
@@ -0,0 +1,106 @@
+// Package parallel runs tasks concurrently, at most a set number at a time,
+// each under its own timeout, and tells a Reporter how they progress.
+// Canceling the context given to a pool stops tasks that have not started
+// and is seen by the running ones, which clean up before Wait returns.
+package parallel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Task is a unit of work run by a Pool
+type Task struct {
+	// Name identifies the task to the Reporter
+	Name string
+	// Timeout bounds the context given to Run; 0 leaves it unbounded
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// Reporter is told when tasks start and finish. It is called from the
+// tasks' goroutines.
+type Reporter interface {
+	Started(name string)
+	Finished(name string, err error)
+}
+
+// Pool runs tasks with bounded concurrency
+type Pool struct {
+	slots    chan struct{}
+	wg       sync.WaitGroup
+	reporter Reporter
+}
+
+// New returns a pool running at most workers tasks at a time, reporting to
+// reporter if it is not nil
+func New(workers int, reporter Reporter) *Pool {
+	return &Pool{slots: make(chan struct{}, max(workers, 1)), reporter: reporter}
+}
+
+// Do runs task in the calling goroutine once a slot is free. If ctx is done
+// first, the task does not run and ctx's error is returned.
+func (p *Pool) Do(ctx context.Context, task Task) error {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-p.slots }()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if task.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, task.Timeout)
+		defer cancel()
+	}
+	if p.reporter != nil {
+		p.reporter.Started(task.Name)
+	}
+	err := task.Run(ctx)
+	if p.reporter != nil {
+		p.reporter.Finished(task.Name, err)
+	}
+	return err
+}
+
+// Go runs task as Do does in a new goroutine, calling done, if set, with
+// its error. Wait waits for it.
+func (p *Pool) Go(ctx context.Context, task Task, done func(error)) {
+	p.Spawn(func() {
+		err := p.Do(ctx, task)
+		if done != nil {
+			done(err)
+		}
+	})
+}
+
+// Spawn runs fn in a new goroutine without taking a slot, for work that
+// only waits for tasks it runs with Do or Go. Wait waits for it.
+func (p *Pool) Spawn(fn func()) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		fn()
+	}()
+}
+
+// Wait blocks until every task and spawned function has returned
+func (p *Pool) Wait() {
+	p.wg.Wait()
+}
+
+// Run runs tasks on a pool of workers and returns their errors, in the
+// order of tasks
+func Run(ctx context.Context, workers int, reporter Reporter, tasks []Task) []error {
+	errs := make([]error, len(tasks))
+	pool := New(workers, reporter)
+	for i, task := range tasks {
+		pool.Go(ctx, task, func(err error) { errs[i] = err })
+	}
+	pool.Wait()
+	return errs
+}
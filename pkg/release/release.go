@@ -0,0 +1,281 @@
+// Package release finds kubectl-pocket releases on GitHub and installs
+// their binaries in place of the running one.
+package release
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the GitHub API endpoint of the project's releases
+const DefaultAPIURL = "https://api.github.com/repos/enbiyagoral/kubectl-pocket/releases"
+
+// checksumsAsset lists the SHA-256 of every archive of a release
+const checksumsAsset = "checksums.txt"
+
+// binaryName is the executable inside the archives
+const binaryName = "kubectl-pocket"
+
+// maxArchiveSize bounds a download, well above the size of a release
+const maxArchiveSize = 256 << 20
+
+// ErrNotFound is returned when a release or one of its assets does not exist
+var ErrNotFound = errors.New("not found")
+
+// Release is a published version
+type Release struct {
+	Tag string `json:"tag_name"`
+	URL string `json:"html_url"`
+	// Assets map the file names of the release to their download URL
+	Assets map[string]string `json:"-"`
+}
+
+// Client reads releases from the GitHub API
+type Client struct {
+	APIURL string
+	HTTP   *http.Client
+	// Token is sent to GitHub to raise its rate limit when set
+	Token string
+}
+
+// New returns a client of the project's releases, authenticated with
+// GITHUB_TOKEN when it is set
+func New() *Client {
+	return &Client{
+		APIURL: DefaultAPIURL,
+		HTTP:   &http.Client{Timeout: 5 * time.Minute},
+		Token:  os.Getenv("GITHUB_TOKEN"),
+	}
+}
+
+// Latest returns the newest release that is not a pre-release
+func (c *Client) Latest(ctx context.Context) (*Release, error) {
+	return c.release(ctx, c.APIURL+"/latest")
+}
+
+// Get returns the release tagged tag, with or without its leading v
+func (c *Client) Get(ctx context.Context, tag string) (*Release, error) {
+	if !strings.HasPrefix(tag, "v") {
+		tag = "v" + tag
+	}
+	return c.release(ctx, c.APIURL+"/tags/"+tag)
+}
+
+func (c *Client) release(ctx context.Context, url string) (*Release, error) {
+	body, err := c.get(ctx, url, 1<<20)
+	if err != nil {
+		return nil, err
+	}
+	var payload struct {
+		Release
+		Assets []struct {
+			Name string `json:"name"`
+			URL  string `json:"browser_download_url"`
+		} `json:"assets"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse release: %w", err)
+	}
+	rel := payload.Release
+	rel.Assets = make(map[string]string, len(payload.Assets))
+	for _, asset := range payload.Assets {
+		rel.Assets[asset.Name] = asset.URL
+	}
+	return &rel, nil
+}
+
+// ArchiveName is the release archive built for goos and goarch
+func ArchiveName(goos, goarch string) string {
+	return fmt.Sprintf("%s_%s_%s.tar.gz", binaryName, goos, goarch)
+}
+
+// Download fetches the binary of rel for goos and goarch. The archive must
+// match its SHA-256 in the release's checksums before it is unpacked.
+func (c *Client) Download(ctx context.Context, rel *Release, goos, goarch string) ([]byte, error) {
+	archive := ArchiveName(goos, goarch)
+	archiveURL, ok := rel.Assets[archive]
+	if !ok {
+		return nil, fmt.Errorf("%s has no build for %s/%s: %w", rel.Tag, goos, goarch, ErrNotFound)
+	}
+	checksumsURL, ok := rel.Assets[checksumsAsset]
+	if !ok {
+		return nil, fmt.Errorf("%s has no %s: %w", rel.Tag, checksumsAsset, ErrNotFound)
+	}
+
+	checksums, err := c.get(ctx, checksumsURL, 1<<20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", checksumsAsset, err)
+	}
+	want, err := findChecksum(checksums, archive)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.get(ctx, archiveURL, maxArchiveSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", archive, err)
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", archive, want, got)
+	}
+	return extractBinary(data, goos)
+}
+
+// findChecksum returns the SHA-256 of name in a sha256sum style listing
+func findChecksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum for %s in %s: %w", name, checksumsAsset, ErrNotFound)
+}
+
+// extractBinary returns the executable from a tar.gz release archive
+func extractBinary(archive []byte, goos string) ([]byte, error) {
+	name := binaryName
+	if goos == "windows" {
+		name += ".exe"
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("no %s in archive: %w", name, ErrNotFound)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag == tar.TypeReg && filepath.Base(header.Name) == name {
+			return io.ReadAll(io.LimitReader(tr, maxArchiveSize))
+		}
+	}
+}
+
+// get returns the body of url, reading at most limit bytes
+func (c *Client) get(ctx context.Context, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.Token != "" && strings.HasPrefix(url, c.APIURL) {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%s: %w", url, ErrNotFound)
+	case resp.StatusCode/100 != 2:
+		return nil, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, limit))
+}
+
+// Newer reports whether version is newer than current. Versions are
+// compared as semantic versions with an optional leading v; a current
+// version that is not one, such as a dev build, is never older.
+func Newer(version, current string) bool {
+	v, ok := parseVersion(version)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	for i := range v {
+		if v[i] != c[i] {
+			return v[i] > c[i]
+		}
+	}
+	return false
+}
+
+// parseVersion parses major.minor.patch, ignoring pre-release and build
+// suffixes
+func parseVersion(version string) ([3]int, bool) {
+	var parsed [3]int
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return parsed, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return parsed, false
+		}
+		parsed[i] = n
+	}
+	return parsed, true
+}
+
+// Replace installs binary in place of the executable at path through a
+// file in the same directory, so a failed upgrade leaves the old one. A
+// running executable cannot be overwritten on Windows, so it is moved aside
+// first; the .old file is removed by the next upgrade.
+func Replace(path string, binary []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(binary); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o755); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		old := path + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(path, old); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp.Name(), path); err != nil {
+			_ = os.Rename(old, path)
+			return err
+		}
+		return nil
+	}
+	return os.Rename(tmp.Name(), path)
+}
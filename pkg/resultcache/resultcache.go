@@ -0,0 +1,118 @@
+// Package resultcache remembers recent successful test results on disk so
+// callers can skip re-testing a target that was just verified.
+package resultcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Entry is a cached successful test result
+type Entry struct {
+	Tester    string    `json:"tester"`
+	Namespace string    `json:"namespace"`
+	Server    string    `json:"server"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Store is a JSON file of entries keyed by Key
+type Store struct {
+	path string
+}
+
+// DefaultPath returns the cache file location under the user cache directory
+func DefaultPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "kubectl-pocket", "results.json")
+}
+
+// New returns a store backed by the file at path
+func New(path string) *Store {
+	return &Store{path: path}
+}
+
+// Key identifies a test target. Targets often embed credentials, so only a
+// hash of the inputs is stored.
+func Key(server, namespace, tester, target string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{server, namespace, tester, target}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Lookup returns the entry for key if it was recorded within ttl
+func (s *Store) Lookup(key string, ttl time.Duration) (Entry, bool) {
+	entries, err := s.load()
+	if err != nil {
+		return Entry{}, false
+	}
+
+	entry, ok := entries[key]
+	if !ok || time.Since(entry.CheckedAt) > ttl {
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// Record stores a successful result for key
+func (s *Store) Record(key string, entry Entry) error {
+	return s.update(func(entries map[string]Entry) {
+		entries[key] = entry
+	})
+}
+
+// Forget removes the entry for key, e.g. after a failed test
+func (s *Store) Forget(key string) error {
+	return s.update(func(entries map[string]Entry) {
+		delete(entries, key)
+	})
+}
+
+func (s *Store) update(fn func(map[string]Entry)) error {
+	if s.path == "" {
+		return nil
+	}
+
+	entries, err := s.load()
+	if err != nil {
+		// A corrupt cache is simply rebuilt
+		entries = map[string]Entry{}
+	}
+	fn(entries)
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return os.WriteFile(s.path, data, 0o600)
+}
+
+func (s *Store) load() (map[string]Entry, error) {
+	entries := map[string]Entry{}
+	if s.path == "" {
+		return entries, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
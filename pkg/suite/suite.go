@@ -0,0 +1,133 @@
+// Package suite loads the check files run by "pocket test suite".
+package suite
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Expected outcomes of a check
+const (
+	// ExpectPass requires the connection to succeed
+	ExpectPass = "pass"
+	// ExpectFail requires the connection to fail, e.g. to verify a
+	// NetworkPolicy blocks it
+	ExpectFail = "fail"
+)
+
+// Suite is a set of checks run together
+type Suite struct {
+	// Parallel bounds how many checks run at once; 0 leaves it to the
+	// command line
+	Parallel int `json:"parallel,omitempty"`
+	// Timeout is the default timeout of each check
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	Checks  []Check          `json:"checks"`
+}
+
+// Check is one connection test
+type Check struct {
+	// Name identifies the check in the report; defaults to type and target
+	Name string `json:"name,omitempty"`
+	// Type is the tester, e.g. postgres
+	Type string `json:"type"`
+	// Target is a connection string or a secret:// or configmap://
+	// reference to one
+	Target string `json:"target,omitempty"`
+	// From reads the target from a workload's environment instead, as
+	// deploy/<name> or pod/<name> with an optional :<container>
+	From string `json:"from,omitempty"`
+	// PasswordFromSecret reads the password from a Secret as
+	// <name>[:<key>]
+	PasswordFromSecret string `json:"passwordFromSecret,omitempty"`
+	// Namespace runs the check in another namespace than the current one
+	Namespace string `json:"namespace,omitempty"`
+	// Expect is pass (default) or fail
+	Expect  string           `json:"expect,omitempty"`
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// Load reads and validates a suite file; "-" reads stdin
+func Load(path string) (*Suite, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suite: %w", err)
+	}
+
+	s := &Suite{}
+	if err := yaml.UnmarshalStrict(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse suite %s: %w", path, err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid suite %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Validate checks the suite for missing and conflicting fields
+func (s *Suite) Validate() error {
+	if len(s.Checks) == 0 {
+		return fmt.Errorf("no checks")
+	}
+	if s.Parallel < 0 {
+		return fmt.Errorf("parallel must not be negative")
+	}
+
+	names := map[string]bool{}
+	for i, c := range s.Checks {
+		label := fmt.Sprintf("checks[%d]", i)
+		if c.Name != "" {
+			label = c.Name
+			if names[c.Name] {
+				return fmt.Errorf("duplicate check name %q", c.Name)
+			}
+			names[c.Name] = true
+		}
+
+		switch {
+		case c.Type == "":
+			return fmt.Errorf("%s: type is required", label)
+		case c.Target == "" && c.From == "":
+			return fmt.Errorf("%s: target or from is required", label)
+		case c.Target != "" && c.From != "":
+			return fmt.Errorf("%s: target and from are mutually exclusive", label)
+		}
+		switch c.Expect {
+		case "", ExpectPass, ExpectFail:
+		default:
+			return fmt.Errorf("%s: expect must be %s or %s", label, ExpectPass, ExpectFail)
+		}
+	}
+	return nil
+}
+
+// DisplayName returns the check's name, or its type and target
+func (c Check) DisplayName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	source := c.Target
+	if source == "" {
+		source = "from " + c.From
+	}
+	return strings.TrimSpace(c.Type + " " + source)
+}
+
+// Expected returns the expected outcome, defaulting to pass
+func (c Check) Expected() string {
+	if c.Expect == "" {
+		return ExpectPass
+	}
+	return c.Expect
+}
@@ -0,0 +1,78 @@
+package tester
+
+import (
+	"github.com/enbiyagoral/kubectl-pocket/pkg/images"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// AgentPodName is the name of the agent pod in its namespace
+const AgentPodName = "pocket-agent"
+
+// AgentImage is the agent's main container, a shell with basic network tools
+const AgentImage = "busybox:1.36"
+
+// agentKeepalive keeps a container running until the pod is deleted
+const agentKeepalive = `trap 'exit 0' TERM; while true; do sleep 3600 & wait; done`
+
+// AgentPodConfig returns the config of an agent pod in ns that serves
+// testers: next to its main container each tester gets a keepalive
+// container named after it, running image(t)
+func AgentPodConfig(ns string, testers []Tester, image func(t Tester) string) k8s.PodConfig {
+	config := k8s.PodConfig{
+		Name:      AgentPodName,
+		Namespace: ns,
+		Image:     images.Pin(AgentImage),
+		Command:   []string{"sh", "-c", agentKeepalive},
+		Purpose:   "agent",
+	}
+	for _, t := range testers {
+		config.Containers = append(config.Containers, corev1.Container{
+			Name:    t.Name(),
+			Image:   image(t),
+			Command: []string{"sh", "-c", agentKeepalive},
+		})
+	}
+	return config
+}
+
+// usesAgent reports whether tests with t run in the runner's Agent: it must
+// have a running container for t with t's image, and the pod options must
+// not need env vars or sidecars the agent was created without
+func (r *Runner) usesAgent(t Tester) bool {
+	if r.Agent == nil || r.FromPod != "" || r.Keep {
+		return false
+	}
+	if !agentServes(r.Agent, t.Name(), r.image(t)) {
+		return false
+	}
+	if r.ConfigurePod != nil {
+		var config k8s.PodConfig
+		if err := r.ConfigurePod(&config); err != nil || len(config.Env) > 0 || len(config.Sidecars) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// agentServes reports whether agent has a running container name with image
+func agentServes(agent *corev1.Pod, name, image string) bool {
+	found := false
+	for _, container := range agent.Spec.Containers {
+		if container.Name == name && container.Image == image {
+			found = true
+		}
+	}
+	for _, status := range agent.Status.ContainerStatuses {
+		if status.Name == name {
+			return found && status.State.Running != nil
+		}
+	}
+	return false
+}
+
+// agentProbe returns a probe pod for t that execs into the runner's Agent
+func (r *Runner) agentProbe(t Tester) *ProbePod {
+	r.progress(StepUseAgent, r.Agent.Namespace, r.Agent.Name)
+	return &ProbePod{Namespace: r.Agent.Namespace, Name: r.Agent.Name, Container: t.Name(), runner: r, tester: t, shared: true}
+}
@@ -0,0 +1,51 @@
+package tester
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Custom runs a user-supplied image and command. It is not registered;
+// callers construct it from their own configuration.
+type Custom struct {
+	// TesterName names the check in pod names; defaults to "custom"
+	TesterName string
+	ImageName  string
+	// Command is the exact container command; the target is ignored
+	Command []string
+	// SuccessRegex, if set, must match the output for the test to pass
+	SuccessRegex *regexp.Regexp
+}
+
+// Name implements Tester
+func (c Custom) Name() string {
+	if c.TesterName != "" {
+		return c.TesterName
+	}
+	return "custom"
+}
+
+// DisplayName implements Tester
+func (Custom) DisplayName() string { return "Custom" }
+
+// Image implements Tester
+func (c Custom) Image() string { return c.ImageName }
+
+// BuildArgs implements Tester
+func (c Custom) BuildArgs(string) ([]string, error) {
+	if len(c.Command) == 0 {
+		return nil, fmt.Errorf("custom tester has no command")
+	}
+	return c.Command, nil
+}
+
+// ParseResult implements Tester
+func (c Custom) ParseResult(output string, exitOK bool) error {
+	if !exitOK {
+		return fmt.Errorf("command exited with an error")
+	}
+	if c.SuccessRegex != nil && !c.SuccessRegex.MatchString(output) {
+		return fmt.Errorf("output did not match %q", c.SuccessRegex.String())
+	}
+	return nil
+}
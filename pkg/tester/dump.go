@@ -0,0 +1,136 @@
+package tester
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+)
+
+// Dumper is implemented by testers whose image has a tool that dumps the
+// database to stdout
+type Dumper interface {
+	// DumpCommand returns the container command that writes a dump of
+	// target to stdout
+	DumpCommand(target string) ([]string, error)
+	// DumpExtension is the file extension of the dump, e.g. ".sql"
+	DumpExtension() string
+}
+
+// Restorer is implemented by testers whose image has a tool that restores
+// a dump read from stdin
+type Restorer interface {
+	// RestoreCommand returns the container command that restores the dump
+	// on its stdin into target, failing on the first error
+	RestoreCommand(target string) ([]string, error)
+}
+
+// DumpCommand implements Dumper. pg_dump refuses servers of a newer major
+// version than its own; --image can supply a newer client.
+func (Postgres) DumpCommand(target string) ([]string, error) {
+	return []string{"pg_dump", "--no-password", target}, nil
+}
+
+// DumpExtension implements Dumper
+func (Postgres) DumpExtension() string { return ".sql" }
+
+// RestoreCommand implements Restorer. The dump is plain SQL, as written by
+// pg_dump without --format.
+func (Postgres) RestoreCommand(target string) ([]string, error) {
+	return []string{"psql", target, "-X", "-q", "-v", "ON_ERROR_STOP=1"}, nil
+}
+
+// DumpCommand implements Dumper. The dump is a mongorestore archive.
+func (m Mongo) DumpCommand(target string) ([]string, error) {
+	return m.command("mongodump", "--uri", target, "--archive"), nil
+}
+
+// DumpExtension implements Dumper
+func (Mongo) DumpExtension() string { return ".archive" }
+
+// RestoreCommand implements Restorer. The dump is a mongodump archive.
+func (m Mongo) RestoreCommand(target string) ([]string, error) {
+	return m.command("mongorestore", "--uri", target, "--archive"), nil
+}
+
+// DumpCommand implements Dumper. The server sends an RDB snapshot as it
+// would to a replica.
+func (r Redis) DumpCommand(target string) ([]string, error) {
+	args, err := r.cliArgs(target)
+	if err != nil {
+		return nil, err
+	}
+	return append(args, "--rdb", "-"), nil
+}
+
+// DumpExtension implements Dumper
+func (Redis) DumpExtension() string { return ".rdb" }
+
+// Dump runs t's dump tool against target in a probe pod and streams the
+// dump to out and the tool's messages to stderr. It runs until the dump
+// completes or ctx is done.
+func (r *Runner) Dump(ctx context.Context, t Tester, target string, out, stderr io.Writer) error {
+	dumper, ok := t.(Dumper)
+	if !ok {
+		return fmt.Errorf("%s cannot dump databases", t.Name())
+	}
+	command, err := dumper.DumpCommand(target)
+	if err != nil {
+		return err
+	}
+
+	probe, err := r.StartProbePod(ctx, t)
+	if err != nil {
+		return err
+	}
+	defer probe.Close()
+
+	r.progress(StepWaitCompletion, probe.Namespace, probe.Name)
+	err = r.Client.Exec(ctx, k8s.ExecOptions{
+		Namespace: probe.Namespace,
+		PodName:   probe.Name,
+		Container: probe.Container,
+		Command:   command,
+		Stdout:    out,
+		Stderr:    stderr,
+	})
+	if err != nil {
+		return fmt.Errorf("dump failed: %w", err)
+	}
+	return nil
+}
+
+// Restore runs t's restore tool against target in a probe pod, feeding it
+// the dump from in and streaming its messages to out and stderr
+func (r *Runner) Restore(ctx context.Context, t Tester, target string, in io.Reader, out, stderr io.Writer) error {
+	restorer, ok := t.(Restorer)
+	if !ok {
+		return fmt.Errorf("%s cannot restore databases", t.Name())
+	}
+	command, err := restorer.RestoreCommand(target)
+	if err != nil {
+		return err
+	}
+
+	probe, err := r.StartProbePod(ctx, t)
+	if err != nil {
+		return err
+	}
+	defer probe.Close()
+
+	r.progress(StepWaitCompletion, probe.Namespace, probe.Name)
+	err = r.Client.Exec(ctx, k8s.ExecOptions{
+		Namespace: probe.Namespace,
+		PodName:   probe.Name,
+		Container: probe.Container,
+		Command:   command,
+		Stdin:     in,
+		Stdout:    out,
+		Stderr:    stderr,
+	})
+	if err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package tester
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// EnvTargeter is implemented by testers that can find their target in an
+// application's environment variables
+type EnvTargeter interface {
+	// TargetFromEnv returns the target env configures and the variables it
+	// was built from
+	TargetFromEnv(env map[string]string) (target string, vars []string, ok bool)
+}
+
+// TargetFromEnv implements EnvTargeter
+func (Redis) TargetFromEnv(env map[string]string) (string, []string, bool) {
+	if name, value, ok := firstEnv(env, "REDIS_URL", "REDIS_URI", "REDIS_TLS_URL", "CACHE_URL"); ok && strings.HasPrefix(value, "redis") {
+		return value, []string{name}, true
+	}
+
+	hostVar, host, ok := firstEnv(env, "REDIS_HOST", "REDIS_HOSTNAME", "REDIS_SERVICE_HOST")
+	if !ok {
+		return "", nil, false
+	}
+	vars := []string{hostVar}
+	port := "6379"
+	if name, value, ok := firstEnv(env, "REDIS_PORT", "REDIS_SERVICE_PORT"); ok && !strings.Contains(value, "://") {
+		port = value
+		vars = append(vars, name)
+	}
+
+	target := url.URL{Scheme: "redis", Host: net.JoinHostPort(host, port)}
+	if name, password, ok := firstEnv(env, "REDIS_PASSWORD", "REDIS_AUTH"); ok {
+		target.User = url.UserPassword("", password)
+		vars = append(vars, name)
+	}
+	return target.String(), vars, true
+}
+
+// TargetFromEnv implements EnvTargeter
+func (Postgres) TargetFromEnv(env map[string]string) (string, []string, bool) {
+	for _, name := range []string{"DATABASE_URL", "POSTGRES_URL", "POSTGRESQL_URL", "PG_URL", "DB_URL"} {
+		if value := env[name]; strings.HasPrefix(value, "postgres://") || strings.HasPrefix(value, "postgresql://") {
+			return value, []string{name}, true
+		}
+	}
+
+	// libpq's own variables, then the common POSTGRES_* and DB_* spellings
+	for _, prefix := range [][]string{
+		{"PGHOST", "PGPORT", "PGUSER", "PGPASSWORD", "PGDATABASE"},
+		{"POSTGRES_HOST", "POSTGRES_PORT", "POSTGRES_USER", "POSTGRES_PASSWORD", "POSTGRES_DB"},
+		{"DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME"},
+	} {
+		if target, vars, ok := urlFromEnv(env, "postgres", "5432", prefix); ok {
+			return target, vars, true
+		}
+	}
+	return "", nil, false
+}
+
+// TargetFromEnv implements EnvTargeter
+func (Mongo) TargetFromEnv(env map[string]string) (string, []string, bool) {
+	for _, name := range []string{"MONGODB_URI", "MONGODB_URL", "MONGO_URI", "MONGO_URL", "DATABASE_URL"} {
+		if value := env[name]; strings.HasPrefix(value, "mongodb://") || strings.HasPrefix(value, "mongodb+srv://") {
+			return value, []string{name}, true
+		}
+	}
+
+	for _, prefix := range [][]string{
+		{"MONGODB_HOST", "MONGODB_PORT", "MONGODB_USERNAME", "MONGODB_PASSWORD", "MONGODB_DATABASE"},
+		{"MONGO_HOST", "MONGO_PORT", "MONGO_USERNAME", "MONGO_PASSWORD", "MONGO_DATABASE"},
+	} {
+		if target, vars, ok := urlFromEnv(env, "mongodb", "27017", prefix); ok {
+			return target, vars, true
+		}
+	}
+	return "", nil, false
+}
+
+// urlFromEnv builds a connection URL from host, port, user, password and
+// database variables, named in that order. The host is required.
+func urlFromEnv(env map[string]string, scheme, defaultPort string, names []string) (string, []string, bool) {
+	host, ok := env[names[0]]
+	if !ok || host == "" {
+		return "", nil, false
+	}
+	vars := []string{names[0]}
+
+	port := defaultPort
+	if value := env[names[1]]; value != "" {
+		port = value
+		vars = append(vars, names[1])
+	}
+
+	u := url.URL{Scheme: scheme, Host: net.JoinHostPort(host, port)}
+	if user := env[names[2]]; user != "" {
+		vars = append(vars, names[2])
+		if password := env[names[3]]; password != "" {
+			u.User = url.UserPassword(user, password)
+			vars = append(vars, names[3])
+		} else {
+			u.User = url.User(user)
+		}
+	}
+	if database := env[names[4]]; database != "" {
+		u.Path = "/" + database
+		vars = append(vars, names[4])
+	}
+	return u.String(), vars, true
+}
+
+// firstEnv returns the first of names set to a non-empty value
+func firstEnv(env map[string]string, names ...string) (name, value string, ok bool) {
+	for _, name := range names {
+		if value := env[name]; value != "" {
+			return name, value, true
+		}
+	}
+	return "", "", false
+}
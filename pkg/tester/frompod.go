@@ -0,0 +1,72 @@
+package tester
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// keepalivePIDFile records the PID of an ephemeral probe container's sleep,
+// which Close kills to stop the container
+const keepalivePIDFile = "/tmp/.pocket-keepalive"
+
+// startProbeContainer adds a keepalive ephemeral container for t to the
+// runner's FromPod and waits until it is running. Ephemeral containers
+// cannot be removed, so it stays in the pod spec after Close stops it.
+func (r *Runner) startProbeContainer(ctx context.Context, t Tester) (*ProbePod, error) {
+	name := PodName(t)
+	ns := r.Namespace
+
+	// Only the container settings of the pod options apply; the pod itself
+	// keeps its identity, scheduling and resources
+	config := k8s.PodConfig{Name: name, Namespace: ns, Image: r.image(t)}
+	if r.ConfigurePod != nil {
+		if err := r.ConfigurePod(&config); err != nil {
+			return nil, err
+		}
+	}
+	if len(config.Sidecars) > 0 {
+		return nil, fmt.Errorf("tests from an existing pod cannot run sidecars")
+	}
+	securityContext := config.SecurityContext
+	if securityContext == nil {
+		securityContext = k8s.RestrictedSecurityContext(k8s.DefaultRunAsUser)
+	}
+
+	r.progress(StepAddContainer, ns, r.FromPod)
+	start := time.Now()
+	container := corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:            name,
+			Image:           config.Image,
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command: []string{"sh", "-c",
+				fmt.Sprintf("sleep %d & echo $! > %s; wait", int(r.probeLifetime().Seconds()), keepalivePIDFile)},
+			Env:             config.Env,
+			SecurityContext: securityContext,
+		},
+	}
+	if err := r.Client.AddEphemeralContainer(ctx, ns, r.FromPod, container, 2*time.Minute); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPodNotStarted, err)
+	}
+
+	// The ephemeral container has no scheduling; its startup is the image
+	// pull and container creation
+	startup := Timings{ImagePull: time.Since(start)}
+	startup.Total = startup.ImagePull
+	return &ProbePod{Namespace: ns, Name: r.FromPod, Container: name, runner: r, tester: t, ephemeral: true, startup: startup}, nil
+}
+
+// stopProbeContainer ends the keepalive of an ephemeral probe container with
+// a fresh context so it runs even after the run's context was cancelled
+func (r *Runner) stopProbeContainer(p *ProbePod) {
+	r.progress(StepStopContainer, p.Namespace, p.Name)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	// Best effort: the keepalive sleep ends on its own
+	_, _, _ = r.Client.ExecOutput(ctx, p.Namespace, p.Name, p.Container,
+		[]string{"sh", "-c", "kill $(cat " + keepalivePIDFile + ")"})
+}
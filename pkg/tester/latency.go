@@ -0,0 +1,130 @@
+package tester
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// latencyMarker starts the line that ends each run's output in a latency
+// probe, followed by the exit code and the duration in nanoseconds
+const latencyMarker = "@@pocket-latency"
+
+// Latency is the outcome of a repeated probe
+type Latency struct {
+	// Samples are the durations of the passing runs, in run order
+	Samples []time.Duration
+	// Failed is the number of runs that did not pass
+	Failed int
+	// LastErr is the error of the last failed run
+	LastErr error
+}
+
+// Count returns the number of runs
+func (l *Latency) Count() int {
+	return len(l.Samples) + l.Failed
+}
+
+// Min returns the fastest passing run
+func (l *Latency) Min() time.Duration {
+	return l.Percentile(0)
+}
+
+// Max returns the slowest passing run
+func (l *Latency) Max() time.Duration {
+	return l.Percentile(100)
+}
+
+// Avg returns the mean of the passing runs
+func (l *Latency) Avg() time.Duration {
+	if len(l.Samples) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, s := range l.Samples {
+		total += s
+	}
+	return total / time.Duration(len(l.Samples))
+}
+
+// Percentile returns the nearest-rank p-th percentile of the passing runs
+func (l *Latency) Percentile(p float64) time.Duration {
+	if len(l.Samples) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), l.Samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+// Latency runs the test of target count times in a row inside the pod and
+// times each run there, so the exec round trip through the API server is
+// not measured. Each run is a full client invocation, including connecting.
+// The image needs a POSIX shell and a date supporting %N.
+func (p *ProbePod) Latency(ctx context.Context, target string, count int) (*Latency, error) {
+	command, err := p.tester.BuildArgs(target)
+	if err != nil {
+		return nil, err
+	}
+
+	script := fmt.Sprintf(`n=0
+while [ "$n" -lt %d ]; do
+  n=$((n+1))
+  s=$(date +%%s%%N)
+  %s 2>&1
+  rc=$?
+  e=$(date +%%s%%N)
+  echo
+  echo "%s $rc $((e-s))"
+done`, count, shellJoin(command), latencyMarker)
+
+	ctx, cancel := context.WithTimeout(ctx, p.runner.Timeout*time.Duration(count))
+	defer cancel()
+
+	output, _, err := p.runner.Client.ExecOutput(ctx, p.Namespace, p.Name, p.Container, []string{"sh", "-c", script})
+	if err != nil {
+		return nil, fmt.Errorf("failed to run probes: %w", err)
+	}
+	return p.parseLatency(output)
+}
+
+// parseLatency splits the output of a latency probe into runs
+func (p *ProbePod) parseLatency(output string) (*Latency, error) {
+	latency := &Latency{}
+	var run strings.Builder
+	for _, line := range strings.Split(output, "\n") {
+		rest, ok := strings.CutPrefix(line, latencyMarker+" ")
+		if !ok {
+			run.WriteString(line + "\n")
+			continue
+		}
+
+		fields := strings.Fields(rest)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("unexpected probe timing %q", line)
+		}
+		nanos, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to time probes, the image's date may not support %%N: %q", line)
+		}
+
+		if err := p.tester.ParseResult(run.String(), fields[0] == "0"); err != nil {
+			latency.Failed++
+			latency.LastErr = err
+		} else {
+			latency.Samples = append(latency.Samples, time.Duration(nanos))
+		}
+		run.Reset()
+	}
+
+	if latency.Count() == 0 {
+		return nil, fmt.Errorf("probe produced no timings: %s", strings.TrimSpace(output))
+	}
+	return latency, nil
+}
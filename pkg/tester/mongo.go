@@ -0,0 +1,135 @@
+package tester
+
+import (
+	"fmt"
+	"strings"
+)
+
+func init() {
+	Register(Mongo{})
+}
+
+// Mongo tests MongoDB connections with mongosh
+type Mongo struct {
+	// passwordEnv, if set, names the environment variable holding the
+	// password. mongosh has no such variable, so the shell adds it to the
+	// connection string.
+	passwordEnv string
+	// tls is set by WithTLS
+	tls TLS
+	// checkReplicaSet is set by WithReplicaSetCheck
+	checkReplicaSet bool
+}
+
+// mongoPasswordEnv holds an injected MongoDB password
+const mongoPasswordEnv = "POCKET_MONGO_PASSWORD"
+
+// mongoPasswordScript moves the password from $PASSWORD_ENV into the
+// connection string in $1, percent-encoded, and leaves it in $uri
+const mongoPasswordScript = `uri=$1; shift
+password=$PASSWORD_ENV; encoded=
+while [ -n "$password" ]; do
+  rest=${password#?}; c=${password%"$rest"}; password=$rest
+  case $c in
+    [A-Za-z0-9._~-]) encoded=$encoded$c ;;
+    *) encoded=$encoded$(printf '%%%02X' "'$c") ;;
+  esac
+done
+case $uri in
+  *://*@*) ;;
+  *) echo "the connection string names no user for the password" >&2; exit 1 ;;
+esac
+rest=${uri#*://}; user=${rest%%@*}
+uri=${uri%%://*}://${user%%:*}:$encoded@${rest#*@}
+`
+
+// Name implements Tester
+func (Mongo) Name() string { return "mongo" }
+
+// DisplayName implements Tester
+func (Mongo) DisplayName() string { return "MongoDB" }
+
+// Image implements Tester
+func (Mongo) Image() string { return "mongo:7" }
+
+// BuildArgs implements Tester
+func (m Mongo) BuildArgs(target string) ([]string, error) {
+	if err := checkMongoTarget(target); err != nil {
+		return nil, err
+	}
+	if m.checkReplicaSet {
+		return m.mongosh(target, "--eval", replicaSetScript, "--quiet"), nil
+	}
+	return m.mongosh(target, "--eval", "db.runCommand({ping: 1})", "--quiet"), nil
+}
+
+// ParseResult implements Tester. With WithReplicaSetCheck the replica set
+// must have a primary and no unreachable member.
+func (m Mongo) ParseResult(output string, exitOK bool) error {
+	if !exitOK {
+		if m.checkReplicaSet && strings.Contains(output, "NoReplicationEnabled") {
+			return fmt.Errorf("not a replica set member")
+		}
+		return fmt.Errorf("mongosh exited with an error")
+	}
+	if m.checkReplicaSet {
+		status, ok := parseReplicaSetStatus(output)
+		if !ok {
+			return fmt.Errorf("no replica set status in output")
+		}
+		return status.err()
+	}
+	return nil
+}
+
+// ShellCommand implements Sheller
+func (m Mongo) ShellCommand(target string) ([]string, error) {
+	if err := checkMongoTarget(target); err != nil {
+		return nil, err
+	}
+	return m.mongosh(target), nil
+}
+
+// ShellHint implements Sheller
+func (Mongo) ShellHint() string { return "Type 'exit' to quit." }
+
+// InjectPassword implements PasswordInjector
+func (m Mongo) InjectPassword() (Tester, string) {
+	m.passwordEnv = mongoPasswordEnv
+	return m, mongoPasswordEnv
+}
+
+// mongosh builds the mongosh command for target with extra arguments
+func (m Mongo) mongosh(target string, args ...string) []string {
+	return m.command("mongosh", "", target, args...)
+}
+
+// command builds the command that runs program with the connection string
+// target, given after uriFlag if set, then args and the TLS settings. It
+// runs through a shell when the password or client certificate must be
+// prepared in the pod.
+func (m Mongo) command(program, uriFlag, target string, args ...string) []string {
+	var uriArgs []string
+	if uriFlag != "" {
+		uriArgs = append(uriArgs, uriFlag)
+	}
+	args = append(args, m.tlsArgs(program)...)
+	prelude := m.tlsPrelude()
+	if m.passwordEnv == "" {
+		args = append(append(uriArgs, target), args...)
+		if prelude == "" {
+			return append([]string{program}, args...)
+		}
+		return append([]string{"/bin/sh", "-c", prelude + "exec " + program + ` "$@"`, program}, args...)
+	}
+
+	// The password is expanded inside the pod, so it never appears in the
+	// pod spec or on the local command line
+	script := strings.ReplaceAll(mongoPasswordScript, "PASSWORD_ENV", m.passwordEnv)
+	script += prelude + "exec " + program
+	for _, arg := range uriArgs {
+		script += " " + arg
+	}
+	script += ` "$uri" "$@"`
+	return append([]string{"/bin/sh", "-c", script, program, target}, args...)
+}
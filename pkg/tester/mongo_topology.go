@@ -0,0 +1,150 @@
+package tester
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// mongoSRVScheme is the scheme of targets whose hosts are SRV records
+const mongoSRVScheme = "mongodb+srv"
+
+// replicaSetMarker prefixes the replica set status in the probe output
+const replicaSetMarker = "pocket-rs "
+
+// replicaSetScript prints the members of the replica set as JSON. Optimes
+// are milliseconds, 0 for members that cannot be reached.
+const replicaSetScript = `const s = rs.status();
+print("` + replicaSetMarker + `" + JSON.stringify({
+  set: s.set,
+  members: s.members.map(m => ({
+    name: m.name,
+    state: m.stateStr,
+    health: m.health,
+    optime: m.optimeDate ? m.optimeDate.getTime() : 0,
+  })),
+}));`
+
+// WithReplicaSetCheck returns a tester that inspects the replica set with
+// rs.status(): it passes when there is a primary and every member is
+// reachable, and summarizes roles and replication lag
+func (m Mongo) WithReplicaSetCheck() Mongo {
+	m.checkReplicaSet = true
+	return m
+}
+
+// checkMongoTarget rejects mongodb+srv:// targets mongosh would not resolve:
+// an SRV record names one host and the ports come from the record
+func checkMongoTarget(target string) error {
+	if !strings.HasPrefix(target, mongoSRVScheme+"://") {
+		return nil
+	}
+	u, err := url.Parse(target)
+	if err != nil || u.Hostname() == "" {
+		return fmt.Errorf("invalid %s target %q", mongoSRVScheme, target)
+	}
+	if u.Port() != "" || strings.Contains(u.Host, ",") {
+		return fmt.Errorf("%s:// takes a single host without a port, got %q", mongoSRVScheme, u.Host)
+	}
+	return nil
+}
+
+// replicaSetMember is a member in the output of replicaSetScript
+type replicaSetMember struct {
+	Name   string  `json:"name"`
+	State  string  `json:"state"`
+	Health float64 `json:"health"`
+	Optime int64   `json:"optime"`
+}
+
+// replicaSetStatus is the output of replicaSetScript
+type replicaSetStatus struct {
+	Set     string             `json:"set"`
+	Members []replicaSetMember `json:"members"`
+}
+
+// parseReplicaSetStatus reads the status replicaSetScript printed
+func parseReplicaSetStatus(output string) (replicaSetStatus, bool) {
+	var status replicaSetStatus
+	for _, line := range strings.Split(output, "\n") {
+		if data, ok := strings.CutPrefix(strings.TrimSpace(line), replicaSetMarker); ok {
+			return status, json.Unmarshal([]byte(data), &status) == nil
+		}
+	}
+	return status, false
+}
+
+// primary returns the primary member, or nil if there is none
+func (s replicaSetStatus) primary() *replicaSetMember {
+	for i, member := range s.Members {
+		if member.State == "PRIMARY" {
+			return &s.Members[i]
+		}
+	}
+	return nil
+}
+
+// unreachable returns the names of the members that cannot be reached
+func (s replicaSetStatus) unreachable() []string {
+	var names []string
+	for _, member := range s.Members {
+		if member.Health == 0 {
+			names = append(names, member.Name)
+		}
+	}
+	return names
+}
+
+// replicationLag is how far member is behind primary
+func replicationLag(primary, member *replicaSetMember) time.Duration {
+	if member.Optime == 0 || member.Optime > primary.Optime {
+		return 0
+	}
+	return time.Duration(primary.Optime-member.Optime) * time.Millisecond
+}
+
+// err explains why the replica set is unhealthy, or returns nil
+func (s replicaSetStatus) err() error {
+	var problems []string
+	if s.primary() == nil {
+		problems = append(problems, "no primary")
+	}
+	if unreachable := s.unreachable(); len(unreachable) > 0 {
+		problems = append(problems, "unreachable members: "+strings.Join(unreachable, ", "))
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("replica set %s unhealthy: %s", valueOrUnknown(s.Set), strings.Join(problems, "; "))
+}
+
+// summary describes the role of each member, with the replication lag of
+// the secondaries, in a line
+func (s replicaSetStatus) summary() string {
+	primary := s.primary()
+	members := make([]string, len(s.Members))
+	for i := range s.Members {
+		member := &s.Members[i]
+		switch {
+		case member.Health == 0:
+			members[i] = member.Name + " unreachable"
+		case member.State == "SECONDARY" && primary != nil:
+			members[i] = fmt.Sprintf("%s SECONDARY (lag %s)", member.Name, replicationLag(primary, member))
+		default:
+			members[i] = member.Name + " " + member.State
+		}
+	}
+	return fmt.Sprintf("replica set %s: %s", valueOrUnknown(s.Set), strings.Join(members, ", "))
+}
+
+// Summarize implements Summarizer: the roles and lag of the replica set
+// members with WithReplicaSetCheck
+func (Mongo) Summarize(output string) string {
+	status, ok := parseReplicaSetStatus(output)
+	if !ok {
+		return ""
+	}
+	return status.summary()
+}
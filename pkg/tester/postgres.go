@@ -0,0 +1,57 @@
+package tester
+
+import "fmt"
+
+func init() {
+	Register(Postgres{})
+}
+
+// Postgres tests PostgreSQL connections with psql
+type Postgres struct {
+	// checkReplication is set by WithReplicationCheck
+	checkReplication bool
+}
+
+// Name implements Tester
+func (Postgres) Name() string { return "postgres" }
+
+// DisplayName implements Tester
+func (Postgres) DisplayName() string { return "PostgreSQL" }
+
+// Image implements Tester
+func (Postgres) Image() string { return "postgres:14-alpine" }
+
+// BuildArgs implements Tester
+func (p Postgres) BuildArgs(target string) ([]string, error) {
+	if p.checkReplication {
+		return []string{"psql", target, "-X", "-A", "-t", "-v", "ON_ERROR_STOP=1", "-c", replicationQuery}, nil
+	}
+	return []string{"psql", target, "-c", "SELECT 1 as connection_test;"}, nil
+}
+
+// ParseResult implements Tester. With WithReplicationCheck a replica must
+// be streaming from its upstream.
+func (p Postgres) ParseResult(output string, exitOK bool) error {
+	if !exitOK {
+		return fmt.Errorf("psql exited with an error")
+	}
+	if p.checkReplication {
+		status, ok := parseReplicationStatus(output)
+		if !ok {
+			return fmt.Errorf("no replication status in output")
+		}
+		return status.err()
+	}
+	return nil
+}
+
+// ShellCommand implements Sheller
+func (Postgres) ShellCommand(target string) ([]string, error) {
+	return []string{"psql", target}, nil
+}
+
+// ShellHint implements Sheller
+func (Postgres) ShellHint() string { return "Type '\\q' to quit." }
+
+// InjectPassword implements PasswordInjector
+func (p Postgres) InjectPassword() (Tester, string) { return p, "PGPASSWORD" }
@@ -0,0 +1,138 @@
+package tester
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// replicationMarker prefixes the replication status in the probe output
+const replicationMarker = "pocket-replication "
+
+// replicationQuery prints the role of the server and its replication as
+// JSON. A replica whose received WAL is all replayed has no lag, however
+// long ago the primary last wrote.
+const replicationQuery = `SELECT '` + replicationMarker + `' || json_build_object(
+  'in_recovery', pg_is_in_recovery(),
+  'upstream', (SELECT sender_host FROM pg_stat_wal_receiver LIMIT 1),
+  'receiver', (SELECT status FROM pg_stat_wal_receiver LIMIT 1),
+  'lag_bytes', CASE WHEN pg_is_in_recovery() THEN
+    pg_wal_lsn_diff(pg_last_wal_receive_lsn(), pg_last_wal_replay_lsn()) END,
+  'lag_seconds', CASE WHEN pg_is_in_recovery() THEN
+    CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+    ELSE extract(epoch FROM now() - pg_last_xact_replay_timestamp()) END END,
+  'standbys', (SELECT coalesce(json_agg(json_build_object(
+    'name', application_name,
+    'client', client_addr,
+    'state', state,
+    'sync', sync_state,
+    'lag_bytes', pg_wal_lsn_diff(CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn()
+      ELSE pg_current_wal_lsn() END, replay_lsn),
+    'lag_seconds', extract(epoch FROM replay_lag)
+  )), '[]') FROM pg_stat_replication)
+);`
+
+// WithReplicationCheck returns a tester that reports whether the server is
+// a primary or a replica, its connected standbys and the replication lag.
+// A replica fails the test when it is not streaming from its upstream.
+func (p Postgres) WithReplicationCheck() Postgres {
+	p.checkReplication = true
+	return p
+}
+
+// standby is a row of pg_stat_replication in the output of
+// replicationQuery
+type standby struct {
+	Name       string   `json:"name"`
+	Client     *string  `json:"client"`
+	State      string   `json:"state"`
+	Sync       string   `json:"sync"`
+	LagBytes   *float64 `json:"lag_bytes"`
+	LagSeconds *float64 `json:"lag_seconds"`
+}
+
+// replicationStatus is the output of replicationQuery
+type replicationStatus struct {
+	InRecovery bool      `json:"in_recovery"`
+	Upstream   *string   `json:"upstream"`
+	Receiver   *string   `json:"receiver"`
+	LagBytes   *float64  `json:"lag_bytes"`
+	LagSeconds *float64  `json:"lag_seconds"`
+	Standbys   []standby `json:"standbys"`
+}
+
+// parseReplicationStatus reads the status replicationQuery printed
+func parseReplicationStatus(output string) (replicationStatus, bool) {
+	var status replicationStatus
+	for _, line := range strings.Split(output, "\n") {
+		if data, ok := strings.CutPrefix(strings.TrimSpace(line), replicationMarker); ok {
+			return status, json.Unmarshal([]byte(data), &status) == nil
+		}
+	}
+	return status, false
+}
+
+// err explains why replication is broken, or returns nil
+func (s replicationStatus) err() error {
+	if s.InRecovery && (s.Receiver == nil || *s.Receiver != "streaming") {
+		return fmt.Errorf("replica is not streaming from its upstream (WAL receiver %s)", valueOrUnknown(deref(s.Receiver)))
+	}
+	return nil
+}
+
+// summary describes the role and replication of the server in a line
+func (s replicationStatus) summary() string {
+	var role string
+	if s.InRecovery {
+		role = fmt.Sprintf("replica of %s (%s), lag %s", valueOrUnknown(deref(s.Upstream)),
+			valueOrUnknown(deref(s.Receiver)), formatLag(s.LagBytes, s.LagSeconds))
+	} else {
+		role = "primary"
+	}
+	if len(s.Standbys) == 0 {
+		if s.InRecovery {
+			return role
+		}
+		return role + ", no standbys connected"
+	}
+
+	standbys := make([]string, len(s.Standbys))
+	for i, sb := range s.Standbys {
+		name := sb.Name
+		if name == "" {
+			name = valueOrUnknown(deref(sb.Client))
+		}
+		standbys[i] = fmt.Sprintf("%s %s %s lag %s", name, sb.State, sb.Sync, formatLag(sb.LagBytes, sb.LagSeconds))
+	}
+	return fmt.Sprintf("%s, %d standby(s): %s", role, len(s.Standbys), strings.Join(standbys, ", "))
+}
+
+// formatLag renders replication lag in bytes and time, either unknown
+func formatLag(lagBytes, lagSeconds *float64) string {
+	b, t := "? B", "?"
+	if lagBytes != nil {
+		b = fmt.Sprintf("%.0f B", *lagBytes)
+	}
+	if lagSeconds != nil {
+		t = (time.Duration(*lagSeconds * float64(time.Second))).Round(time.Millisecond).String()
+	}
+	return b + "/" + t
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// Summarize implements Summarizer: the role and replication of the server
+// with WithReplicationCheck
+func (Postgres) Summarize(output string) string {
+	status, ok := parseReplicationStatus(output)
+	if !ok {
+		return ""
+	}
+	return status.summary()
+}
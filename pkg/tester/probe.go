@@ -0,0 +1,197 @@
+package tester
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+)
+
+// ProbePod is a keepalive pod that runs repeated probes through exec, so
+// only the first probe pays for pod scheduling and image pulls
+type ProbePod struct {
+	Namespace string
+	Name      string
+	// Container runs the probes: "main" in a pocket pod, or an ephemeral
+	// container in the Runner's FromPod
+	Container string
+
+	runner *Runner
+	tester Tester
+	// ephemeral is set when the probes run in an existing pod, which Close
+	// must not delete
+	ephemeral bool
+	// shared is set when the probes run in the agent pod, which outlives
+	// the probe pod
+	shared bool
+	// startup is how long the pod or container took to start; zero for the
+	// agent pod
+	startup Timings
+}
+
+// StartProbePod creates a keepalive pod for t and waits until it is running.
+// With FromPod set it adds a keepalive ephemeral container to that pod
+// instead, and with an Agent serving t it uses the agent.
+func (r *Runner) StartProbePod(ctx context.Context, t Tester) (*ProbePod, error) {
+	if r.FromPod != "" {
+		return r.startProbeContainer(ctx, t)
+	}
+	if r.usesAgent(t) {
+		return r.agentProbe(t), nil
+	}
+
+	podName := PodName(t)
+	ns := r.Namespace
+
+	r.progress(StepCreatePod, ns, podName)
+	watch := newStopwatch()
+
+	lifetime := r.probeLifetime()
+	podConfig := k8s.PodConfig{
+		Name:           podName,
+		Namespace:      ns,
+		Image:          r.image(t),
+		Command:        []string{"sleep", strconv.Itoa(int(lifetime.Seconds()))},
+		Purpose:        "probe",
+		ActiveDeadline: lifetime,
+		Labels:         map[string]string{k8s.LabelTester: t.Name()},
+	}
+
+	if err := r.createPod(ctx, podConfig); err != nil {
+		return nil, fmt.Errorf("failed to create pod: %w", err)
+	}
+
+	r.progress(StepWaitRunning, ns, podName)
+	if err := r.Client.WatchPodRunning(ctx, ns, podName, 2*time.Minute, r.timedStages(watch)); err != nil {
+		err = r.startError(ns, podName, err)
+		r.cleanup(ns, podName)
+		return nil, err
+	}
+
+	return &ProbePod{Namespace: ns, Name: podName, Container: "main", runner: r, tester: t, startup: watch.startup()}, nil
+}
+
+// probeLifetime is how long a probe pod lives, with startupGrace for
+// scheduling and image pulls
+func (r *Runner) probeLifetime() time.Duration {
+	if r.Keep {
+		// Kept pods wait to be attached to, as long as kept test pods
+		return k8s.DefaultActiveDeadline
+	}
+	if r.ProbeLifetime > 0 {
+		return r.ProbeLifetime + startupGrace
+	}
+	lifetime := r.Timeout
+	backoff := r.RetryBackoff
+	for range r.Retries {
+		lifetime += backoff + r.Timeout
+		backoff *= 2
+	}
+	return lifetime + startupGrace
+}
+
+// Probe runs one test of target inside the pod. The returned error reports
+// infrastructure problems; a failed connection is reported in the Result.
+func (p *ProbePod) Probe(ctx context.Context, target string) (*Result, error) {
+	command, err := p.tester.BuildArgs(target)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.runner.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	output, exitOK, err := p.runner.Client.ExecOutput(ctx, p.Namespace, p.Name, p.Container, command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run probe: %w", err)
+	}
+	took := time.Since(start)
+
+	result := &Result{
+		Tester:    p.tester.Name(),
+		Target:    target,
+		Namespace: p.Namespace,
+		PodName:   p.Name,
+		Output:    output,
+		Timings:   Timings{Probe: took, Total: took},
+	}
+	result.parse(p.tester, output, exitOK)
+	return result, nil
+}
+
+// Close deletes the probe pod, or stops the ephemeral probe container. The
+// agent pod is left running.
+func (p *ProbePod) Close() {
+	if p.shared {
+		return
+	}
+	if p.ephemeral {
+		p.runner.stopProbeContainer(p)
+		return
+	}
+	p.runner.cleanup(p.Namespace, p.Name)
+}
+
+// runInProbePod runs a test of target with t in a probe pod, which can
+// outlive the test: with FromPod it is the existing pod, with Keep it is
+// left running and with an Agent it is the agent pod. With Retries a
+// failed test is rerun in the same pod.
+func (r *Runner) runInProbePod(ctx context.Context, t Tester, target string) (*Result, error) {
+	began := time.Now()
+	probe, err := r.StartProbePod(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	defer probe.Close()
+
+	r.progress(StepWaitCompletion, probe.Namespace, probe.Name)
+	if r.Retries <= 0 {
+		result, err := probe.Probe(ctx, target)
+		return probe.timed(result, began), err
+	}
+
+	var attempts []Attempt
+	backoff := r.RetryBackoff
+	for n := 1; ; n++ {
+		start := time.Now()
+		result, err := probe.Probe(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+
+		attempt := Attempt{Number: n, Of: r.Retries + 1, Duration: time.Since(start), Err: result.Err}
+		retry := !result.Success && n <= r.Retries
+		if retry {
+			attempt.Backoff = backoff
+		}
+		attempts = append(attempts, attempt)
+		if r.OnAttempt != nil {
+			r.OnAttempt(attempt)
+		}
+		if !retry {
+			result.Attempts = attempts
+			return probe.timed(result, began), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// timed adds the startup of the probe pod to the timings of result, a run
+// that began at began. It returns result, which may be nil.
+func (p *ProbePod) timed(result *Result, began time.Time) *Result {
+	if result != nil {
+		result.Timings.Scheduling = p.startup.Scheduling
+		result.Timings.ImagePull = p.startup.ImagePull
+		result.Timings.Total = time.Since(began)
+	}
+	return result
+}
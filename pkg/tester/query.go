@@ -0,0 +1,177 @@
+package tester
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+)
+
+// Querier is implemented by testers whose client can run a single
+// statement and print its result
+type Querier interface {
+	// QueryCommand returns the container command that runs query against
+	// target. With jsonOut the client prints the result as JSON.
+	QueryCommand(target, query string, jsonOut bool) ([]string, error)
+}
+
+// Scripter is implemented by testers whose client can run a script file
+type Scripter interface {
+	// ScriptCommand returns the container command that runs the script
+	// file at path against target, failing if the script fails
+	ScriptCommand(target, path string) ([]string, error)
+}
+
+// scriptPath is where RunScript copies a script in the pod
+const scriptPath = "/tmp/pocket-script"
+
+// Query returns a tester that runs query instead of the connection test of
+// q. A Result's Output is then the query's result.
+func Query(q Querier, query string, jsonOut bool) (Tester, error) {
+	t, ok := q.(Tester)
+	if !ok {
+		return nil, fmt.Errorf("querier %T is not a tester", q)
+	}
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("query is empty")
+	}
+	return queryTester{Tester: t, querier: q, query: query, jsonOut: jsonOut}, nil
+}
+
+// queryTester runs a query in place of a tester's connection test
+type queryTester struct {
+	Tester
+	querier Querier
+	query   string
+	jsonOut bool
+}
+
+// BuildArgs implements Tester
+func (q queryTester) BuildArgs(target string) ([]string, error) {
+	return q.querier.QueryCommand(target, q.query, q.jsonOut)
+}
+
+// ParseResult implements Tester
+func (q queryTester) ParseResult(_ string, exitOK bool) error {
+	if !exitOK {
+		return fmt.Errorf("query failed")
+	}
+	return nil
+}
+
+// QueryCommand implements Querier. The command is read by redis-cli from
+// stdin, so quoted arguments are split as at the redis-cli prompt.
+func (r Redis) QueryCommand(target, query string, jsonOut bool) ([]string, error) {
+	args, err := r.cliArgs(target)
+	if err != nil {
+		return nil, err
+	}
+	if jsonOut {
+		args = append(args, "--json")
+	}
+	script := fmt.Sprintf("printf '%%s\\n' %s | exec %s", ShellQuote(query), shellJoin(args))
+	return []string{"sh", "-c", script}, nil
+}
+
+// QueryCommand implements Querier. JSON output aggregates the rows of
+// query, which must then be a SELECT or another row-returning statement.
+func (Postgres) QueryCommand(target, query string, jsonOut bool) ([]string, error) {
+	if !jsonOut {
+		return []string{"psql", target, "-X", "-v", "ON_ERROR_STOP=1", "-c", query}, nil
+	}
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+	wrapped := fmt.Sprintf("SELECT coalesce(json_agg(q), '[]') FROM (%s) q", query)
+	return []string{"psql", target, "-X", "-v", "ON_ERROR_STOP=1", "-A", "-t", "-c", wrapped}, nil
+}
+
+// QueryCommand implements Querier. The query is a mongosh expression;
+// cursors are read to the end for JSON output.
+func (m Mongo) QueryCommand(target, query string, jsonOut bool) ([]string, error) {
+	if !jsonOut {
+		return m.mongosh(target, "--quiet", "--eval", query), nil
+	}
+	script := fmt.Sprintf(`const result = (%s);
+print(EJSON.stringify(result && typeof result.toArray === "function" ? result.toArray() : result, null, 2, {relaxed: true}));`, query)
+	return m.mongosh(target, "--quiet", "--eval", script), nil
+}
+
+// ScriptCommand implements Scripter. redis-cli runs one command per line.
+func (r Redis) ScriptCommand(target, path string) ([]string, error) {
+	args, err := r.cliArgs(target)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, "-e")
+	return []string{"sh", "-c", fmt.Sprintf("exec %s < %s", shellJoin(args), ShellQuote(path))}, nil
+}
+
+// ScriptCommand implements Scripter
+func (Postgres) ScriptCommand(target, path string) ([]string, error) {
+	return []string{"psql", target, "-X", "-v", "ON_ERROR_STOP=1", "-f", path}, nil
+}
+
+// ScriptCommand implements Scripter
+func (m Mongo) ScriptCommand(target, path string) ([]string, error) {
+	return m.mongosh(target, "--quiet", "--file", path), nil
+}
+
+// RunScript copies script into a probe pod for t and runs it against
+// target with t's client. The returned error reports infrastructure
+// problems; a failed script is reported in the Result.
+func (r *Runner) RunScript(ctx context.Context, t Tester, target string, script io.Reader) (*Result, error) {
+	scripter, ok := t.(Scripter)
+	if !ok {
+		return nil, fmt.Errorf("%s cannot run scripts", t.Name())
+	}
+	command, err := scripter.ScriptCommand(target, scriptPath)
+	if err != nil {
+		return nil, err
+	}
+
+	began := time.Now()
+	probe, err := r.StartProbePod(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	defer probe.Close()
+
+	err = r.Client.Exec(ctx, k8s.ExecOptions{
+		Namespace: probe.Namespace,
+		PodName:   probe.Name,
+		Container: probe.Container,
+		Command:   []string{"sh", "-c", "cat > " + scriptPath},
+		Stdin:     script,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy script: %w", err)
+	}
+
+	r.progress(StepWaitCompletion, probe.Namespace, probe.Name)
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	output, exitOK, err := r.Client.ExecOutput(ctx, probe.Namespace, probe.Name, probe.Container, command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run script: %w", err)
+	}
+	took := time.Since(start)
+
+	result := &Result{
+		Tester:    t.Name(),
+		Target:    target,
+		Namespace: probe.Namespace,
+		PodName:   probe.Name,
+		Output:    output,
+		Timings:   Timings{Probe: took, Total: took},
+	}
+	var scriptErr error
+	if !exitOK {
+		scriptErr = fmt.Errorf("script failed")
+	}
+	result.setErr(scriptErr)
+	return probe.timed(result, began), nil
+}
@@ -0,0 +1,205 @@
+package tester
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register(Redis{})
+}
+
+// Redis tests Redis connections with redis-cli
+type Redis struct {
+	// tls is set by WithTLS
+	tls TLS
+}
+
+// Name implements Tester
+func (Redis) Name() string { return "redis" }
+
+// DisplayName implements Tester
+func (Redis) DisplayName() string { return "Redis" }
+
+// Image implements Tester
+func (Redis) Image() string { return "redis:7-alpine" }
+
+// BuildArgs implements Tester
+func (r Redis) BuildArgs(target string) ([]string, error) {
+	switch {
+	case strings.HasPrefix(target, redisSentinelScheme):
+		return r.sentinelCommand(target, "PING")
+	case strings.HasPrefix(target, redisClusterScheme):
+		return r.clusterCommand(target)
+	}
+	args, err := r.cliArgs(target)
+	if err != nil {
+		return nil, err
+	}
+	return append(args, "PING"), nil
+}
+
+// ParseResult implements Tester. A cluster passes when all slots are
+// covered and no node is failing, rather than on a PONG.
+func (Redis) ParseResult(output string, exitOK bool) error {
+	if !exitOK {
+		return fmt.Errorf("redis-cli exited with an error")
+	}
+	if strings.Contains(output, clusterSeparator) {
+		return parseClusterStatus(output).err()
+	}
+	if !strings.Contains(output, "PONG") {
+		return fmt.Errorf("no PONG in response")
+	}
+	return nil
+}
+
+// ShellCommand implements Sheller
+func (r Redis) ShellCommand(target string) ([]string, error) {
+	if strings.HasPrefix(target, redisSentinelScheme) {
+		return r.sentinelCommand(target)
+	}
+	return r.cliArgs(target)
+}
+
+// ShellHint implements Sheller
+func (Redis) ShellHint() string { return "Type 'quit' to exit." }
+
+// InjectPassword implements PasswordInjector
+func (r Redis) InjectPassword() (Tester, string) { return r, "REDISCLI_AUTH" }
+
+// cliArgs builds the redis-cli connection and TLS arguments for target.
+// Cluster targets follow redirections to other nodes; Sentinel targets need
+// sentinelCommand.
+func (r Redis) cliArgs(target string) ([]string, error) {
+	if strings.HasPrefix(target, redisSentinelScheme) {
+		return nil, fmt.Errorf("%s targets only support connection tests and --shell", strings.TrimSuffix(redisSentinelScheme, "://"))
+	}
+	rest, cluster := strings.CutPrefix(target, redisClusterScheme)
+	t, err := ParseRedisTarget(rest)
+	if err != nil {
+		return nil, err
+	}
+	args := redisCliArgs(t)
+	if cluster {
+		args = append(args, "-c")
+	}
+	if tls := r.tlsArgs(); len(tls) > 0 {
+		return append(args, tls...), nil
+	}
+	if t.TLS {
+		args = append(args, "--tls")
+	}
+	return args, nil
+}
+
+// redisCliArgs builds the redis-cli connection arguments for t, without TLS
+func redisCliArgs(t RedisTarget) []string {
+	args := []string{"redis-cli", "-h", t.Host, "-p", t.Port}
+	if t.User != "" {
+		args = append(args, "--user", t.User)
+	}
+	if t.Password != "" {
+		args = append(args, "-a", t.Password)
+	}
+	if t.DB != "" {
+		args = append(args, "-n", t.DB)
+	}
+	return args
+}
+
+// RedisTarget is a parsed Redis target
+type RedisTarget struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	// DB is the database number; empty selects the default database
+	DB string
+	// TLS is set for rediss:// targets
+	TLS bool
+}
+
+// ParseRedisTarget parses host[:port], [[user]:password@]host[:port][/db]
+// and redis:// or rediss:// URLs of that form. A user without a password
+// is taken as the password, as redis-cli -a would. IPv6 literals may be
+// bracketed ([fd00::1]:6379) or bare (fd00::1, default port); the returned
+// host is never bracketed. Percent-encoded credentials are decoded; others
+// are returned as written.
+func ParseRedisTarget(target string) (RedisTarget, error) {
+	t := RedisTarget{Port: "6379"}
+	rest := target
+	if scheme, afterScheme, ok := strings.Cut(target, "://"); ok {
+		switch scheme {
+		case "redis":
+		case "rediss":
+			t.TLS = true
+		default:
+			return t, fmt.Errorf("unsupported redis target %q (use redis:// or rediss://)", target)
+		}
+		rest = afterScheme
+	}
+
+	// The password may itself contain "@" or "/"; the host never does
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		user, password, ok := strings.Cut(rest[:i], ":")
+		if !ok {
+			user, password = "", user
+		}
+		t.User, t.Password = unescapeRedis(user), unescapeRedis(password)
+		rest = rest[i+1:]
+	}
+	if strings.Contains(rest, "?") {
+		return t, fmt.Errorf("redis target %q: URL parameters are not supported", target)
+	}
+	hostPort, db, hasDB := strings.Cut(rest, "/")
+	if hasDB && db != "" {
+		if n, err := strconv.Atoi(db); err != nil || n < 0 {
+			return t, fmt.Errorf("redis target %q: invalid database %q", target, db)
+		}
+		t.DB = db
+	}
+
+	switch h, p, err := net.SplitHostPort(hostPort); {
+	case err == nil:
+		t.Host, t.Port = h, p
+	case strings.Count(hostPort, ":") > 1 || strings.HasPrefix(hostPort, "["):
+		t.Host = strings.Trim(hostPort, "[]")
+	default:
+		t.Host = hostPort
+	}
+	if t.Host == "" {
+		return t, fmt.Errorf("invalid redis target %q: no host", target)
+	}
+	if _, err := strconv.Atoi(t.Port); err != nil {
+		return t, fmt.Errorf("invalid redis target %q: invalid port %q", target, t.Port)
+	}
+	return t, nil
+}
+
+// String returns t as a redis:// or rediss:// URL
+func (t RedisTarget) String() string {
+	u := url.URL{Scheme: "redis", Host: net.JoinHostPort(t.Host, t.Port)}
+	if t.TLS {
+		u.Scheme = "rediss"
+	}
+	if t.User != "" || t.Password != "" {
+		u.User = url.UserPassword(t.User, t.Password)
+	}
+	if t.DB != "" {
+		u.Path = "/" + t.DB
+	}
+	return u.String()
+}
+
+// unescapeRedis decodes a percent-encoded credential, or returns it as
+// written if it is not valid percent-encoding
+func unescapeRedis(s string) string {
+	if decoded, err := url.PathUnescape(s); err == nil {
+		return decoded
+	}
+	return s
+}
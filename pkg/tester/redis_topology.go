@@ -0,0 +1,213 @@
+package tester
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Redis target schemes for deployments of several servers
+const (
+	// redisSentinelScheme targets the master Sentinel names:
+	// redis+sentinel://[:password@]host[:port][,host[:port]...]/<master>
+	redisSentinelScheme = "redis+sentinel://"
+	// redisClusterScheme targets a Redis Cluster through one of its nodes:
+	// redis+cluster://[:password@]host[:port]
+	redisClusterScheme = "redis+cluster://"
+)
+
+// defaultSentinelPort is the port of Sentinels without one
+const defaultSentinelPort = "26379"
+
+// redisClusterSlots is the number of hash slots a cluster must cover
+const redisClusterSlots = 16384
+
+// clusterSeparator separates CLUSTER INFO from CLUSTER NODES in the output
+const clusterSeparator = "--- cluster nodes ---"
+
+// sentinelTarget is a parsed redis+sentinel:// target
+type sentinelTarget struct {
+	// sentinels are host and port pairs
+	sentinels [][2]string
+	master    string
+	// password authenticates with the master, not the Sentinels
+	password string
+}
+
+// parseSentinelTarget parses a redis+sentinel:// target
+func parseSentinelTarget(target string) (sentinelTarget, error) {
+	var st sentinelTarget
+	rest := strings.TrimPrefix(target, redisSentinelScheme)
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		st.password = strings.TrimPrefix(rest[:i], ":")
+		rest = rest[i+1:]
+	}
+	hosts, path, _ := strings.Cut(rest, "/")
+	st.master, _, _ = strings.Cut(path, "/")
+	if hosts == "" || st.master == "" {
+		return st, fmt.Errorf("invalid sentinel target %q (use %s[:password@]host[:port][,host[:port]...]/<master>)", target, redisSentinelScheme)
+	}
+
+	for _, hostPort := range strings.Split(hosts, ",") {
+		host, port, err := net.SplitHostPort(hostPort)
+		if err != nil {
+			host, port = strings.Trim(hostPort, "[]"), defaultSentinelPort
+		}
+		if host == "" {
+			return st, fmt.Errorf("invalid sentinel %q in %q", hostPort, target)
+		}
+		st.sentinels = append(st.sentinels, [2]string{host, port})
+	}
+	return st, nil
+}
+
+// sentinelCommand returns a command that asks the Sentinels in turn for the
+// master's address and runs redis-cli against it with args. Sentinels are
+// queried without the master's password, which they usually do not share.
+func (r Redis) sentinelCommand(target string, args ...string) ([]string, error) {
+	st, err := parseSentinelTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	sentinels := make([]string, len(st.sentinels))
+	for i, s := range st.sentinels {
+		sentinels[i] = ShellQuote(s[0] + " " + s[1])
+	}
+	tls := shellJoin(r.tlsArgs())
+
+	script := fmt.Sprintf(`for sentinel in %s; do
+  set -- $sentinel
+  master=$(env -u REDISCLI_AUTH redis-cli -h "$1" -p "$2" %s SENTINEL get-master-addr-by-name %s | tr '\n' ' ')
+  set -- "$1" "$2" $master
+  if [ $# -eq 4 ]; then
+    echo "master $3:$4 (from sentinel $1:$2)"
+    exec redis-cli -h "$3" -p "$4" %s %s
+  fi
+  echo "sentinel $1:$2 did not name master "%s
+done
+exit 1`, strings.Join(sentinels, " "), tls, ShellQuote(st.master),
+		passwordArgs(st.password), tls+" "+shellJoin(args), ShellQuote(st.master))
+	return []string{"sh", "-c", script}, nil
+}
+
+// passwordArgs returns the quoted redis-cli arguments passing password
+func passwordArgs(password string) string {
+	if password == "" {
+		return ""
+	}
+	return "-a " + ShellQuote(password)
+}
+
+// clusterCommand returns a command that prints CLUSTER INFO and CLUSTER
+// NODES of the cluster target is a node of
+func (r Redis) clusterCommand(target string) ([]string, error) {
+	args, err := r.cliArgs(target)
+	if err != nil {
+		return nil, err
+	}
+	cli := shellJoin(args)
+	script := fmt.Sprintf("%s CLUSTER INFO && echo %s && exec %s CLUSTER NODES", cli, ShellQuote(clusterSeparator), cli)
+	return []string{"sh", "-c", script}, nil
+}
+
+// clusterStatus is the health of a Redis Cluster
+type clusterStatus struct {
+	state       string
+	slotsOK     int
+	masters     int
+	replicas    int
+	failing     []string
+	unconnected []string
+}
+
+// parseClusterStatus reads the output of clusterCommand
+func parseClusterStatus(output string) clusterStatus {
+	var status clusterStatus
+	info, nodes, _ := strings.Cut(output, clusterSeparator)
+
+	for _, line := range strings.Split(info, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "cluster_state":
+			status.state = value
+		case "cluster_slots_ok":
+			status.slotsOK, _ = strconv.Atoi(value)
+		}
+	}
+
+	// <id> <ip:port@cport[,hostname]> <flags> <master> <ping-sent> <pong-recv> <config-epoch> <link-state> <slot>...
+	for _, line := range strings.Split(nodes, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 8 {
+			continue
+		}
+		address, _, _ := strings.Cut(fields[1], "@")
+		flags := "," + fields[2] + ","
+		switch {
+		case strings.Contains(flags, ",master,"):
+			status.masters++
+		case strings.Contains(flags, ",slave,"):
+			status.replicas++
+		}
+		switch {
+		case strings.Contains(flags, ",fail,") || strings.Contains(flags, ",fail?,"):
+			status.failing = append(status.failing, address)
+		case fields[7] != "connected":
+			status.unconnected = append(status.unconnected, address)
+		}
+	}
+	return status
+}
+
+// err explains why the cluster is unhealthy, or returns nil
+func (s clusterStatus) err() error {
+	var problems []string
+	if s.state != "ok" {
+		problems = append(problems, "cluster_state is "+valueOrUnknown(s.state))
+	}
+	if s.slotsOK < redisClusterSlots {
+		problems = append(problems, fmt.Sprintf("%d of %d slots covered", s.slotsOK, redisClusterSlots))
+	}
+	if len(s.failing) > 0 {
+		problems = append(problems, "failing nodes: "+strings.Join(s.failing, ", "))
+	}
+	if len(s.unconnected) > 0 {
+		problems = append(problems, "disconnected nodes: "+strings.Join(s.unconnected, ", "))
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("cluster unhealthy: %s", strings.Join(problems, "; "))
+}
+
+// summary describes the cluster in a line
+func (s clusterStatus) summary() string {
+	return fmt.Sprintf("cluster %s: %d/%d slots covered, %d master(s), %d replica(s), %d failing node(s)",
+		valueOrUnknown(s.state), s.slotsOK, redisClusterSlots, s.masters, s.replicas, len(s.failing))
+}
+
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
+
+// Summarize implements Summarizer: the slot coverage and node health of a
+// cluster, or the master a Sentinel named
+func (Redis) Summarize(output string) string {
+	if strings.Contains(output, clusterSeparator) {
+		return parseClusterStatus(output).summary()
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "master ") && strings.Contains(line, "(from sentinel ") {
+			return strings.TrimSpace(line)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,419 @@
+package tester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/images"
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+)
+
+// Step identifies a stage of a test run, reported through Runner.Progress
+type Step string
+
+const (
+	// StepCreatePod is reported before the test pod is created
+	StepCreatePod Step = "create-pod"
+	// StepWaitCompletion is reported while waiting for the probe to finish
+	StepWaitCompletion Step = "wait-completion"
+	// StepWaitRunning is reported while waiting for a shell pod to start
+	StepWaitRunning Step = "wait-running"
+	// StepAttach is reported once a shell pod is running, before attaching
+	StepAttach Step = "attach"
+	// StepCleanup is reported before the test pod is deleted
+	StepCleanup Step = "cleanup"
+	// StepAddContainer is reported before a test container is added to the
+	// runner's FromPod
+	StepAddContainer Step = "add-container"
+	// StepStopContainer is reported before that test container is stopped
+	StepStopContainer Step = "stop-container"
+	// StepKeep is reported instead of StepCleanup when the pod is kept
+	StepKeep Step = "keep"
+	// StepUseAgent is reported when a test runs in the agent pod instead of
+	// a new pod
+	StepUseAgent Step = "use-agent"
+	// StepScheduling is reported while the pod waits for a node
+	StepScheduling Step = "scheduling"
+	// StepPulling is reported once the pod has a node, while its image is
+	// pulled and its container created
+	StepPulling Step = "pulling"
+)
+
+// ErrPodNotStarted is wrapped by errors about test pods that never started,
+// e.g. because they could not be scheduled or their image not pulled
+var ErrPodNotStarted = errors.New("test pod did not start")
+
+// Result is the outcome of a single test run
+type Result struct {
+	Tester    string
+	Target    string
+	Namespace string
+	PodName   string
+	Success   bool
+	// Status is StatusPassed when Success is true and StatusFailed otherwise
+	Status Status
+	// Output is the raw output of the test
+	Output string
+	// Summary describes Output in a line for testers implementing Summarizer
+	Summary string
+	// Timings break down the duration of the run
+	Timings Timings
+	// Footprint is set when the runner collects footprints
+	Footprint *k8s.Footprint
+	// Attempts are the runs of a test retried with Runner.Retries
+	Attempts []Attempt
+	// Err explains why the test failed; nil when Success is true
+	Err error
+}
+
+// Cluster is what a Runner needs of Kubernetes. *k8s.Client implements it;
+// tests can wrap or replace it.
+type Cluster interface {
+	k8s.PodManager
+	k8s.Execer
+	k8s.PodInspector
+}
+
+var _ Cluster = (*k8s.Client)(nil)
+
+// Runner runs testers in temporary pods
+type Runner struct {
+	Client    Cluster
+	Namespace string
+	Timeout   time.Duration
+	// Image, if set, resolves the image to run for a tester instead of
+	// Tester.Image
+	Image func(t Tester) string
+	// ConfigurePod, if set, adjusts every pod spec before it is created
+	ConfigurePod func(config *k8s.PodConfig) error
+	// Footprint enables collecting the pod's scheduling, image pull and
+	// resource usage footprint
+	Footprint bool
+	// Progress, if set, is called as the run moves through its steps
+	Progress func(step Step, namespace, podName string)
+	// Observer, if set, is told about every pod the runner creates
+	Observer Observer
+	// FromPod, if set, names an existing pod in Namespace whose network
+	// identity tests use: they run in an ephemeral container added to it
+	// instead of in a new pod
+	FromPod string
+	// Keep leaves test pods running instead of deleting them, labeled
+	// k8s.LabelKept. Tests then run through exec in a probe pod, so the
+	// pod is still running afterwards.
+	Keep bool
+	// Agent, if set, is a running agent pod (see AgentPodConfig). Tests
+	// with testers it serves run in it through exec instead of in a new
+	// pod; others still get their own pod.
+	Agent *corev1.Pod
+	// Keepalive is how long a shell pod lives if it is abandoned; 0 uses
+	// DefaultKeepalive
+	Keepalive time.Duration
+	// ProbeLifetime is how long a probe pod may serve tests before it stops;
+	// 0 covers one test with its retries, or a day with Keep. Watches and
+	// other long sessions set it.
+	ProbeLifetime time.Duration
+	// Retries reruns a failed test up to this many times in the same pod,
+	// waiting RetryBackoff before the first retry and twice as long before
+	// each further one
+	Retries      int
+	RetryBackoff time.Duration
+	// OnAttempt, if set, is called after every attempt of a test with
+	// Retries
+	OnAttempt func(attempt Attempt)
+}
+
+// Attempt is one run of a test retried with Runner.Retries
+type Attempt struct {
+	// Number counts attempts from 1 up to Of
+	Number   int
+	Of       int
+	Duration time.Duration
+	// Err is why the attempt failed; nil when it succeeded
+	Err error
+	// Backoff is the wait before the next attempt; 0 for the last one
+	Backoff time.Duration
+}
+
+// DefaultKeepalive is how long shell pods live by default
+const DefaultKeepalive = time.Hour
+
+// startupGrace is added to pod deadlines for scheduling and image pulls
+const startupGrace = 2 * time.Minute
+
+// Observer watches the pods a Runner creates, e.g. to record a run
+type Observer interface {
+	// PodCreated is called with each pod as created
+	PodCreated(pod *corev1.Pod)
+	// PodDone is called before the pod is deleted, while its logs and
+	// status can still be read
+	PodDone(namespace, name string)
+}
+
+// NewRunner creates a runner for the client's default namespace
+func NewRunner(client Cluster, timeout time.Duration) *Runner {
+	return &Runner{
+		Client:    client,
+		Namespace: client.DefaultNamespace(),
+		Timeout:   timeout,
+	}
+}
+
+// PodName returns a unique pod name for a tester. The random suffix keeps
+// pods started in the same second, e.g. by parallel suite groups, apart.
+func PodName(t Tester) string {
+	return fmt.Sprintf("pocket-%s-%d-%s", t.Name(), time.Now().Unix(), utilrand.String(5))
+}
+
+// Run tests target with t in a temporary pod. The returned error reports
+// infrastructure problems; a failed connection is reported in the Result.
+func (r *Runner) Run(ctx context.Context, t Tester, target string) (*Result, error) {
+	command, err := t.BuildArgs(target)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.FromPod != "" || r.Keep || r.Retries > 0 || r.usesAgent(t) {
+		return r.runInProbePod(ctx, t, target)
+	}
+
+	podName := PodName(t)
+	ns := r.Namespace
+
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout+30*time.Second)
+	defer cancel()
+
+	r.progress(StepCreatePod, ns, podName)
+	watch := newStopwatch()
+
+	podConfig := k8s.PodConfig{
+		Name:      podName,
+		Namespace: ns,
+		Image:     r.image(t),
+		Command:   command,
+		Purpose:   "test",
+		// A pod that outlives its test is stopped by Kubernetes
+		ActiveDeadline: r.Timeout + startupGrace,
+		Labels:         map[string]string{k8s.LabelTester: t.Name()},
+	}
+
+	if err := r.createPod(ctx, podConfig); err != nil {
+		return nil, fmt.Errorf("failed to create pod: %w", err)
+	}
+	defer r.cleanup(ns, podName)
+
+	var footprint *k8s.Footprint
+	stopSampling := func() {}
+	if r.Footprint {
+		footprint = &k8s.Footprint{}
+		stopSampling = r.sampleUsage(ctx, ns, podName, footprint)
+	}
+
+	r.progress(StepWaitCompletion, ns, podName)
+	pod, err := r.Client.WatchPodCompletion(ctx, ns, podName, r.Timeout, r.timedStages(watch))
+	stopSampling()
+	if err != nil {
+		if k8s.PendingReason(pod) != "" {
+			return nil, r.startError(ns, podName, err)
+		}
+		return nil, fmt.Errorf("timeout waiting for test: %w", err)
+	}
+	timings := watch.timings(pod)
+
+	if footprint != nil {
+		// Footprint details are best effort and never fail the test
+		_ = r.Client.CollectFootprint(ctx, pod, footprint)
+	}
+
+	logs, err := r.Client.GetPodLogs(ctx, ns, podName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logs: %w", err)
+	}
+
+	result := &Result{
+		Tester:    t.Name(),
+		Target:    target,
+		Namespace: ns,
+		PodName:   podName,
+		Output:    logs,
+		Footprint: footprint,
+	}
+	result.parse(t, logs, pod.Status.Phase == corev1.PodSucceeded)
+	result.Timings = timings
+	result.Timings.Total = time.Since(watch.start)
+	return result, nil
+}
+
+// ShellOptions holds the streams for an interactive shell session
+type ShellOptions struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// MakeRaw, if set, is called right before attaching to switch the
+	// terminal to raw mode; the returned function restores it.
+	MakeRaw func() (restore func(), err error)
+	// Resize, if set, reports the local terminal size to the session
+	Resize k8s.TerminalSizeQueue
+}
+
+// Shell starts a keepalive pod for t and attaches an interactive client
+// session to target. The pod is deleted when the session ends. With FromPod
+// set the session runs in an ephemeral container in that pod, and with an
+// Agent serving t in the agent pod.
+func (r *Runner) Shell(ctx context.Context, t Tester, target string, opts ShellOptions) error {
+	sheller, ok := t.(Sheller)
+	if !ok {
+		return fmt.Errorf("%s does not support interactive shells", t.DisplayName())
+	}
+
+	command, err := sheller.ShellCommand(target)
+	if err != nil {
+		return err
+	}
+
+	ns, podName, container := r.Namespace, PodName(t), "main"
+	if r.FromPod != "" || r.usesAgent(t) {
+		probe, err := r.StartProbePod(ctx, t)
+		if err != nil {
+			return err
+		}
+		defer probe.Close()
+		podName, container = probe.Name, probe.Container
+	} else {
+		r.progress(StepCreatePod, ns, podName)
+
+		keepalive := r.Keepalive
+		if keepalive <= 0 {
+			keepalive = DefaultKeepalive
+		}
+		podConfig := k8s.PodConfig{
+			Name:           podName,
+			Namespace:      ns,
+			Image:          r.image(t),
+			Command:        []string{"sleep", strconv.Itoa(int(keepalive.Seconds()))},
+			TTY:            true,
+			Stdin:          true,
+			Purpose:        "shell",
+			ActiveDeadline: keepalive + startupGrace,
+			Labels:         map[string]string{k8s.LabelTester: t.Name()},
+		}
+
+		if err := r.createPod(ctx, podConfig); err != nil {
+			return fmt.Errorf("failed to create pod: %w", err)
+		}
+		defer r.cleanup(ns, podName)
+
+		r.progress(StepWaitRunning, ns, podName)
+		if err := r.Client.WatchPodRunning(ctx, ns, podName, 2*time.Minute, r.stageProgress); err != nil {
+			return r.startError(ns, podName, err)
+		}
+	}
+
+	r.progress(StepAttach, ns, podName)
+
+	if opts.MakeRaw != nil {
+		restore, err := opts.MakeRaw()
+		if err != nil {
+			return fmt.Errorf("failed to set raw terminal: %w", err)
+		}
+		defer restore()
+	}
+
+	execOpts := k8s.ExecOptions{
+		Namespace: ns,
+		PodName:   podName,
+		Container: container,
+		Command:   command,
+		Stdin:     opts.Stdin,
+		Stdout:    opts.Stdout,
+		Stderr:    opts.Stderr,
+		TTY:       true,
+		Resize:    opts.Resize,
+	}
+
+	return r.Client.Exec(ctx, execOpts)
+}
+
+// createPod applies ConfigurePod and creates the pod
+func (r *Runner) createPod(ctx context.Context, config k8s.PodConfig) error {
+	config.Kept = r.Keep
+	if r.ConfigurePod != nil {
+		if err := r.ConfigurePod(&config); err != nil {
+			return err
+		}
+	}
+	created, err := r.Client.CreatePod(ctx, config)
+	if err == nil && r.Observer != nil {
+		r.Observer.PodCreated(created)
+	}
+	if err != nil && strings.Contains(err.Error(), "admission webhook") {
+		return fmt.Errorf("%w (run 'kubectl pocket policy simulate' to see which policy denies it)", err)
+	}
+	// Admission rejections (quota, limit range, Pod Security) are Forbidden
+	// too, but unlike RBAC denials they do not say "cannot create"
+	if apierrors.IsForbidden(err) && !strings.Contains(err.Error(), "cannot create") {
+		return fmt.Errorf("%w (run 'kubectl pocket quota check' to see which namespace constraint rejects it)", err)
+	}
+	return err
+}
+
+// sampleUsage samples the pod's resource usage in the background until the
+// returned stop function is called
+func (r *Runner) sampleUsage(ctx context.Context, ns, podName string, fp *k8s.Footprint) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Client.SampleUsage(ctx, ns, podName, 2*time.Second, fp)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// cleanup deletes the test pod with a fresh context so it runs even after
+// the run's context was cancelled. With Keep the pod is left running.
+func (r *Runner) cleanup(ns, podName string) {
+	if r.Observer != nil {
+		r.Observer.PodDone(ns, podName)
+	}
+	if r.Keep {
+		r.progress(StepKeep, ns, podName)
+		return
+	}
+	r.progress(StepCleanup, ns, podName)
+	cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cleanupCancel()
+	_ = r.Client.DeletePod(cleanupCtx, ns, podName)
+}
+
+func (r *Runner) image(t Tester) string {
+	if r.Image != nil {
+		return r.Image(t)
+	}
+	return images.Pin(t.Image())
+}
+
+// stageProgress reports a test pod's startup stages as steps
+func (r *Runner) stageProgress(stage k8s.PodStage, pod *corev1.Pod) {
+	switch stage {
+	case k8s.PodScheduling:
+		r.progress(StepScheduling, pod.Namespace, pod.Name)
+	case k8s.PodPulling:
+		r.progress(StepPulling, pod.Namespace, pod.Name)
+	}
+}
+
+func (r *Runner) progress(step Step, ns, podName string) {
+	if r.Progress != nil {
+		r.Progress(step, ns, podName)
+	}
+}
@@ -0,0 +1,185 @@
+package tester
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+// recordingCluster is a Cluster that records the pods created through it
+type recordingCluster struct {
+	*k8s.Client
+
+	mu      sync.Mutex
+	created []k8s.PodConfig
+}
+
+func (c *recordingCluster) CreatePod(ctx context.Context, config k8s.PodConfig) (*corev1.Pod, error) {
+	c.mu.Lock()
+	c.created = append(c.created, config)
+	c.mu.Unlock()
+	return c.Client.CreatePod(ctx, config)
+}
+
+// finishPods sets the phase of the first pod created in ns to phase, as the
+// kubelet would when its container exits
+func finishPods(t *testing.T, client *k8s.Client, ns string, phase corev1.PodPhase) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for ctx.Err() == nil {
+		pods, err := client.Clientset.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+		if err == nil && len(pods.Items) > 0 {
+			pod := pods.Items[0]
+			now := metav1.Now()
+			pod.Status.Phase = phase
+			pod.Status.StartTime = &now
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				Name: "main",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					StartedAt:  now,
+					FinishedAt: now,
+				}},
+			}}
+			if _, err := client.Clientset.CoreV1().Pods(ns).UpdateStatus(ctx, &pod, metav1.UpdateOptions{}); err != nil {
+				t.Errorf("failed to update pod status: %v", err)
+			}
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Error("no pod was created")
+}
+
+func TestRunnerWithFakeClientset(t *testing.T) {
+	tests := []struct {
+		name        string
+		phase       corev1.PodPhase
+		wantSuccess bool
+		wantStatus  Status
+	}{
+		{"succeeded", corev1.PodSucceeded, true, StatusPassed},
+		{"failed", corev1.PodFailed, false, StatusFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewClientset()
+			client := k8s.NewClientForClientset(clientset, nil, nil, "payments")
+			cluster := &recordingCluster{Client: client}
+			runner := NewRunner(cluster, 5*time.Second)
+
+			tester := Custom{
+				TesterName: "probe",
+				ImageName:  "busybox:1.36",
+				Command:    []string{"true"},
+				// The fake clientset returns these logs for every pod
+				SuccessRegex: regexp.MustCompile("fake logs"),
+			}
+			go finishPods(t, client, "payments", tt.phase)
+			result, err := runner.Run(context.Background(), tester, "ignored")
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			if result.Success != tt.wantSuccess || result.Status != tt.wantStatus {
+				t.Errorf("Run() = success %v, status %q; want %v, %q", result.Success, result.Status, tt.wantSuccess, tt.wantStatus)
+			}
+			if result.Namespace != "payments" || result.Output != "fake logs" {
+				t.Errorf("Run() = namespace %q, output %q", result.Namespace, result.Output)
+			}
+
+			if len(cluster.created) != 1 {
+				t.Fatalf("created %d pods, want 1", len(cluster.created))
+			}
+			config := cluster.created[0]
+			if config.Image != "busybox:1.36" || config.Labels[k8s.LabelTester] != "probe" || config.Purpose != "test" {
+				t.Errorf("pod config = image %q, labels %v, purpose %q", config.Image, config.Labels, config.Purpose)
+			}
+			pods, err := clientset.CoreV1().Pods("payments").List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pods.Items) != 0 {
+				t.Errorf("%d pods left after the run, want 0", len(pods.Items))
+			}
+		})
+	}
+}
+
+func TestRunnerTimeout(t *testing.T) {
+	clientset := fake.NewClientset()
+	runner := NewRunner(k8s.NewClientForClientset(clientset, nil, nil, "payments"), 200*time.Millisecond)
+
+	tester := Custom{TesterName: "probe", ImageName: "busybox:1.36", Command: []string{"sleep", "3600"}}
+	result, err := runner.Run(context.Background(), tester, "ignored")
+	if err == nil || !strings.Contains(err.Error(), "timeout waiting for test") {
+		t.Fatalf("Run() = %+v, %v; want a timeout error", result, err)
+	}
+	pods, err := clientset.CoreV1().Pods("payments").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods.Items) != 0 {
+		t.Errorf("%d pods left after the timeout, want 0", len(pods.Items))
+	}
+}
+
+func TestProbePodLifetime(t *testing.T) {
+	tests := []struct {
+		name   string
+		runner Runner
+		want   time.Duration
+	}{
+		{"one test", Runner{Timeout: 30 * time.Second}, 30*time.Second + startupGrace},
+		{"retries", Runner{Timeout: 10 * time.Second, Retries: 2, RetryBackoff: time.Second}, 33*time.Second + startupGrace},
+		{"session", Runner{Timeout: 10 * time.Second, ProbeLifetime: time.Hour}, time.Hour + startupGrace},
+		{"kept", Runner{Timeout: 10 * time.Second, Keep: true, ProbeLifetime: time.Hour}, k8s.DefaultActiveDeadline},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.runner.probeLifetime(); got != tt.want {
+				t.Errorf("probeLifetime() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartProbePodDeadline(t *testing.T) {
+	clientset := fake.NewClientset()
+	clientset.PrependReactor("create", "pods", func(action ktesting.Action) (bool, runtime.Object, error) {
+		pod := action.(ktesting.CreateAction).GetObject().(*corev1.Pod)
+		pod.Status.Phase = corev1.PodRunning
+		return false, nil, nil
+	})
+	client := k8s.NewClientForClientset(clientset, nil, nil, "payments")
+	cluster := &recordingCluster{Client: client}
+	runner := NewRunner(cluster, 30*time.Second)
+
+	probe, err := runner.StartProbePod(context.Background(), Custom{TesterName: "probe", ImageName: "busybox:1.36"})
+	if err != nil {
+		t.Fatalf("StartProbePod() error = %v", err)
+	}
+	probe.Close()
+
+	if len(cluster.created) != 1 {
+		t.Fatalf("created %d pods, want 1", len(cluster.created))
+	}
+	config := cluster.created[0]
+	want := 30*time.Second + startupGrace
+	if config.ActiveDeadline != want {
+		t.Errorf("ActiveDeadline = %s, want %s", config.ActiveDeadline, want)
+	}
+	if sleep := strings.Join(config.Command, " "); sleep != "sleep 150" {
+		t.Errorf("Command = %q, want %q", sleep, "sleep 150")
+	}
+}
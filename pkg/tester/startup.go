@@ -0,0 +1,54 @@
+package tester
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodStartError reports a test pod that never started, e.g. because it
+// could not be scheduled or its image not pulled. It matches
+// ErrPodNotStarted, setting it apart from a failed connection.
+type PodStartError struct {
+	Namespace string
+	Pod       string
+	// Reason is the pod's scheduling or container waiting reason, if known
+	Reason string
+	// Events are the pod's warning events, oldest first
+	Events []string
+	Err    error
+}
+
+func (e *PodStartError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("%s: %v", ErrPodNotStarted, e.Err)
+	}
+	return fmt.Sprintf("%s: %s (%v)", ErrPodNotStarted, e.Reason, e.Err)
+}
+
+func (e *PodStartError) Unwrap() []error { return []error{ErrPodNotStarted, e.Err} }
+
+// startError explains why pod ns/name did not start after waiting for it
+// failed with err. It reads the pod with a fresh context, so it must run
+// before the pod is deleted but may run after the run's context is done.
+func (r *Runner) startError(ns, name string, err error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	startErr := &PodStartError{Namespace: ns, Pod: name, Err: err}
+	if pod, getErr := r.Client.GetPod(ctx, ns, name); getErr == nil {
+		startErr.Reason = k8s.PendingReason(pod)
+	}
+	// Events are best effort; the error stands without them
+	if events, eventsErr := r.Client.PodEvents(ctx, ns, name); eventsErr == nil {
+		for _, event := range events {
+			if event.Type == corev1.EventTypeWarning {
+				startErr.Events = append(startErr.Events, fmt.Sprintf("%s: %s", event.Reason, event.Message))
+			}
+		}
+	}
+	return startErr
+}
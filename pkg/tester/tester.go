@@ -0,0 +1,111 @@
+// Package tester defines connection testers and the runner that executes
+// them in temporary pods.
+//
+// To test a connection from other programs, create a k8s.Client, get a
+// tester with Get and run it:
+//
+//	client := k8s.NewClientForClientset(clientset, nil, restConfig, "payments")
+//	t, _ := tester.Get("postgres")
+//	result, err := tester.NewRunner(client, 30*time.Second).Run(ctx, t, target)
+//
+// Together with package k8s this package is pocket's library surface,
+// whose exported API only changes compatibly within a major version.
+package tester
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Tester describes how to test a connection with a client image
+type Tester interface {
+	// Name is the registry key and command name (e.g. "redis")
+	Name() string
+	// DisplayName is the human-readable name (e.g. "Redis")
+	DisplayName() string
+	// Image is the container image that provides the client
+	Image() string
+	// BuildArgs returns the container command that tests target
+	BuildArgs(target string) ([]string, error)
+	// ParseResult interprets the probe output. exitOK reports whether the
+	// probe command exited successfully. A nil error means the test passed.
+	ParseResult(output string, exitOK bool) error
+}
+
+// Sheller is implemented by testers that can open an interactive client shell
+type Sheller interface {
+	// ShellCommand returns the interactive client command for target
+	ShellCommand(target string) ([]string, error)
+	// ShellHint tells the user how to leave the shell
+	ShellHint() string
+}
+
+// PasswordInjector is implemented by testers whose client can read the
+// password from an environment variable, keeping it off the command line
+type PasswordInjector interface {
+	// InjectPassword returns a tester that reads the password from the
+	// returned environment variable
+	InjectPassword() (Tester, string)
+}
+
+// Summarizer is implemented by testers whose output is better described by
+// a line than shown raw, such as the health of a cluster
+type Summarizer interface {
+	// Summarize describes output in a line, or returns "" to show it raw
+	Summarize(output string) string
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Tester{}
+)
+
+// Register adds a tester to the registry. It panics if a tester with the
+// same name is already registered.
+func Register(t Tester) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[t.Name()]; exists {
+		panic(fmt.Sprintf("tester: %q already registered", t.Name()))
+	}
+	registry[t.Name()] = t
+}
+
+// Get returns the registered tester with the given name
+func Get(name string) (Tester, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	t, ok := registry[name]
+	return t, ok
+}
+
+// Names returns the names of all registered testers, sorted
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ShellQuote quotes s for safe use as a single POSIX shell word
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellJoin quotes and joins args into a POSIX shell command line
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = ShellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
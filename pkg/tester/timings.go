@@ -0,0 +1,141 @@
+package tester
+
+import (
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Status is the outcome of a test
+type Status string
+
+const (
+	// StatusPassed means the connection test succeeded
+	StatusPassed Status = "passed"
+	// StatusFailed means the test ran and the connection failed
+	StatusFailed Status = "failed"
+)
+
+// Timings break down where the time of a test run went. Durations of steps
+// a run skipped, such as scheduling in an agent pod, are zero.
+type Timings struct {
+	// Scheduling is the wait for a node
+	Scheduling time.Duration
+	// ImagePull is the time from scheduling until the container started:
+	// pulling its image, or not when cached, and creating it
+	ImagePull time.Duration
+	// Probe is the time the test itself ran
+	Probe time.Duration
+	// Total is the whole run, from creating the pod until the result was
+	// read
+	Total time.Duration
+}
+
+// stopwatch times the startup stages of a pod as its watch reports them
+type stopwatch struct {
+	start     time.Time
+	scheduled time.Time
+	started   time.Time
+}
+
+func newStopwatch() *stopwatch {
+	return &stopwatch{start: time.Now()}
+}
+
+// stage records the time the pod entered stage. A pod first seen already
+// scheduled or started is timed as entering the skipped stages then.
+func (s *stopwatch) stage(stage k8s.PodStage) {
+	now := time.Now()
+	switch stage {
+	case k8s.PodPulling:
+		s.scheduled = now
+	case k8s.PodStarted:
+		if s.scheduled.IsZero() {
+			s.scheduled = now
+		}
+		s.started = now
+	}
+}
+
+// timings returns the timings of a run that ended now. A probe that
+// finished before the watch saw it running is timed from its container's
+// state instead, which the kubelet reports to the second.
+func (s *stopwatch) timings(pod *corev1.Pod) Timings {
+	return s.timingsAt(pod, time.Now())
+}
+
+// timingsAt returns the timings of a run that ended at end
+func (s *stopwatch) timingsAt(pod *corev1.Pod, end time.Time) Timings {
+	started := s.started
+	if started.IsZero() {
+		started = end
+	}
+	scheduled := s.scheduled
+	if scheduled.IsZero() {
+		scheduled = started
+	}
+
+	t := Timings{
+		Scheduling: scheduled.Sub(s.start),
+		ImagePull:  started.Sub(scheduled),
+		Probe:      end.Sub(started),
+		Total:      end.Sub(s.start),
+	}
+	if ran := containerRunTime(pod); ran > t.Probe && ran <= t.Probe+t.ImagePull {
+		t.ImagePull -= ran - t.Probe
+		t.Probe = ran
+	}
+	return t
+}
+
+// timedStages returns a stage callback that records the stages on watch
+// and reports them as steps
+func (r *Runner) timedStages(watch *stopwatch) k8s.PodStageFunc {
+	return func(stage k8s.PodStage, pod *corev1.Pod) {
+		watch.stage(stage)
+		r.stageProgress(stage, pod)
+	}
+}
+
+// startup returns the timings of a pod that is now running
+func (s *stopwatch) startup() Timings {
+	t := s.timings(nil)
+	t.Probe = 0
+	return t
+}
+
+// containerRunTime returns how long the main container of a finished pod
+// ran, or 0 if that is unknown
+func containerRunTime(pod *corev1.Pod) time.Duration {
+	if pod == nil {
+		return 0
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != "main" || status.State.Terminated == nil {
+			continue
+		}
+		terminated := status.State.Terminated
+		return terminated.FinishedAt.Sub(terminated.StartedAt.Time)
+	}
+	return 0
+}
+
+// setErr records the outcome of a test: err is why it failed, nil if it
+// passed
+func (r *Result) setErr(err error) {
+	r.Err = err
+	r.Success = err == nil
+	r.Status = StatusPassed
+	if err != nil {
+		r.Status = StatusFailed
+	}
+}
+
+// parse records the outcome of t's output, and its summary
+func (r *Result) parse(t Tester, output string, exitOK bool) {
+	r.setErr(t.ParseResult(output, exitOK))
+	if s, ok := t.(Summarizer); ok {
+		r.Summary = s.Summarize(output)
+	}
+}
@@ -0,0 +1,159 @@
+package tester
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/enbiyagoral/kubectl-pocket/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// finishedPod returns a pod whose main container ran for ran
+func finishedPod(ran time.Duration) *corev1.Pod {
+	started := metav1.NewTime(time.Unix(1000, 0))
+	return &corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+		Name: "main",
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			StartedAt:  started,
+			FinishedAt: metav1.NewTime(started.Add(ran)),
+		}},
+	}}}}
+}
+
+func TestStopwatchTimings(t *testing.T) {
+	start := time.Unix(1000, 0)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+
+	tests := []struct {
+		name      string
+		scheduled time.Duration
+		started   time.Duration
+		end       time.Duration
+		pod       *corev1.Pod
+		want      Timings
+	}{
+		{
+			name:      "every stage seen",
+			scheduled: 2 * time.Second,
+			started:   7 * time.Second,
+			end:       10 * time.Second,
+			want:      Timings{Scheduling: 2 * time.Second, ImagePull: 5 * time.Second, Probe: 3 * time.Second, Total: 10 * time.Second},
+		},
+		{
+			name: "finished before the watch saw it",
+			end:  4 * time.Second,
+			want: Timings{Scheduling: 4 * time.Second, Total: 4 * time.Second},
+		},
+		{
+			name:      "container ran longer than the probe was seen",
+			scheduled: 2 * time.Second,
+			started:   7 * time.Second,
+			end:       10 * time.Second,
+			pod:       finishedPod(4 * time.Second),
+			want:      Timings{Scheduling: 2 * time.Second, ImagePull: 4 * time.Second, Probe: 4 * time.Second, Total: 10 * time.Second},
+		},
+		{
+			name:      "container run time beyond the pull is ignored",
+			scheduled: 2 * time.Second,
+			started:   7 * time.Second,
+			end:       10 * time.Second,
+			pod:       finishedPod(9 * time.Second),
+			want:      Timings{Scheduling: 2 * time.Second, ImagePull: 5 * time.Second, Probe: 3 * time.Second, Total: 10 * time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &stopwatch{start: start}
+			if tt.scheduled > 0 {
+				s.scheduled = at(tt.scheduled)
+			}
+			if tt.started > 0 {
+				s.started = at(tt.started)
+			}
+			if got := s.timingsAt(tt.pod, at(tt.end)); got != tt.want {
+				t.Errorf("timingsAt() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStopwatchStage(t *testing.T) {
+	s := newStopwatch()
+	s.stage(k8s.PodStarted)
+	if s.scheduled.IsZero() || !s.scheduled.Equal(s.started) {
+		t.Errorf("a pod first seen started is scheduled %v, started %v; want both set and equal", s.scheduled, s.started)
+	}
+
+	s = newStopwatch()
+	s.stage(k8s.PodPulling)
+	pulling := s.scheduled
+	s.stage(k8s.PodStarted)
+	if !s.scheduled.Equal(pulling) || s.started.Before(pulling) {
+		t.Errorf("scheduled %v, started %v; want scheduled at the pull %v", s.scheduled, s.started, pulling)
+	}
+}
+
+func TestContainerRunTime(t *testing.T) {
+	running := &corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+		Name:  "main",
+		State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+	}}}}
+	sidecar := finishedPod(time.Second)
+	sidecar.Status.ContainerStatuses[0].Name = "sidecar"
+
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want time.Duration
+	}{
+		{"no pod", nil, 0},
+		{"still running", running, 0},
+		{"only other containers", sidecar, 0},
+		{"finished", finishedPod(3 * time.Second), 3 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containerRunTime(tt.pod); got != tt.want {
+				t.Errorf("containerRunTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      string
+		exitOK      bool
+		wantSuccess bool
+		wantStatus  Status
+	}{
+		{"success", "PONG\n", true, true, StatusPassed},
+		{"wrong reply", "NOAUTH Authentication required.\n", true, false, StatusFailed},
+		{"client error", "Could not connect to Redis at redis:6379: Connection refused\n", false, false, StatusFailed},
+		{"client timeout", "Could not connect to Redis at redis:6379: Connection timed out\n", false, false, StatusFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r Result
+			r.parse(Redis{}, tt.output, tt.exitOK)
+			if r.Success != tt.wantSuccess || r.Status != tt.wantStatus {
+				t.Errorf("parse() = success %v, status %q; want %v, %q", r.Success, r.Status, tt.wantSuccess, tt.wantStatus)
+			}
+			if (r.Err == nil) != tt.wantSuccess {
+				t.Errorf("parse() err = %v, want an error only on failure", r.Err)
+			}
+		})
+	}
+}
+
+func TestResultSetErr(t *testing.T) {
+	var r Result
+	r.setErr(errors.New("no PONG in response"))
+	r.setErr(nil)
+	if !r.Success || r.Status != StatusPassed || r.Err != nil {
+		t.Errorf("setErr(nil) after a failure = %+v, want a pass", r)
+	}
+}
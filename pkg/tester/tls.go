@@ -0,0 +1,130 @@
+package tester
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TLS says how a tester's client connects with TLS. Files are paths in the
+// test pod.
+type TLS struct {
+	// CAFile verifies the server certificate; empty leaves verification to
+	// the client's defaults
+	CAFile string
+	// CertFile and KeyFile are a client certificate and its key, both empty
+	// or both set
+	CertFile string
+	KeyFile  string
+	// InsecureSkipVerify connects without verifying the server certificate
+	InsecureSkipVerify bool
+}
+
+// enabled reports whether the client connects with TLS
+func (t TLS) enabled() bool {
+	return t != TLS{}
+}
+
+// TLSConnector is implemented by testers whose client can connect with TLS
+type TLSConnector interface {
+	// WithTLS returns a tester whose client connects with tls, and the
+	// environment its pods need for that
+	WithTLS(tls TLS) (Tester, []corev1.EnvVar)
+}
+
+// WithTLS implements TLSConnector. psql reads its TLS settings from the
+// environment, where parameters of the target still override them. Without
+// a CA psql encrypts the connection but cannot verify the server, as libpq
+// before 16 has no system trust store.
+func (p Postgres) WithTLS(tls TLS) (Tester, []corev1.EnvVar) {
+	mode := "require"
+	if tls.CAFile != "" && !tls.InsecureSkipVerify {
+		mode = "verify-full"
+	}
+	env := []corev1.EnvVar{{Name: "PGSSLMODE", Value: mode}}
+	if tls.CAFile != "" {
+		env = append(env, corev1.EnvVar{Name: "PGSSLROOTCERT", Value: tls.CAFile})
+	}
+	if tls.CertFile != "" {
+		env = append(env,
+			corev1.EnvVar{Name: "PGSSLCERT", Value: tls.CertFile},
+			corev1.EnvVar{Name: "PGSSLKEY", Value: tls.KeyFile})
+	}
+	return p, env
+}
+
+// WithTLS implements TLSConnector
+func (r Redis) WithTLS(tls TLS) (Tester, []corev1.EnvVar) {
+	r.tls = tls
+	return r, nil
+}
+
+// tlsArgs returns the redis-cli TLS arguments
+func (r Redis) tlsArgs() []string {
+	if !r.tls.enabled() {
+		return nil
+	}
+	args := []string{"--tls"}
+	if r.tls.CAFile != "" {
+		args = append(args, "--cacert", r.tls.CAFile)
+	}
+	if r.tls.CertFile != "" {
+		args = append(args, "--cert", r.tls.CertFile, "--key", r.tls.KeyFile)
+	}
+	if r.tls.InsecureSkipVerify {
+		args = append(args, "--insecure")
+	}
+	return args
+}
+
+// WithTLS implements TLSConnector
+func (m Mongo) WithTLS(tls TLS) (Tester, []corev1.EnvVar) {
+	m.tls = tls
+	return m, nil
+}
+
+// mongoClientPEM is where the client certificate and key are joined in the
+// pod, as the MongoDB tools read both from one file
+const mongoClientPEM = "/tmp/pocket-mongo-client.pem"
+
+// tlsArgs returns the TLS arguments of program: mongosh, or one of the
+// database tools, which still spell them the old way
+func (m Mongo) tlsArgs(program string) []string {
+	if !m.tls.enabled() {
+		return nil
+	}
+	if program == "mongosh" {
+		args := []string{"--tls"}
+		if m.tls.CAFile != "" {
+			args = append(args, "--tlsCAFile", m.tls.CAFile)
+		}
+		if m.tls.CertFile != "" {
+			args = append(args, "--tlsCertificateKeyFile", mongoClientPEM)
+		}
+		if m.tls.InsecureSkipVerify {
+			args = append(args, "--tlsAllowInvalidCertificates")
+		}
+		return args
+	}
+	args := []string{"--ssl"}
+	if m.tls.CAFile != "" {
+		args = append(args, "--sslCAFile", m.tls.CAFile)
+	}
+	if m.tls.CertFile != "" {
+		args = append(args, "--sslPEMKeyFile", mongoClientPEM)
+	}
+	if m.tls.InsecureSkipVerify {
+		args = append(args, "--tlsInsecure")
+	}
+	return args
+}
+
+// tlsPrelude returns the shell commands that prepare the client
+// certificate before program runs, or "" if there is none
+func (m Mongo) tlsPrelude() string {
+	if m.tls.CertFile == "" {
+		return ""
+	}
+	return fmt.Sprintf("(umask 077 && cat %s %s > %s) && ",
+		ShellQuote(m.tls.CertFile), ShellQuote(m.tls.KeyFile), mongoClientPEM)
+}